@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/chzyer/readline"
+	"github.com/dustin/go-humanize"
+)
+
+var arrayFormats = map[string]pb.ArrayHeader_Format{
+	".npy":         pb.ArrayHeader_NPY,
+	".npz":         pb.ArrayHeader_NPZ,
+	".safetensors": pb.ArrayHeader_SAFETENSORS,
+}
+
+// parses <FILEPATH>[:<NAME>] and returns the path and the header of
+// the array, the format is given by the file extension
+func parseArrayPath(arg string) (string, *pb.ArrayHeader, error) {
+	path, name := arg, ""
+	if idx := strings.LastIndex(arg, ":"); idx > 0 {
+		if _, found := arrayFormats[strings.ToLower(filepath.Ext(arg[:idx]))]; found {
+			path, name = arg[:idx], arg[idx+1:]
+		}
+	}
+
+	format, found := arrayFormats[strings.ToLower(filepath.Ext(path))]
+	if !found {
+		return "", nil, fmt.Errorf("unsupported file %s, use a .npy, .npz or .safetensors file", path)
+	}
+
+	return path, &pb.ArrayHeader{Format: format, Name: name, Collection: currentCollection}, nil
+}
+
+var importHandler = handler{
+	Name:        "IMPORT",
+	Mnemonic:    "IMPORT <FILEPATH>[:<NAME>] [<META FILEPATH>]",
+	Completer:   readline.PcItem("import"),
+	Parser:      regexp.MustCompile(`^(?i)(IMPORT)\s+(\S+)(?:\s+(\S+))?$`),
+	Description: "Create a record for every row of the array in the .npy, .npz or .safetensors <FILEPATH>, <NAME> selects the array of a file with many, <META FILEPATH> is a JSON lines file with the meta data of every row.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		path, header, err := parseArrayPath(args[0])
+		if err != nil {
+			return err
+		}
+
+		data, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer data.Close()
+
+		var meta *os.File
+		if args[1] != "" {
+			if meta, err = os.Open(args[1]); err != nil {
+				return err
+			}
+			defer meta.Close()
+		}
+
+		stream, err := client.Import(context.TODO())
+		if err != nil {
+			return err
+		} else if err = stream.Send(&pb.ArrayChunk{Header: header}); err != nil {
+			return err
+		}
+
+		send := func(fp *os.File, isMeta bool) error {
+			buf := make([]byte, 1024*1024)
+			for {
+				n, err := fp.Read(buf)
+				if n > 0 {
+					chunk := &pb.ArrayChunk{Data: buf[:n]}
+					if isMeta {
+						chunk = &pb.ArrayChunk{Meta: buf[:n]}
+					}
+					if err := stream.Send(chunk); err != nil {
+						return err
+					}
+				}
+
+				if err == io.EOF {
+					return nil
+				} else if err != nil {
+					return err
+				}
+			}
+		}
+
+		// the server closes the stream early if the import fails
+		if err = send(data, false); err == nil && meta != nil {
+			err = send(meta, true)
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		if resp.Records == 0 {
+			fmt.Printf("no records imported.\n")
+		} else {
+			fmt.Printf("imported %d records with ids from %d to %d.\n", resp.Records, resp.FirstId, resp.FirstId+resp.Records-1)
+		}
+
+		return nil
+	},
+}
+
+var exportHandler = handler{
+	Name:        "EXPORT",
+	Mnemonic:    "EXPORT <FILEPATH>[:<NAME>] [<META FILEPATH>]",
+	Completer:   readline.PcItem("export"),
+	Parser:      regexp.MustCompile(`^(?i)(EXPORT)\s+(\S+)(?:\s+(\S+))?$`),
+	Description: "Save the records as an array with a row per record, sorted by id, to the .npy, .npz or .safetensors <FILEPATH>, <NAME> is the name of the array, <META FILEPATH> is a JSON lines file to save the meta data of every row to.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		path, header, err := parseArrayPath(args[0])
+		if err != nil {
+			return err
+		}
+		header.Meta = args[1] != ""
+
+		stream, err := client.Export(context.TODO(), header)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer data.Close()
+
+		var meta *os.File
+		if header.Meta {
+			if meta, err = os.Create(args[1]); err != nil {
+				return err
+			}
+			defer meta.Close()
+		}
+
+		cleanup := func() {
+			os.Remove(path)
+			if meta != nil {
+				os.Remove(args[1])
+			}
+		}
+
+		total := uint64(0)
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				cleanup()
+				return err
+			}
+
+			if _, err = data.Write(chunk.Data); err == nil && meta != nil {
+				_, err = meta.Write(chunk.Meta)
+			}
+			if err != nil {
+				cleanup()
+				return err
+			}
+			total += uint64(len(chunk.Data))
+		}
+
+		fmt.Printf("array of %s saved to %s\n", humanize.Bytes(total), path)
+
+		return nil
+	},
+}
@@ -30,8 +30,9 @@ var callOracleHandler = handler{
 		}
 
 		call := pb.Call{
-			OracleId: resp.Oracle.Id,
-			Args:     str.Comma(args[0]),
+			OracleId:   resp.Oracle.Id,
+			Args:       str.Comma(args[0]),
+			Collection: currentCollection,
 		}
 		cresp, err := client.Run(context.TODO(), &call)
 		if err != nil {
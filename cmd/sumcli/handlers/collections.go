@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/chzyer/readline"
+
+	"github.com/evilsocket/islazy/tui"
+)
+
+// collection the record commands and oracle calls work on,
+// empty for the default one
+var currentCollection = ""
+
+func collectionName(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return name
+}
+
+var useCollectionHandler = handler{
+	Name:        "USE",
+	Mnemonic:    "USE [<NAME>]",
+	Completer:   readline.PcItem("use"),
+	Parser:      regexp.MustCompile(`^(?i)(USE)(?:\s+([^\s]+))?$`),
+	Description: "Use the collection <NAME> for the record commands and oracle calls, or the default collection if no <NAME> is given.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		currentCollection = args[0]
+		fmt.Printf("using the %s collection.\n", collectionName(currentCollection))
+		return nil
+	},
+}
+
+var createCollectionHandler = handler{
+	Name:        "CCREATE",
+	Mnemonic:    "CCREATE or CC <NAME>",
+	Completer:   readline.PcItem("ccreate"),
+	Parser:      regexp.MustCompile(`^(?i)(CCREATE|CC)\s+([^\s]+)$`),
+	Description: "Create a new collection of records given its <NAME>.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		resp, err := client.CreateCollection(context.TODO(), &pb.ByName{Name: args[0]})
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		fmt.Printf("collection %s successfully created.\n", args[0])
+
+		return nil
+	},
+}
+
+var dropCollectionHandler = handler{
+	Name:        "CDROP",
+	Mnemonic:    "CDROP <NAME>",
+	Completer:   readline.PcItem("cdrop"),
+	Parser:      regexp.MustCompile(`^(?i)(CDROP)\s+([^\s]+)$`),
+	Description: "Delete the collection <NAME> and all of its records.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		resp, err := client.DropCollection(context.TODO(), &pb.ByName{Name: args[0]})
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		if currentCollection == args[0] {
+			currentCollection = ""
+		}
+
+		fmt.Printf("collection %s successfully dropped.\n", args[0])
+
+		return nil
+	},
+}
+
+var listCollectionsHandler = handler{
+	Name:        "CLIST",
+	Mnemonic:    "CLIST or CL",
+	Completer:   readline.PcItem("clist"),
+	Parser:      regexp.MustCompile(`^(?i)(CLIST|CL)$`),
+	Description: "List the collections of records.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		resp, err := client.ListCollections(context.TODO(), &pb.Empty{})
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		columns := []string{
+			"name",
+			"records",
+			"next id",
+			"trashed",
+			"schema",
+		}
+		rows := [][]string{}
+
+		for _, c := range resp.Collections {
+			name := c.Name
+			if name == currentCollection {
+				name += " *"
+			}
+			rows = append(rows, []string{
+				name,
+				fmt.Sprintf("%d", c.Records),
+				fmt.Sprintf("%d", c.NextRecordId),
+				fmt.Sprintf("%d", c.Trashed),
+				schemaString(c.Schema),
+			})
+		}
+
+		tui.Table(os.Stdout, columns, rows)
+
+		return nil
+	},
+}
+
+func schemaString(schema *pb.Schema) string {
+	if schema == nil {
+		return "-"
+	}
+
+	parts := []string{}
+	if len(schema.Shape) > 0 {
+		dims := make([]string, len(schema.Shape))
+		for i, dim := range schema.Shape {
+			dims[i] = fmt.Sprintf("%d", dim)
+		}
+		parts = append(parts, "shape="+strings.Join(dims, "x"))
+	} else if schema.Dimension > 0 {
+		parts = append(parts, fmt.Sprintf("dimension=%d", schema.Dimension))
+	}
+
+	for _, key := range schema.RequiredMeta {
+		if t, found := schema.MetaTypes[key]; found {
+			key += ":" + strings.ToLower(t.String())
+		}
+		parts = append(parts, key)
+	}
+
+	if schema.Quantization != pb.QuantizedVector_FLOAT32 {
+		parts = append(parts, "quantization="+strings.ToLower(schema.Quantization.String()))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// parses a schema given its shape (128 or 2x3) and the comma separated
+// list of required meta keys, each one with an optional type (label:text)
+func parseSchema(shape string, meta string) (*pb.Schema, error) {
+	schema := &pb.Schema{Dimension: 1, MetaTypes: make(map[string]pb.Schema_Type)}
+	for _, tok := range strings.Split(strings.ToLower(shape), "x") {
+		dim, err := strconv.ParseUint(tok, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shape %s", shape)
+		}
+		schema.Shape = append(schema.Shape, dim)
+		schema.Dimension *= dim
+	}
+
+	if meta == "" {
+		return schema, nil
+	}
+
+	for _, tok := range strings.Split(meta, ",") {
+		parts := strings.SplitN(tok, ":", 2)
+		schema.RequiredMeta = append(schema.RequiredMeta, parts[0])
+		if len(parts) == 2 {
+			t, found := pb.Schema_Type_value[strings.ToUpper(parts[1])]
+			if !found {
+				return nil, fmt.Errorf("invalid type %s for meta %s", parts[1], parts[0])
+			}
+			schema.MetaTypes[parts[0]] = pb.Schema_Type(t)
+		}
+	}
+
+	return schema, nil
+}
+
+// returns the schema of the current collection, or nil
+func currentSchema(info *pb.ServerInfo) *pb.Schema {
+	schema := info.Schema
+	for _, c := range info.Collections {
+		if c.Name == currentCollection {
+			schema = c.Schema
+		}
+	}
+	return schema
+}
+
+var schemaHandler = handler{
+	Name:        "SCHEMA",
+	Mnemonic:    "SCHEMA [NONE | <SHAPE> [<META>]]",
+	Completer:   readline.PcItem("schema"),
+	Parser:      regexp.MustCompile(`^(?i)(SCHEMA)(?:\s+([^\s]+)(?:\s+([^\s]+))?)?$`),
+	Description: "Show the schema of the current collection, or set it given the <SHAPE> of the vectors (128 or 2x3) and an optional comma separated list of required <META> keys with their types (label:text,score:number), NONE removes the schema.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		if args[0] == "" {
+			info, err := client.Info(context.TODO(), &pb.Empty{})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s: %s\n", collectionName(currentCollection), schemaString(currentSchema(info)))
+			return nil
+		}
+
+		schema := &pb.Schema{}
+		if strings.ToUpper(args[0]) != "NONE" {
+			var err error
+			if schema, err = parseSchema(args[0], args[1]); err != nil {
+				return err
+			}
+		}
+
+		resp, err := client.SetSchema(context.TODO(), &pb.Collection{Name: currentCollection, Schema: schema})
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		fmt.Printf("schema of the %s collection successfully updated.\n", collectionName(currentCollection))
+
+		return nil
+	},
+}
+
+var quantizeHandler = handler{
+	Name:        "QUANTIZE",
+	Mnemonic:    "QUANTIZE <FLOAT32 | INT8 | FLOAT16>",
+	Completer:   readline.PcItem("quantize"),
+	Parser:      regexp.MustCompile(`^(?i)(QUANTIZE)\s+([^\s]+)$`),
+	Description: "Store the vectors of the new records of the current collection as int8 or float16 to save memory at the cost of precision, FLOAT32 uses the default of the nodes.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		t, found := pb.QuantizedVector_Type_value[strings.ToUpper(args[0])]
+		if !found {
+			return fmt.Errorf("invalid quantization %s", args[0])
+		}
+
+		info, err := client.Info(context.TODO(), &pb.Empty{})
+		if err != nil {
+			return err
+		}
+
+		// the rest of the schema is kept as it is
+		schema := &pb.Schema{}
+		if current := currentSchema(info); current != nil {
+			schema = current
+		}
+		schema.Quantization = pb.QuantizedVector_Type(t)
+
+		resp, err := client.SetSchema(context.TODO(), &pb.Collection{Name: currentCollection, Schema: schema})
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		fmt.Printf("new vectors of the %s collection are now stored as %s.\n", collectionName(currentCollection), strings.ToLower(args[0]))
+
+		return nil
+	},
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"regexp"
 	"strconv"
+	"strings"
 
 	pb "github.com/evilsocket/sum/proto"
 
@@ -86,6 +87,40 @@ func readMetas(reader *readline.Instance) (map[string]string, error) {
 	return meta, nil
 }
 
+func readTypedMetas(reader *readline.Instance) (map[string]*pb.MetaValue, error) {
+	meta := make(map[string]*pb.MetaValue)
+
+	for {
+		raw, err := readWithPrompt(reader, "typed meta key:text|number|time:value (blank to stop)> ")
+		if err != nil {
+			return nil, err
+		} else if raw == "" {
+			break
+		}
+
+		// times can contain colons
+		values := strings.SplitN(raw, ":", 3)
+		if len(values) != 3 {
+			return nil, fmt.Errorf("could not parse '%s' as 'key:type:value'", raw)
+		}
+
+		key := strings.TrimSpace(values[0])
+		if meta[key], err = parseMetaValue(strings.TrimSpace(values[1]), strings.TrimSpace(values[2])); err != nil {
+			return nil, err
+		}
+	}
+
+	return meta, nil
+}
+
+func readTTL(reader *readline.Instance) (uint64, error) {
+	raw, err := readWithPrompt(reader, "ttl in seconds (blank for none)> ")
+	if err != nil || raw == "" {
+		return 0, err
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
 var createRecordHandler = handler{
 	Name:        "CREATE",
 	Mnemonic:    "CREATE or C",
@@ -105,9 +140,23 @@ var createRecordHandler = handler{
 			return err
 		}
 
+		typed, err := readTypedMetas(reader)
+		if err != nil {
+			return err
+		}
+
+		ttl, err := readTTL(reader)
+		if err != nil {
+			return err
+		}
+
 		record := pb.Record{
-			Data: data,
-			Meta: meta,
+			Data:      data,
+			Meta:      meta,
+			TypedMeta: typed,
+			Ttl:       ttl,
+
+			Collection: currentCollection,
 		}
 
 		resp, err := client.CreateRecord(context.TODO(), &record)
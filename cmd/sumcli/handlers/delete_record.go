@@ -13,17 +13,24 @@ import (
 
 var deleteRecordHandler = handler{
 	Name:        "DELETE",
-	Mnemonic:    "DELETE or D <ID>",
+	Mnemonic:    "DELETE or D <ID> [VERSION]",
 	Completer:   readline.PcItem("delete"),
-	Parser:      regexp.MustCompile(`^(?i)(DELETE|D)\s+(\d+)$`),
-	Description: "Delete a vector given its <ID>.",
+	Parser:      regexp.MustCompile(`^(?i)(DELETE|D)\s+(\d+)(?:\s+(\d+))?$`),
+	Description: "Delete a vector given its <ID>, only if its version is [VERSION] when specified.",
 	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
 		id, err := strconv.ParseUint(args[0], 10, 64)
 		if err != nil {
 			return err
 		}
 
-		resp, err := client.DeleteRecord(context.TODO(), &pb.ById{Id: id})
+		version := uint64(0)
+		if len(args) > 1 && args[1] != "" {
+			if version, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+				return err
+			}
+		}
+
+		resp, err := client.DeleteRecord(context.TODO(), &pb.ById{Id: id, Version: version, Collection: currentCollection})
 		if err != nil {
 			return err
 		} else if resp.Success == false {
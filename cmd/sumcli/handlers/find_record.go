@@ -10,20 +10,28 @@ import (
 	"github.com/chzyer/readline"
 )
 
+var legacyFindParser = regexp.MustCompile(`^([^\s]+)\s+(.+)$`)
+
 var findRecordHandler = handler{
 	Name:        "FIND",
-	Mnemonic:    "FIND or F <KEY> <VALUE>",
+	Mnemonic:    "FIND or F <KEY> <VALUE> | <QUERY>",
 	Completer:   readline.PcItem("find"),
-	Parser:      regexp.MustCompile(`^(?i)(FIND|F)\s+([^\s]+)\s+(.+)$`),
-	Description: "Find a record by the <KEY> meta data with <VALUE>.",
+	Parser:      regexp.MustCompile(`^(?i)(FIND|F)\s+(.+)$`),
+	Description: "Find records by the <KEY> meta data with <VALUE>, or matching a <QUERY> (e.g. score > 0.8 AND NOT (name ^= tmp OR age BETWEEN 1 AND 10)).",
 	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
-		key := args[0]
-		value := args[1]
+		query := pb.ByMeta{Collection: currentCollection}
 
-		query := pb.ByMeta{
-			Meta:  key,
-			Value: value,
+		if tokens, err := tokenize(args[0]); err == nil && isQuery(tokens) {
+			if query.Query, err = parseQuery(tokens); err != nil {
+				return err
+			}
+		} else if parts := legacyFindParser.FindStringSubmatch(args[0]); parts != nil {
+			query.Meta = parts[1]
+			query.Value = parts[2]
+		} else {
+			return fmt.Errorf("usage: FIND <KEY> <VALUE> | <QUERY>")
 		}
+
 		resp, err := client.FindRecords(context.TODO(), &query)
 		if err != nil {
 			return err
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/chzyer/readline"
+
+	"github.com/evilsocket/islazy/tui"
+)
+
+var fsckHandler = handler{
+	Name:        "FSCK",
+	Mnemonic:    "FSCK [REBUILD]",
+	Completer:   readline.PcItem("fsck"),
+	Parser:      regexp.MustCompile(`^(?i)(FSCK)(?:\s+(REBUILD))?$`),
+	Description: "Check the meta, sorted and keys indexes of the current collection against its records, and rebuild them from the records if REBUILD is given.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		req := &pb.CheckRequest{
+			Collection: currentCollection,
+			Rebuild:    strings.ToUpper(args[0]) == "REBUILD",
+		}
+		resp, err := client.CheckIndexes(context.TODO(), req)
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		columns := []string{
+			"problem",
+			"index",
+			"record",
+			"meta",
+			"value",
+		}
+		rows := [][]string{}
+
+		problems := map[string][]*pb.IndexEntry{
+			"stale":      resp.Stale,
+			"missing":    resp.Missing,
+			"duplicated": resp.Duplicated,
+		}
+		for _, problem := range []string{"stale", "missing", "duplicated"} {
+			for _, e := range problems[problem] {
+				rows = append(rows, []string{
+					problem,
+					e.Index,
+					fmt.Sprintf("%d", e.Id),
+					e.Meta,
+					e.Value,
+				})
+			}
+		}
+
+		if len(rows) > 0 {
+			tui.Table(os.Stdout, columns, rows)
+		}
+
+		fmt.Printf("%s.\n", resp.Msg)
+		if req.Rebuild {
+			fmt.Printf("indexes of the %s collection successfully rebuilt.\n", collectionName(currentCollection))
+		}
+
+		return nil
+	},
+}
@@ -38,6 +38,20 @@ func init() {
 		deleteRecordHandler,
 		listRecordsHandler,
 		findRecordHandler,
+		readKeyHandler,
+		upsertHandler,
+		// trash
+		listTrashHandler,
+		undeleteHandler,
+		purgeTrashHandler,
+		// collections
+		useCollectionHandler,
+		createCollectionHandler,
+		dropCollectionHandler,
+		listCollectionsHandler,
+		schemaHandler,
+		quantizeHandler,
+		fsckHandler,
 		// oracles CRUD and execution
 		createOracleHandler,
 		readOracleHandler,
@@ -46,6 +60,15 @@ func init() {
 		findOracleHandler,
 		listOraclesHandler,
 		callOracleHandler,
+		// snapshots
+		snapshotHandler,
+		restoreHandler,
+		// arrays
+		importHandler,
+		exportHandler,
+		// ingestion
+		ingestHandler,
+		dumpHandler,
 		// nodes management
 		createNodeHandler,
 		listNodesHandler,
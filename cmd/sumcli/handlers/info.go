@@ -39,6 +39,10 @@ func tos(value reflect.Value) string {
 			res = append(res, tos(item))
 		}
 		fieldValue = strings.Join(res, " ")
+	case reflect.Ptr:
+		if stringer, ok := value.Interface().(fmt.Stringer); ok && !value.IsNil() {
+			fieldValue = "[" + stringer.String() + "]"
+		}
 	default:
 		fieldValue = value.String()
 	}
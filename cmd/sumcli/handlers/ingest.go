@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/evilsocket/sum/ingest"
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/chzyer/readline"
+)
+
+// maximum number of rejected rows to print
+const maxRejectedShown = 10
+
+var internalClient pb.SumInternalServiceClient
+
+// SetInternalClient sets the client used to create records in batches
+// with their identifiers.
+func SetInternalClient(client pb.SumInternalServiceClient) {
+	internalClient = client
+}
+
+func isJSONL(path string) (bool, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return false, nil
+	case ".jsonl", ".ndjson":
+		return true, nil
+	}
+	return false, fmt.Errorf("unsupported file %s, use a .csv, .jsonl or .ndjson file", path)
+}
+
+// parses name=value options, values can contain =
+func parseOptions(args string) (map[string]string, error) {
+	options := make(map[string]string)
+	for _, opt := range strings.Fields(args) {
+		parts := strings.SplitN(opt, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("could not parse option '%s'", opt)
+		}
+		options[strings.ToLower(parts[0])] = parts[1]
+	}
+	return options, nil
+}
+
+func parseMapping(options map[string]string) (ingest.Mapping, error) {
+	mapping := ingest.Mapping{}
+	for name, value := range options {
+		switch name {
+		case "id":
+			mapping.ID = value
+		case "vector":
+			mapping.Vector = strings.Split(value, ",")
+		case "meta":
+			for _, def := range strings.Split(value, ",") {
+				col, err := ingest.ParseMetaColumn(def)
+				if err != nil {
+					return mapping, err
+				}
+				mapping.Meta = append(mapping.Meta, col)
+			}
+		case "batch", "overwrite":
+		default:
+			return mapping, fmt.Errorf("unknown option %s", name)
+		}
+	}
+	return mapping, nil
+}
+
+var ingestHandler = handler{
+	Name:        "INGEST",
+	Mnemonic:    "INGEST <FILEPATH> [id=<COLUMN>] [vector=<COLUMN>,...] [meta=<COLUMN>[=<KEY>][:<TYPE>],...] [batch=<SIZE>] [overwrite=true]",
+	Completer:   readline.PcItem("ingest"),
+	Parser:      regexp.MustCompile(`^(?i)(INGEST)\s+(\S+)((?:\s+\w+=\S+)*)$`),
+	Description: "Create a record for every row of the .csv, .jsonl or .ndjson <FILEPATH>, mapping columns to the identifier, the vector components (a name ending with * matches every column starting with it, by default all the columns left for CSV and the vector field for JSON lines) and meta keys of type text, number or time, records with an identifier are rejected if it already exists, or with overwrite=true are created or overwritten <SIZE> at a time.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		isJSON, err := isJSONL(args[0])
+		if err != nil {
+			return err
+		}
+
+		options, err := parseOptions(args[1])
+		if err != nil {
+			return err
+		}
+
+		mapping, err := parseMapping(options)
+		if err != nil {
+			return err
+		}
+
+		uploader := &ingest.Uploader{
+			Client:     client,
+			Internal:   internalClient,
+			Collection: currentCollection,
+			OnProgress: func(r *ingest.Report) {
+				fmt.Printf("\r%d rows read, %d records created, %d rows rejected", r.Rows, r.Created, len(r.Rejected))
+			},
+		}
+
+		if overwrite, found := options["overwrite"]; found {
+			if uploader.Overwrite, err = strconv.ParseBool(overwrite); err != nil {
+				return fmt.Errorf("could not parse overwrite flag '%s'", overwrite)
+			}
+		}
+
+		if batch, found := options["batch"]; found {
+			if uploader.BatchSize, err = strconv.Atoi(batch); err != nil {
+				return fmt.Errorf("could not parse batch size '%s'", batch)
+			}
+		}
+
+		fp, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+
+		var rows ingest.Reader
+		if isJSON {
+			rows, err = ingest.NewJSONLReader(fp, mapping)
+		} else {
+			rows, err = ingest.NewCSVReader(fp, mapping)
+		}
+		if err != nil {
+			return err
+		}
+
+		report, err := uploader.Upload(context.TODO(), rows)
+		fmt.Printf("\r%d rows read, %d records created, %d rows rejected.\n", report.Rows, report.Created, len(report.Rejected))
+
+		for i, rejected := range report.Rejected {
+			if i == maxRejectedShown {
+				fmt.Printf("  ... and %d more\n", len(report.Rejected)-maxRejectedShown)
+				break
+			}
+			fmt.Printf("  %s\n", rejected)
+		}
+
+		return err
+	},
+}
+
+var dumpHandler = handler{
+	Name:        "DUMP",
+	Mnemonic:    "DUMP <FILEPATH> [<META KEY>,...]",
+	Completer:   readline.PcItem("dump"),
+	Parser:      regexp.MustCompile(`^(?i)(DUMP)\s+(\S+)(?:\s+(\S+))?$`),
+	Description: "Save the records to the .csv, .jsonl or .ndjson <FILEPATH> with their identifier, vector and the given meta keys.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		isJSON, err := isJSONL(args[0])
+		if err != nil {
+			return err
+		}
+
+		meta := []string{}
+		if args[1] != "" {
+			meta = strings.Split(args[1], ",")
+		}
+
+		fp, err := os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+
+		var w ingest.Writer
+		if isJSON {
+			if w, err = ingest.NewJSONLWriter(fp, meta); err != nil {
+				os.Remove(args[0])
+				return err
+			}
+		} else {
+			w = ingest.NewCSVWriter(fp, meta)
+		}
+
+		done, err := ingest.Dump(context.TODO(), client, currentCollection, ingest.DefaultBatchSize, w, func(done, total uint64) {
+			fmt.Printf("\r%d/%d records saved", done, total)
+		})
+		if err != nil {
+			fmt.Println()
+			os.Remove(args[0])
+			return err
+		}
+
+		fmt.Printf("\r%d records saved to %s\n", done, args[0])
+
+		return nil
+	},
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/chzyer/readline"
+)
+
+var readKeyHandler = handler{
+	Name:        "READKEY",
+	Mnemonic:    "READKEY or RK <KEY>",
+	Completer:   readline.PcItem("readkey"),
+	Parser:      regexp.MustCompile(`^(?i)(READKEY|RK)\s+(\S+)$`),
+	Description: "Read the data and metadata of a record given its unique <KEY>.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		resp, err := client.ReadRecordByKey(context.TODO(), &pb.ByKey{Key: args[0], Collection: currentCollection})
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		showRecord(resp.Record, 0)
+
+		return nil
+	},
+}
+
+var upsertHandler = handler{
+	Name:        "UPSERT",
+	Mnemonic:    "UPSERT <KEY>",
+	Completer:   readline.PcItem("upsert"),
+	Parser:      regexp.MustCompile(`^(?i)(UPSERT)\s+(\S+)$`),
+	Description: "Update the record with the unique <KEY> with specified elements and metadata, or create it if it does not exist.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		data, err := readData(reader)
+		if err != nil {
+			return err
+		} else if data == nil {
+			return nil
+		}
+
+		meta, err := readMetas(reader)
+		if err != nil {
+			return err
+		}
+
+		typed, err := readTypedMetas(reader)
+		if err != nil {
+			return err
+		}
+
+		record := pb.Record{
+			Key:       args[0],
+			Data:      data,
+			Meta:      meta,
+			TypedMeta: typed,
+
+			Collection: currentCollection,
+		}
+
+		resp, err := client.UpsertRecord(context.TODO(), &record)
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%v", resp.Msg)
+		}
+
+		fmt.Printf("record %s with key %s successfully stored.\n", resp.Msg, args[0])
+
+		return nil
+	},
+}
@@ -31,8 +31,9 @@ var listRecordsHandler = handler{
 		}
 
 		req := pb.ListRequest{
-			Page:    page,
-			PerPage: per_page,
+			Page:       page,
+			PerPage:    per_page,
+			Collection: currentCollection,
 		}
 		resp, err := client.ListRecords(context.TODO(), &req)
 		if err != nil {
@@ -50,8 +51,8 @@ var listRecordsHandler = handler{
 		for _, r := range resp.Records {
 			row := []string{
 				fmt.Sprintf("%d", r.Id),
-				fmt.Sprintf("%d", len(r.Data)),
-				dataAsString(r.Data, 10),
+				fmt.Sprintf("%d", vectorSize(r)),
+				vectorAsString(r, 10),
 				metaAsString(r.Meta),
 			}
 			rows = append(rows, row)
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// parseMetaValue parses a typed meta value, kind can be text, number or
+// time, times are either RFC3339 strings or unix times in nanoseconds.
+func parseMetaValue(kind, raw string) (*pb.MetaValue, error) {
+	switch strings.ToLower(kind) {
+	case "text", "t":
+		return &pb.MetaValue{Value: &pb.MetaValue_Text{Text: raw}}, nil
+	case "number", "n":
+		num, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse '%s' as a number", raw)
+		}
+		return &pb.MetaValue{Value: &pb.MetaValue_Number{Number: num}}, nil
+	case "time":
+		if ns, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return &pb.MetaValue{Value: &pb.MetaValue_Time{Time: ns}}, nil
+		} else if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return &pb.MetaValue{Value: &pb.MetaValue_Time{Time: t.UnixNano()}}, nil
+		}
+		return nil, fmt.Errorf("could not parse '%s' as a RFC3339 or unix nanoseconds time", raw)
+	}
+	return nil, fmt.Errorf("unknown meta type '%s', use text, number or time", kind)
+}
+
+func metaValueAsString(v *pb.MetaValue) string {
+	switch x := v.GetValue().(type) {
+	case *pb.MetaValue_Text:
+		return strconv.Quote(x.Text)
+	case *pb.MetaValue_Number:
+		return strconv.FormatFloat(x.Number, 'g', -1, 64)
+	case *pb.MetaValue_Time:
+		return time.Unix(0, x.Time).Format(time.RFC3339)
+	}
+	return "null"
+}
+
+func typedMetaAsString(meta map[string]*pb.MetaValue) string {
+	keys := []string{}
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{}
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", key, metaValueAsString(meta[key])))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// query syntax:
+//
+//   expr      := term { OR term }
+//   term      := factor { AND factor }
+//   factor    := NOT factor | '(' expr ')' | predicate
+//   predicate := KEY op VALUE | KEY BETWEEN VALUE AND VALUE
+//   op        := = | == | != | < | <= | > | >= | ^=
+//
+// values are numbers if they can be parsed as such, times if they are
+// RFC3339 strings and text otherwise, quoted values are always text and
+// the type can be forced with the text:, number: and time: prefixes.
+
+var filterOps = map[string]pb.MetaFilter_Op{
+	"=":  pb.MetaFilter_EQ,
+	"==": pb.MetaFilter_EQ,
+	"<":  pb.MetaFilter_LT,
+	"<=": pb.MetaFilter_LTE,
+	">":  pb.MetaFilter_GT,
+	">=": pb.MetaFilter_GTE,
+	"^=": pb.MetaFilter_PREFIX,
+}
+
+type token struct {
+	value  string
+	quoted bool
+}
+
+func (t token) is(keyword string) bool {
+	return !t.quoted && strings.EqualFold(t.value, keyword)
+}
+
+func (t token) isOperator() bool {
+	if t.quoted {
+		return false
+	}
+	_, found := filterOps[t.value]
+	return found || t.value == "!=" || t.is("BETWEEN")
+}
+
+func tokenize(expr string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, token{value: string(c)})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(runes) && runes[end] != '"'; end++ {
+				if runes[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			value, err := strconv.Unquote(string(runes[i : end+1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %s", i, err)
+			}
+			tokens = append(tokens, token{value: value, quoted: true})
+			i = end + 1
+		case strings.ContainsRune("=!<>^", c):
+			end := i + 1
+			if end < len(runes) && runes[end] == '=' {
+				end++
+			}
+			tokens = append(tokens, token{value: string(runes[i:end])})
+			i = end
+		default:
+			end := i
+			for ; end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("()\"=!<>^", runes[end]); end++ {
+			}
+			tokens = append(tokens, token{value: string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// isQuery returns true if the tokens are a query expression rather
+// than the legacy <KEY> <VALUE> form.
+func isQuery(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	} else if tokens[0].value == "(" || tokens[0].is("NOT") {
+		return true
+	}
+	return len(tokens) > 1 && tokens[1].isOperator()
+}
+
+func parseLiteral(t token) (*pb.MetaValue, error) {
+	if t.quoted {
+		return &pb.MetaValue{Value: &pb.MetaValue_Text{Text: t.value}}, nil
+	}
+
+	for _, kind := range []string{"text", "number", "time"} {
+		if strings.HasPrefix(t.value, kind+":") {
+			return parseMetaValue(kind, t.value[len(kind)+1:])
+		}
+	}
+
+	if num, err := strconv.ParseFloat(t.value, 64); err == nil {
+		return &pb.MetaValue{Value: &pb.MetaValue_Number{Number: num}}, nil
+	} else if tm, err := time.Parse(time.RFC3339, t.value); err == nil {
+		return &pb.MetaValue{Value: &pb.MetaValue_Time{Time: tm.UnixNano()}}, nil
+	}
+	return &pb.MetaValue{Value: &pb.MetaValue_Text{Text: t.value}}, nil
+}
+
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *queryParser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *queryParser) next() (token, error) {
+	if t := p.peek(); t != nil {
+		p.pos++
+		return *t, nil
+	}
+	return token{}, fmt.Errorf("unexpected end of query")
+}
+
+func (p *queryParser) value() (*pb.MetaValue, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	} else if !t.quoted && (t.value == "(" || t.value == ")" || t.isOperator()) {
+		return nil, fmt.Errorf("value expected, found '%s'", t.value)
+	}
+	return parseLiteral(t)
+}
+
+func filterQuery(f *pb.MetaFilter) *pb.MetaQuery {
+	return &pb.MetaQuery{Op: pb.MetaQuery_FILTER, Filter: f}
+}
+
+func (p *queryParser) predicate() (*pb.MetaQuery, error) {
+	key, err := p.next()
+	if err != nil {
+		return nil, err
+	} else if !key.quoted && (key.value == "(" || key.value == ")" || key.isOperator()) {
+		return nil, fmt.Errorf("meta name expected, found '%s'", key.value)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	} else if !op.isOperator() {
+		return nil, fmt.Errorf("operator expected after '%s', found '%s'", key.value, op.value)
+	}
+
+	f := &pb.MetaFilter{Meta: key.value}
+	if f.Value, err = p.value(); err != nil {
+		return nil, err
+	}
+
+	if op.is("BETWEEN") {
+		f.Op = pb.MetaFilter_BETWEEN
+		if and, err := p.next(); err != nil {
+			return nil, err
+		} else if !and.is("AND") {
+			return nil, fmt.Errorf("AND expected after BETWEEN, found '%s'", and.value)
+		} else if f.To, err = p.value(); err != nil {
+			return nil, err
+		}
+	} else if op.value == "!=" {
+		f.Op = pb.MetaFilter_EQ
+		return &pb.MetaQuery{Op: pb.MetaQuery_NOT, Operands: []*pb.MetaQuery{filterQuery(f)}}, nil
+	} else {
+		f.Op = filterOps[op.value]
+	}
+
+	return filterQuery(f), nil
+}
+
+func (p *queryParser) factor() (*pb.MetaQuery, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of query")
+	} else if t.is("NOT") {
+		p.pos++
+		operand, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return &pb.MetaQuery{Op: pb.MetaQuery_NOT, Operands: []*pb.MetaQuery{operand}}, nil
+	} else if !t.quoted && t.value == "(" {
+		p.pos++
+		q, err := p.expr()
+		if err != nil {
+			return nil, err
+		} else if closed, err := p.next(); err != nil || closed.quoted || closed.value != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return q, nil
+	}
+	return p.predicate()
+}
+
+// parses a sequence of operands separated by the given keyword
+func (p *queryParser) sequence(keyword string, op pb.MetaQuery_Op, operand func() (*pb.MetaQuery, error)) (*pb.MetaQuery, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	q := &pb.MetaQuery{Op: op, Operands: []*pb.MetaQuery{first}}
+	for t := p.peek(); t != nil && t.is(keyword); t = p.peek() {
+		p.pos++
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		q.Operands = append(q.Operands, next)
+	}
+
+	if len(q.Operands) == 1 {
+		return first, nil
+	}
+	return q, nil
+}
+
+func (p *queryParser) term() (*pb.MetaQuery, error) {
+	return p.sequence("AND", pb.MetaQuery_AND, p.factor)
+}
+
+func (p *queryParser) expr() (*pb.MetaQuery, error) {
+	return p.sequence("OR", pb.MetaQuery_OR, p.term)
+}
+
+func parseQuery(tokens []token) (*pb.MetaQuery, error) {
+	p := &queryParser{tokens: tokens}
+	q, err := p.expr()
+	if err != nil {
+		return nil, err
+	} else if t := p.peek(); t != nil {
+		return nil, fmt.Errorf("unexpected '%s' at the end of the query", t.value)
+	}
+	return q, nil
+}
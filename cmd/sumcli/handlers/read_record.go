@@ -7,7 +7,9 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/evilsocket/sum/node/backend"
 	pb "github.com/evilsocket/sum/proto"
 
 	"github.com/chzyer/readline"
@@ -43,6 +45,39 @@ func dataAsString(data []float32, limit int) string {
 	return s
 }
 
+// shows the non zero elements of sparse vectors as index:value
+func vectorAsString(rec *pb.Record, limit int) string {
+	if q := rec.Quantized; q != nil {
+		return dataAsString(backend.NewQuantized(backend.Quantization(q.Type), int(q.Size), q.Data, q.Scale, q.Offset).Dense(), limit)
+	} else if rec.Sparse == nil {
+		return dataAsString(rec.Data, limit)
+	}
+
+	tot := len(rec.Sparse.Indices)
+	num := tot
+	if limit > 0 && limit < tot {
+		num = limit
+	}
+	strs := make([]string, num)
+	for i := 0; i < num; i++ {
+		strs[i] = fmt.Sprintf("%d:%g", rec.Sparse.Indices[i], rec.Sparse.Values[i])
+	}
+	s := strings.Join(strs, ",")
+	if num < tot {
+		s += " ..."
+	}
+	return s
+}
+
+func vectorSize(rec *pb.Record) uint64 {
+	if rec.Sparse != nil {
+		return rec.Sparse.Size
+	} else if rec.Quantized != nil {
+		return rec.Quantized.Size
+	}
+	return uint64(len(rec.Data))
+}
+
 func metaAsString(meta map[string]string) string {
 	keys := []string{}
 	for key, _ := range meta {
@@ -59,9 +94,25 @@ func metaAsString(meta map[string]string) string {
 }
 
 func showRecord(rec *pb.Record, dataLimit int) {
-	fmt.Printf("id   : %d\n", rec.Id)
-	fmt.Printf("data : %s\n", dataAsString(rec.Data, dataLimit))
-	fmt.Printf("meta : %s\n", metaAsString(rec.Meta))
+	fmt.Printf("id      : %d\n", rec.Id)
+	if rec.Key != "" {
+		fmt.Printf("key     : %s\n", rec.Key)
+	}
+	fmt.Printf("version : %d (%s)\n", rec.Version, time.Unix(0, rec.UpdatedAt).Format(time.RFC3339))
+	if rec.ExpiresAt != 0 {
+		fmt.Printf("expires : %s\n", time.Unix(0, rec.ExpiresAt).Format(time.RFC3339))
+	}
+	if rec.Sparse != nil {
+		fmt.Printf("sparse  : %d elements, %d non zero\n", rec.Sparse.Size, len(rec.Sparse.Indices))
+	}
+	if rec.Quantized != nil {
+		fmt.Printf("quant.  : %s, %d elements in %d bytes\n", strings.ToLower(rec.Quantized.Type.String()), rec.Quantized.Size, len(rec.Quantized.Data))
+	}
+	fmt.Printf("data    : %s\n", vectorAsString(rec, dataLimit))
+	fmt.Printf("meta    : %s\n", metaAsString(rec.Meta))
+	if len(rec.TypedMeta) > 0 {
+		fmt.Printf("typed   : %s\n", typedMetaAsString(rec.TypedMeta))
+	}
 }
 
 var readRecordHandler = handler{
@@ -76,7 +127,7 @@ var readRecordHandler = handler{
 			return err
 		}
 
-		resp, err := client.ReadRecord(context.TODO(), &pb.ById{Id: id})
+		resp, err := client.ReadRecord(context.TODO(), &pb.ById{Id: id, Collection: currentCollection})
 		if err != nil {
 			return err
 		} else if resp.Success == false {
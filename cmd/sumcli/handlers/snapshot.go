@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/chzyer/readline"
+	"github.com/dustin/go-humanize"
+)
+
+var snapshotHandler = handler{
+	Name:        "SNAPSHOT",
+	Mnemonic:    "SNAPSHOT <FILEPATH>",
+	Completer:   readline.PcItem("snapshot"),
+	Parser:      regexp.MustCompile(`^(?i)(SNAPSHOT)\s+(.+)$`),
+	Description: "Save a consistent snapshot of the collections and oracles of the node to <FILEPATH>.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		path := args[0]
+		stream, err := client.Snapshot(context.TODO(), &pb.Empty{})
+		if err != nil {
+			return err
+		}
+
+		fp, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+
+		total := uint64(0)
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				os.Remove(path)
+				return err
+			} else if _, err = fp.Write(chunk.Data); err != nil {
+				return err
+			}
+			total += uint64(len(chunk.Data))
+		}
+
+		fmt.Printf("snapshot of %s saved to %s\n", humanize.Bytes(total), path)
+
+		return nil
+	},
+}
+
+var restoreHandler = handler{
+	Name:        "RESTORE",
+	Mnemonic:    "RESTORE <FILEPATH>",
+	Completer:   readline.PcItem("restore"),
+	Parser:      regexp.MustCompile(`^(?i)(RESTORE)\s+(.+)$`),
+	Description: "Replace the collections and oracles of the node with the ones of the snapshot in <FILEPATH>.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		path := args[0]
+		fp, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+
+		stream, err := client.Restore(context.TODO())
+		if err != nil {
+			return err
+		}
+
+		buf := make([]byte, 1024*1024)
+		for {
+			n, err := fp.Read(buf)
+			if n > 0 {
+				if err := stream.Send(&pb.SnapshotChunk{Data: buf[:n]}); err != nil {
+					return err
+				}
+			}
+
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+		}
+
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		fmt.Printf("restored %d records, %d oracles and %d collections, next record id is %d.\n",
+			resp.Header.Records,
+			resp.Header.Oracles,
+			resp.Header.Collections,
+			resp.Header.NextRecordId)
+
+		return nil
+	},
+}
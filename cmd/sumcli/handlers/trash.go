@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/chzyer/readline"
+
+	"github.com/evilsocket/islazy/tui"
+)
+
+func parseIds(list string) ([]uint64, error) {
+	ids := make([]uint64, 0)
+	for _, tok := range strings.Split(list, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(tok), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+var listTrashHandler = handler{
+	Name:        "TRASH",
+	Mnemonic:    "TRASH <PAGE> <PER PAGE>",
+	Completer:   readline.PcItem("trash"),
+	Parser:      regexp.MustCompile(`^(?i)(TRASH)\s+(\d+)\s+(\d+)$`),
+	Description: "Show the deleted records still in the trash at <PAGE> while including <PER PAGE> elements per page.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		page, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		per_page, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		req := pb.ListRequest{
+			Page:       page,
+			PerPage:    per_page,
+			Collection: currentCollection,
+		}
+		resp, err := client.ListTrash(context.TODO(), &req)
+		if err != nil {
+			return err
+		}
+
+		columns := []string{
+			"id",
+			"deleted",
+			"size",
+			"data",
+			"meta",
+		}
+		rows := [][]string{}
+
+		for _, r := range resp.Records {
+			row := []string{
+				fmt.Sprintf("%d", r.Id),
+				time.Unix(0, r.DeletedAt).Format(time.RFC3339),
+				fmt.Sprintf("%d", vectorSize(r)),
+				vectorAsString(r, 10),
+				metaAsString(r.Meta),
+			}
+			rows = append(rows, row)
+		}
+
+		tui.Table(os.Stdout, columns, rows)
+
+		fmt.Printf("[page %d of %d (%d total records)]\n", page, resp.Pages, resp.Total)
+
+		return nil
+	},
+}
+
+var undeleteHandler = handler{
+	Name:        "UNDELETE",
+	Mnemonic:    "UNDELETE or UNDEL <ID>[,<ID>...]",
+	Completer:   readline.PcItem("undelete"),
+	Parser:      regexp.MustCompile(`^(?i)(UNDELETE|UNDEL)\s+([\d,\s]+)$`),
+	Description: "Move the deleted records with the given <ID>s back from the trash.",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		ids, err := parseIds(args[0])
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Undelete(context.TODO(), &pb.RecordIds{Ids: ids, Collection: currentCollection})
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		fmt.Printf("%s records successfully restored.\n", resp.Msg)
+
+		return nil
+	},
+}
+
+var purgeTrashHandler = handler{
+	Name:        "PURGE",
+	Mnemonic:    "PURGE ALL | <ID>[,<ID>...] | <AGE>",
+	Completer:   readline.PcItem("purge"),
+	Parser:      regexp.MustCompile(`^(?i)(PURGE)\s+(.+)$`),
+	Description: "Permanently remove from the trash all the deleted records, the ones with the given <ID>s or the ones deleted more than <AGE> ago (like 1h or 30m).",
+	Callback: func(cmd string, args []string, reader *readline.Instance, client pb.SumServiceClient) error {
+		req := &pb.PurgeRequest{Collection: currentCollection}
+		arg := strings.TrimSpace(args[0])
+
+		if strings.ToUpper(arg) != "ALL" {
+			if age, err := time.ParseDuration(arg); err == nil {
+				req.Before = time.Now().Add(-age).UnixNano()
+			} else if req.Ids, err = parseIds(arg); err != nil {
+				return fmt.Errorf("expected ALL, a list of ids or an age, got %s", arg)
+			}
+		}
+
+		resp, err := client.PurgeTrash(context.TODO(), req)
+		if err != nil {
+			return err
+		} else if resp.Success == false {
+			return fmt.Errorf("%s", resp.Msg)
+		}
+
+		fmt.Printf("%s records permanently removed.\n", resp.Msg)
+
+		return nil
+	},
+}
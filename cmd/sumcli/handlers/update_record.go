@@ -23,7 +23,7 @@ var updateRecordHandler = handler{
 			return err
 		}
 
-		resp, err := client.ReadRecord(context.TODO(), &pb.ById{Id: id})
+		resp, err := client.ReadRecord(context.TODO(), &pb.ById{Id: id, Collection: currentCollection})
 		if err != nil {
 			return err
 		} else if resp.Success == false {
@@ -48,20 +48,33 @@ var updateRecordHandler = handler{
 			return err
 		}
 
+		typed, err := readTypedMetas(reader)
+		if err != nil {
+			return err
+		}
+
+		// fail if somebody else updated the record in the meantime
 		record := pb.Record{
-			Id:   id,
-			Data: data,
-			Meta: meta,
+			Id:        id,
+			Data:      data,
+			Meta:      meta,
+			TypedMeta: typed,
+			Version:   resp.Record.Version,
+
+			Collection: currentCollection,
 		}
 
 		resp, err = client.UpdateRecord(context.TODO(), &record)
 		if err != nil {
 			return err
 		} else if resp.Success == false {
+			if resp.Record != nil {
+				return fmt.Errorf("%v: record %d has been updated to version %d in the meantime", resp.Msg, id, resp.Record.Version)
+			}
 			return fmt.Errorf("%v", resp.Msg)
 		}
 
-		fmt.Printf("record %d successfully updated.\n", id)
+		fmt.Printf("record %d successfully updated to version %s.\n", id, resp.Msg)
 
 		return nil
 	},
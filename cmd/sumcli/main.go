@@ -64,6 +64,7 @@ func main() {
 
 	client := pb.NewSumServiceClient(conn)
 	masterClient := pb.NewSumMasterServiceClient(conn)
+	handlers.SetInternalClient(pb.NewSumInternalServiceClient(conn))
 	reader, err := readline.NewEx(&readline.Config{
 		Prompt:          fmt.Sprintf("sumd@%s %s", *serverAddress, prompt),
 		HistoryFile:     history,
@@ -9,11 +9,14 @@ import (
 	"path"
 	"runtime"
 	"runtime/pprof"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/evilsocket/sum/master"
+	"github.com/evilsocket/sum/node/backend"
 	node "github.com/evilsocket/sum/node/service"
+	"github.com/evilsocket/sum/node/storage"
 
 	pb "github.com/evilsocket/sum/proto"
 
@@ -35,6 +38,18 @@ var (
 	maxMsgSize   = flag.Int("max-msg-size", 10*1024*1024, "Maximum size in bytes of a GRPC message.")
 	logFile      = flag.String("log-file", "", "If filled, sumd will log to this file.")
 	logDebug     = flag.Bool("debug", false, "Enable debug logs.")
+	segmentSize  = flag.Int64("segment-size", storage.DefaultSegmentSize, "Size in bytes after which a new data segment is started.")
+	compaction   = flag.Float64("compaction-ratio", storage.DefaultCompactionRatio, "Ratio of garbage in the data segments that triggers a compaction.")
+	arena        = flag.Bool("arena", false, "Keep the vectors of the records in a contiguous memory mapped arena.")
+	arenaChunk   = flag.Int64("arena-chunk-size", storage.DefaultArenaChunkSize, "Size in bytes of every memory mapped chunk of the arena.")
+	reapPeriod   = flag.Duration("reap-period", 10*time.Second, "Period to delete expired records.")
+	cacheBudget  = flag.Int64("cache-budget", 0, "If greater than 0, only keep this amount of bytes of record vectors in memory and page in the rest from disk.")
+	loadWorkers  = flag.Int("load-workers", 0, "Number of goroutines used to load the data at startup, 0 to use one per CPU.")
+	feedSize     = flag.Int("feed-size", storage.DefaultFeedSize, "Number of recent changes kept in memory for watchers to resume from.")
+	trashTime    = flag.Duration("trash-retention", storage.DefaultTrashRetention, "Time deleted records are kept in the trash before being purged, 0 to delete them permanently.")
+	quantization = flag.String("quantization", "float32", "How the vectors of new records are stored when the schema of their collection does not set it, either float32, int8 or float16.")
+	backendName  = flag.String("backend", "", "Pin the backend of the vector operations, one of: "+strings.Join(backend.Available(), ", ")+".")
+	autoBackend  = flag.Bool("auto-backend", false, "Measure the available backends at startup with the most common size of the loaded vectors and use the fastest one, ignored if -backend is set.")
 
 	// master
 	masterCfgFile = flag.String("master", "", "Load sum master configuration and become the master.")
@@ -100,6 +115,8 @@ func main() {
 	if *masterCfgFile != "" {
 		master.SetCommunicationTimeout(*timeout)
 		master.SetMaxMsgSize(*maxMsgSize)
+		storage.SetFeedSize(*feedSize)
+		storage.SetTrashRetention(*trashTime)
 
 		if masterSvc, err = master.NewServiceFromConfig(*masterCfgFile, *credsPath, *listenString); err != nil {
 			log.Fatal("cannot start master service: %v", err)
@@ -114,11 +131,43 @@ func main() {
 
 		go master.NodeUpdater(ctx, masterSvc, *pollPeriod)
 	} else {
+		storage.SetSegmentSize(*segmentSize)
+		storage.SetCompactionRatio(*compaction)
+		storage.EnableArena(*arena)
+		storage.SetArenaChunkSize(*arenaChunk)
+		storage.SetCacheBudget(*cacheBudget)
+		storage.SetLoadWorkers(*loadWorkers)
+		storage.SetFeedSize(*feedSize)
+		storage.SetTrashRetention(*trashTime)
+
+		if q, err := storage.ParseQuantization(*quantization); err != nil {
+			log.Fatal("%v", err)
+		} else {
+			storage.SetQuantization(q)
+		}
+
+		if *backendName != "" {
+			if _, err := backend.Get(*backendName); err != nil {
+				log.Fatal("%v", err)
+			}
+			backend.Select(*backendName)
+		}
+
 		if nodeSvc, err = node.New(*dataPath, *credsPath, *listenString); err != nil {
 			log.Fatal("%v", err)
 		}
+
+		if *backendName == "" && *autoBackend {
+			log.Info("measuring the available backends ...")
+			nodeSvc.SelectFastestBackend(backend.DefaultBenchmarkTime)
+		}
 		pb.RegisterSumInternalServiceServer(server, nodeSvc)
 		pb.RegisterSumServiceServer(server, nodeSvc)
+
+		ctx, cf := context.WithCancel(context.Background())
+		defer cf()
+
+		go node.Reaper(ctx, nodeSvc, *reapPeriod)
 	}
 
 	go statsReport()
@@ -0,0 +1,135 @@
+package ingest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// CSVReader maps the rows of a CSV file with a header to records.
+type CSVReader struct {
+	reader  *csv.Reader
+	columns []string
+	id      int
+	vector  []int
+	meta    []int
+	mapping Mapping
+	row     int
+}
+
+// NewCSVReader reads the header of a CSV file and resolves the columns of
+// the mapping, if no vector columns are given every column that is not the
+// identifier or meta data is a component of the vector.
+func NewCSVReader(r io.Reader, mapping Mapping) (*CSVReader, error) {
+	reader := csv.NewReader(r)
+	// rows with a wrong number of columns are rejected instead
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error while reading the CSV header: %s", err)
+	}
+
+	c := &CSVReader{
+		reader:  reader,
+		columns: append([]string(nil), header...),
+		id:      -1,
+		mapping: mapping,
+	}
+
+	index := make(map[string]int, len(header))
+	for i, col := range header {
+		index[col] = i
+	}
+
+	lookup := func(col string) (int, error) {
+		if i, found := index[col]; found {
+			return i, nil
+		}
+		return -1, fmt.Errorf("column %s not found", col)
+	}
+
+	used := make(map[int]bool)
+	if mapping.ID != "" {
+		if c.id, err = lookup(mapping.ID); err != nil {
+			return nil, err
+		}
+		used[c.id] = true
+	}
+
+	for _, m := range mapping.Meta {
+		i, err := lookup(m.Column)
+		if err != nil {
+			return nil, err
+		}
+		c.meta = append(c.meta, i)
+		used[i] = true
+	}
+
+	if len(mapping.Vector) == 0 {
+		for i := range header {
+			if !used[i] {
+				c.vector = append(c.vector, i)
+			}
+		}
+	} else {
+		columns, err := mapping.vectorColumns(header)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range columns {
+			i, err := lookup(col)
+			if err != nil {
+				return nil, err
+			}
+			c.vector = append(c.vector, i)
+		}
+	}
+
+	if len(c.vector) == 0 {
+		return nil, fmt.Errorf("no columns for the vector")
+	}
+	return c, nil
+}
+
+// Next returns the record mapped from the next row of the file.
+func (c *CSVReader) Next() (*pb.Record, error) {
+	fields, err := c.reader.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+
+	c.row++
+	if err != nil {
+		// the reader can go on after a malformed row
+		if _, ok := err.(*csv.ParseError); ok {
+			return nil, &RowError{Row: c.row, Err: err}
+		}
+		return nil, err
+	} else if len(fields) != len(c.columns) {
+		return nil, &RowError{Row: c.row, Err: fmt.Errorf("expected %d columns, got %d", len(c.columns), len(fields))}
+	}
+
+	rec := &pb.Record{Data: make([]float32, len(c.vector))}
+	if c.id >= 0 {
+		if rec.Id, err = parseID(c.columns[c.id], fields[c.id]); err != nil {
+			return nil, &RowError{Row: c.row, Err: err}
+		}
+	}
+
+	for i, col := range c.vector {
+		if rec.Data[i], err = parseComponent(c.columns[col], fields[col]); err != nil {
+			return nil, &RowError{Row: c.row, Err: err}
+		}
+	}
+
+	for i, col := range c.meta {
+		if err := c.mapping.Meta[i].set(rec, fields[col]); err != nil {
+			return nil, &RowError{Row: c.row, Err: err}
+		}
+	}
+
+	return rec, nil
+}
@@ -0,0 +1,90 @@
+package ingest
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+const testCSV = `id,label,score,v0,v1,v2
+1,cat,0.5,1,2,3
+2,dog,nope,4,5,6
+3,"fish, red",1,7,8
+0,bird,2,7,8,9
+4,cow,3,10,11,x
+5,"ant",4,13,14,15
+`
+
+func readAll(t *testing.T, r Reader) ([]*pb.Record, []*RowError) {
+	records := make([]*pb.Record, 0)
+	rejected := make([]*RowError, 0)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return records, rejected
+		} else if rowErr, ok := err.(*RowError); ok {
+			rejected = append(rejected, rowErr)
+		} else if err != nil {
+			t.Fatal(err)
+		} else {
+			records = append(records, rec)
+		}
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	mapping := Mapping{
+		ID:     "id",
+		Vector: []string{"v*"},
+		Meta:   []MetaColumn{{Column: "label"}, {Column: "score", Type: pb.Schema_NUMBER}},
+	}
+
+	r, err := NewCSVReader(strings.NewReader(testCSV), mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, rejected := readAll(t, r)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	} else if records[0].Id != 1 || !reflect.DeepEqual(records[0].Data, []float32{1, 2, 3}) {
+		t.Fatalf("unexpected record %v", records[0])
+	} else if records[0].Meta["label"] != "cat" || records[0].TypedMeta["score"].GetNumber() != 0.5 {
+		t.Fatalf("unexpected meta %v", records[0])
+	} else if records[1].Id != 5 || records[1].Meta["label"] != "ant" {
+		t.Fatalf("unexpected record %v", records[1])
+	}
+
+	rows := []int{}
+	for _, r := range rejected {
+		rows = append(rows, r.Row)
+	}
+	if !reflect.DeepEqual(rows, []int{2, 3, 4, 5}) {
+		t.Fatalf("unexpected rejected rows %v", rows)
+	}
+}
+
+func TestCSVReaderDefaultVector(t *testing.T) {
+	r, err := NewCSVReader(strings.NewReader("a,name,b\n1,x,2\n"), Mapping{Meta: []MetaColumn{{Column: "name"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, rejected := readAll(t, r)
+	if len(rejected) > 0 {
+		t.Fatal(rejected[0])
+	} else if records[0].Id != 0 || !reflect.DeepEqual(records[0].Data, []float32{1, 2}) {
+		t.Fatalf("unexpected record %v", records[0])
+	}
+
+	if _, err := NewCSVReader(strings.NewReader("a,b\n"), Mapping{ID: "id"}); err == nil {
+		t.Fatal("expected error for missing id column")
+	} else if _, err := NewCSVReader(strings.NewReader("a,b\n"), Mapping{Vector: []string{"v*"}}); err == nil {
+		t.Fatal("expected error for no matching vector columns")
+	} else if _, err := NewCSVReader(strings.NewReader("id\n"), Mapping{ID: "id"}); err == nil {
+		t.Fatal("expected error for no vector columns")
+	}
+}
@@ -0,0 +1,7 @@
+/*
+Package ingest provides readers that map the rows of CSV and JSON lines
+files to records, an uploader that creates them in batches reporting the
+rows that have been rejected, and writers to dump records to the same
+formats.
+*/
+package ingest
@@ -0,0 +1,149 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// DefaultVectorField is the field of the JSON objects with the vector if
+// the mapping does not specify one.
+const DefaultVectorField = "vector"
+
+// maximum size of a line of a JSON lines file
+const maxLineSize = 64 * 1024 * 1024
+
+// JSONLReader maps the objects of a JSON lines file to records.
+type JSONLReader struct {
+	scanner *bufio.Scanner
+	mapping Mapping
+	row     int
+}
+
+// NewJSONLReader creates a reader of JSON lines, the vector is either a
+// field with an array of numbers or a list of fields with a number each.
+func NewJSONLReader(r io.Reader, mapping Mapping) (*JSONLReader, error) {
+	if len(mapping.Vector) == 0 {
+		mapping.Vector = []string{DefaultVectorField}
+	}
+
+	for _, field := range mapping.Vector {
+		if strings.HasSuffix(field, "*") {
+			return nil, fmt.Errorf("the fields of a JSON object are not ordered, list the vector fields instead of %s", field)
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	return &JSONLReader{scanner: scanner, mapping: mapping}, nil
+}
+
+func asText(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case json.Number:
+		return x.String(), true
+	case bool:
+		return fmt.Sprintf("%t", x), true
+	}
+	return "", false
+}
+
+func (j *JSONLReader) mapObject(obj map[string]interface{}) (*pb.Record, error) {
+	rec := &pb.Record{}
+
+	if j.mapping.ID != "" {
+		raw, ok := asText(obj[j.mapping.ID])
+		if !ok {
+			return nil, fmt.Errorf("field %s: missing or invalid identifier", j.mapping.ID)
+		}
+		id, err := parseID(j.mapping.ID, raw)
+		if err != nil {
+			return nil, err
+		}
+		rec.Id = id
+	}
+
+	for _, field := range j.mapping.Vector {
+		switch v := obj[field].(type) {
+		case []interface{}:
+			for _, elem := range v {
+				num, ok := elem.(json.Number)
+				if !ok {
+					return nil, fmt.Errorf("field %s: %v is not a number", field, elem)
+				}
+				c, err := parseComponent(field, num.String())
+				if err != nil {
+					return nil, err
+				}
+				rec.Data = append(rec.Data, c)
+			}
+		case json.Number:
+			c, err := parseComponent(field, v.String())
+			if err != nil {
+				return nil, err
+			}
+			rec.Data = append(rec.Data, c)
+		default:
+			return nil, fmt.Errorf("field %s: missing or not a number or an array of numbers", field)
+		}
+	}
+
+	for _, m := range j.mapping.Meta {
+		value, found := obj[m.Column]
+		if !found || value == nil {
+			continue
+		}
+
+		// without a type numbers are typed meta like when importing arrays
+		if num, ok := value.(json.Number); ok && m.Type == pb.Schema_ANY {
+			m.Type = pb.Schema_NUMBER
+			value = num.String()
+		}
+
+		raw, ok := asText(value)
+		if !ok {
+			return nil, fmt.Errorf("field %s: unsupported meta value", m.Column)
+		} else if err := m.set(rec, raw); err != nil {
+			return nil, err
+		}
+	}
+
+	return rec, nil
+}
+
+// Next returns the record mapped from the next object of the file.
+func (j *JSONLReader) Next() (*pb.Record, error) {
+	for j.scanner.Scan() {
+		line := bytes.TrimSpace(j.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		j.row++
+		obj := make(map[string]interface{})
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if err := decoder.Decode(&obj); err != nil {
+			return nil, &RowError{Row: j.row, Err: err}
+		}
+
+		rec, err := j.mapObject(obj)
+		if err != nil {
+			return nil, &RowError{Row: j.row, Err: err}
+		}
+		return rec, nil
+	}
+
+	if err := j.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,72 @@
+package ingest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+const testJSONL = `{"id": 1, "vector": [1, 2, 3], "label": "cat", "score": 0.5, "cute": true}
+
+{"id": 2, "vector": [4, "x", 6]}
+{"id": "3", "vector": [7, 8, 9], "label": null}
+{"vector": [1, 2, 3]}
+not json
+{"id": 4, "vector": [1], "label": {"nested": true}}
+`
+
+func TestJSONLReader(t *testing.T) {
+	mapping := Mapping{
+		ID:   "id",
+		Meta: []MetaColumn{{Column: "label"}, {Column: "score"}, {Column: "cute"}},
+	}
+
+	r, err := NewJSONLReader(strings.NewReader(testJSONL), mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, rejected := readAll(t, r)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	} else if records[0].Id != 1 || !reflect.DeepEqual(records[0].Data, []float32{1, 2, 3}) {
+		t.Fatalf("unexpected record %v", records[0])
+	} else if records[0].Meta["label"] != "cat" || records[0].Meta["cute"] != "true" {
+		t.Fatalf("unexpected meta %v", records[0].Meta)
+	} else if records[0].TypedMeta["score"].GetNumber() != 0.5 {
+		t.Fatalf("unexpected typed meta %v", records[0].TypedMeta)
+	} else if records[1].Id != 3 || records[1].Meta != nil {
+		t.Fatalf("unexpected record %v", records[1])
+	}
+
+	rows := []int{}
+	for _, r := range rejected {
+		rows = append(rows, r.Row)
+	}
+	if !reflect.DeepEqual(rows, []int{2, 4, 5, 6}) {
+		t.Fatalf("unexpected rejected rows %v", rows)
+	}
+}
+
+func TestJSONLReaderVectorFields(t *testing.T) {
+	mapping := Mapping{Vector: []string{"x", "y"}, Meta: []MetaColumn{{Column: "n", Type: pb.Schema_TEXT}}}
+	r, err := NewJSONLReader(strings.NewReader(`{"x": 1, "y": 2.5, "n": 3}`), mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, rejected := readAll(t, r)
+	if len(rejected) > 0 {
+		t.Fatal(rejected[0])
+	} else if !reflect.DeepEqual(records[0].Data, []float32{1, 2.5}) {
+		t.Fatalf("unexpected data %v", records[0].Data)
+	} else if records[0].Meta["n"] != "3" {
+		t.Fatalf("unexpected meta %v", records[0].Meta)
+	}
+
+	if _, err := NewJSONLReader(strings.NewReader(""), Mapping{Vector: []string{"v*"}}); err == nil {
+		t.Fatal("expected error for vector fields wildcard")
+	}
+}
@@ -0,0 +1,143 @@
+package ingest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// MetaColumn maps a column, or a field of a JSON object, to a meta key.
+type MetaColumn struct {
+	Column string
+	// meta key to store the value with, the column name if empty
+	Key string
+	// ANY and TEXT values are stored as meta, NUMBER and TIME ones as
+	// typed meta, times are RFC3339 strings or unix times in nanoseconds
+	Type pb.Schema_Type
+}
+
+// Mapping describes how the columns of a row are mapped to a record.
+type Mapping struct {
+	// column with the identifier of the record, if empty the identifiers
+	// are assigned when the records are created
+	ID string
+	// columns with the components of the vector, in order, a name ending
+	// with * matches every column starting with it. For JSON lines a single
+	// field can hold the whole vector as an array.
+	Vector []string
+	Meta   []MetaColumn
+}
+
+// ParseMetaColumn parses a meta column definition in the form
+// <COLUMN>[=<KEY>][:<TYPE>], where type is text, number or time.
+func ParseMetaColumn(def string) (MetaColumn, error) {
+	col := MetaColumn{}
+	if idx := strings.LastIndex(def, ":"); idx >= 0 {
+		typeName := strings.ToUpper(def[idx+1:])
+		t, found := pb.Schema_Type_value[typeName]
+		if !found {
+			return col, fmt.Errorf("unknown meta type '%s', use text, number or time", def[idx+1:])
+		}
+		col.Type = pb.Schema_Type(t)
+		def = def[:idx]
+	}
+
+	if idx := strings.Index(def, "="); idx >= 0 {
+		col.Column, col.Key = def[:idx], def[idx+1:]
+	} else {
+		col.Column = def
+	}
+
+	if col.Column == "" {
+		return col, fmt.Errorf("empty meta column name")
+	}
+	return col, nil
+}
+
+func (c MetaColumn) key() string {
+	if c.Key == "" {
+		return c.Column
+	}
+	return c.Key
+}
+
+func setMeta(rec *pb.Record, key string, value string) {
+	if rec.Meta == nil {
+		rec.Meta = make(map[string]string)
+	}
+	rec.Meta[key] = value
+}
+
+func setTypedMeta(rec *pb.Record, key string, value *pb.MetaValue) {
+	if rec.TypedMeta == nil {
+		rec.TypedMeta = make(map[string]*pb.MetaValue)
+	}
+	rec.TypedMeta[key] = value
+}
+
+// sets the meta of a record from the text value of a column
+func (c MetaColumn) set(rec *pb.Record, raw string) error {
+	switch c.Type {
+	case pb.Schema_ANY, pb.Schema_TEXT:
+		setMeta(rec, c.key(), raw)
+	case pb.Schema_NUMBER:
+		num, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("column %s: could not parse '%s' as a number", c.Column, raw)
+		}
+		setTypedMeta(rec, c.key(), &pb.MetaValue{Value: &pb.MetaValue_Number{Number: num}})
+	case pb.Schema_TIME:
+		raw = strings.TrimSpace(raw)
+		if ns, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			setTypedMeta(rec, c.key(), &pb.MetaValue{Value: &pb.MetaValue_Time{Time: ns}})
+		} else if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			setTypedMeta(rec, c.key(), &pb.MetaValue{Value: &pb.MetaValue_Time{Time: t.UnixNano()}})
+		} else {
+			return fmt.Errorf("column %s: could not parse '%s' as a RFC3339 or unix nanoseconds time", c.Column, raw)
+		}
+	}
+	return nil
+}
+
+func parseID(column, raw string) (uint64, error) {
+	id, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+	if err != nil || id == 0 {
+		// identifiers start from 1, 0 means not assigned
+		return 0, fmt.Errorf("column %s: could not parse '%s' as an identifier", column, raw)
+	}
+	return id, nil
+}
+
+func parseComponent(column, raw string) (float32, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 32)
+	if err != nil {
+		return 0, fmt.Errorf("column %s: could not parse '%s' as a number", column, raw)
+	}
+	return float32(v), nil
+}
+
+// returns the columns matching the vector definition, in order
+func (m Mapping) vectorColumns(columns []string) ([]string, error) {
+	matched := make([]string, 0)
+	for _, def := range m.Vector {
+		if strings.HasSuffix(def, "*") {
+			prefix := strings.TrimSuffix(def, "*")
+			found := false
+			for _, col := range columns {
+				if strings.HasPrefix(col, prefix) && col != m.ID {
+					matched = append(matched, col)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("no columns match %s", def)
+			}
+		} else {
+			matched = append(matched, def)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,67 @@
+package ingest
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func TestParseMetaColumn(t *testing.T) {
+	tests := map[string]MetaColumn{
+		"label":               {Column: "label"},
+		"label:text":          {Column: "label", Type: pb.Schema_TEXT},
+		"rating=score:NUMBER": {Column: "rating", Key: "score", Type: pb.Schema_NUMBER},
+		"ts=seen":             {Column: "ts", Key: "seen"},
+		"ts:time":             {Column: "ts", Type: pb.Schema_TIME},
+	}
+
+	for def, expected := range tests {
+		if col, err := ParseMetaColumn(def); err != nil {
+			t.Fatalf("%s: %s", def, err)
+		} else if !reflect.DeepEqual(col, expected) {
+			t.Fatalf("%s: expected %v, got %v", def, expected, col)
+		}
+	}
+
+	for _, def := range []string{"", "label:nope", "=key"} {
+		if _, err := ParseMetaColumn(def); err == nil {
+			t.Fatalf("expected error for '%s'", def)
+		}
+	}
+}
+
+func TestMetaColumnSet(t *testing.T) {
+	rec := &pb.Record{}
+	columns := []struct {
+		col MetaColumn
+		raw string
+	}{
+		{MetaColumn{Column: "label"}, "cat"},
+		{MetaColumn{Column: "rating", Key: "score", Type: pb.Schema_NUMBER}, " 4.5 "},
+		{MetaColumn{Column: "seen", Type: pb.Schema_TIME}, "2019-05-01T10:00:00Z"},
+		{MetaColumn{Column: "born", Type: pb.Schema_TIME}, "42"},
+	}
+
+	for _, c := range columns {
+		if err := c.col.set(rec, c.raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if rec.Meta["label"] != "cat" {
+		t.Fatalf("unexpected meta %v", rec.Meta)
+	} else if rec.TypedMeta["score"].GetNumber() != 4.5 {
+		t.Fatalf("unexpected score %v", rec.TypedMeta["score"])
+	} else if rec.TypedMeta["seen"].GetTime() != 1556704800000000000 {
+		t.Fatalf("unexpected time %v", rec.TypedMeta["seen"])
+	} else if rec.TypedMeta["born"].GetTime() != 42 {
+		t.Fatalf("unexpected time %v", rec.TypedMeta["born"])
+	}
+
+	if err := (MetaColumn{Column: "n", Type: pb.Schema_NUMBER}).set(rec, "many"); err == nil {
+		t.Fatal("expected error for invalid number")
+	} else if err := (MetaColumn{Column: "t", Type: pb.Schema_TIME}).set(rec, "yesterday"); err == nil {
+		t.Fatal("expected error for invalid time")
+	}
+}
@@ -0,0 +1,26 @@
+package ingest
+
+import (
+	"fmt"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// RowError is the reason a row has been rejected.
+type RowError struct {
+	// number of the row, starting from 1 and not counting headers
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+// Reader reads records from the rows of a file.
+type Reader interface {
+	// Next returns the record mapped from the next row, io.EOF when
+	// there are no more rows, or a *RowError if the row can not be
+	// mapped and must be rejected.
+	Next() (*pb.Record, error)
+}
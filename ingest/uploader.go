@@ -0,0 +1,145 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// DefaultBatchSize is the number of records created with every request
+// if the uploader does not specify one.
+const DefaultBatchSize = 1000
+
+// Report is the progress of an upload.
+type Report struct {
+	// number of rows read
+	Rows int
+	// number of records created
+	Created int
+	// rows that have not been created and why
+	Rejected []*RowError
+}
+
+// Uploader creates the records read from a file. Records with an identifier
+// are created one by one with CreateRecordWithId, which rejects the ones
+// whose identifier already exists, unless Overwrite is set, in which case
+// they are created or overwritten in batches with CreateRecordsWithId. If a
+// batch fails its records are created one by one to find and reject the
+// invalid ones. Records without an identifier are created one by one with
+// CreateRecord.
+type Uploader struct {
+	Client     pb.SumServiceClient
+	Internal   pb.SumInternalServiceClient
+	Collection string
+	BatchSize  int
+	// if set, records with an existing identifier are overwritten
+	Overwrite bool
+	// if set, called after every batch
+	OnProgress func(report *Report)
+
+	report Report
+	batch  []*pb.Record
+	rows   []int
+}
+
+func (u *Uploader) reject(row int, err error) {
+	u.report.Rejected = append(u.report.Rejected, &RowError{Row: row, Err: err})
+}
+
+// creates the records of a batch one by one rejecting the ones that fail
+func (u *Uploader) createOneByOne(ctx context.Context) error {
+	for i, rec := range u.batch {
+		var resp *pb.RecordResponse
+		var err error
+		if rec.Id == 0 {
+			resp, err = u.Client.CreateRecord(ctx, rec)
+		} else if u.Internal == nil {
+			err = fmt.Errorf("records with an identifier can not be created without the internal service")
+		} else if u.Overwrite {
+			resp, err = u.Internal.CreateRecordsWithId(ctx, &pb.Records{Records: []*pb.Record{rec}, Collection: u.Collection})
+		} else {
+			resp, err = u.Internal.CreateRecordWithId(ctx, rec)
+		}
+
+		if err != nil {
+			return err
+		} else if !resp.Success {
+			u.reject(u.rows[i], fmt.Errorf("%s", resp.Msg))
+		} else {
+			u.report.Created++
+		}
+	}
+	return nil
+}
+
+func (u *Uploader) flush(ctx context.Context) error {
+	if len(u.batch) == 0 {
+		return nil
+	}
+
+	withIds := u.Internal != nil && u.Overwrite
+	for _, rec := range u.batch {
+		withIds = withIds && rec.Id != 0
+	}
+
+	if withIds {
+		resp, err := u.Internal.CreateRecordsWithId(ctx, &pb.Records{Records: u.batch, Collection: u.Collection})
+		if err != nil {
+			return err
+		} else if resp.Success {
+			u.report.Created += len(u.batch)
+		} else if err = u.createOneByOne(ctx); err != nil {
+			return err
+		}
+	} else if err := u.createOneByOne(ctx); err != nil {
+		return err
+	}
+
+	u.batch = u.batch[:0]
+	u.rows = u.rows[:0]
+
+	if u.OnProgress != nil {
+		u.OnProgress(&u.report)
+	}
+	return nil
+}
+
+// Upload reads every row and creates its record, it stops at the first
+// error that is not due to a single row.
+func (u *Uploader) Upload(ctx context.Context, reader Reader) (*Report, error) {
+	if u.BatchSize <= 0 {
+		u.BatchSize = DefaultBatchSize
+	}
+
+	u.report = Report{}
+	u.batch = make([]*pb.Record, 0, u.BatchSize)
+	u.rows = make([]int, 0, u.BatchSize)
+
+	for {
+		rec, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		u.report.Rows++
+		if rowErr, ok := err.(*RowError); ok {
+			u.report.Rejected = append(u.report.Rejected, rowErr)
+			continue
+		} else if err != nil {
+			return &u.report, err
+		}
+
+		rec.Collection = u.Collection
+		u.batch = append(u.batch, rec)
+		u.rows = append(u.rows, u.report.Rows)
+		if len(u.batch) >= u.BatchSize {
+			if err := u.flush(ctx); err != nil {
+				return &u.report, err
+			}
+		}
+	}
+
+	return &u.report, u.flush(ctx)
+}
@@ -0,0 +1,163 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"google.golang.org/grpc"
+)
+
+// creates records in memory, refusing the ones without components
+type fakeServer struct {
+	pb.SumServiceClient
+	pb.SumInternalServiceClient
+	records map[uint64]*pb.Record
+	nextId  uint64
+	batches int
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{records: make(map[uint64]*pb.Record), nextId: 1}
+}
+
+func (s *fakeServer) CreateRecord(ctx context.Context, in *pb.Record, opts ...grpc.CallOption) (*pb.RecordResponse, error) {
+	if len(in.Data) == 0 {
+		return &pb.RecordResponse{Msg: "empty vector"}, nil
+	}
+	in.Id = s.nextId
+	s.nextId++
+	s.records[in.Id] = in
+	return &pb.RecordResponse{Success: true, Msg: fmt.Sprintf("%d", in.Id)}, nil
+}
+
+func (s *fakeServer) CreateRecordsWithId(ctx context.Context, in *pb.Records, opts ...grpc.CallOption) (*pb.RecordResponse, error) {
+	s.batches++
+	for _, rec := range in.Records {
+		if len(rec.Data) == 0 {
+			return &pb.RecordResponse{Msg: fmt.Sprintf("record %d: empty vector", rec.Id)}, nil
+		}
+	}
+	for _, rec := range in.Records {
+		s.records[rec.Id] = rec
+	}
+	return &pb.RecordResponse{Success: true}, nil
+}
+
+func (s *fakeServer) CreateRecordWithId(ctx context.Context, in *pb.Record, opts ...grpc.CallOption) (*pb.RecordResponse, error) {
+	if len(in.Data) == 0 {
+		return &pb.RecordResponse{Msg: "empty vector"}, nil
+	} else if _, found := s.records[in.Id]; found {
+		return &pb.RecordResponse{Msg: "invalid identifier"}, nil
+	}
+	s.records[in.Id] = in
+	return &pb.RecordResponse{Success: true, Msg: fmt.Sprintf("%d", in.Id)}, nil
+}
+
+func TestUploaderWithIds(t *testing.T) {
+	lines := []string{}
+	for i := 1; i <= 10; i++ {
+		vector := "[1, 2]"
+		if i == 7 {
+			vector = "[]"
+		} else if i == 9 {
+			vector = "nope"
+		}
+		lines = append(lines, fmt.Sprintf(`{"id": %d, "vector": %s}`, i, vector))
+	}
+
+	r, err := NewJSONLReader(strings.NewReader(strings.Join(lines, "\n")), Mapping{ID: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newFakeServer()
+	progress := 0
+	u := &Uploader{
+		Client:     server,
+		Internal:   server,
+		Collection: "test",
+		BatchSize:  4,
+		Overwrite:  true,
+		OnProgress: func(r *Report) { progress++ },
+	}
+
+	report, err := u.Upload(context.TODO(), r)
+	if err != nil {
+		t.Fatal(err)
+	} else if report.Rows != 10 || report.Created != 8 || len(report.Rejected) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	} else if report.Rejected[0].Row != 7 || report.Rejected[1].Row != 9 {
+		t.Fatalf("unexpected rejected rows %v", report.Rejected)
+	} else if progress != 3 {
+		t.Fatalf("expected progress after 3 batches, got %d", progress)
+	} else if len(server.records) != 8 || server.records[7] != nil {
+		t.Fatalf("unexpected records %v", server.records)
+	} else if server.records[1].Collection != "test" {
+		t.Fatalf("unexpected collection %s", server.records[1].Collection)
+	}
+
+	// 3 batches plus the 4 records of the failed one one by one
+	if server.batches != 3+4 {
+		t.Fatalf("unexpected number of batches %d", server.batches)
+	}
+}
+
+func TestUploaderWithoutIds(t *testing.T) {
+	r, err := NewCSVReader(strings.NewReader("a,b\n1,2\n3,4\n5,x\n"), Mapping{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newFakeServer()
+	u := &Uploader{Client: server, Internal: server}
+	report, err := u.Upload(context.TODO(), r)
+	if err != nil {
+		t.Fatal(err)
+	} else if report.Rows != 3 || report.Created != 2 || len(report.Rejected) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	} else if server.batches != 0 {
+		t.Fatal("records without ids should be created one by one")
+	} else if server.records[2].Data[0] != 3 {
+		t.Fatalf("unexpected records %v", server.records)
+	}
+}
+
+func TestUploaderExistingIds(t *testing.T) {
+	upload := func(server *fakeServer, overwrite bool) *Report {
+		r, err := NewJSONLReader(strings.NewReader("{\"id\": 1, \"vector\": [3]}\n{\"id\": 2, \"vector\": [4]}\n"), Mapping{ID: "id"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		u := &Uploader{Client: server, Internal: server, Overwrite: overwrite}
+		report, err := u.Upload(context.TODO(), r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	server := newFakeServer()
+	server.records[1] = &pb.Record{Id: 1, Data: []float32{1}}
+
+	// existing records are rejected unless overwriting is requested
+	if report := upload(server, false); report.Created != 1 || len(report.Rejected) != 1 || report.Rejected[0].Row != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	} else if server.batches != 0 {
+		t.Fatal("records should be created one by one")
+	} else if server.records[1].Data[0] != 1 || server.records[2].Data[0] != 4 {
+		t.Fatalf("unexpected records %v", server.records)
+	}
+
+	if report := upload(server, true); report.Created != 2 || len(report.Rejected) != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	} else if server.batches != 1 {
+		t.Fatalf("expected 1 batch, got %d", server.batches)
+	} else if server.records[1].Data[0] != 3 {
+		t.Fatalf("the existing record should be overwritten, got %v", server.records[1])
+	}
+}
@@ -0,0 +1,174 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/evilsocket/sum/node/backend"
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// Writer writes records to a file.
+type Writer interface {
+	Write(rec *pb.Record) error
+	// Flush writes any buffered data.
+	Flush() error
+}
+
+// returns the value of a meta key, typed meta first, and if found
+func metaValue(rec *pb.Record, key string) (interface{}, bool) {
+	if v, found := rec.TypedMeta[key]; found {
+		switch x := v.GetValue().(type) {
+		case *pb.MetaValue_Text:
+			return x.Text, true
+		case *pb.MetaValue_Number:
+			return x.Number, true
+		case *pb.MetaValue_Time:
+			return time.Unix(0, x.Time).UTC().Format(time.RFC3339Nano), true
+		}
+	}
+	v, found := rec.Meta[key]
+	return v, found
+}
+
+// returns the vector of a record with all of its elements
+func vector(rec *pb.Record) []float32 {
+	if q := rec.Quantized; q != nil {
+		return backend.NewQuantized(backend.Quantization(q.Type), int(q.Size), q.Data, q.Scale, q.Offset).Dense()
+	} else if rec.Sparse == nil {
+		return rec.Data
+	}
+
+	data := make([]float32, rec.Sparse.Size)
+	for i, idx := range rec.Sparse.Indices {
+		data[idx] = rec.Sparse.Values[i]
+	}
+	return data
+}
+
+// CSVWriter writes records as CSV rows with the identifier, the chosen
+// meta keys and a column for every component of the vectors.
+type CSVWriter struct {
+	writer *csv.Writer
+	meta   []string
+	size   int
+}
+
+// NewCSVWriter creates a CSV writer, the header is written with the first
+// record and every record must have the same number of components.
+func NewCSVWriter(w io.Writer, meta []string) *CSVWriter {
+	return &CSVWriter{writer: csv.NewWriter(w), meta: meta, size: -1}
+}
+
+func (c *CSVWriter) Write(rec *pb.Record) error {
+	data := vector(rec)
+	if c.size < 0 {
+		c.size = len(data)
+		header := append([]string{"id"}, c.meta...)
+		for i := 0; i < c.size; i++ {
+			header = append(header, fmt.Sprintf("v%d", i))
+		}
+		if err := c.writer.Write(header); err != nil {
+			return err
+		}
+	} else if len(data) != c.size {
+		return fmt.Errorf("record %d has %d components instead of %d", rec.Id, len(data), c.size)
+	}
+
+	row := make([]string, 0, 1+len(c.meta)+c.size)
+	row = append(row, strconv.FormatUint(rec.Id, 10))
+	for _, key := range c.meta {
+		switch v, _ := metaValue(rec, key); x := v.(type) {
+		case float64:
+			row = append(row, strconv.FormatFloat(x, 'g', -1, 64))
+		case string:
+			row = append(row, x)
+		default:
+			row = append(row, "")
+		}
+	}
+	for _, v := range data {
+		row = append(row, strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	return c.writer.Write(row)
+}
+
+func (c *CSVWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// JSONLWriter writes records as JSON objects with the identifier, the
+// vector and the chosen meta keys, one per line.
+type JSONLWriter struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+	meta    []string
+}
+
+// NewJSONLWriter creates a JSON lines writer, the meta keys can not be
+// the same as the fields of the identifier and the vector.
+func NewJSONLWriter(w io.Writer, meta []string) (*JSONLWriter, error) {
+	for _, key := range meta {
+		if key == "id" || key == DefaultVectorField {
+			return nil, fmt.Errorf("meta %s would overwrite the %s field", key, key)
+		}
+	}
+
+	writer := bufio.NewWriter(w)
+	return &JSONLWriter{writer: writer, encoder: json.NewEncoder(writer), meta: meta}, nil
+}
+
+func (j *JSONLWriter) Write(rec *pb.Record) error {
+	obj := map[string]interface{}{
+		"id":               rec.Id,
+		DefaultVectorField: vector(rec),
+	}
+	for _, key := range j.meta {
+		if v, found := metaValue(rec, key); found {
+			obj[key] = v
+		}
+	}
+	return j.encoder.Encode(obj)
+}
+
+func (j *JSONLWriter) Flush() error {
+	return j.writer.Flush()
+}
+
+// Dump reads the records of a collection in pages of perPage records and
+// writes them, onProgress is called after every page if set.
+func Dump(ctx context.Context, client pb.SumServiceClient, collection string, perPage uint64, w Writer, onProgress func(done, total uint64)) (uint64, error) {
+	if perPage == 0 {
+		perPage = DefaultBatchSize
+	}
+
+	done := uint64(0)
+	for page := uint64(1); ; page++ {
+		resp, err := client.ListRecords(ctx, &pb.ListRequest{Page: page, PerPage: perPage, Collection: collection})
+		if err != nil {
+			return done, err
+		}
+
+		for _, rec := range resp.Records {
+			if err := w.Write(rec); err != nil {
+				return done, err
+			}
+			done++
+		}
+
+		if onProgress != nil {
+			onProgress(done, resp.Total)
+		}
+
+		if page >= resp.Pages {
+			return done, w.Flush()
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"google.golang.org/grpc"
+)
+
+var testRecords = []*pb.Record{
+	{Id: 1, Data: []float32{1, 0.5}, Meta: map[string]string{"label": "cat, black"},
+		TypedMeta: map[string]*pb.MetaValue{"score": {Value: &pb.MetaValue_Number{Number: 2}}}},
+	{Id: 2, Data: []float32{3, 4}, TypedMeta: map[string]*pb.MetaValue{"seen": {Value: &pb.MetaValue_Time{Time: 0}}}},
+	// sparse vectors are written with all of their elements
+	{Id: 3, Sparse: &pb.SparseVector{Size: 2, Indices: []uint32{0, 1}, Values: []float32{5, 6}}},
+}
+
+// lists the test records in pages
+type listServer struct {
+	pb.SumServiceClient
+}
+
+func (s listServer) ListRecords(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (*pb.RecordListResponse, error) {
+	total := uint64(len(testRecords))
+	start, end := (in.Page-1)*in.PerPage, in.Page*in.PerPage
+	if end > total {
+		end = total
+	}
+	return &pb.RecordListResponse{Total: total, Pages: (total + in.PerPage - 1) / in.PerPage, Records: testRecords[start:end]}, nil
+}
+
+func TestDumpCSV(t *testing.T) {
+	buf := bytes.Buffer{}
+	pages := 0
+	done, err := Dump(context.TODO(), listServer{}, "", 2, NewCSVWriter(&buf, []string{"label", "score", "seen"}), func(done, total uint64) { pages++ })
+	if err != nil {
+		t.Fatal(err)
+	} else if done != 3 || pages != 2 {
+		t.Fatalf("unexpected progress %d records in %d pages", done, pages)
+	}
+
+	expected := "id,label,score,seen,v0,v1\n" +
+		"1,\"cat, black\",2,,1,0.5\n" +
+		"2,,,1970-01-01T00:00:00Z,3,4\n" +
+		"3,,,,5,6\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+
+	// and it can be read back
+	mapping := Mapping{ID: "id", Vector: []string{"v*"}, Meta: []MetaColumn{{Column: "label"}, {Column: "score"}}}
+	r, err := NewCSVReader(&buf, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, rejected := readAll(t, r)
+	if len(rejected) > 0 {
+		t.Fatal(rejected[0])
+	} else if !reflect.DeepEqual(records[0].Data, testRecords[0].Data) || records[0].Meta["label"] != "cat, black" {
+		t.Fatalf("unexpected record %v", records[0])
+	}
+
+	w := NewCSVWriter(&buf, nil)
+	if err := w.Write(testRecords[0]); err != nil {
+		t.Fatal(err)
+	} else if err := w.Write(&pb.Record{Id: 4, Data: []float32{1}}); err == nil {
+		t.Fatal("expected error for record with a different size")
+	}
+}
+
+func TestDumpJSONL(t *testing.T) {
+	w, err := NewJSONLWriter(&bytes.Buffer{}, []string{"vector"})
+	if err == nil {
+		t.Fatal("expected error for meta overwriting the vector")
+	}
+
+	buf := bytes.Buffer{}
+	if w, err = NewJSONLWriter(&buf, []string{"label", "score"}); err != nil {
+		t.Fatal(err)
+	} else if _, err := Dump(context.TODO(), listServer{}, "", 10, w, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"id":1,"label":"cat, black","score":2,"vector":[1,0.5]}` + "\n" +
+		`{"id":2,"vector":[3,4]}` + "\n" +
+		`{"id":3,"vector":[5,6]}` + "\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,111 @@
+package master
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/evilsocket/sum/node/storage"
+	pb "github.com/evilsocket/sum/proto"
+	. "github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type importStream struct {
+	grpc.ServerStream
+	chunks []*pb.ArrayChunk
+	resp   *pb.ImportResponse
+}
+
+func (s *importStream) Context() context.Context {
+	return context.TODO()
+}
+
+func (s *importStream) Recv() (*pb.ArrayChunk, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func (s *importStream) SendAndClose(resp *pb.ImportResponse) error {
+	s.resp = resp
+	return nil
+}
+
+type exportStream struct {
+	grpc.ServerStream
+	data bytes.Buffer
+	meta bytes.Buffer
+}
+
+func (s *exportStream) Context() context.Context {
+	return context.TODO()
+}
+
+func (s *exportStream) Send(chunk *pb.ArrayChunk) error {
+	s.data.Write(chunk.Data)
+	s.meta.Write(chunk.Meta)
+	return nil
+}
+
+func TestService_ImportExport(t *testing.T) {
+	ns, err := setupNetwork(2, 1)
+	NoError(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	array := &storage.Array{Shape: []uint64{5, 2}, Data: []float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}}
+	buf := bytes.Buffer{}
+	NoError(t, storage.WriteArray(&buf, pb.ArrayHeader_NPY, "", array))
+
+	meta := "{\"n\": 1}\n{\"n\": 2}\n{\"n\": 3}\n{\"n\": 4}\n{\"n\": 5}\n"
+	stream := &importStream{chunks: []*pb.ArrayChunk{
+		{Header: &pb.ArrayHeader{Format: pb.ArrayHeader_NPY}, Data: buf.Bytes()},
+		{Meta: []byte(meta)},
+	}}
+	NoError(t, ms.Import(stream))
+	True(t, stream.resp.Success, stream.resp.Msg)
+	Equal(t, uint64(5), stream.resp.Records)
+
+	// the records are spread among the nodes
+	for _, n := range ns.nodes {
+		NotZero(t, n.svc.NumRecords())
+	}
+
+	for i := uint64(0); i < 5; i++ {
+		resp, err := ms.ReadRecord(context.TODO(), &pb.ById{Id: stream.resp.FirstId + i})
+		NoError(t, err)
+		True(t, resp.Success, resp.Msg)
+		Equal(t, array.Data[i*2:(i+1)*2], resp.Record.Data)
+		Equal(t, float64(i+1), resp.Record.TypedMeta["n"].GetNumber())
+	}
+
+	// new records do not reuse the identifiers of the imported ones
+	resp, err := ms.CreateRecord(context.TODO(), &pb.Record{Data: []float32{11, 12}})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+	id, err := strconv.ParseUint(resp.Msg, 10, 64)
+	NoError(t, err)
+	True(t, id >= stream.resp.FirstId+5)
+
+	export := &exportStream{}
+	NoError(t, ms.Export(&pb.ArrayHeader{Format: pb.ArrayHeader_SAFETENSORS, Meta: true}, export))
+
+	r := bytes.NewReader(export.data.Bytes())
+	exported, err := storage.ReadArray(r, r.Size(), pb.ArrayHeader_SAFETENSORS, "")
+	NoError(t, err)
+	Equal(t, []uint64{6, 2}, exported.Shape)
+	Equal(t, []float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, exported.Data)
+	Equal(t, "{\"n\":1}\n{\"n\":2}\n{\"n\":3}\n{\"n\":4}\n{\"n\":5}\n{}\n", export.meta.String())
+
+	// invalid arrays are not imported
+	stream = &importStream{chunks: []*pb.ArrayChunk{{Data: []byte("nope")}}}
+	NoError(t, ms.Import(stream))
+	False(t, stream.resp.Success)
+}
@@ -1,14 +1,16 @@
 package master
 
 import (
+	"fmt"
+
 	pb "github.com/evilsocket/sum/proto"
 
 	"github.com/evilsocket/islazy/log"
 )
 
-// transfer nRecords from a node to another
-func (ms *Service) transfer(fromNode, toNode *NodeInfo, nRecords int64) {
-	log.Info("transferring %d records: %s -> %s ...", nRecords, fromNode.Name, toNode.Name)
+// transfer nRecords of a collection from a node to another
+func (ms *Service) transfer(fromNode, toNode *NodeInfo, nRecords int64, collection string) {
+	log.Info("transferring %d records%s: %s -> %s ...", nRecords, collectionLabel(collection), fromNode.Name, toNode.Name)
 
 	fromNode.Lock()
 	toNode.Lock()
@@ -18,7 +20,7 @@ func (ms *Service) transfer(fromNode, toNode *NodeInfo, nRecords int64) {
 	ctx, cf := newCommContext()
 	defer cf()
 
-	list, err := fromNode.Client.ListRecords(ctx, &pb.ListRequest{PerPage: uint64(nRecords), Page: 1})
+	list, err := fromNode.Client.ListRecords(ctx, &pb.ListRequest{PerPage: uint64(nRecords), Page: 1, Collection: collection, Quantized: true})
 	if err != nil {
 		log.Error("Cannot get records from node %d: %v", fromNode.ID, err)
 		return
@@ -26,7 +28,7 @@ func (ms *Service) transfer(fromNode, toNode *NodeInfo, nRecords int64) {
 
 	log.Debug("Master[%s]: got %d records from node %s", ms.address, len(list.Records), fromNode.Name)
 
-	resp, err := toNode.InternalClient.CreateRecordsWithId(ctx, &pb.Records{Records: list.Records})
+	resp, err := toNode.InternalClient.CreateRecordsWithId(ctx, &pb.Records{Records: list.Records, Collection: collection, Transfer: true})
 
 	if err != nil || !resp.Success {
 		log.Error("Unable to store records on node %d: %v", toNode.ID, getErrorMessage(err, resp))
@@ -35,17 +37,19 @@ func (ms *Service) transfer(fromNode, toNode *NodeInfo, nRecords int64) {
 
 	log.Debug("Master[%s]: created %d records on node %s", ms.address, len(list.Records), toNode.Name)
 
-	delReq := &pb.RecordIds{Ids: make([]uint64, 0, nRecords)}
-	toNode.status.Records += uint64(nRecords)
+	delReq := &pb.RecordIds{Ids: make([]uint64, 0, nRecords), Collection: collection, Transfer: true}
+	maxId := uint64(0)
 
 	for _, r := range list.Records {
 		delReq.Ids = append(delReq.Ids, r.Id)
 
-		if toNode.status.NextRecordId <= r.Id {
-			toNode.status.NextRecordId = r.Id + 1
+		if r.Id > maxId {
+			maxId = r.Id
 		}
 	}
 
+	toNode._updateCollection(collection, nRecords, maxId+1)
+
 	resp1, err := fromNode.InternalClient.DeleteRecords(ctx, delReq)
 
 	if err != nil || !resp1.Success {
@@ -55,17 +59,32 @@ func (ms *Service) transfer(fromNode, toNode *NodeInfo, nRecords int64) {
 		log.Debug("Master[%s]: deleted %d records from node %s", ms.address, len(delReq.Ids), fromNode.Name)
 	}
 
-	fromNode.status.Records -= uint64(nRecords)
+	fromNode._updateCollection(collection, -nRecords, 0)
+}
+
+// used to log which collection is being balanced
+func collectionLabel(collection string) string {
+	if collection == "" {
+		return ""
+	}
+	return fmt.Sprintf(" of collection %s", collection)
 }
 
-// balance the load among nodes
+// balance the load of every collection among nodes
 func (ms *Service) balance() {
-	log.Debug("Master[%s]: balancing...", ms.address)
+	for _, collection := range ms._collections() {
+		ms.balanceCollection(collection)
+	}
+}
+
+// balance the load of a collection among nodes
+func (ms *Service) balanceCollection(collection string) {
+	log.Debug("Master[%s]: balancing%s...", ms.address, collectionLabel(collection))
 	var totRecords = uint64(0)
 	var nNodes = len(ms.nodes)
 
 	for _, n := range ms.nodes {
-		totRecords += n.Status().Records
+		totRecords += n.Records(collection)
 	}
 
 	if totRecords == 0 || nNodes == 0 {
@@ -85,7 +104,7 @@ func (ms *Service) balance() {
 		if i < reminder {
 			targets[i]++
 		}
-		deltas[i] = int64(targets[i]) - int64(n.Status().Records)
+		deltas[i] = int64(targets[i]) - int64(n.Records(collection))
 
 		// 5% hysteresis
 		if !needsBalancing && deltas[i] > int64(targetRecordsPerNode/20) {
@@ -97,7 +116,7 @@ func (ms *Service) balance() {
 		return
 	}
 
-	log.Info("balancing %d deltas ...", len(deltas))
+	log.Info("balancing %d deltas%s ...", len(deltas), collectionLabel(collection))
 
 	for i, delta := range deltas {
 		// foreach node that need records
@@ -117,7 +136,7 @@ func (ms *Service) balance() {
 			if nRecords == 0 {
 				continue
 			}
-			ms.transfer(ms.nodes[j], ms.nodes[i], nRecords)
+			ms.transfer(ms.nodes[j], ms.nodes[i], nRecords, collection)
 			delta -= nRecords
 			deltas[i] -= nRecords
 			deltas[j] += nRecords
@@ -0,0 +1,140 @@
+package master
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+	. "github.com/stretchr/testify/require"
+)
+
+func collectionRecords(t *testing.T, ns networkSetup, i int, collection string) uint64 {
+	resp, err := ns.nodes[i].svc.ListRecords(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 1, Collection: collection})
+	NoError(t, err)
+	return resp.Total
+}
+
+func TestService_Collections(t *testing.T) {
+	ns, err := setupNetwork(2, 1)
+	NoError(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	resp, err := ms.CreateCollection(context.TODO(), &pb.ByName{Name: "../nope"})
+	NoError(t, err)
+	False(t, resp.Success)
+
+	resp, err = ms.CreateCollection(context.TODO(), &pb.ByName{Name: "images"})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	resp, err = ms.CreateCollection(context.TODO(), &pb.ByName{Name: "images"})
+	NoError(t, err)
+	False(t, resp.Success)
+	Equal(t, "collection images already exists.", resp.Msg)
+
+	for _, n := range ns.nodes {
+		Equal(t, 1, n.svc.NumCollections())
+	}
+
+	for i := 1; i <= 4; i++ {
+		rec := &pb.Record{
+			Data:       []float32{float32(i)},
+			Meta:       map[string]string{"kind": "image"},
+			Collection: "images",
+		}
+		resp, err := ms.CreateRecord(context.TODO(), rec)
+		NoError(t, err)
+		True(t, resp.Success, resp.Msg)
+		Equal(t, uint64(i), rec.Id)
+	}
+
+	// records are balanced within the collection only
+	for i := range ns.nodes {
+		Equal(t, uint64(2), collectionRecords(t, ns, i, "images"))
+		Zero(t, ns.nodes[i].svc.NumRecords())
+	}
+
+	read, err := ms.ReadRecord(context.TODO(), &pb.ById{Id: 3, Collection: "images"})
+	NoError(t, err)
+	True(t, read.Success, read.Msg)
+	Equal(t, []float32{3}, read.Record.Data)
+
+	read, err = ms.ReadRecord(context.TODO(), &pb.ById{Id: 3})
+	NoError(t, err)
+	False(t, read.Success)
+
+	list, err := ms.ListRecords(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 10, Collection: "images"})
+	NoError(t, err)
+	Equal(t, uint64(4), list.Total)
+	Equal(t, 4, len(list.Records))
+
+	found, err := ms.FindRecords(context.TODO(), &pb.ByMeta{Meta: "kind", Value: "image", Collection: "images"})
+	NoError(t, err)
+	True(t, found.Success, found.Msg)
+	Equal(t, 4, len(found.Records))
+
+	del, err := ms.DeleteRecord(context.TODO(), &pb.ById{Id: 1, Collection: "images"})
+	NoError(t, err)
+	True(t, del.Success, del.Msg)
+
+	collections, err := ms.ListCollections(context.TODO(), &pb.Empty{})
+	NoError(t, err)
+	True(t, collections.Success)
+	Equal(t, 1, len(collections.Collections))
+	Equal(t, "images", collections.Collections[0].Name)
+	Equal(t, uint64(3), collections.Collections[0].Records)
+	Equal(t, uint64(5), collections.Collections[0].NextRecordId)
+
+	resp, err = ms.DropCollection(context.TODO(), &pb.ByName{Name: "images"})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	for _, n := range ns.nodes {
+		Zero(t, n.svc.NumCollections())
+	}
+
+	resp, err = ms.DropCollection(context.TODO(), &pb.ByName{Name: "images"})
+	NoError(t, err)
+	False(t, resp.Success)
+	Equal(t, "collection images not found.", resp.Msg)
+}
+
+func TestService_CollectionsAddNode(t *testing.T) {
+	ns, err := setupNetwork(2, 1)
+	NoError(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	resp, err := ms.CreateCollection(context.TODO(), &pb.ByName{Name: "texts"})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	for i := 0; i < 6; i++ {
+		resp, err := ms.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1}, Collection: "texts"})
+		NoError(t, err)
+		True(t, resp.Success, resp.Msg)
+	}
+
+	dir, err := setupEmptyTmpFolder()
+	NoError(t, err)
+
+	node, sum := spawnNode(t, 12348, dir)
+	defer node.Stop()
+
+	added, err := ms.AddNode(context.TODO(), &pb.ByAddr{Address: "127.0.0.1:12348"})
+	NoError(t, err)
+	True(t, added.Success, added.Msg)
+
+	// the new node gets the collection and its share of records
+	Equal(t, 1, sum.NumCollections())
+	list, err := sum.ListRecords(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 1, Collection: "texts"})
+	NoError(t, err)
+	Equal(t, uint64(2), list.Total)
+
+	for i := range ns.nodes {
+		Equal(t, uint64(2), collectionRecords(t, ns, i, "texts"))
+	}
+}
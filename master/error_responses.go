@@ -2,6 +2,7 @@ package master
 
 import (
 	"fmt"
+	"github.com/evilsocket/sum/node/storage"
 	. "github.com/evilsocket/sum/proto"
 	"github.com/golang/protobuf/proto"
 )
@@ -31,6 +32,21 @@ func errNodeResponse(format string, args ...interface{}) *NodeResponse {
 	return &NodeResponse{Success: false, Msg: fmt.Sprintf(format, args...)}
 }
 
+// builds a collection response that contains an error
+func errCollectionResponse(format string, args ...interface{}) *CollectionResponse {
+	return &CollectionResponse{Success: false, Msg: fmt.Sprintf(format, args...)}
+}
+
+// builds an import response that contains an error
+func errImportResponse(format string, args ...interface{}) *ImportResponse {
+	return &ImportResponse{Success: false, Msg: fmt.Sprintf(format, args...)}
+}
+
+// builds a check response that contains an error
+func errCheckResponse(format string, args ...interface{}) *CheckResponse {
+	return &CheckResponse{Success: false, Msg: fmt.Sprintf(format, args...)}
+}
+
 // Get the error message from either a GRPC error or a application-level one
 func getErrorMessage(err error, response proto.Message) string {
 	if err != nil {
@@ -53,6 +69,12 @@ func getErrorMessage(err error, response proto.Message) string {
 	case *FindResponse:
 		success = response.(*FindResponse).Success
 		msg = response.(*FindResponse).Msg
+	case *CollectionResponse:
+		success = response.(*CollectionResponse).Success
+		msg = response.(*CollectionResponse).Msg
+	case *CheckResponse:
+		success = response.(*CheckResponse).Success
+		msg = response.(*CheckResponse).Msg
 	default:
 		panic(fmt.Sprintf("unsupported message %T: %v", response, response))
 	}
@@ -63,3 +85,9 @@ func getErrorMessage(err error, response proto.Message) string {
 
 	panic("no errors dude")
 }
+
+// returns true if a node refused to change a record because the
+// expected version did not match the stored one
+func isConflict(err error, resp *RecordResponse) bool {
+	return err == nil && !resp.Success && resp.Msg == storage.ErrVersionConflict.Error()
+}
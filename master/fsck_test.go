@@ -0,0 +1,37 @@
+package master
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+	. "github.com/stretchr/testify/require"
+)
+
+func TestService_CheckIndexes(t *testing.T) {
+	ns, err := setupNetwork(2, 1)
+	NoError(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	for i := 1; i <= 4; i++ {
+		resp, err := ms.CreateRecord(context.TODO(), &pb.Record{Data: []float32{float32(i)}, Meta: map[string]string{"n": "x"}})
+		NoError(t, err)
+		True(t, resp.Success, resp.Msg)
+	}
+
+	// every node checks its own records
+	resp, err := ms.CheckIndexes(context.TODO(), &pb.CheckRequest{Rebuild: true})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+	Equal(t, uint64(4), resp.Records)
+	Equal(t, uint64(8), resp.Entries)
+	Empty(t, resp.Stale)
+	Empty(t, resp.Missing)
+	Empty(t, resp.Duplicated)
+
+	resp, err = ms.CheckIndexes(context.TODO(), &pb.CheckRequest{Collection: "nope"})
+	NoError(t, err)
+	False(t, resp.Success)
+}
@@ -0,0 +1,82 @@
+package master
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+	. "github.com/stretchr/testify/require"
+)
+
+func TestService_Keys(t *testing.T) {
+	ns, err := setupNetwork(2, 1)
+	NoError(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	// records with different keys end up on different nodes
+	for _, key := range []string{"a", "b", "c", "d"} {
+		resp, err := ms.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1}, Key: key})
+		NoError(t, err)
+		True(t, resp.Success, resp.Msg)
+	}
+
+	resp, err := ms.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1}, Key: "c"})
+	NoError(t, err)
+	False(t, resp.Success)
+	Contains(t, resp.Msg, "key is not unique")
+
+	read, err := ms.ReadRecordByKey(context.TODO(), &pb.ByKey{Key: "c"})
+	NoError(t, err)
+	True(t, read.Success, read.Msg)
+	Equal(t, uint64(3), read.Record.Id)
+
+	read, err = ms.ReadRecordByKey(context.TODO(), &pb.ByKey{Key: "nope"})
+	NoError(t, err)
+	False(t, read.Success)
+	Equal(t, "record with key 'nope' not found.", read.Msg)
+
+	// the key of record 4 is on another node than record 3 one
+	resp, err = ms.UpdateRecord(context.TODO(), &pb.Record{Id: 4, Key: "c"})
+	NoError(t, err)
+	False(t, resp.Success)
+	Contains(t, resp.Msg, "key is not unique")
+
+	resp, err = ms.UpdateRecord(context.TODO(), &pb.Record{Id: 3, Key: "c", Data: []float32{2}})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+}
+
+func TestService_UpsertRecord(t *testing.T) {
+	ns, err := setupNetwork(2, 1)
+	NoError(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	resp, err := ms.UpsertRecord(context.TODO(), &pb.Record{Data: []float32{1}})
+	NoError(t, err)
+	False(t, resp.Success)
+	Equal(t, "record has no key", resp.Msg)
+
+	for i := 1; i <= 3; i++ {
+		resp, err = ms.UpsertRecord(context.TODO(), &pb.Record{Data: []float32{float32(i)}, Key: "k"})
+		NoError(t, err)
+		True(t, resp.Success, resp.Msg)
+		Equal(t, "1", resp.Msg)
+	}
+
+	resp, err = ms.UpsertRecord(context.TODO(), &pb.Record{Data: []float32{4}, Key: "other"})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+	Equal(t, "2", resp.Msg)
+
+	read, err := ms.ReadRecord(context.TODO(), &pb.ById{Id: 1})
+	NoError(t, err)
+	True(t, read.Success, read.Msg)
+	Equal(t, []float32{3}, read.Record.Data)
+	Equal(t, "k", read.Record.Key)
+	Equal(t, uint64(3), read.Record.Version)
+	Equal(t, 2, ms.NumRecords())
+}
@@ -0,0 +1,143 @@
+package master
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/evilsocket/islazy/log"
+	"github.com/evilsocket/sum/node/service"
+	. "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
+)
+
+// create a record for every row of the array streamed by the client, the
+// records are given consecutive identifiers and distributed among the nodes
+// in batches that fit in a message, created as the rows are decoded
+func (ms *Service) Import(stream SumService_ImportServer) error {
+	array, err := service.ReceiveArray(stream, "")
+	if err != nil {
+		return stream.SendAndClose(errImportResponse("%s", err))
+	}
+	defer array.Close()
+
+	rows := array.Rows()
+	collection := array.Header.Collection
+	if rows == 0 {
+		return stream.SendAndClose(&ImportResponse{Success: true})
+	}
+
+	ms.idLock.Lock()
+	firstId := ms._nextIdOf(collection)
+	ms._setNextIdOf(collection, firstId+rows)
+	ms.idLock.Unlock()
+
+	created := uint64(0)
+	fail := func(format string, args ...interface{}) error {
+		// best effort
+		if created > 0 {
+			ids := &RecordIds{Ids: make([]uint64, 0, created), Collection: collection}
+			for id := firstId; id < firstId+created; id++ {
+				ids.Ids = append(ids.Ids, id)
+			}
+			ms.DeleteRecords(stream.Context(), ids)
+		}
+		return stream.SendAndClose(errImportResponse(format, args...))
+	}
+
+	batch, size := make([]*Record, 0), 0
+	flush := func() (*RecordResponse, error) {
+		resp, err := ms.CreateRecordsWithId(stream.Context(), &Records{Records: batch, Collection: collection})
+		if err == nil && resp.Success {
+			created += uint64(len(batch))
+			batch, size = make([]*Record, 0), 0
+		}
+		return resp, err
+	}
+
+	for {
+		record, err := array.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fail("%s", err)
+		}
+
+		record.Id = firstId + created + uint64(len(batch))
+		recSize := proto.Size(record)
+		if len(batch) > 0 && size+recSize > maxMsgSize/2 {
+			if resp, err := flush(); err != nil || !resp.Success {
+				return fail("%s", getErrorMessage(err, resp))
+			}
+		}
+		batch = append(batch, record)
+		size += recSize
+	}
+
+	if resp, err := flush(); err != nil || !resp.Success {
+		return fail("%s", getErrorMessage(err, resp))
+	}
+
+	log.Info("Master[%s]: imported %d records from a %s array", ms.address, rows, array.Header.Format)
+
+	return stream.SendAndClose(&ImportResponse{Success: true, Records: rows, FirstId: firstId})
+}
+
+// get every record of a collection from a node, in pages that fit in a message
+func listAllRecords(node *NodeInfo, collection string) ([]*Record, error) {
+	ctx, cf := newCommContext()
+	defer cf()
+
+	// estimate how many records fit in a page from the size of the first one
+	probe, err := node.Client.ListRecords(ctx, &ListRequest{Page: 1, PerPage: 1, Collection: collection, Quantized: true})
+	if err != nil {
+		return nil, err
+	} else if len(probe.Records) == 0 {
+		return nil, nil
+	}
+
+	perPage := uint64(maxMsgSize/2) / uint64(proto.Size(probe.Records[0])+1)
+	if perPage < 1 {
+		perPage = 1
+	}
+
+	records := make([]*Record, 0, probe.Total)
+	for page := uint64(1); ; page++ {
+		resp, err := node.Client.ListRecords(ctx, &ListRequest{Page: page, PerPage: perPage, Collection: collection, Quantized: true})
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, resp.Records...)
+		if page >= resp.Pages {
+			return records, nil
+		}
+	}
+}
+
+// stream the records of a collection from every node as an array
+func (ms *Service) Export(arg *ArrayHeader, stream SumService_ExportServer) error {
+	ms.nodesLock.RLock()
+	results, errs := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
+		records, err := listAllRecords(node, arg.Collection)
+		if err != nil {
+			errorChannel <- fmt.Sprintf("node %d: %v", node.ID, err)
+			return
+		}
+		resultChannel <- records
+	})
+	ms.nodesLock.RUnlock()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to communicate with nodes: [%s]", strings.Join(errs, ", "))
+	}
+
+	records := make([]*Record, 0)
+	for _, res := range results {
+		records = append(records, res.([]*Record)...)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Id < records[j].Id })
+
+	return service.SendArray(stream, arg, records)
+}
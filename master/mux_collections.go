@@ -0,0 +1,168 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/evilsocket/islazy/log"
+	"github.com/evilsocket/sum/node/service"
+	. "github.com/evilsocket/sum/proto"
+)
+
+// create a collection on every node
+func (ms *Service) CreateCollection(_ context.Context, arg *ByName) (*CollectionResponse, error) {
+	if err := service.ValidCollectionName(arg.Name); err != nil {
+		return errCollectionResponse("%v", err), nil
+	}
+
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+
+	if len(ms.nodes) == 0 {
+		return errCollectionResponse("No nodes available, try later"), nil
+	}
+
+	for _, c := range ms._collections() {
+		if c == arg.Name {
+			return errCollectionResponse("collection %s already exists.", arg.Name), nil
+		}
+	}
+
+	ctx, cf := newCommContext()
+	defer cf()
+
+	results, errs := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
+		resp, err := node.Client.CreateCollection(ctx, arg)
+		if err != nil || !resp.Success {
+			errorChannel <- fmt.Sprintf("node %d: %v", node.ID, getErrorMessage(err, resp))
+		} else {
+			resultChannel <- node
+		}
+	})
+
+	if len(errs) > 0 {
+		// rollback, best effort
+		for _, res := range results {
+			node := res.(*NodeInfo)
+			if resp, err := node.Client.DropCollection(ctx, arg); err != nil || !resp.Success {
+				log.Warning("Unable to drop collection %s from node %d: %v", arg.Name, node.ID, getErrorMessage(err, resp))
+			}
+		}
+		return errCollectionResponse("Unable to create collection on nodes: [%s]", strings.Join(errs, ", ")), nil
+	}
+
+	for _, n := range ms.nodes {
+		n.UpdateStatus()
+	}
+
+	return &CollectionResponse{Success: true, Collections: []*Collection{{Name: arg.Name, NextRecordId: 1}}}, nil
+}
+
+// drop a collection and its records from every node
+func (ms *Service) DropCollection(_ context.Context, arg *ByName) (*CollectionResponse, error) {
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+
+	notFoundError := fmt.Sprintf("collection %s not found.", arg.Name)
+
+	ctx, cf := newCommContext()
+	defer cf()
+
+	results, errs := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
+		resp, err := node.Client.DropCollection(ctx, arg)
+		if err != nil || !resp.Success {
+			if msg := getErrorMessage(err, resp); msg != notFoundError {
+				errorChannel <- fmt.Sprintf("node %d: %v", node.ID, msg)
+			}
+		} else {
+			resultChannel <- node
+		}
+		node.UpdateStatus()
+	})
+
+	if len(errs) > 0 {
+		return errCollectionResponse("Unable to drop collection from nodes: [%s]", strings.Join(errs, ", ")), nil
+	} else if len(results) == 0 {
+		return errCollectionResponse("%s", notFoundError), nil
+	}
+
+	ms.idLock.Lock()
+	defer ms.idLock.Unlock()
+	delete(ms.nextIds, arg.Name)
+
+	return &CollectionResponse{Success: true}, nil
+}
+
+// aggregate the collections of the nodes
+// NB: assumes an held lock on ms.nodesLock
+func (ms *Service) _collectionsInfo() []*Collection {
+	byName := make(map[string]*Collection)
+	for _, n := range ms.nodes {
+		st := n.Status()
+		for _, c := range st.Collections {
+			if agg, found := byName[c.Name]; !found {
+				byName[c.Name] = &Collection{Name: c.Name, Records: c.Records, NextRecordId: c.NextRecordId, Schema: c.Schema, Trashed: c.Trashed}
+			} else {
+				agg.Records += c.Records
+				agg.Trashed += c.Trashed
+				if agg.NextRecordId < c.NextRecordId {
+					agg.NextRecordId = c.NextRecordId
+				}
+			}
+		}
+	}
+
+	ms.idLock.RLock()
+	defer ms.idLock.RUnlock()
+
+	list := make([]*Collection, 0, len(byName))
+	for name, c := range byName {
+		if nextId := ms._nextIdOf(name); c.NextRecordId < nextId {
+			c.NextRecordId = nextId
+		}
+		list = append(list, c)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// list the collections of every node
+func (ms *Service) ListCollections(context.Context, *Empty) (*CollectionResponse, error) {
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+
+	return &CollectionResponse{Success: true, Collections: ms._collectionsInfo()}, nil
+}
+
+// create on a node the collections of the other nodes it doesn't have
+// NB: assumes an held lock on ms.nodesLock
+func (ms *Service) createCollectionsOn(n *NodeInfo) {
+	existing := make(map[string]bool)
+	for _, name := range n.Collections() {
+		existing[name] = true
+	}
+
+	ctx, cf := newCommContext()
+	defer cf()
+
+	created := 0
+	for _, name := range ms._collections() {
+		if name == "" || existing[name] {
+			continue
+		}
+
+		if resp, err := n.Client.CreateCollection(ctx, &ByName{Name: name}); err != nil || !resp.Success {
+			log.Error("Unable to create collection %s on node %s: %v", name, n.Name, getErrorMessage(err, resp))
+		} else {
+			created++
+		}
+	}
+
+	if created > 0 {
+		n.UpdateStatus()
+	}
+}
@@ -0,0 +1,45 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	. "github.com/evilsocket/sum/proto"
+)
+
+// check, and optionally rebuild, the indexes of a collection on every node
+func (ms *Service) CheckIndexes(_ context.Context, arg *CheckRequest) (*CheckResponse, error) {
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+
+	ctx, cf := newCommContext()
+	defer cf()
+
+	results, errs := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
+		resp, err := node.Client.CheckIndexes(ctx, arg)
+		if err != nil || !resp.Success {
+			errorChannel <- fmt.Sprintf("node %d: %v", node.ID, getErrorMessage(err, resp))
+		} else {
+			resultChannel <- resp
+		}
+	})
+
+	if len(errs) > 0 {
+		return errCheckResponse("unable to check the indexes: [%s]", strings.Join(errs, ", ")), nil
+	}
+
+	merged := &CheckResponse{Success: true}
+	for _, res := range results {
+		resp := res.(*CheckResponse)
+		merged.Records += resp.Records
+		merged.Entries += resp.Entries
+		merged.Stale = append(merged.Stale, resp.Stale...)
+		merged.Missing = append(merged.Missing, resp.Missing...)
+		merged.Duplicated = append(merged.Duplicated, resp.Duplicated...)
+	}
+	merged.Msg = fmt.Sprintf("%d records, %d index entries, %d stale, %d missing, %d duplicated",
+		merged.Records, merged.Entries, len(merged.Stale), len(merged.Missing), len(merged.Duplicated))
+
+	return merged, nil
+}
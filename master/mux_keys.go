@@ -0,0 +1,149 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"github.com/evilsocket/islazy/log"
+	"github.com/evilsocket/sum/node/storage"
+	. "github.com/evilsocket/sum/proto"
+	"strings"
+)
+
+// the record with a key and the node storing it
+type keyOwner struct {
+	node   *NodeInfo
+	record *Record
+}
+
+func keyNotFound(key string) string {
+	return fmt.Sprintf("record with key '%s' not found.", key)
+}
+
+// find the node storing the record with the given key, returns nil if
+// no node has it.
+// NB: assumes an held lock on ms.nodesLock
+func (ms *Service) _findKeyOwner(collection, key string, quantized bool) (*keyOwner, error) {
+	ctx, cf := newCommContext()
+	defer cf()
+
+	query := &ByKey{Key: key, Collection: collection, Quantized: quantized}
+	results, errs := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
+		resp, err := node.Client.ReadRecordByKey(ctx, query)
+		if err != nil || !resp.Success {
+			msg := getErrorMessage(err, resp)
+			if msg != keyNotFound(key) {
+				errorChannel <- fmt.Sprintf("node %d: %v", node.ID, msg)
+			}
+		} else {
+			cf() // cancel other queries
+			resultChannel <- &keyOwner{node: node, record: resp.Record}
+		}
+	})
+
+	switch len(results) {
+	case 0:
+		if len(errs) == 0 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("No node was able to satisfy your request: [%s]", strings.Join(errs, ", "))
+	default:
+		log.Warning("Got %d results when only one was expected: %v", len(results), results)
+		fallthrough
+	case 1:
+		return results[0].(*keyOwner), nil
+	}
+}
+
+// returns an error if the key belongs to a record other than id
+// NB: assumes an held lock on ms.nodesLock and ms.keyLock
+func (ms *Service) _checkKey(collection, key string, id uint64) error {
+	if key == "" {
+		return nil
+	} else if owner, err := ms._findKeyOwner(collection, key, true); err != nil {
+		return err
+	} else if owner != nil && owner.record.Id != id {
+		return fmt.Errorf("%s: %v (record %d)", key, storage.ErrKeyExists, owner.record.Id)
+	}
+	return nil
+}
+
+func (ms *Service) checkKey(collection, key string, id uint64) error {
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+	return ms._checkKey(collection, key, id)
+}
+
+// records with a key are written one at a time
+func (ms *Service) lockKey(key string) func() {
+	if key == "" {
+		return func() {}
+	}
+	ms.keyLock.Lock()
+	return ms.keyLock.Unlock
+}
+
+// retrieve a record's content by its key
+func (ms *Service) ReadRecordByKey(_ context.Context, arg *ByKey) (*RecordResponse, error) {
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+
+	owner, err := ms._findKeyOwner(arg.Collection, arg.Key, arg.Quantized)
+	if err != nil {
+		return errRecordResponse("%v", err), nil
+	} else if owner == nil {
+		return errRecordResponse("%s", keyNotFound(arg.Key)), nil
+	}
+	return &RecordResponse{Success: true, Record: owner.record}, nil
+}
+
+// update the record with the same key of the given argument, or create
+// it on the less loaded node if no record has that key
+func (ms *Service) UpsertRecord(ctx context.Context, arg *Record) (*RecordResponse, error) {
+	if arg.Key == "" {
+		return errRecordResponse("%v", storage.ErrMissingKey), nil
+	}
+
+	ms.keyLock.Lock()
+	defer ms.keyLock.Unlock()
+
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+
+	owner, err := ms._findKeyOwner(arg.Collection, arg.Key, true)
+	if err != nil {
+		return errRecordResponse("%v", err), nil
+	}
+
+	if owner != nil {
+		if err := storage.ValidateUpdate(ms._schemaOf(arg.Collection), arg); err != nil {
+			return errRecordResponse("record does not match the schema: %v", err), nil
+		}
+		return owner.node.Client.UpsertRecord(ctx, arg)
+	}
+
+	if err := storage.ValidateRecord(ms._schemaOf(arg.Collection), arg); err != nil {
+		return errRecordResponse("record does not match the schema: %v", err), nil
+	}
+
+	targetNode := ms._findLessLoadedNode(arg.Collection)
+	if targetNode == nil {
+		return errRecordResponse("No nodes available, try later"), nil
+	}
+
+	targetNode.Lock()
+	defer targetNode.Unlock()
+
+	ms.idLock.Lock()
+	defer ms.idLock.Unlock()
+
+	arg.Id = ms._nextIdOf(arg.Collection)
+	arg.Version = 0
+	arg.UpdatedAt = 0
+
+	resp, err := targetNode.InternalClient.CreateRecordWithId(ctx, arg)
+	if err == nil && resp.Success {
+		ms._setNextIdOf(arg.Collection, arg.Id+1)
+		targetNode._updateCollection(arg.Collection, 1, arg.Id+1)
+	}
+	return resp, err
+}
@@ -16,13 +16,23 @@ func (ms *Service) AddNode(ctx context.Context, addr *ByAddr) (*NodeResponse, er
 	ms.nodesLock.Lock()
 	defer ms.nodesLock.Unlock()
 
-	ms.setNextIdIfHigher(n.Status().NextRecordId)
+	st := n.Status()
+	ms.setNextIdIfHigher("", st.NextRecordId)
+	for _, c := range st.Collections {
+		ms.setNextIdIfHigher(c.Name, c.NextRecordId)
+	}
+
+	// the node must have every collection to get its share of records
+	ms.createCollectionsOn(n)
+	ms.setSchemasOn(n)
 
 	n.ID = ms.nextNodeId
 	ms.nodes = append(ms.nodes, n)
 
 	ms.nextNodeId++
 
+	ms.watchNode(n)
+
 	go ms.updateConfig()
 
 	ms.balance()
@@ -72,10 +82,15 @@ func (ms *Service) DeleteNode(ctx context.Context, id *ById) (*NodeResponse, err
 
 	go ms.updateConfig()
 
-	nRecords := n.Status().Records
 	nNodes := uint64(len(ms.nodes))
+	collections := append([]string{""}, n.Collections()...)
+
+	for _, collection := range collections {
+		nRecords := n.Records(collection)
+		if nNodes == 0 || nRecords == 0 {
+			continue
+		}
 
-	if nNodes > 0 && nRecords > 0 {
 		perNode := nRecords / nNodes
 		remainder := nRecords % nNodes
 
@@ -86,9 +101,11 @@ func (ms *Service) DeleteNode(ctx context.Context, id *ById) (*NodeResponse, err
 				target++
 			}
 
-			ms.transfer(n, n1, int64(target))
+			ms.transfer(n, n1, int64(target), collection)
 		}
 	}
 
+	ms.unwatchNode(n.ID)
+
 	return &NodeResponse{Success: true}, nil
 }
@@ -10,22 +10,43 @@ import (
 	"strings"
 )
 
-func (ms *Service) setNextIdIfHigher(newId uint64) {
+// get the id of the next record of a collection
+// NB: assumes an held lock on ms.idLock
+func (ms *Service) _nextIdOf(collection string) uint64 {
+	if collection == "" {
+		return ms.nextId
+	} else if nextId, found := ms.nextIds[collection]; found {
+		return nextId
+	}
+	return 1
+}
+
+// set the id of the next record of a collection
+// NB: assumes an held lock on ms.idLock
+func (ms *Service) _setNextIdOf(collection string, nextId uint64) {
+	if collection == "" {
+		ms.nextId = nextId
+	} else {
+		ms.nextIds[collection] = nextId
+	}
+}
+
+func (ms *Service) setNextIdIfHigher(collection string, newId uint64) {
 	ms.idLock.Lock()
 	defer ms.idLock.Unlock()
-	if ms.nextId <= newId {
-		ms.nextId = newId + 1
+	if ms._nextIdOf(collection) <= newId {
+		ms._setNextIdOf(collection, newId+1)
 	}
 }
 
-func (ms *Service) _findLessLoadedNode() *NodeInfo {
-	var lowestRecords *uint64
+func (ms *Service) _findLessLoadedNode(collection string) *NodeInfo {
+	var lowestRecords uint64
 	var targetNode *NodeInfo
 
 	for _, n := range ms.nodes {
-		st := n.Status()
-		if lowestRecords == nil || st.Records < *lowestRecords {
-			lowestRecords = &st.Records
+		records := n.Records(collection)
+		if targetNode == nil || records < lowestRecords {
+			lowestRecords = records
 			targetNode = n
 		}
 	}
@@ -33,16 +54,25 @@ func (ms *Service) _findLessLoadedNode() *NodeInfo {
 	return targetNode
 }
 
-func (ms *Service) findLessLoadedNode() *NodeInfo {
+func (ms *Service) findLessLoadedNode(collection string) *NodeInfo {
 	ms.nodesLock.RLock()
 	defer ms.nodesLock.RUnlock()
 
-	return ms._findLessLoadedNode()
+	return ms._findLessLoadedNode(collection)
 }
 
 // create a record from the given argument
 func (ms *Service) CreateRecord(ctx context.Context, record *Record) (*RecordResponse, error) {
-	targetNode := ms.findLessLoadedNode()
+	if err := storage.ValidateRecord(ms.schemaOf(record.Collection), record); err != nil {
+		return errRecordResponse("record does not match the schema: %v", err), nil
+	}
+
+	defer ms.lockKey(record.Key)()
+	if err := ms.checkKey(record.Collection, record.Key, 0); err != nil {
+		return errRecordResponse("%v", err), nil
+	}
+
+	targetNode := ms.findLessLoadedNode(record.Collection)
 
 	if targetNode == nil {
 		return errRecordResponse("No nodes available, try later"), nil
@@ -55,14 +85,16 @@ func (ms *Service) CreateRecord(ctx context.Context, record *Record) (*RecordRes
 	ms.idLock.Lock()
 	defer ms.idLock.Unlock()
 
-	record.Id = ms.nextId
+	record.Id = ms._nextIdOf(record.Collection)
+	// this is a new record, the node will assign its first version
+	record.Version = 0
+	record.UpdatedAt = 0
 
 	resp, err := targetNode.InternalClient.CreateRecordWithId(ctx, record)
 
 	if err == nil && resp.Success {
-		ms.nextId++
-		targetNode.status.Records++
-		targetNode.status.NextRecordId = ms.nextId
+		ms._setNextIdOf(record.Collection, record.Id+1)
+		targetNode._updateCollection(record.Collection, 1, record.Id+1)
 	}
 
 	return resp, err
@@ -70,22 +102,33 @@ func (ms *Service) CreateRecord(ctx context.Context, record *Record) (*RecordRes
 
 // update a record from the given argument
 func (ms *Service) UpdateRecord(_ context.Context, arg *Record) (*RecordResponse, error) {
+	defer ms.lockKey(arg.Key)()
+
 	ms.nodesLock.RLock()
 	defer ms.nodesLock.RUnlock()
 
+	if err := storage.ValidateUpdate(ms._schemaOf(arg.Collection), arg); err != nil {
+		return errRecordResponse("record does not match the schema: %v", err), nil
+	} else if err := ms._checkKey(arg.Collection, arg.Key, arg.Id); err != nil {
+		return errRecordResponse("%v", err), nil
+	}
+
 	ctx, cf := newCommContext()
 	defer cf()
 
 	results, errs := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
 		resp, err := node.Client.UpdateRecord(ctx, arg)
-		if err != nil || !resp.Success {
+		if isConflict(err, resp) {
+			cf() // the node owning the record answered
+			resultChannel <- resp
+		} else if err != nil || !resp.Success {
 			msg := getErrorMessage(err, resp)
 			if msg != storage.ErrRecordNotFound.Error() {
 				errorChannel <- fmt.Sprintf("node %d: %v", node.ID, msg)
 			}
 		} else {
 			cf() // cancel other queries
-			resultChannel <- true
+			resultChannel <- resp
 		}
 	})
 
@@ -99,7 +142,7 @@ func (ms *Service) UpdateRecord(_ context.Context, arg *Record) (*RecordResponse
 		log.Warning("Got %d results when only one was expected: %v", len(results), results)
 		fallthrough
 	case 1:
-		return &RecordResponse{Success: true}, nil
+		return results[0].(*RecordResponse), nil
 	}
 }
 
@@ -160,7 +203,7 @@ func (ms *Service) ListRecords(ctx context.Context, arg *ListRequest) (*RecordLi
 		n.RLock()
 		defer n.RUnlock()
 		orderedNodes = append(orderedNodes, n)
-		total += n.status.Records
+		total += n._records(arg.Collection)
 	}
 
 	sort.Slice(orderedNodes, func(i, j int) bool {
@@ -174,6 +217,10 @@ func (ms *Service) ListRecords(ctx context.Context, arg *ListRequest) (*RecordLi
 
 	start := arg.PerPage * (arg.Page - 1)
 	end := start + arg.PerPage
+	// the last page can be partially filled
+	if end > total {
+		end = total
+	}
 	cursor := uint64(0)
 	firstNodeIndex := -1
 	lastNodeIndex := -1
@@ -182,18 +229,19 @@ func (ms *Service) ListRecords(ctx context.Context, arg *ListRequest) (*RecordLi
 
 	for i, n := range orderedNodes {
 		lastNodeId = n.ID
+		nodeRecords := n._records(arg.Collection)
 
-		if cursor <= start && cursor+n.status.Records > start {
+		if cursor <= start && cursor+nodeRecords > start {
 			firstNodeIndex = i
 		}
 
-		if cursor < end && cursor+n.status.Records >= end {
+		if cursor < end && cursor+nodeRecords >= end {
 			lastNodeIndex = i
 			lastNodeRecords = end - cursor
 			break
 		}
 
-		cursor = cursor + n.status.Records
+		cursor = cursor + nodeRecords
 	}
 
 	if firstNodeIndex == -1 || lastNodeIndex == -1 {
@@ -205,14 +253,14 @@ func (ms *Service) ListRecords(ctx context.Context, arg *ListRequest) (*RecordLi
 	defer cf()
 
 	results, errs := doParallel(toQueryNodes, func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
-		arg := &ListRequest{Page: 1}
+		arg := &ListRequest{Page: 1, Collection: arg.Collection, Quantized: arg.Quantized}
 
 		if node.ID == lastNodeId {
 			arg.PerPage = lastNodeRecords
 		} else {
 			// this size will never exceed the original request,
 			// which we assume to be reasonable
-			arg.PerPage = node.status.Records
+			arg.PerPage = node._records(arg.Collection)
 		}
 
 		resp, err := node.Client.ListRecords(ctx, arg)
@@ -259,15 +307,18 @@ func (ms *Service) DeleteRecord(_ context.Context, arg *ById) (*RecordResponse,
 		ctx, _ := context.WithTimeout(ctx, timeout)
 
 		resp, err := node.Client.DeleteRecord(ctx, arg)
-		if err != nil || !resp.Success {
+		if isConflict(err, resp) {
+			cf() // the node owning the record answered
+			resultChannel <- resp
+		} else if err != nil || !resp.Success {
 			msg := getErrorMessage(err, resp)
 			if msg != notFoundError {
 				errorChannel <- fmt.Sprintf("node %d: %v", node.ID, msg)
 			}
 		} else {
 			cf() // cancel other queries
-			node.status.Records--
-			resultChannel <- true
+			node._updateCollection(arg.Collection, -1, 0)
+			resultChannel <- resp
 		}
 	})
 
@@ -281,11 +332,12 @@ func (ms *Service) DeleteRecord(_ context.Context, arg *ById) (*RecordResponse,
 		log.Warning("Got %d results when only one was expected", len(results))
 		fallthrough
 	case 1:
-		return &RecordResponse{Success: true}, nil
+		return results[0].(*RecordResponse), nil
 	}
 }
 
-// find records that meet the given requirements
+// find records that meet the given requirements, the query is forwarded
+// as it is to every node
 func (ms *Service) FindRecords(ctx context.Context, arg *ByMeta) (*FindResponse, error) {
 	notIndexedErrmsg := fmt.Sprintf("meta %v not indexed.", arg.Meta)
 
@@ -315,6 +367,10 @@ func (ms *Service) FindRecords(ctx context.Context, arg *ByMeta) (*FindResponse,
 		records = append(records, r.(*Record))
 	}
 
+	// records are partitioned among the nodes, so the union of their
+	// results is the result of the whole query, also for negations.
+	sort.Slice(records, func(i, j int) bool { return records[i].Id < records[j].Id })
+
 	return &FindResponse{Success: true, Records: records}, nil
 }
 
@@ -322,12 +378,18 @@ func (ms *Service) FindRecords(ctx context.Context, arg *ByMeta) (*FindResponse,
 
 // create a record with a given ID
 func (ms *Service) CreateRecordWithId(_ context.Context, in *Record) (*RecordResponse, error) {
+	defer ms.lockKey(in.Key)()
+
 	ms.nodesLock.RLock()
 	defer ms.nodesLock.RUnlock()
 
-	n := ms._findLessLoadedNode()
+	n := ms._findLessLoadedNode(in.Collection)
 	if n == nil {
 		return errRecordResponse("No nodes available, try later"), nil
+	} else if err := storage.ValidateRecord(ms._schemaOf(in.Collection), in); err != nil {
+		return errRecordResponse("record does not match the schema: %v", err), nil
+	} else if err := ms._checkKey(in.Collection, in.Key, in.Id); err != nil {
+		return errRecordResponse("%v", err), nil
 	}
 
 	// must query the record from nodes to check its existence
@@ -336,7 +398,7 @@ func (ms *Service) CreateRecordWithId(_ context.Context, in *Record) (*RecordRes
 	defer cf()
 
 	results, _ := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
-		resp, err := node.Client.ReadRecord(ctx, &ById{Id: in.Id})
+		resp, err := node.Client.ReadRecord(ctx, &ById{Id: in.Id, Collection: in.Collection, Quantized: true})
 		if err == nil && resp.Success {
 			cf() // cancel other queries
 			resultChannel <- true
@@ -352,14 +414,11 @@ func (ms *Service) CreateRecordWithId(_ context.Context, in *Record) (*RecordRes
 
 	resp, err := n.InternalClient.CreateRecordWithId(ctx, in)
 	if err == nil && resp.Success {
-		n.status.Records++
-		if n.status.NextRecordId <= in.Id {
-			n.status.NextRecordId = in.Id + 1
-		}
+		n._updateCollection(in.Collection, 1, in.Id+1)
 		ms.idLock.Lock()
 		defer ms.idLock.Unlock()
-		if ms.nextId < n.status.NextRecordId {
-			ms.nextId = n.status.NextRecordId
+		if ms._nextIdOf(in.Collection) <= in.Id {
+			ms._setNextIdOf(in.Collection, in.Id+1)
 		}
 	}
 	return resp, err
@@ -374,6 +433,14 @@ func (ms *Service) CreateRecordsWithId(ctx context.Context, in *Records) (*Recor
 		return errRecordResponse("No nodes available, try later"), nil
 	}
 
+	// validate everything first so records are never partially created
+	schema := ms._schemaOf(in.Collection)
+	for _, r := range in.Records {
+		if err := storage.ValidateRecord(schema, r); err != nil {
+			return errRecordResponse("record %d does not match the schema: %v", r.Id, err), nil
+		}
+	}
+
 	perNode := len(in.Records) / len(ms.nodes)
 	remainder := len(in.Records) % len(ms.nodes)
 
@@ -393,13 +460,13 @@ func (ms *Service) CreateRecordsWithId(ctx context.Context, in *Records) (*Recor
 		ctx, cf := newCommContext()
 		defer cf()
 
-		if resp, err := n.InternalClient.CreateRecordsWithId(ctx, &Records{Records: records}); err != nil || !resp.Success {
+		if resp, err := n.InternalClient.CreateRecordsWithId(ctx, &Records{Records: records, Collection: in.Collection}); err != nil || !resp.Success {
 			return fmt.Errorf("%s", getErrorMessage(err, resp))
 		} else {
-			n.status.Records += uint64(len(records))
-			if n.status.NextRecordId <= maxId {
-				n.status.NextRecordId = maxId + 1
-				ms.setNextIdIfHigher(maxId + 1)
+			_, nextId := collectionStatus(&n.status, in.Collection)
+			n._updateCollection(in.Collection, int64(len(records)), maxId+1)
+			if nextId <= maxId {
+				ms.setNextIdIfHigher(in.Collection, maxId+1)
 			}
 		}
 		return nil
@@ -432,7 +499,7 @@ func (ms *Service) CreateRecordsWithId(ctx context.Context, in *Records) (*Recor
 		if err := creator(successfulNode, in.Records[start:end]); err != nil {
 			// rollback
 
-			arg := &RecordIds{Ids: make([]uint64, 0, len(in.Records))}
+			arg := &RecordIds{Ids: make([]uint64, 0, len(in.Records)), Collection: in.Collection}
 
 			for _, r := range in.Records {
 				arg.Ids = append(arg.Ids, r.Id)
@@ -459,7 +526,7 @@ func (ms *Service) DeleteRecords(ctx context.Context, in *RecordIds) (*RecordRes
 	defer ms.nodesLock.RUnlock()
 
 	for _, n := range ms.nodes {
-		oldTotal += n.Status().Records
+		oldTotal += n.Records(in.Collection)
 	}
 
 	ctx, cf := newCommContext()
@@ -469,13 +536,15 @@ func (ms *Service) DeleteRecords(ctx context.Context, in *RecordIds) (*RecordRes
 		node.InternalClient.DeleteRecords(ctx, in)
 		node.UpdateStatus()
 
-		ms.setNextIdIfHigher(node.Status().NextRecordId)
+		st := node.Status()
+		_, nextId := collectionStatus(&st, in.Collection)
+		ms.setNextIdIfHigher(in.Collection, nextId)
 	})
 
 	ms.balance()
 
 	for _, n := range ms.nodes {
-		newTotal += n.Status().Records
+		newTotal += n.Records(in.Collection)
 	}
 
 	deleted := oldTotal - newTotal
@@ -57,7 +57,7 @@ func (ms *Service) Run(ctx context.Context, arg *Call) (*CallResponse, error) {
 			return errCallResponse("Unable to parse record id form parameter #%d: %v", i, err), nil
 		}
 
-		record, err := ms.ReadRecord(ctx, &ById{Id: recId})
+		record, err := ms.ReadRecord(ctx, &ById{Id: recId, Collection: arg.Collection})
 
 		if err != nil || !record.Success {
 			msg := getErrorMessage(err, record)
@@ -115,7 +115,7 @@ func (ms *Service) Run(ctx context.Context, arg *Call) (*CallResponse, error) {
 			node2oracleId[n] = oId
 		}()
 
-		resp1, err := n.Client.Run(ctx, &Call{OracleId: oId, Args: arg.Args})
+		resp1, err := n.Client.Run(ctx, &Call{OracleId: oId, Args: arg.Args, Collection: arg.Collection})
 		if err != nil || !resp1.Success {
 			return nil, getErrorMessage(err, resp1)
 		}
@@ -0,0 +1,126 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/evilsocket/islazy/log"
+	"github.com/evilsocket/sum/node/storage"
+	. "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
+)
+
+// get the schema of a collection, nodes are kept in sync so the
+// first one having the collection is enough
+// NB: assumes an held lock on ms.nodesLock
+func (ms *Service) _schemaOf(collection string) *Schema {
+	for _, n := range ms.nodes {
+		st := n.Status()
+		if collection == "" {
+			return st.Schema
+		}
+		for _, c := range st.Collections {
+			if c.Name == collection {
+				return c.Schema
+			}
+		}
+	}
+	return nil
+}
+
+// get the schema of a collection
+func (ms *Service) schemaOf(collection string) *Schema {
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+	return ms._schemaOf(collection)
+}
+
+// set the schema of a collection on every node
+func (ms *Service) SetSchema(_ context.Context, arg *Collection) (*CollectionResponse, error) {
+	if err := storage.CheckSchema(arg.Schema); err != nil {
+		return errCollectionResponse("%v", err), nil
+	}
+
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+
+	if len(ms.nodes) == 0 {
+		return errCollectionResponse("No nodes available, try later"), nil
+	}
+
+	found := false
+	for _, c := range ms._collections() {
+		if c == arg.Name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errCollectionResponse("collection %s not found.", arg.Name), nil
+	}
+
+	previous := &Collection{Name: arg.Name, Schema: ms._schemaOf(arg.Name)}
+
+	ctx, cf := newCommContext()
+	defer cf()
+
+	results, errs := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
+		resp, err := node.Client.SetSchema(ctx, arg)
+		if err != nil || !resp.Success {
+			errorChannel <- fmt.Sprintf("node %d: %v", node.ID, getErrorMessage(err, resp))
+		} else {
+			resultChannel <- node
+		}
+	})
+
+	if len(errs) > 0 {
+		// rollback, best effort
+		for _, res := range results {
+			node := res.(*NodeInfo)
+			if resp, err := node.Client.SetSchema(ctx, previous); err != nil || !resp.Success {
+				log.Warning("Unable to restore the schema of collection '%s' on node %d: %v", arg.Name, node.ID, getErrorMessage(err, resp))
+			}
+		}
+		return errCollectionResponse("Unable to set schema on nodes: [%s]", strings.Join(errs, ", ")), nil
+	}
+
+	for _, n := range ms.nodes {
+		n.UpdateStatus()
+	}
+
+	return &CollectionResponse{Success: true, Collections: []*Collection{{Name: arg.Name, Schema: ms._schemaOf(arg.Name)}}}, nil
+}
+
+// set on a node the schemas of the collections of the other nodes
+// NB: assumes an held lock on ms.nodesLock
+func (ms *Service) setSchemasOn(n *NodeInfo) {
+	if len(ms.nodes) == 0 {
+		// the first node defines the schemas
+		return
+	}
+
+	ctx, cf := newCommContext()
+	defer cf()
+
+	st := n.Status()
+	updated := 0
+	for _, name := range ms._collections() {
+		schema, current := ms._schemaOf(name), collectionSchema(&st, name)
+		if storage.EmptySchema(schema) && storage.EmptySchema(current) {
+			continue
+		} else if proto.Equal(schema, current) {
+			continue
+		}
+
+		if resp, err := n.Client.SetSchema(ctx, &Collection{Name: name, Schema: schema}); err != nil || !resp.Success {
+			log.Error("Unable to set the schema of collection '%s' on node %s: %v", name, n.Name, getErrorMessage(err, resp))
+		} else {
+			updated++
+		}
+	}
+
+	if updated > 0 {
+		n.UpdateStatus()
+	}
+}
@@ -1,12 +1,15 @@
 package master
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/evilsocket/sum/node/service"
+	"github.com/evilsocket/sum/node/storage"
 
 	"github.com/evilsocket/islazy/log"
 	"github.com/robertkrimen/otto"
@@ -21,10 +24,15 @@ type Service struct {
 	nodes []*NodeInfo
 	// next node id
 	nextNodeId uint
-	// control access to `nextId`
+	// control access to `nextId` and `nextIds`
 	idLock sync.RWMutex
-	// id of the next record
+	// id of the next record of the default collection
 	nextId uint64
+	// id of the next record of the named collections
+	nextIds map[string]uint64
+	// serialize the writes of records with a key, so that checking
+	// the key on all the nodes and storing the record are atomic
+	keyLock sync.Mutex
 	// control access to `raccoons`
 	cageLock sync.RWMutex
 	// raccoons ready to mess with messy JS code
@@ -48,12 +56,24 @@ type Service struct {
 	address string
 	// configuration file path
 	configFile string
+
+	// changes of the records of every node
+	feed *storage.Feed
+	// control access to `watching`, `nodeWatchers` and `cursors`
+	watchLock sync.Mutex
+	// number of clients watching the feed
+	watching int
+	// stop following the feed of a node
+	nodeWatchers map[uint]context.CancelFunc
+	// sequence number of the last event received from each node
+	cursors map[uint]uint64
 }
 
 // create a new Service that manage the given nodes
 func NewService(nodes []*NodeInfo, credsPath, address string) (*Service, error) {
 	ms := &Service{
 		nextId:        1,
+		nextIds:       make(map[string]uint64),
 		nextRaccoonId: 1,
 		nextNodeId:    uint(len(nodes) + 1),
 		nodes:         nodes[:],
@@ -64,6 +84,9 @@ func NewService(nodes []*NodeInfo, credsPath, address string) (*Service, error)
 		uid:           uint64(os.Getuid()),
 		credsPath:     credsPath,
 		address:       address,
+		feed:          storage.NewFeed(),
+		nodeWatchers:  make(map[uint]context.CancelFunc),
+		cursors:       make(map[uint]uint64),
 	}
 
 	ms.balance()
@@ -107,6 +130,7 @@ func (ms *Service) UpdateNodes() {
 	}
 }
 
+// number of records of the default collection
 func (ms *Service) NumRecords() int {
 	res := 0
 
@@ -120,6 +144,24 @@ func (ms *Service) NumRecords() int {
 	return res
 }
 
+// names of the collections found on any node, the default
+// collection is always the first one
+// NB: assumes an held lock on ms.nodesLock
+func (ms *Service) _collections() []string {
+	names := []string{""}
+	seen := map[string]bool{"": true}
+	for _, n := range ms.nodes {
+		for _, name := range n.Collections() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (ms *Service) NumOracles() int {
 	ms.cageLock.RLock()
 	defer ms.cageLock.RUnlock()
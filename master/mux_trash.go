@@ -0,0 +1,180 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/evilsocket/sum/node/storage"
+	. "github.com/evilsocket/sum/proto"
+)
+
+// a deleted record and the node whose trash it is in
+type trashed struct {
+	node   *NodeInfo
+	record *Record
+}
+
+// get the deleted records of a collection from the trash of every node,
+// if a record is in more than one trash the last deleted copy is kept
+// NB: assumes an held lock on ms.nodesLock
+func (ms *Service) _trashOf(collection string, quantized bool) (map[uint64]trashed, []string) {
+	ctx, cf := newCommContext()
+	defer cf()
+
+	results, errs := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
+		resp, err := node.Client.ListTrash(ctx, &ListRequest{Page: 1, PerPage: math.MaxInt32, Collection: collection, Quantized: quantized})
+		if err != nil {
+			errorChannel <- fmt.Sprintf("node %d: %v", node.ID, err)
+			return
+		}
+		for _, r := range resp.Records {
+			resultChannel <- trashed{node: node, record: r}
+		}
+	})
+
+	byId := make(map[uint64]trashed, len(results))
+	for _, res := range results {
+		t := res.(trashed)
+		if prev, found := byId[t.record.Id]; !found || prev.record.DeletedAt < t.record.DeletedAt {
+			byId[t.record.Id] = t
+		}
+	}
+
+	return byId, errs
+}
+
+// list the deleted records of every node
+func (ms *Service) ListTrash(ctx context.Context, arg *ListRequest) (*RecordListResponse, error) {
+	if arg.Page < 1 {
+		arg.Page = 1
+	}
+
+	if arg.PerPage < 1 {
+		arg.PerPage = 1
+	}
+
+	ms.nodesLock.RLock()
+	byId, errs := ms._trashOf(arg.Collection, arg.Quantized)
+	ms.nodesLock.RUnlock()
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("unable to communicate with nodes: [%s]", strings.Join(errs, ", "))
+	}
+
+	records := make([]*Record, 0, len(byId))
+	for _, t := range byId {
+		records = append(records, t.record)
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Id < records[j].Id })
+
+	total := uint64(len(records))
+	pages := total / arg.PerPage
+	if total%arg.PerPage > 0 {
+		pages++
+	}
+
+	start := arg.PerPage * (arg.Page - 1)
+	end := start + arg.PerPage
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+
+	return &RecordListResponse{Total: total, Pages: pages, Records: records[start:end]}, nil
+}
+
+// move deleted records back from the trash of the nodes
+func (ms *Service) Undelete(_ context.Context, arg *RecordIds) (*RecordResponse, error) {
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+
+	if len(ms.nodes) == 0 {
+		return errRecordResponse("No nodes available, try later"), nil
+	}
+
+	byId, errs := ms._trashOf(arg.Collection, true)
+	if len(errs) > 0 {
+		return errRecordResponse("Unable to read the trash of nodes: [%s]", strings.Join(errs, ", ")), nil
+	}
+
+	byNode := make(map[*NodeInfo][]uint64)
+	for _, id := range arg.Ids {
+		t, found := byId[id]
+		if !found {
+			return errRecordResponse("record %d: %v", id, storage.ErrRecordNotFound), nil
+		}
+		byNode[t.node] = append(byNode[t.node], id)
+	}
+
+	ctx, cf := newCommContext()
+	defer cf()
+
+	// records moved between nodes are trashed by the node they were moved
+	// from, so a record can be in the trash and still exist on another node
+	live, _ := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
+		for _, id := range arg.Ids {
+			resp, err := node.Client.ReadRecord(ctx, &ById{Id: id, Collection: arg.Collection, Quantized: true})
+			if err == nil && resp.Success {
+				resultChannel <- id
+			}
+		}
+	})
+
+	if len(live) > 0 {
+		return errRecordResponse("record %d: %v", live[0].(uint64), storage.ErrInvalidID), nil
+	}
+
+	restored := 0
+	errs = nil
+	for node, ids := range byNode {
+		resp, err := node.Client.Undelete(ctx, &RecordIds{Ids: ids, Collection: arg.Collection})
+		if err != nil || !resp.Success {
+			errs = append(errs, fmt.Sprintf("node %d: %v", node.ID, getErrorMessage(err, resp)))
+		} else if n, err := strconv.Atoi(resp.Msg); err == nil {
+			restored += n
+		}
+		node.UpdateStatus()
+	}
+
+	if len(errs) > 0 {
+		return errRecordResponse("restored %d records out of %d: [%s]", restored, len(arg.Ids), strings.Join(errs, ", ")), nil
+	}
+
+	return &RecordResponse{Success: true, Msg: fmt.Sprintf("%d", restored)}, nil
+}
+
+// permanently remove deleted records from the trash of every node
+func (ms *Service) PurgeTrash(_ context.Context, arg *PurgeRequest) (*RecordResponse, error) {
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+
+	ctx, cf := newCommContext()
+	defer cf()
+
+	results, errs := ms.doParallel(func(node *NodeInfo, resultChannel chan<- interface{}, errorChannel chan<- string) {
+		resp, err := node.Client.PurgeTrash(ctx, arg)
+		if err != nil || !resp.Success {
+			errorChannel <- fmt.Sprintf("node %d: %v", node.ID, getErrorMessage(err, resp))
+		} else if n, err := strconv.Atoi(resp.Msg); err == nil {
+			resultChannel <- n
+		}
+	})
+
+	purged := 0
+	for _, res := range results {
+		purged += res.(int)
+	}
+
+	if len(errs) > 0 {
+		return errRecordResponse("purged %d records: [%s]", purged, strings.Join(errs, ", ")), nil
+	}
+
+	return &RecordResponse{Success: true, Msg: fmt.Sprintf("%d", purged)}, nil
+}
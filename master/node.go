@@ -95,3 +95,86 @@ func CreateNode(node, certFile string) (*NodeInfo, error) {
 
 	return ni, nil
 }
+
+// get the number of records and the next record id of a collection,
+// the default one if empty, from a node's status
+func collectionStatus(st *ServerInfo, collection string) (records uint64, nextRecordId uint64) {
+	if collection == "" {
+		return st.Records, st.NextRecordId
+	}
+	for _, c := range st.Collections {
+		if c.Name == collection {
+			return c.Records, c.NextRecordId
+		}
+	}
+	return 0, 0
+}
+
+// get the schema of a collection, the default one if empty, from
+// a node's status
+func collectionSchema(st *ServerInfo, collection string) *Schema {
+	if collection == "" {
+		return st.Schema
+	}
+	for _, c := range st.Collections {
+		if c.Name == collection {
+			return c.Schema
+		}
+	}
+	return nil
+}
+
+// update the counters of a collection in the node's status
+// NB: assumes an held lock on the node
+func (n *NodeInfo) _updateCollection(collection string, delta int64, nextRecordId uint64) {
+	if collection == "" {
+		n.status.Records = uint64(int64(n.status.Records) + delta)
+		if n.status.NextRecordId < nextRecordId {
+			n.status.NextRecordId = nextRecordId
+		}
+		return
+	}
+
+	// entries are shared with the copies returned by Status, so they
+	// are replaced instead of being modified
+	collections := make([]*Collection, len(n.status.Collections))
+	copy(collections, n.status.Collections)
+	for i, c := range collections {
+		if c.Name == collection {
+			updated := *c
+			updated.Records = uint64(int64(updated.Records) + delta)
+			if updated.NextRecordId < nextRecordId {
+				updated.NextRecordId = nextRecordId
+			}
+			collections[i] = &updated
+			break
+		}
+	}
+	n.status.Collections = collections
+}
+
+// get the number of records of a collection on this node
+// NB: assumes an held lock on the node
+func (n *NodeInfo) _records(collection string) uint64 {
+	records, _ := collectionStatus(&n.status, collection)
+	return records
+}
+
+// get the number of records of a collection on this node
+func (n *NodeInfo) Records(collection string) uint64 {
+	n.RLock()
+	defer n.RUnlock()
+	return n._records(collection)
+}
+
+// get the names of the collections on this node
+func (n *NodeInfo) Collections() []string {
+	n.RLock()
+	defer n.RUnlock()
+
+	names := make([]string, 0, len(n.status.Collections))
+	for _, c := range n.status.Collections {
+		names = append(names, c.Name)
+	}
+	return names
+}
@@ -71,6 +71,14 @@ func TestServiceCreateRecordNotUniqueId(t *testing.T) {
 	}
 }
 
+// compares two records ignoring their version and update time
+func sameRecord(a, b *pb.Record) bool {
+	ac, bc := proto.Clone(a).(*pb.Record), proto.Clone(b).(*pb.Record)
+	ac.Version, ac.UpdatedAt = 0, 0
+	bc.Version, bc.UpdatedAt = 0, 0
+	return proto.Equal(ac, bc)
+}
+
 func TestServiceUpdateRecord(t *testing.T) {
 	setup(t, true, true)
 	defer teardown(t)
@@ -91,8 +99,10 @@ func TestServiceUpdateRecord(t *testing.T) {
 		t.Fatalf("unaexpected error %v", err)
 	} else if stored.Record == nil {
 		t.Fatal("expected stored record with id 1")
-	} else if !proto.Equal(stored.Record, &updatedRecord) {
+	} else if !sameRecord(stored.Record, &updatedRecord) {
 		t.Fatal("record has not been updated as expected")
+	} else if stored.Record.Version != 2 {
+		t.Fatalf("expected version 2, got %d", stored.Record.Version)
 	}
 }
 
@@ -111,6 +121,35 @@ func TestService_FindRecords(t *testing.T) {
 	}
 }
 
+func TestService_FindRecordsWithFilters(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	prefix := pb.MetaFilter{
+		Meta:  "666",
+		Op:    pb.MetaFilter_PREFIX,
+		Value: &pb.MetaValue{Value: &pb.MetaValue_Text{Text: "6"}},
+	}
+
+	svc, err := NewClient(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	} else if resp, err := svc.FindRecords(context.TODO(), &pb.ByMeta{Filters: []*pb.MetaFilter{&prefix}}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if len(resp.Records) != testRecords {
+		t.Fatalf("unexpected records: %v", resp.Records)
+	}
+
+	prefix.Value = &pb.MetaValue{Value: &pb.MetaValue_Number{Number: 6}}
+	if resp, err := svc.FindRecords(context.TODO(), &pb.ByMeta{Filters: []*pb.MetaFilter{&prefix}}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response")
+	}
+}
+
 func TestService_FindRecordsNotFound(t *testing.T) {
 	setup(t, true, true)
 	defer teardown(t)
@@ -186,7 +225,7 @@ func TestServiceReadRecord(t *testing.T) {
 		t.Fatalf("expected success response: %v", resp)
 	} else if resp.Record == nil {
 		t.Fatal("expected record pointer")
-	} else if testRecord.Id = byID.Id; !proto.Equal(resp.Record, &testRecord) {
+	} else if testRecord.Id = byID.Id; !sameRecord(resp.Record, &testRecord) {
 		t.Fatalf("unexpected record: %v", resp.Record)
 	}
 }
@@ -229,7 +268,7 @@ func TestServiceListRecordsSinglePage(t *testing.T) {
 		t.Fatalf("expected %d total records, got %d", testRecords, len(resp.Records))
 	} else {
 		for _, r := range resp.Records {
-			if testRecord.Id = r.Id; !proto.Equal(r, &testRecord) {
+			if testRecord.Id = r.Id; !sameRecord(r, &testRecord) {
 				t.Fatalf("unexpected record: %v", r)
 			}
 		}
@@ -257,7 +296,7 @@ func TestServiceListRecordsMultiPage(t *testing.T) {
 		t.Fatalf("expected %d total records, got %d", 2, len(resp.Records))
 	} else {
 		for _, r := range resp.Records {
-			if testRecord.Id = r.Id; !proto.Equal(r, &testRecord) {
+			if testRecord.Id = r.Id; !sameRecord(r, &testRecord) {
 				t.Fatalf("unexpected record: %v", r)
 			}
 		}
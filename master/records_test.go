@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	pb "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 	. "github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
@@ -62,7 +63,8 @@ func TestService_MultipleAnswers(t *testing.T) {
 
 	aRecord := resp.Records[0]
 
-	resp1, err := sum2.CreateRecordWithId(context.TODO(), aRecord)
+	// nodes are in process, make sure they don't share the same object
+	resp1, err := sum2.CreateRecordWithId(context.TODO(), proto.Clone(aRecord).(*pb.Record))
 	NoError(t, err)
 	True(t, resp1.Success, resp1.Msg)
 
@@ -542,3 +544,94 @@ func TestService_CreateRecordWithId2(t *testing.T) {
 	False(t, resp.Success)
 	Equal(t, "No nodes available, try later", resp.Msg)
 }
+
+func TestService_VersionConflict(t *testing.T) {
+	ns, err := setupPopulatedNetwork(2, 1)
+	NoError(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	resp, err := ms.ReadRecord(context.TODO(), &pb.ById{Id: 1})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	update := proto.Clone(resp.Record).(*pb.Record)
+	update.Data[0] = update.Data[0] / 2
+
+	resp, err = ms.UpdateRecord(context.TODO(), update)
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+	Equal(t, fmt.Sprintf("%d", update.Version+1), resp.Msg)
+
+	// same expected version, the record has been updated already
+	resp, err = ms.UpdateRecord(context.TODO(), update)
+	NoError(t, err)
+	False(t, resp.Success)
+	Equal(t, "version conflict", resp.Msg)
+	NotNil(t, resp.Record)
+	Equal(t, update.Version+1, resp.Record.Version)
+
+	resp, err = ms.DeleteRecord(context.TODO(), &pb.ById{Id: 1, Version: update.Version})
+	NoError(t, err)
+	False(t, resp.Success)
+	Equal(t, "version conflict", resp.Msg)
+
+	resp, err = ms.DeleteRecord(context.TODO(), &pb.ById{Id: 1, Version: update.Version + 1})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+}
+
+func TestMuxService_FindRecordsWithQuery(t *testing.T) {
+	ns, err := setupNetwork(2, 1)
+	Nil(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	for i := 1; i <= 6; i++ {
+		rec := &pb.Record{
+			Data: []float32{1, 2, 3},
+			Meta: map[string]string{"parity": []string{"even", "odd"}[i%2]},
+			TypedMeta: map[string]*pb.MetaValue{
+				"n": {Value: &pb.MetaValue_Number{Number: float64(i)}},
+			},
+		}
+		resp, err := ms.CreateRecord(context.TODO(), rec)
+		NoError(t, err)
+		True(t, resp.Success, resp.Msg)
+	}
+
+	Equal(t, 3, ns.nodes[0].svc.NumRecords())
+	Equal(t, 3, ns.nodes[1].svc.NumRecords())
+
+	// odd AND NOT n > 4
+	query := &pb.MetaQuery{
+		Op: pb.MetaQuery_AND,
+		Operands: []*pb.MetaQuery{
+			{Op: pb.MetaQuery_FILTER, Filter: &pb.MetaFilter{
+				Meta:  "parity",
+				Value: &pb.MetaValue{Value: &pb.MetaValue_Text{Text: "odd"}},
+			}},
+			{Op: pb.MetaQuery_NOT, Operands: []*pb.MetaQuery{
+				{Op: pb.MetaQuery_FILTER, Filter: &pb.MetaFilter{
+					Meta:  "n",
+					Op:    pb.MetaFilter_GT,
+					Value: &pb.MetaValue{Value: &pb.MetaValue_Number{Number: 4}},
+				}},
+			}},
+		},
+	}
+
+	resp, err := ms.FindRecords(context.TODO(), &pb.ByMeta{Query: query})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+	Equal(t, 2, len(resp.Records))
+	Equal(t, 1.0, resp.Records[0].TypedMeta["n"].GetNumber())
+	Equal(t, 3.0, resp.Records[1].TypedMeta["n"].GetNumber())
+
+	// invalid queries are reported by every node
+	resp, err = ms.FindRecords(context.TODO(), &pb.ByMeta{Query: &pb.MetaQuery{Op: pb.MetaQuery_NOT}})
+	NoError(t, err)
+	False(t, resp.Success)
+}
@@ -0,0 +1,85 @@
+package master
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+	. "github.com/stretchr/testify/require"
+)
+
+func TestService_SetSchema(t *testing.T) {
+	ns, err := setupNetwork(2, 1)
+	NoError(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	resp, err := ms.SetSchema(context.TODO(), &pb.Collection{Name: "nope", Schema: &pb.Schema{Dimension: 2}})
+	NoError(t, err)
+	False(t, resp.Success)
+
+	resp, err = ms.SetSchema(context.TODO(), &pb.Collection{Schema: &pb.Schema{Shape: []uint64{2, 0}}})
+	NoError(t, err)
+	False(t, resp.Success)
+
+	schema := &pb.Schema{Dimension: 2, RequiredMeta: []string{"label"}}
+	resp, err = ms.SetSchema(context.TODO(), &pb.Collection{Schema: schema})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	for _, n := range ns.nodes {
+		info, err := n.svc.Info(context.TODO(), &pb.Empty{})
+		NoError(t, err)
+		Equal(t, uint64(2), info.Schema.GetDimension())
+	}
+
+	info, err := ms.Info(context.TODO(), &pb.Empty{})
+	NoError(t, err)
+	Equal(t, []string{"label"}, info.Schema.GetRequiredMeta())
+
+	rec, err := ms.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 2, 3}, Meta: map[string]string{"label": "x"}})
+	NoError(t, err)
+	False(t, rec.Success)
+	Contains(t, rec.Msg, "does not match the schema")
+
+	rec, err = ms.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 2}, Meta: map[string]string{"label": "x"}})
+	NoError(t, err)
+	True(t, rec.Success, rec.Msg)
+
+	// invalid batches are rejected as a whole
+	rec, err = ms.CreateRecordsWithId(context.TODO(), &pb.Records{Records: []*pb.Record{
+		{Id: 10, Data: []float32{1, 2}, Meta: map[string]string{"label": "x"}},
+		{Id: 11, Data: []float32{1, 2}},
+	}})
+	NoError(t, err)
+	False(t, rec.Success)
+	Equal(t, 1, ms.NumRecords())
+
+	rec, err = ms.UpdateRecord(context.TODO(), &pb.Record{Id: 1, Data: []float32{1}})
+	NoError(t, err)
+	False(t, rec.Success)
+	Contains(t, rec.Msg, "does not match the schema")
+
+	rec, err = ms.UpdateRecord(context.TODO(), &pb.Record{Id: 1, Data: []float32{3, 4}})
+	NoError(t, err)
+	True(t, rec.Success, rec.Msg)
+
+	// collections have their own schema
+	coll, err := ms.CreateCollection(context.TODO(), &pb.ByName{Name: "images"})
+	NoError(t, err)
+	True(t, coll.Success, coll.Msg)
+
+	coll, err = ms.SetSchema(context.TODO(), &pb.Collection{Name: "images", Schema: &pb.Schema{Dimension: 4}})
+	NoError(t, err)
+	True(t, coll.Success, coll.Msg)
+
+	list, err := ms.ListCollections(context.TODO(), &pb.Empty{})
+	NoError(t, err)
+	Len(t, list.Collections, 1)
+	Equal(t, uint64(4), list.Collections[0].Schema.GetDimension())
+
+	rec, err = ms.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 2}, Collection: "images"})
+	NoError(t, err)
+	False(t, rec.Success)
+}
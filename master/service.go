@@ -2,8 +2,10 @@ package master
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/evilsocket/sum/node/service"
+	"github.com/evilsocket/sum/node/storage"
 
 	. "github.com/evilsocket/sum/proto"
 )
@@ -12,10 +14,33 @@ import (
 func (ms *Service) Info(ctx context.Context, arg *Empty) (*ServerInfo, error) {
 	nRecords := ms.NumRecords()
 
+	ms.nodesLock.RLock()
+	collections := ms._collectionsInfo()
+	schema := ms._schemaOf("")
+	trashed := uint64(0)
+	for _, n := range ms.nodes {
+		trashed += n.Status().RecordsTrashed
+	}
+	ms.nodesLock.RUnlock()
+
 	ms.idLock.RLock()
 	defer ms.idLock.RUnlock()
 	ms.cageLock.RLock()
 	defer ms.cageLock.RUnlock()
 
-	return service.Info("", ms.credsPath, ms.address, ms.started, nRecords, len(ms.raccoons), ms.nextId), nil
+	info := service.Info("", ms.credsPath, ms.address, ms.started, nRecords, len(ms.raccoons), ms.nextId, storage.CacheStats{})
+	info.Collections = collections
+	info.Schema = schema
+	info.RecordsTrashed = trashed
+	return info, nil
+}
+
+// snapshots are per node, the master has no storage of its own
+func (ms *Service) Snapshot(arg *Empty, stream SumService_SnapshotServer) error {
+	return fmt.Errorf("snapshots are not supported by the master, connect to a node instead")
+}
+
+// snapshots are per node, the master has no storage of its own
+func (ms *Service) Restore(stream SumService_RestoreServer) error {
+	return stream.SendAndClose(&RestoreResponse{Success: false, Msg: "snapshots are not supported by the master, connect to a node instead"})
 }
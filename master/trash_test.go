@@ -0,0 +1,79 @@
+package master
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+	. "github.com/stretchr/testify/require"
+)
+
+func TestService_Trash(t *testing.T) {
+	ns, err := setupNetwork(2, 1)
+	NoError(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	for i := 1; i <= 4; i++ {
+		resp, err := ms.CreateRecord(context.TODO(), &pb.Record{Data: []float32{float32(i)}})
+		NoError(t, err)
+		True(t, resp.Success, resp.Msg)
+	}
+
+	resp, err := ms.DeleteRecord(context.TODO(), &pb.ById{Id: 1})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	resp, err = ms.DeleteRecord(context.TODO(), &pb.ById{Id: 2})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	list, err := ms.ListTrash(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 10})
+	NoError(t, err)
+	Equal(t, uint64(2), list.Total)
+	Equal(t, uint64(1), list.Records[0].Id)
+	Equal(t, uint64(2), list.Records[1].Id)
+
+	list, err = ms.ListTrash(context.TODO(), &pb.ListRequest{Page: 2, PerPage: 1})
+	NoError(t, err)
+	Equal(t, uint64(2), list.Pages)
+	Len(t, list.Records, 1)
+	Equal(t, uint64(2), list.Records[0].Id)
+
+	resp, err = ms.Undelete(context.TODO(), &pb.RecordIds{Ids: []uint64{1, 3}})
+	NoError(t, err)
+	False(t, resp.Success)
+
+	resp, err = ms.Undelete(context.TODO(), &pb.RecordIds{Ids: []uint64{1}})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+	Equal(t, "1", resp.Msg)
+
+	read, err := ms.ReadRecord(context.TODO(), &pb.ById{Id: 1})
+	NoError(t, err)
+	True(t, read.Success, read.Msg)
+	Equal(t, []float32{1}, read.Record.Data)
+
+	// records moved by the balancer still exist elsewhere and are not
+	// trashed by the node they were moved from
+	ms.nodesLock.RLock()
+	from, to := ms.nodes[0], ms.nodes[1]
+	moving := ns.nodes[0].svc.NumRecords()
+	ms.transfer(from, to, int64(moving), "")
+	ms.nodesLock.RUnlock()
+
+	list, err = ms.ListTrash(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 10})
+	NoError(t, err)
+	Equal(t, uint64(1), list.Total)
+	Equal(t, uint64(2), list.Records[0].Id)
+
+	resp, err = ms.PurgeTrash(context.TODO(), &pb.PurgeRequest{})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+	Equal(t, "1", resp.Msg)
+
+	list, err = ms.ListTrash(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 10})
+	NoError(t, err)
+	Zero(t, list.Total)
+}
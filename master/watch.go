@@ -0,0 +1,158 @@
+package master
+
+import (
+	"context"
+	"time"
+
+	"github.com/evilsocket/islazy/log"
+	"github.com/evilsocket/sum/node/service"
+	. "github.com/evilsocket/sum/proto"
+)
+
+// time to wait before following again the feed of a node after an error
+var watchRetryPeriod = 1 * time.Second
+
+// start following the feeds of the nodes if this is the first watcher
+func (ms *Service) startWatching() {
+	ms.nodesLock.RLock()
+	defer ms.nodesLock.RUnlock()
+
+	ms.watchLock.Lock()
+	defer ms.watchLock.Unlock()
+
+	ms.watching++
+	if ms.watching == 1 {
+		for _, n := range ms.nodes {
+			ms._watchNode(n)
+		}
+	}
+}
+
+// stop following the feeds of the nodes if this was the last watcher,
+// the last sequence numbers received are kept to resume from them
+func (ms *Service) stopWatching() {
+	ms.watchLock.Lock()
+	defer ms.watchLock.Unlock()
+
+	ms.watching--
+	if ms.watching == 0 {
+		for id, cancel := range ms.nodeWatchers {
+			cancel()
+			delete(ms.nodeWatchers, id)
+		}
+	}
+}
+
+// NB: assumes an held lock on ms.watchLock
+func (ms *Service) _watchNode(n *NodeInfo) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ms.nodeWatchers[n.ID] = cancel
+	go ms.followNode(ctx, n)
+}
+
+// start following the feed of a new node if there are watchers
+func (ms *Service) watchNode(n *NodeInfo) {
+	ms.watchLock.Lock()
+	defer ms.watchLock.Unlock()
+
+	if ms.watching > 0 {
+		ms._watchNode(n)
+	}
+}
+
+// stop following the feed of a node that has been removed
+func (ms *Service) unwatchNode(id uint) {
+	ms.watchLock.Lock()
+	defer ms.watchLock.Unlock()
+
+	if cancel, found := ms.nodeWatchers[id]; found {
+		cancel()
+		delete(ms.nodeWatchers, id)
+	}
+	delete(ms.cursors, id)
+}
+
+func (ms *Service) cursorOf(id uint) uint64 {
+	ms.watchLock.Lock()
+	defer ms.watchLock.Unlock()
+	return ms.cursors[id]
+}
+
+func (ms *Service) setCursorOf(id uint, seq uint64) {
+	ms.watchLock.Lock()
+	defer ms.watchLock.Unlock()
+	ms.cursors[id] = seq
+}
+
+// follow the feed of a node until the context is cancelled
+func (ms *Service) followNode(ctx context.Context, n *NodeInfo) {
+	for {
+		err := ms.consumeNode(ctx, n)
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Warning("Lost the feed of node %s, retrying in %s: %v", n.Name, watchRetryPeriod, err)
+
+		select {
+		case <-time.After(watchRetryPeriod):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// publish the events of a node to the cluster feed, the ones due to
+// records being moved between nodes are not part of it
+func (ms *Service) consumeNode(ctx context.Context, n *NodeInfo) error {
+	stream, err := n.Client.Watch(ctx, &WatchRequest{Since: ms.cursorOf(n.ID)})
+	if err != nil {
+		return err
+	}
+
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		ms.setCursorOf(n.ID, ev.Seq)
+		if ev.Transfer {
+			continue
+		}
+
+		ev.Node = uint64(n.ID)
+		ms.feed.Publish(ev)
+	}
+}
+
+// stream the changes of the records of every node, merged in the order
+// they are received by the master and with its own sequence numbers
+func (ms *Service) Watch(arg *WatchRequest, stream SumService_WatchServer) error {
+	ms.startWatching()
+	defer ms.stopWatching()
+
+	match := service.EventFilter(arg.Collections)
+
+	send := func(ev *Event) error {
+		if !match(ev) {
+			return nil
+		}
+		return stream.Send(ev)
+	}
+
+	reset := func(seq uint64) error {
+		ms.nodesLock.RLock()
+		collections := ms._collections()
+		ms.nodesLock.RUnlock()
+
+		for _, name := range collections {
+			if err := send(&Event{Seq: seq, Type: Event_RESET, Collection: name, Timestamp: time.Now().UnixNano()}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return ms.feed.Follow(stream.Context(), arg.Since, send, reset)
+}
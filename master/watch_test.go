@@ -0,0 +1,120 @@
+package master
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+	. "github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type watchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *pb.Event
+}
+
+func (s *watchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *watchStream) Send(ev *pb.Event) error {
+	s.events <- ev
+	return nil
+}
+
+func (s *watchStream) next(t *testing.T) *pb.Event {
+	select {
+	case ev := <-s.events:
+		return ev
+	case <-time.After(5 * time.Second):
+		FailNow(t, "event not received")
+	}
+	return nil
+}
+
+func TestService_Watch(t *testing.T) {
+	ns, err := setupNetwork(2, 1)
+	NoError(t, err)
+	defer cleanupNetwork(&ns)
+
+	ms := ns.orchestrators[0].svc
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &watchStream{ctx: ctx, events: make(chan *pb.Event, 1024)}
+	go ms.Watch(&pb.WatchRequest{}, stream)
+
+	// the feeds of the nodes are followed asynchronously, create records
+	// until the events of both nodes are received
+	seen := map[uint64]bool{}
+	for deadline := time.Now().Add(5 * time.Second); len(seen) < 2; {
+		True(t, time.Now().Before(deadline), "nodes feeds not followed")
+
+		resp, err := ms.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1}})
+		NoError(t, err)
+		True(t, resp.Success, resp.Msg)
+
+		select {
+		case ev := <-stream.events:
+			seen[ev.Node] = true
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	// drain the events of the probes
+	for drained := false; !drained; {
+		select {
+		case <-stream.events:
+		case <-time.After(200 * time.Millisecond):
+			drained = true
+		}
+	}
+
+	rec := &pb.Record{Data: []float32{1, 2}}
+	resp, err := ms.CreateRecord(context.TODO(), rec)
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	resp, err = ms.UpdateRecord(context.TODO(), &pb.Record{Id: rec.Id, Data: []float32{3, 4}})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	resp, err = ms.DeleteRecord(context.TODO(), &pb.ById{Id: rec.Id})
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	last := uint64(0)
+	node := uint64(0)
+	for _, expected := range []pb.Event_Type{pb.Event_CREATE, pb.Event_UPDATE, pb.Event_DELETE} {
+		ev := stream.next(t)
+		Equal(t, expected, ev.Type)
+		Equal(t, rec.Id, ev.RecordId)
+		True(t, ev.Seq > last)
+		NotZero(t, ev.Node)
+		if node != 0 {
+			Equal(t, node, ev.Node)
+		}
+		last, node = ev.Seq, ev.Node
+	}
+
+	// records moved between nodes are not part of the feed
+	ms.nodesLock.RLock()
+	moved := ns.nodes[0].svc.NumRecords()
+	ms.transfer(ms.nodes[0], ms.nodes[1], 1, "")
+	ms.nodesLock.RUnlock()
+	Equal(t, moved-1, ns.nodes[0].svc.NumRecords())
+
+	marker := &pb.Record{Data: []float32{5}, Meta: map[string]string{"marker": "yes"}}
+	resp, err = ms.CreateRecord(context.TODO(), marker)
+	NoError(t, err)
+	True(t, resp.Success, resp.Msg)
+
+	ev := stream.next(t)
+	Equal(t, pb.Event_CREATE, ev.Type)
+	Equal(t, marker.Id, ev.RecordId)
+	False(t, ev.Transfer)
+}
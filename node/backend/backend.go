@@ -2,70 +2,276 @@ package backend
 
 import (
 	"fmt"
-	"sync"
+	"sort"
+	"sync/atomic"
 )
 
-var lock = sync.Mutex{}
-
 var available = map[string]implementation{
 	"naive":  naive{},
 	"blas32": blas{},
+	"simd":   simd{},
+}
+
+// Backend is an instance of one of the available backends, it never
+// changes once created so it can be shared by any number of goroutines
+// without locking.
+type Backend struct {
+	impl implementation
 }
 
+// one instance for every backend, so that they can be compared
+var instances = func() map[string]*Backend {
+	m := make(map[string]*Backend)
+	for name, impl := range available {
+		m[name] = &Backend{impl: impl}
+	}
+	return m
+}()
+
+// the default backend, readers load it without locking while
+// Select atomically replaces it.
 // TODO: pick at runtime the best backend available ( CUDA, OpenCL or BLAS32 ).
-var selected = available["blas32"]
+var selected atomic.Value
 
+func init() {
+	selected.Store(instances["blas32"])
+}
+
+// Available returns the names of the available backends.
 func Available() []string {
 	keys := []string{}
 	for k, _ := range available {
 		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 	return keys
 }
 
-// Select selects the current backend by name.
-func Select(name string) {
-	lock.Lock()
-	defer lock.Unlock()
+// Get returns the backend with the given name.
+func Get(name string) (*Backend, error) {
+	if b, found := instances[name]; found {
+		return b, nil
+	}
+	return nil, fmt.Errorf("backend %s is not available", name)
+}
 
-	if impl, found := available[name]; found {
-		selected = impl
-	} else {
-		panic(fmt.Errorf("backend %s is not available", name))
+// Select selects the default backend by name.
+func Select(name string) {
+	b, err := Get(name)
+	if err != nil {
+		panic(err)
 	}
+	selected.Store(b)
+}
+
+// Default returns the default backend, the one used by the package
+// level functions.
+func Default() *Backend {
+	return selected.Load().(*Backend)
+}
+
+// Name returns the name of the backend.
+func (be *Backend) Name() string {
+	return be.impl.Name()
+}
+
+// Space returns the total available memory in bytes to the backend.
+func (be *Backend) Space() uint64 {
+	return be.impl.Space()
+}
+
+// Used returns the backend used memory in bytes.
+func (be *Backend) Used() uint64 {
+	return be.impl.Used()
+}
+
+// Wrap creates an opaque reference to the data, backend specific.
+func (be *Backend) Wrap(size int, data []float32) Vector {
+	return be.impl.Wrap(size, data)
+}
+
+// Dot performs the dot product between a vector and another.
+func (be *Backend) Dot(a, b Vector) float64 {
+	return be.impl.Dot(a, b)
+}
+
+// Axpy adds alpha * x to the vector y, in place.
+func (be *Backend) Axpy(alpha float32, x, y Vector) {
+	be.impl.Axpy(alpha, x, y)
+}
+
+// Scal multiplies the vector x by alpha, in place.
+func (be *Backend) Scal(alpha float32, x Vector) {
+	be.impl.Scal(alpha, x)
+}
+
+// Nrm2 returns the euclidean norm of a vector.
+func (be *Backend) Nrm2(x Vector) float64 {
+	return be.impl.Nrm2(x)
+}
+
+// Asum returns the sum of the absolute values of a vector.
+func (be *Backend) Asum(x Vector) float64 {
+	return be.impl.Asum(x)
+}
+
+// L1 returns the manhattan distance between a vector and another.
+func (be *Backend) L1(a, b Vector) float64 {
+	return be.impl.L1(a, b)
+}
+
+// L2 returns the euclidean distance between a vector and another.
+func (be *Backend) L2(a, b Vector) float64 {
+	return be.impl.L2(a, b)
+}
+
+// SquaredL2 returns the squared euclidean distance between a vector and another.
+func (be *Backend) SquaredL2(a, b Vector) float64 {
+	return be.impl.SquaredL2(a, b)
+}
+
+// Hamming returns the number of elements that are different between a vector and another.
+func (be *Backend) Hamming(a, b Vector) float64 {
+	return be.impl.Hamming(a, b)
+}
+
+// Jaccard returns the Jaccard index between a vector and another.
+func (be *Backend) Jaccard(a, b Vector) float64 {
+	return be.impl.Jaccard(a, b)
 }
 
-// Name returns the name of the current backend.
+// Add returns the element-wise sum of a vector and another.
+func (be *Backend) Add(a, b Vector) []float32 {
+	return be.impl.Add(a, b)
+}
+
+// Sub returns the element-wise difference of a vector and another.
+func (be *Backend) Sub(a, b Vector) []float32 {
+	return be.impl.Sub(a, b)
+}
+
+// Mul returns the element-wise product of a vector and another.
+func (be *Backend) Mul(a, b Vector) []float32 {
+	return be.impl.Mul(a, b)
+}
+
+// Div returns the element-wise quotient of a vector and another.
+func (be *Backend) Div(a, b Vector) []float32 {
+	return be.impl.Div(a, b)
+}
+
+// MatVec returns the product of a matrix of rows x cols elements, stored
+// in row-major order, and a vector of cols elements.
+func (be *Backend) MatVec(rows, cols int, m, x []float32) []float32 {
+	return be.impl.MatVec(rows, cols, m, x)
+}
+
+// MatMul returns the product of a matrix of rows x inner elements and a
+// matrix of inner x cols elements, all stored in row-major order.
+func (be *Backend) MatMul(rows, inner, cols int, a, b []float32) []float32 {
+	return be.impl.MatMul(rows, inner, cols, a, b)
+}
+
+// The following functions use the default backend.
+
+// Name returns the name of the default backend.
 func Name() string {
-	lock.Lock()
-	defer lock.Unlock()
-	return selected.Name()
+	return Default().Name()
 }
 
-// Space returns the total available memory in bytes to the specific backend.
+// Space returns the total available memory in bytes to the default backend.
 func Space() uint64 {
-	lock.Lock()
-	defer lock.Unlock()
-	return selected.Space()
+	return Default().Space()
 }
 
-// Used returns the backend used memory in bytes.
+// Used returns the default backend used memory in bytes.
 func Used() uint64 {
-	lock.Lock()
-	defer lock.Unlock()
-	return selected.Used()
+	return Default().Used()
 }
 
 // Wrap creates an opaque reference to the data, backend specific.
 func Wrap(size int, data []float32) Vector {
-	lock.Lock()
-	defer lock.Unlock()
-	return selected.Wrap(size, data)
+	return Default().Wrap(size, data)
 }
 
 // Dot performs the dot product between a vector and another.
 func Dot(a, b Vector) float64 {
-	lock.Lock()
-	defer lock.Unlock()
-	return selected.Dot(a, b)
+	return Default().Dot(a, b)
+}
+
+// Axpy adds alpha * x to the vector y, in place.
+func Axpy(alpha float32, x, y Vector) {
+	Default().Axpy(alpha, x, y)
+}
+
+// Scal multiplies the vector x by alpha, in place.
+func Scal(alpha float32, x Vector) {
+	Default().Scal(alpha, x)
+}
+
+// Nrm2 returns the euclidean norm of a vector.
+func Nrm2(x Vector) float64 {
+	return Default().Nrm2(x)
+}
+
+// Asum returns the sum of the absolute values of a vector.
+func Asum(x Vector) float64 {
+	return Default().Asum(x)
+}
+
+// L1 returns the manhattan distance between a vector and another.
+func L1(a, b Vector) float64 {
+	return Default().L1(a, b)
+}
+
+// L2 returns the euclidean distance between a vector and another.
+func L2(a, b Vector) float64 {
+	return Default().L2(a, b)
+}
+
+// SquaredL2 returns the squared euclidean distance between a vector and another.
+func SquaredL2(a, b Vector) float64 {
+	return Default().SquaredL2(a, b)
+}
+
+// Hamming returns the number of elements that are different between a vector and another.
+func Hamming(a, b Vector) float64 {
+	return Default().Hamming(a, b)
+}
+
+// Jaccard returns the Jaccard index between a vector and another.
+func Jaccard(a, b Vector) float64 {
+	return Default().Jaccard(a, b)
+}
+
+// Add returns the element-wise sum of a vector and another.
+func Add(a, b Vector) []float32 {
+	return Default().Add(a, b)
+}
+
+// Sub returns the element-wise difference of a vector and another.
+func Sub(a, b Vector) []float32 {
+	return Default().Sub(a, b)
+}
+
+// Mul returns the element-wise product of a vector and another.
+func Mul(a, b Vector) []float32 {
+	return Default().Mul(a, b)
+}
+
+// Div returns the element-wise quotient of a vector and another.
+func Div(a, b Vector) []float32 {
+	return Default().Div(a, b)
+}
+
+// MatVec returns the product of a matrix of rows x cols elements, stored
+// in row-major order, and a vector of cols elements.
+func MatVec(rows, cols int, m, x []float32) []float32 {
+	return Default().MatVec(rows, cols, m, x)
+}
+
+// MatMul returns the product of a matrix of rows x inner elements and a
+// matrix of inner x cols elements, all stored in row-major order.
+func MatMul(rows, inner, cols int, a, b []float32) []float32 {
+	return Default().MatMul(rows, inner, cols, a, b)
 }
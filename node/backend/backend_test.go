@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackendGet(t *testing.T) {
+	for _, name := range Available() {
+		if b, err := Get(name); err != nil {
+			t.Fatal(err)
+		} else if b.Name() != name {
+			t.Fatalf("expected backend %s, got %s", name, b.Name())
+		} else if again, _ := Get(name); again != b {
+			t.Fatalf("expected the same instance of %s", name)
+		}
+	}
+
+	if _, err := Get("nope"); err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+}
+
+func TestBackendSelect(t *testing.T) {
+	defer Select(Name())
+
+	Select("naive")
+	if Name() != "naive" || Default() != instances["naive"] {
+		t.Fatalf("expected naive backend, got %s", Name())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for unknown backend")
+		} else if Name() != "naive" {
+			t.Fatalf("the backend should not have changed, got %s", Name())
+		}
+	}()
+	Select("nope")
+}
+
+// readers never block nor see a torn backend while it's replaced,
+// run with -race to check it
+func TestBackendSelectConcurrently(t *testing.T) {
+	defer Select(Name())
+
+	r := rand.New(rand.NewSource(42))
+	data := randomFloats(r, 64)
+	expected := naive{}.Dot(data, data)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				b := Default()
+				v := b.Wrap(len(data), data)
+				if dot := b.Dot(v, v); !near(expected, dot, float64(len(data))) {
+					t.Errorf("expected %f, got %f", expected, dot)
+					return
+				}
+			}
+		}()
+	}
+
+	for j := 0; j < 1000; j++ {
+		Select(Available()[j%len(available)])
+	}
+	wg.Wait()
+}
+
+func TestBackendBenchmark(t *testing.T) {
+	timings := Benchmark(256, time.Millisecond)
+	if len(timings) != len(Available()) {
+		t.Fatalf("expected %d timings, got %d", len(Available()), len(timings))
+	}
+
+	measured := make(map[string]bool)
+	for i, timing := range timings {
+		if timing.Size != 256 || timing.NsPerOp <= 0 {
+			t.Fatalf("unexpected timing %+v", timing)
+		} else if i > 0 && timing.NsPerOp < timings[i-1].NsPerOp {
+			t.Fatalf("timings are not sorted: %+v", timings)
+		}
+		measured[timing.Name] = true
+	}
+
+	for _, name := range Available() {
+		if !measured[name] {
+			t.Fatalf("backend %s was not measured", name)
+		}
+	}
+}
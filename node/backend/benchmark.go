@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultBenchmarkTime is the default time every backend is measured for.
+const DefaultBenchmarkTime = 50 * time.Millisecond
+
+// Timing is the time a backend takes for a dot product of two vectors
+// of Size elements.
+type Timing struct {
+	Name    string
+	Size    int
+	NsPerOp float64
+}
+
+// keeps the results alive so the measured calls are not optimized out
+var benchmarkSink float64
+
+// Benchmark measures every available backend for about duration with a
+// dot product of two vectors of size elements, the operation the oracles
+// use the most, and returns the timings from the fastest backend to the
+// slowest one.
+func Benchmark(size int, duration time.Duration) []Timing {
+	a := make([]float32, size)
+	b := make([]float32, size)
+	for i := range a {
+		a[i] = float32(i%7) / 7
+		b[i] = float32(i%5) / 5
+	}
+
+	timings := make([]Timing, 0, len(instances))
+	for _, name := range Available() {
+		timings = append(timings, Timing{
+			Name:    name,
+			Size:    size,
+			NsPerOp: measure(instances[name], a, b, duration),
+		})
+	}
+
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].NsPerOp < timings[j].NsPerOp
+	})
+	return timings
+}
+
+// runs the dot product in growing batches until duration has passed and
+// returns the time per operation of the fastest batch, which is less
+// affected by the scheduler and the gc than the average of all of them
+func measure(be *Backend, a, b []float32, duration time.Duration) float64 {
+	va, vb := be.Wrap(len(a), a), be.Wrap(len(b), b)
+	// warm up the caches and any lazy initialization
+	benchmarkSink += be.Dot(va, vb)
+
+	best := math.Inf(1)
+	batch := 1
+	for start := time.Now(); time.Since(start) < duration; {
+		began := time.Now()
+		for i := 0; i < batch; i++ {
+			benchmarkSink += be.Dot(va, vb)
+		}
+		elapsed := time.Since(began)
+
+		if perOp := float64(elapsed.Nanoseconds()) / float64(batch); perOp < best {
+			best = perOp
+		}
+		// batches shorter than this are dominated by the clock resolution
+		if elapsed < 100*time.Microsecond {
+			batch *= 2
+		}
+	}
+	return best
+}
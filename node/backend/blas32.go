@@ -2,6 +2,7 @@ package backend
 
 import (
 	"github.com/pbnjay/memory"
+	gonum "gonum.org/v1/gonum/blas"
 	"gonum.org/v1/gonum/blas/blas32"
 	"runtime"
 )
@@ -14,6 +15,17 @@ type blasWrap struct {
 	sz int
 }
 
+// the elements of the vector, up to its size
+func (w blasWrap) data() []float32 {
+	return w.v.Data[:w.sz]
+}
+
+func (w blasWrap) clone() blasWrap {
+	c := blasWrap{v: blas32.Vector{Inc: 1, Data: make([]float32, w.sz)}, sz: w.sz}
+	copy(c.v.Data, w.data())
+	return c
+}
+
 func (impl blas) Name() string {
 	return "blas32"
 }
@@ -41,3 +53,91 @@ func (impl blas) Wrap(size int, data []float32) Vector {
 func (impl blas) Dot(a, b Vector) float64 {
 	return float64(blas32.Dot(a.(blasWrap).sz, a.(blasWrap).v, b.(blasWrap).v))
 }
+
+func (impl blas) Axpy(alpha float32, x, y Vector) {
+	blas32.Axpy(x.(blasWrap).sz, alpha, x.(blasWrap).v, y.(blasWrap).v)
+}
+
+func (impl blas) Scal(alpha float32, x Vector) {
+	blas32.Scal(x.(blasWrap).sz, alpha, x.(blasWrap).v)
+}
+
+func (impl blas) Nrm2(x Vector) float64 {
+	return float64(blas32.Nrm2(x.(blasWrap).sz, x.(blasWrap).v))
+}
+
+func (impl blas) Asum(x Vector) float64 {
+	return float64(blas32.Asum(x.(blasWrap).sz, x.(blasWrap).v))
+}
+
+// returns a - b as a new vector
+func (impl blas) diff(a, b Vector) blasWrap {
+	d := a.(blasWrap).clone()
+	blas32.Axpy(d.sz, -1, b.(blasWrap).v, d.v)
+	return d
+}
+
+func (impl blas) L1(a, b Vector) float64 {
+	return impl.Asum(impl.diff(a, b))
+}
+
+func (impl blas) L2(a, b Vector) float64 {
+	return impl.Nrm2(impl.diff(a, b))
+}
+
+func (impl blas) SquaredL2(a, b Vector) float64 {
+	d := impl.diff(a, b)
+	return impl.Dot(d, d)
+}
+
+// BLAS has no equivalent of the following ones
+func (impl blas) Hamming(a, b Vector) float64 {
+	return hamming(a.(blasWrap).data(), b.(blasWrap).data())
+}
+
+func (impl blas) Jaccard(a, b Vector) float64 {
+	return denseJaccard(a.(blasWrap).data(), b.(blasWrap).data())
+}
+
+func (impl blas) Add(a, b Vector) []float32 {
+	sum := a.(blasWrap).clone()
+	blas32.Axpy(sum.sz, 1, b.(blasWrap).v, sum.v)
+	return sum.v.Data
+}
+
+func (impl blas) Sub(a, b Vector) []float32 {
+	return impl.diff(a, b).v.Data
+}
+
+func (impl blas) Mul(a, b Vector) []float32 {
+	return elementWise(a.(blasWrap).data(), b.(blasWrap).data(), func(va, vb float32) float32 { return va * vb })
+}
+
+func (impl blas) Div(a, b Vector) []float32 {
+	return elementWise(a.(blasWrap).data(), b.(blasWrap).data(), func(va, vb float32) float32 { return va / vb })
+}
+
+func (impl blas) MatVec(rows, cols int, m, x []float32) []float32 {
+	y := make([]float32, rows)
+	// gonum does not accept empty matrices
+	if rows == 0 || cols == 0 {
+		return y
+	}
+	blas32.Gemv(gonum.NoTrans, 1,
+		blas32.General{Rows: rows, Cols: cols, Stride: cols, Data: m},
+		blas32.Vector{Inc: 1, Data: x},
+		0, blas32.Vector{Inc: 1, Data: y})
+	return y
+}
+
+func (impl blas) MatMul(rows, inner, cols int, a, b []float32) []float32 {
+	c := make([]float32, rows*cols)
+	if rows == 0 || inner == 0 || cols == 0 {
+		return c
+	}
+	blas32.Gemm(gonum.NoTrans, gonum.NoTrans, 1,
+		blas32.General{Rows: rows, Cols: inner, Stride: inner, Data: a},
+		blas32.General{Rows: inner, Cols: cols, Stride: cols, Data: b},
+		0, blas32.General{Rows: rows, Cols: cols, Stride: cols, Data: c})
+	return c
+}
@@ -35,3 +35,123 @@ func BenchmarkBackendBLAS32Dot512(b *testing.B) {
 func BenchmarkBackendBLAS32Dot1024(b *testing.B) {
 	dotWithSize(blas{}, b, 1014)
 }
+
+func BenchmarkBackendBLAS32MatVec128(b *testing.B) {
+	matVecWithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32MatVec512(b *testing.B) {
+	matVecWithSize(blas{}, b, 512)
+}
+
+func BenchmarkBackendBLAS32MatMul64(b *testing.B) {
+	matMulWithSize(blas{}, b, 64)
+}
+
+func BenchmarkBackendBLAS32MatMul256(b *testing.B) {
+	matMulWithSize(blas{}, b, 256)
+}
+
+func BenchmarkBackendBLAS32Axpy128(b *testing.B) {
+	axpyWithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32Axpy1024(b *testing.B) {
+	axpyWithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32Scal128(b *testing.B) {
+	scalWithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32Scal1024(b *testing.B) {
+	scalWithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32Nrm2128(b *testing.B) {
+	nrm2WithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32Nrm21024(b *testing.B) {
+	nrm2WithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32Asum128(b *testing.B) {
+	asumWithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32Asum1024(b *testing.B) {
+	asumWithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32L1128(b *testing.B) {
+	l1WithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32L11024(b *testing.B) {
+	l1WithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32L2128(b *testing.B) {
+	l2WithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32L21024(b *testing.B) {
+	l2WithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32SquaredL2128(b *testing.B) {
+	squaredL2WithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32SquaredL21024(b *testing.B) {
+	squaredL2WithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32Hamming128(b *testing.B) {
+	hammingWithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32Hamming1024(b *testing.B) {
+	hammingWithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32Jaccard128(b *testing.B) {
+	jaccardWithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32Jaccard1024(b *testing.B) {
+	jaccardWithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32Add128(b *testing.B) {
+	addWithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32Add1024(b *testing.B) {
+	addWithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32Sub128(b *testing.B) {
+	subWithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32Sub1024(b *testing.B) {
+	subWithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32Mul128(b *testing.B) {
+	mulWithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32Mul1024(b *testing.B) {
+	mulWithSize(blas{}, b, 1024)
+}
+
+func BenchmarkBackendBLAS32Div128(b *testing.B) {
+	divWithSize(blas{}, b, 128)
+}
+
+func BenchmarkBackendBLAS32Div1024(b *testing.B) {
+	divWithSize(blas{}, b, 1024)
+}
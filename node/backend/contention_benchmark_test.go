@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+// runs b.N dot products split among the given number of goroutines, if
+// the backend does not serialize its callers the time per operation
+// decreases linearly with the goroutines, up to the available cores.
+func contentionWithGoroutines(b *testing.B, goroutines int, dot func(a, b Vector) float64) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	size := 1024
+	va := Wrap(size, randomFloats(r, size))
+	vb := Wrap(size, randomFloats(r, size))
+
+	wg := sync.WaitGroup{}
+	b.ResetTimer()
+	for g := 0; g < goroutines; g++ {
+		n := b.N / goroutines
+		if g == 0 {
+			n += b.N % goroutines
+		}
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				_ = dot(va, vb)
+			}
+		}(n)
+	}
+	wg.Wait()
+}
+
+// how the package functions behaved when every call took a global lock
+var globalLock = sync.Mutex{}
+
+func lockedDot(a, b Vector) float64 {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+	return Default().Dot(a, b)
+}
+
+func BenchmarkBackendContention1(b *testing.B) {
+	contentionWithGoroutines(b, 1, Dot)
+}
+
+func BenchmarkBackendContention2(b *testing.B) {
+	contentionWithGoroutines(b, 2, Dot)
+}
+
+func BenchmarkBackendContention4(b *testing.B) {
+	contentionWithGoroutines(b, 4, Dot)
+}
+
+func BenchmarkBackendContention8(b *testing.B) {
+	contentionWithGoroutines(b, 8, Dot)
+}
+
+func BenchmarkBackendContentionLocked1(b *testing.B) {
+	contentionWithGoroutines(b, 1, lockedDot)
+}
+
+func BenchmarkBackendContentionLocked2(b *testing.B) {
+	contentionWithGoroutines(b, 2, lockedDot)
+}
+
+func BenchmarkBackendContentionLocked4(b *testing.B) {
+	contentionWithGoroutines(b, 4, lockedDot)
+}
+
+func BenchmarkBackendContentionLocked8(b *testing.B) {
+	contentionWithGoroutines(b, 8, lockedDot)
+}
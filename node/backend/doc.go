@@ -3,6 +3,7 @@ Package backend provides an abstraction layer to the available computational bac
 
 	- naive (naive implementation, no optimizations)
 	- blas32 (gonum blas32 interface
+	- simd (AVX2 and FMA kernels on amd64, portable ones elsewhere)
 
 Future:
 
@@ -13,4 +13,28 @@ type implementation interface {
 	Wrap(size int, data []float32) Vector
 
 	Dot(a, b Vector) float64
+
+	// y += alpha * x and x *= alpha, in place
+	Axpy(alpha float32, x, y Vector)
+	Scal(alpha float32, x Vector)
+	// euclidean norm and sum of the absolute values
+	Nrm2(x Vector) float64
+	Asum(x Vector) float64
+
+	// distances between vectors of the same size
+	L1(a, b Vector) float64
+	L2(a, b Vector) float64
+	SquaredL2(a, b Vector) float64
+	Hamming(a, b Vector) float64
+	Jaccard(a, b Vector) float64
+
+	// element-wise operations, the result is a new slice
+	Add(a, b Vector) []float32
+	Sub(a, b Vector) []float32
+	Mul(a, b Vector) []float32
+	Div(a, b Vector) []float32
+
+	// matrices are stored in row-major order
+	MatVec(rows, cols int, m, x []float32) []float32
+	MatMul(rows, inner, cols int, a, b []float32) []float32
 }
@@ -0,0 +1,50 @@
+package backend
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestBackendsMatVecMatMul(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	rows, inner, cols := 7, 5, 3
+	a := randomFloats(r, rows*inner)
+	b := randomFloats(r, inner*cols)
+	x := randomFloats(r, inner)
+
+	for name, impl := range available {
+		y := impl.MatVec(rows, inner, a, x)
+		if len(y) != rows {
+			t.Fatalf("%s: expected %d elements, got %d", name, rows, len(y))
+		}
+		for i := range y {
+			expected := naive{}.Dot(a[i*inner:(i+1)*inner], x)
+			if math.Abs(float64(y[i])-expected) > 1e-5 {
+				t.Fatalf("%s: expected %f at %d, got %f", name, expected, i, y[i])
+			}
+		}
+
+		c := impl.MatMul(rows, inner, cols, a, b)
+		if len(c) != rows*cols {
+			t.Fatalf("%s: expected %d elements, got %d", name, rows*cols, len(c))
+		}
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				expected := 0.0
+				for k := 0; k < inner; k++ {
+					expected += float64(a[i*inner+k]) * float64(b[k*cols+j])
+				}
+				if got := c[i*cols+j]; math.Abs(float64(got)-expected) > 1e-5 {
+					t.Fatalf("%s: expected %f at %d,%d, got %f", name, expected, i, j, got)
+				}
+			}
+		}
+
+		if y := impl.MatVec(0, 0, nil, nil); len(y) != 0 {
+			t.Fatalf("%s: expected empty result, got %v", name, y)
+		} else if c := impl.MatMul(2, 0, 2, nil, nil); len(c) != 4 || c[0] != 0 {
+			t.Fatalf("%s: expected zeros, got %v", name, c)
+		}
+	}
+}
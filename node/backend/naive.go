@@ -2,6 +2,7 @@ package backend
 
 import (
 	"github.com/pbnjay/memory"
+	"math"
 	"runtime"
 )
 
@@ -34,3 +35,131 @@ func (impl naive) Dot(a, b Vector) float64 {
 	}
 	return dot
 }
+
+func (impl naive) Axpy(alpha float32, x, y Vector) {
+	vy := y.([]float32)
+	for i, vx := range x.([]float32) {
+		vy[i] += alpha * vx
+	}
+}
+
+func (impl naive) Scal(alpha float32, x Vector) {
+	vx := x.([]float32)
+	for i := range vx {
+		vx[i] *= alpha
+	}
+}
+
+func (impl naive) Nrm2(x Vector) float64 {
+	return math.Sqrt(impl.Dot(x, x))
+}
+
+func (impl naive) Asum(x Vector) float64 {
+	sum := float64(0.0)
+	for _, v := range x.([]float32) {
+		sum += math.Abs(float64(v))
+	}
+	return sum
+}
+
+func (impl naive) L1(a, b Vector) float64 {
+	dist := float64(0.0)
+	for i, va := range a.([]float32) {
+		dist += math.Abs(float64(va) - float64(b.([]float32)[i]))
+	}
+	return dist
+}
+
+func (impl naive) L2(a, b Vector) float64 {
+	return math.Sqrt(impl.SquaredL2(a, b))
+}
+
+func (impl naive) SquaredL2(a, b Vector) float64 {
+	dist := float64(0.0)
+	for i, va := range a.([]float32) {
+		d := float64(va) - float64(b.([]float32)[i])
+		dist += d * d
+	}
+	return dist
+}
+
+func (impl naive) Hamming(a, b Vector) float64 {
+	return hamming(a.([]float32), b.([]float32))
+}
+
+func (impl naive) Jaccard(a, b Vector) float64 {
+	return denseJaccard(a.([]float32), b.([]float32))
+}
+
+func (impl naive) Add(a, b Vector) []float32 {
+	return elementWise(a.([]float32), b.([]float32), func(va, vb float32) float32 { return va + vb })
+}
+
+func (impl naive) Sub(a, b Vector) []float32 {
+	return elementWise(a.([]float32), b.([]float32), func(va, vb float32) float32 { return va - vb })
+}
+
+func (impl naive) Mul(a, b Vector) []float32 {
+	return elementWise(a.([]float32), b.([]float32), func(va, vb float32) float32 { return va * vb })
+}
+
+func (impl naive) Div(a, b Vector) []float32 {
+	return elementWise(a.([]float32), b.([]float32), func(va, vb float32) float32 { return va / vb })
+}
+
+func (impl naive) MatVec(rows, cols int, m, x []float32) []float32 {
+	y := make([]float32, rows)
+	for i := range y {
+		dot := float64(0.0)
+		for j, vx := range x[:cols] {
+			dot += float64(m[i*cols+j]) * float64(vx)
+		}
+		y[i] = float32(dot)
+	}
+	return y
+}
+
+func (impl naive) MatMul(rows, inner, cols int, a, b []float32) []float32 {
+	c := make([]float32, rows*cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			dot := float64(0.0)
+			for k := 0; k < inner; k++ {
+				dot += float64(a[i*inner+k]) * float64(b[k*cols+j])
+			}
+			c[i*cols+j] = float32(dot)
+		}
+	}
+	return c
+}
+
+// the number of elements that are different
+func hamming(a, b []float32) float64 {
+	dist := 0
+	for i, va := range a {
+		if va != b[i] {
+			dist++
+		}
+	}
+	return float64(dist)
+}
+
+// the Jaccard index with the same semantics of the sparse one
+func denseJaccard(a, b []float32) float64 {
+	m11 := 0.0
+	m10 := 0.0
+	for i, va := range a {
+		t11, t10 := jaccardTerms(va, b[i])
+		m11 += t11
+		m10 += t10
+	}
+	return jaccard(m11, m10)
+}
+
+func elementWise(a, b []float32, op func(va, vb float32) float32) []float32 {
+	out := make([]float32, len(a))
+	for i, va := range a {
+		out[i] = op(va, b[i])
+	}
+	return out
+}
@@ -36,6 +36,36 @@ func dotWithSize(impl implementation, b *testing.B, size int) {
 	}
 }
 
+func randomFloats(r *rand.Rand, size int) []float32 {
+	data := make([]float32, size)
+	for i := range data {
+		data[i] = r.Float32()
+	}
+	return data
+}
+
+func matVecWithSize(impl implementation, b *testing.B, size int) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	m := randomFloats(r, size*size)
+	x := randomFloats(r, size)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = impl.MatVec(size, size, m, x)
+	}
+}
+
+func matMulWithSize(impl implementation, b *testing.B, size int) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	ma := randomFloats(r, size*size)
+	mb := randomFloats(r, size*size)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = impl.MatMul(size, size, size, ma, mb)
+	}
+}
+
 func BenchmarkBackendNaiveWrap128(b *testing.B) {
 	wrapWithSize(naive{}, b, 128)
 }
@@ -67,3 +97,188 @@ func BenchmarkBackendNaiveDot512(b *testing.B) {
 func BenchmarkBackendNaiveDot1024(b *testing.B) {
 	dotWithSize(naive{}, b, 1014)
 }
+
+func BenchmarkBackendNaiveMatVec128(b *testing.B) {
+	matVecWithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveMatVec512(b *testing.B) {
+	matVecWithSize(naive{}, b, 512)
+}
+
+func BenchmarkBackendNaiveMatMul64(b *testing.B) {
+	matMulWithSize(naive{}, b, 64)
+}
+
+func BenchmarkBackendNaiveMatMul256(b *testing.B) {
+	matMulWithSize(naive{}, b, 256)
+}
+
+// benchmarks an operation on two random vectors of the given size
+func vectorOpWithSize(impl implementation, b *testing.B, size int, op func(va, vb Vector)) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	va := impl.Wrap(size, randomFloats(r, size))
+	vb := impl.Wrap(size, randomFloats(r, size))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op(va, vb)
+	}
+}
+
+func axpyWithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { impl.Axpy(1e-6, va, vb) })
+}
+
+func scalWithSize(impl implementation, b *testing.B, size int) {
+	// alternate the sign, so that the values never overflow
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { impl.Scal(-1, va) })
+}
+
+func nrm2WithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.Nrm2(va) })
+}
+
+func asumWithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.Asum(va) })
+}
+
+func l1WithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.L1(va, vb) })
+}
+
+func l2WithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.L2(va, vb) })
+}
+
+func squaredL2WithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.SquaredL2(va, vb) })
+}
+
+func hammingWithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.Hamming(va, vb) })
+}
+
+func jaccardWithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.Jaccard(va, vb) })
+}
+
+func addWithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.Add(va, vb) })
+}
+
+func subWithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.Sub(va, vb) })
+}
+
+func mulWithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.Mul(va, vb) })
+}
+
+func divWithSize(impl implementation, b *testing.B, size int) {
+	vectorOpWithSize(impl, b, size, func(va, vb Vector) { _ = impl.Div(va, vb) })
+}
+
+func BenchmarkBackendNaiveAxpy128(b *testing.B) {
+	axpyWithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveAxpy1024(b *testing.B) {
+	axpyWithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveScal128(b *testing.B) {
+	scalWithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveScal1024(b *testing.B) {
+	scalWithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveNrm2128(b *testing.B) {
+	nrm2WithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveNrm21024(b *testing.B) {
+	nrm2WithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveAsum128(b *testing.B) {
+	asumWithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveAsum1024(b *testing.B) {
+	asumWithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveL1128(b *testing.B) {
+	l1WithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveL11024(b *testing.B) {
+	l1WithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveL2128(b *testing.B) {
+	l2WithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveL21024(b *testing.B) {
+	l2WithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveSquaredL2128(b *testing.B) {
+	squaredL2WithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveSquaredL21024(b *testing.B) {
+	squaredL2WithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveHamming128(b *testing.B) {
+	hammingWithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveHamming1024(b *testing.B) {
+	hammingWithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveJaccard128(b *testing.B) {
+	jaccardWithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveJaccard1024(b *testing.B) {
+	jaccardWithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveAdd128(b *testing.B) {
+	addWithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveAdd1024(b *testing.B) {
+	addWithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveSub128(b *testing.B) {
+	subWithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveSub1024(b *testing.B) {
+	subWithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveMul128(b *testing.B) {
+	mulWithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveMul1024(b *testing.B) {
+	mulWithSize(naive{}, b, 1024)
+}
+
+func BenchmarkBackendNaiveDiv128(b *testing.B) {
+	divWithSize(naive{}, b, 128)
+}
+
+func BenchmarkBackendNaiveDiv1024(b *testing.B) {
+	divWithSize(naive{}, b, 1024)
+}
@@ -0,0 +1,95 @@
+package backend
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// float32 backends are less precise than the reference results
+func assertNear(t *testing.T, what string, expected, got float64) {
+	t.Helper()
+	if math.Abs(expected-got) > 1e-5*math.Max(1, math.Abs(expected)) {
+		t.Fatalf("expected %s %f, got %f", what, expected, got)
+	}
+}
+
+func assertFloats(t *testing.T, what string, expected, got []float32) {
+	t.Helper()
+	if len(expected) != len(got) {
+		t.Fatalf("%s: expected %d elements, got %d", what, len(expected), len(got))
+	}
+	for i := range expected {
+		if math.Abs(float64(expected[i]-got[i])) > 1e-5 {
+			t.Fatalf("%s: expected %f at %d, got %f", what, expected[i], i, got[i])
+		}
+	}
+}
+
+func TestBackendsVectorOps(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	size := 37
+	a := randomFloats(r, size)
+	b := randomFloats(r, size)
+	// some equal elements for the Hamming distance, and binary ones for Jaccard
+	copy(b[:5], a[:5])
+	for i := 5; i < 10; i++ {
+		a[i], b[i] = float32(i%2), float32(i%3%2)
+	}
+	a[10] = -a[10]
+
+	l1, sqL2, asum, hamming := 0.0, 0.0, 0.0, 0.0
+	add, sub, mul, div := make([]float32, size), make([]float32, size), make([]float32, size), make([]float32, size)
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		l1 += math.Abs(d)
+		sqL2 += d * d
+		asum += math.Abs(float64(a[i]))
+		if a[i] != b[i] {
+			hamming++
+		}
+		add[i], sub[i], mul[i], div[i] = a[i]+b[i], a[i]-b[i], a[i]*b[i], a[i]/b[i]
+	}
+
+	for name, impl := range available {
+		va, vb := impl.Wrap(size, a), impl.Wrap(size, b)
+
+		assertNear(t, name+" nrm2", math.Sqrt(naive{}.Dot(a, a)), impl.Nrm2(va))
+		assertNear(t, name+" asum", asum, impl.Asum(va))
+		assertNear(t, name+" l1", l1, impl.L1(va, vb))
+		assertNear(t, name+" l2", math.Sqrt(sqL2), impl.L2(va, vb))
+		assertNear(t, name+" squared l2", sqL2, impl.SquaredL2(va, vb))
+		assertNear(t, name+" hamming", hamming, impl.Hamming(va, vb))
+		assertNear(t, name+" jaccard", naive{}.Jaccard(a, b), impl.Jaccard(va, vb))
+		assertFloats(t, name+" add", add, impl.Add(va, vb))
+		assertFloats(t, name+" sub", sub, impl.Sub(va, vb))
+		assertFloats(t, name+" mul", mul, impl.Mul(va, vb))
+		assertFloats(t, name+" div", div, impl.Div(va, vb))
+
+		y := append([]float32{}, b...)
+		impl.Axpy(2, va, impl.Wrap(size, y))
+		for i := range y {
+			if expected := b[i] + 2*a[i]; math.Abs(float64(expected-y[i])) > 1e-5 {
+				t.Fatalf("%s axpy: expected %f at %d, got %f", name, expected, i, y[i])
+			}
+		}
+
+		x := append([]float32{}, a...)
+		impl.Scal(-3, impl.Wrap(size, x))
+		for i := range x {
+			if expected := -3 * a[i]; math.Abs(float64(expected-x[i])) > 1e-5 {
+				t.Fatalf("%s scal: expected %f at %d, got %f", name, expected, i, x[i])
+			}
+		}
+
+		// the operands are never modified by the other ops
+		if a[10] >= 0 || b[0] != a[0] {
+			t.Fatalf("%s: operands have been modified", name)
+		}
+
+		empty := impl.Wrap(0, []float32{})
+		if impl.Nrm2(empty) != 0 || impl.L1(empty, empty) != 0 || len(impl.Add(empty, empty)) != 0 {
+			t.Fatalf("%s: unexpected results for empty vectors", name)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package backend
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+// Quantization is the precision a vector is stored with, the values
+// match the ones of the QuantizedVector type of the protocol.
+type Quantization int
+
+const (
+	// Float32 vectors are not quantized.
+	Float32 Quantization = iota
+	// Int8 vectors store a byte per element, each element is
+	// Offset + Scale * q where q is in [-127, 127].
+	Int8
+	// Float16 vectors store IEEE 754 half precision elements as
+	// two little endian bytes.
+	Float16
+)
+
+// the largest absolute value of an int8 code, -128 is not used so
+// that the codes are symmetric around the offset
+const int8Levels = 127
+
+// Quantized is a vector of Size elements stored with less precision
+// than a float32 one to save memory, the kernels below operate directly
+// on its Data without restoring the whole vector.
+//
+// As for sparse vectors, the operations between a quantized vector and
+// another one only consider the elements they have in common.
+type Quantized struct {
+	Type   Quantization
+	Size   int
+	Data   []byte
+	Scale  float32
+	Offset float32
+}
+
+// NewQuantized creates a quantized vector of size elements from its
+// encoded data.
+func NewQuantized(t Quantization, size int, data []byte, scale, offset float32) *Quantized {
+	return &Quantized{Type: t, Size: size, Data: data, Scale: scale, Offset: offset}
+}
+
+// Quantize encodes a vector with the given precision, it returns nil
+// for Float32.
+func Quantize(t Quantization, data []float32) *Quantized {
+	switch t {
+	case Int8:
+		return QuantizeInt8(data)
+	case Float16:
+		return QuantizeFloat16(data)
+	}
+	return nil
+}
+
+// QuantizeInt8 encodes a vector with a byte per element, the range of
+// its values is split in 255 levels centered on their midpoint.
+func QuantizeInt8(data []float32) *Quantized {
+	q := &Quantized{Type: Int8, Size: len(data), Data: make([]byte, len(data))}
+	if len(data) == 0 {
+		return q
+	}
+
+	min, max := data[0], data[0]
+	for _, v := range data[1:] {
+		if v < min {
+			min = v
+		} else if v > max {
+			max = v
+		}
+	}
+
+	offset := (float64(max) + float64(min)) / 2
+	scale := (float64(max) - float64(min)) / (2 * int8Levels)
+	q.Offset = float32(offset)
+	q.Scale = float32(scale)
+	if scale == 0 {
+		// all the elements are equal to the offset
+		return q
+	}
+
+	for i, v := range data {
+		code := math.Round((float64(v) - offset) / scale)
+		if code > int8Levels {
+			code = int8Levels
+		} else if code < -int8Levels {
+			code = -int8Levels
+		}
+		q.Data[i] = byte(int8(code))
+	}
+	return q
+}
+
+// QuantizeFloat16 encodes a vector with two bytes per element, values
+// are rounded to the nearest half precision one.
+func QuantizeFloat16(data []float32) *Quantized {
+	q := &Quantized{Type: Float16, Size: len(data), Data: make([]byte, 2*len(data))}
+	for i, v := range data {
+		binary.LittleEndian.PutUint16(q.Data[2*i:], toHalf(v))
+	}
+	return q
+}
+
+// returns the half precision float nearest to f, ties to even
+func toHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+
+	switch {
+	case bits&0x7fffffff > 0x7f800000:
+		// NaN, keep it quiet
+		return sign | 0x7e00
+	case exp >= 0x1f:
+		// too large or infinite
+		return sign | 0x7c00
+	case exp <= 0:
+		// subnormal, or too small to be represented
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint(14 - exp)
+		half := uint16(mant >> shift)
+		rem, mid := mant&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > mid || (rem == mid && half&1 == 1) {
+			// may carry into the smallest normal, which is correct
+			half++
+		}
+		return sign | half
+	}
+
+	half := sign | uint16(exp)<<10 | uint16(mant>>13)
+	if rem := mant & 0x1fff; rem > 0x1000 || (rem == 0x1000 && half&1 == 1) {
+		// may carry into the exponent, up to infinity
+		half++
+	}
+	return half
+}
+
+// returns the float32 value of a half precision float, which is exact
+func fromHalf(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case 0:
+		v := float32(mant) / (1 << 24)
+		if sign != 0 {
+			return -v
+		}
+		return v
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
+
+// the float32 value of every half, filled the first time a float16
+// kernel runs since converting each element is slower than the products
+var (
+	halfOnce  sync.Once
+	halfTable []float32
+)
+
+func halves() []float32 {
+	halfOnce.Do(func() {
+		halfTable = make([]float32, 1<<16)
+		for h := range halfTable {
+			halfTable[h] = fromHalf(uint16(h))
+		}
+	})
+	return halfTable
+}
+
+func (q *Quantized) half(index int) float32 {
+	return fromHalf(binary.LittleEndian.Uint16(q.Data[2*index:]))
+}
+
+// Get returns the index-th element of the vector.
+func (q *Quantized) Get(index int) float32 {
+	if q.Type == Float16 {
+		return q.half(index)
+	}
+	return q.Offset + q.Scale*float32(int8(q.Data[index]))
+}
+
+// Slice returns the elements from start to end as a new quantized vector
+// sharing the same data.
+func (q *Quantized) Slice(start, end int) *Quantized {
+	width := 1
+	if q.Type == Float16 {
+		width = 2
+	}
+	data := q.Data[start*width : end*width : end*width]
+	return &Quantized{Type: q.Type, Size: end - start, Data: data, Scale: q.Scale, Offset: q.Offset}
+}
+
+// Dense returns the vector with all of its elements restored.
+func (q *Quantized) Dense() []float32 {
+	data := make([]float32, q.Size)
+	for i := range data {
+		data[i] = q.Get(i)
+	}
+	return data
+}
+
+// sum of the int8 codes and of their products
+func int8Sums(a, b []byte) (sumA, sumB, dot int64) {
+	b = b[:len(a)]
+	for i, va := range a {
+		qa, qb := int64(int8(va)), int64(int8(b[i]))
+		sumA += qa
+		sumB += qb
+		dot += qa * qb
+	}
+	return
+}
+
+// dot product of two int8 vectors of n elements, each element is
+// o + s*q so the products expand in terms of the sums of the codes
+// that are computed with integers only.
+func int8Dot(a, b *Quantized, n int) float64 {
+	sumA, sumB, dot := int8Sums(a.Data[:n], b.Data[:n])
+	oa, sa := float64(a.Offset), float64(a.Scale)
+	ob, sb := float64(b.Offset), float64(b.Scale)
+	return float64(n)*oa*ob + oa*sb*float64(sumB) + ob*sa*float64(sumA) + sa*sb*float64(dot)
+}
+
+func float16Dot(a, b []byte) float64 {
+	table := halves()
+	b = b[:len(a)]
+	s0, s1 := float32(0), float32(0)
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += table[binary.LittleEndian.Uint16(a[i:])] * table[binary.LittleEndian.Uint16(b[i:])]
+		s1 += table[binary.LittleEndian.Uint16(a[i+2:])] * table[binary.LittleEndian.Uint16(b[i+2:])]
+	}
+	for ; i < len(a); i += 2 {
+		s0 += table[binary.LittleEndian.Uint16(a[i:])] * table[binary.LittleEndian.Uint16(b[i:])]
+	}
+	return float64(s0 + s1)
+}
+
+func minSize(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// QuantizedDot returns the dot product of two quantized vectors.
+func QuantizedDot(a, b *Quantized) float64 {
+	n := minSize(a.Size, b.Size)
+	switch {
+	case a.Type == Int8 && b.Type == Int8:
+		return int8Dot(a, b, n)
+	case a.Type == Float16 && b.Type == Float16:
+		return float16Dot(a.Data[:2*n], b.Data[:2*n])
+	}
+
+	dot := float64(0.0)
+	for i := 0; i < n; i++ {
+		dot += float64(a.Get(i)) * float64(b.Get(i))
+	}
+	return dot
+}
+
+// QuantizedDenseDot returns the dot product of a quantized vector and
+// a dense one.
+func QuantizedDenseDot(a *Quantized, b []float32) float64 {
+	n := minSize(a.Size, len(b))
+	if a.Type == Float16 {
+		table := halves()
+		dot := float32(0.0)
+		for i, vb := range b[:n] {
+			dot += table[binary.LittleEndian.Uint16(a.Data[2*i:])] * vb
+		}
+		return float64(dot)
+	}
+
+	// o*Σb + s*Σq*b
+	sum, dot := float32(0.0), float32(0.0)
+	for i, vb := range b[:n] {
+		sum += vb
+		dot += float32(int8(a.Data[i])) * vb
+	}
+	return float64(a.Offset)*float64(sum) + float64(a.Scale)*float64(dot)
+}
+
+func quantizedNorm(a *Quantized) float64 {
+	norm := QuantizedDot(a, a)
+	if norm < 0 {
+		// rounding of the expanded int8 terms
+		return 0
+	}
+	return math.Sqrt(norm)
+}
+
+// QuantizedCosine returns the cosine similarity of two quantized vectors.
+func QuantizedCosine(a, b *Quantized) float64 {
+	return cosine(QuantizedDot(a, b), quantizedNorm(a)*quantizedNorm(b))
+}
+
+// QuantizedDenseCosine returns the cosine similarity of a quantized
+// vector and a dense one.
+func QuantizedDenseCosine(a *Quantized, b []float32) float64 {
+	return cosine(QuantizedDenseDot(a, b), quantizedNorm(a)*denseNorm(b))
+}
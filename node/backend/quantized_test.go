@@ -0,0 +1,154 @@
+package backend
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFloat16Conversion(t *testing.T) {
+	cases := []struct {
+		f    float32
+		half uint16
+	}{
+		{0, 0x0000},
+		{float32(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff},
+		// rounds up to infinity
+		{65520, 0x7c00},
+		{float32(math.Inf(-1)), 0xfc00},
+		// smallest subnormal, and halfway below it ties to zero
+		{float32(math.Ldexp(1, -24)), 0x0001},
+		{float32(math.Ldexp(1, -25)), 0x0000},
+		{float32(math.Ldexp(3, -25)), 0x0002},
+		{1e-10, 0x0000},
+		// ties to even between 1 and the next half
+		{1 + float32(math.Ldexp(1, -11)), 0x3c00},
+		{1 + float32(math.Ldexp(3, -11)), 0x3c02},
+	}
+
+	for _, c := range cases {
+		if half := toHalf(c.f); half != c.half {
+			t.Fatalf("expected %g to be 0x%04x, got 0x%04x", c.f, c.half, half)
+		}
+	}
+
+	if half := toHalf(float32(math.NaN())); half&0x7c00 != 0x7c00 || half&0x3ff == 0 {
+		t.Fatalf("expected NaN, got 0x%04x", half)
+	}
+
+	// every half but NaN survives a round trip
+	for h := 0; h <= 0xffff; h++ {
+		if f := fromHalf(uint16(h)); f == f {
+			if back := toHalf(f); back != uint16(h) {
+				t.Fatalf("0x%04x became %g and then 0x%04x", h, f, back)
+			}
+		}
+	}
+}
+
+func TestQuantizeInt8(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	data := randomFloats(r, 1000)
+	q := QuantizeInt8(data)
+
+	if q.Size != len(data) || len(q.Data) != len(data) {
+		t.Fatalf("unexpected size %d with %d bytes", q.Size, len(q.Data))
+	}
+	for i, v := range data {
+		if err := math.Abs(float64(q.Get(i) - v)); err > float64(q.Scale)/2+1e-6 {
+			t.Fatalf("element %d is %f instead of %f", i, q.Get(i), v)
+		}
+	}
+
+	same := QuantizeInt8([]float32{3, 3, 3})
+	assertFloats(t, "constant vector", []float32{3, 3, 3}, same.Dense())
+	if empty := QuantizeInt8(nil); empty.Size != 0 || len(empty.Dense()) != 0 {
+		t.Fatalf("unexpected empty vector %v", empty)
+	}
+	if Quantize(Float32, data) != nil {
+		t.Fatalf("float32 vectors should not be quantized")
+	}
+}
+
+func TestQuantizedSlice(t *testing.T) {
+	data := []float32{1, -2, 3, 4.5, 0}
+	for _, kind := range []Quantization{Int8, Float16} {
+		q := Quantize(kind, data)
+		all := q.Dense()
+		assertFloats(t, "slice", all[1:4], q.Slice(1, 4).Dense())
+		if q.Slice(2, 2).Size != 0 {
+			t.Fatalf("expected an empty slice")
+		}
+	}
+}
+
+func TestQuantizedOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	a, b := randomFloats(r, 1003), randomFloats(r, 1003)
+	kinds := []Quantization{Int8, Float16}
+
+	for _, ka := range kinds {
+		qa := Quantize(ka, a)
+		da := qa.Dense()
+		// the kernels must match the restored vectors
+		assertNear(t, "dense dot", naive{}.Dot(da, b), QuantizedDenseDot(qa, b))
+		assertNear(t, "dense cosine", denseCosine(da, b), QuantizedDenseCosine(qa, b))
+
+		for _, kb := range kinds {
+			qb := Quantize(kb, b)
+			db := qb.Dense()
+			assertNear(t, "dot", naive{}.Dot(da, db), QuantizedDot(qa, qb))
+			assertNear(t, "cosine", denseCosine(da, db), QuantizedCosine(qa, qb))
+			// and be close enough to the original ones
+			if cos, exact := QuantizedCosine(qa, qb), denseCosine(a, b); math.Abs(cos-exact) > 0.01 {
+				t.Fatalf("cosine %f is too far from %f", cos, exact)
+			}
+		}
+	}
+}
+
+func TestQuantizedOperationsWithDifferentSizes(t *testing.T) {
+	long := []float32{1, 2, 3, 4}
+	short := []float32{1, 1}
+	for _, kind := range []Quantization{Int8, Float16} {
+		if dot := QuantizedDenseDot(Quantize(kind, long), short); math.Abs(dot-3) > 0.05 {
+			t.Fatalf("expected dot 3, got %f", dot)
+		} else if dot := QuantizedDot(Quantize(kind, short), Quantize(kind, long)); math.Abs(dot-3) > 0.05 {
+			t.Fatalf("expected dot 3, got %f", dot)
+		}
+	}
+}
+
+func quantizedDotWithSize(kind Quantization, b *testing.B, size int) {
+	r := rand.New(rand.NewSource(42))
+	qa := Quantize(kind, randomFloats(r, size))
+	qb := Quantize(kind, randomFloats(r, size))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = QuantizedDot(qa, qb)
+	}
+}
+
+func BenchmarkBackendQuantizedInt8Dot1024(b *testing.B) {
+	quantizedDotWithSize(Int8, b, 1024)
+}
+
+func BenchmarkBackendQuantizedFloat16Dot1024(b *testing.B) {
+	quantizedDotWithSize(Float16, b, 1024)
+}
+
+func BenchmarkBackendQuantizedDenseDot1024(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	qa := QuantizeInt8(randomFloats(r, 1024))
+	db := randomFloats(r, 1024)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = QuantizedDenseDot(qa, db)
+	}
+}
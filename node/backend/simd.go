@@ -0,0 +1,121 @@
+package backend
+
+import (
+	"math"
+)
+
+// simd uses hand vectorized kernels for dot products, distances and
+// norms, on amd64 CPUs with AVX2 and FMA support, and their portable
+// versions otherwise. The other operations are the naive ones.
+type simd struct {
+	naive
+}
+
+func (impl simd) Name() string {
+	return "simd"
+}
+
+func (impl simd) Dot(a, b Vector) float64 {
+	return float64(dotKernel(a.([]float32), b.([]float32)))
+}
+
+func (impl simd) Nrm2(x Vector) float64 {
+	vx := x.([]float32)
+	return math.Sqrt(float64(dotKernel(vx, vx)))
+}
+
+func (impl simd) Asum(x Vector) float64 {
+	return float64(asumKernel(x.([]float32)))
+}
+
+func (impl simd) L1(a, b Vector) float64 {
+	return float64(l1Kernel(a.([]float32), b.([]float32)))
+}
+
+func (impl simd) L2(a, b Vector) float64 {
+	return math.Sqrt(impl.SquaredL2(a, b))
+}
+
+func (impl simd) SquaredL2(a, b Vector) float64 {
+	return float64(squaredL2Kernel(a.([]float32), b.([]float32)))
+}
+
+func (impl simd) MatVec(rows, cols int, m, x []float32) []float32 {
+	y := make([]float32, rows)
+	for i := range y {
+		y[i] = dotKernel(m[i*cols:(i+1)*cols], x[:cols])
+	}
+	return y
+}
+
+// portable versions of the kernels, they use multiple accumulators
+// so that the compiler can overlap the additions.
+
+func dotPortable(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+func squaredL2Portable(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		d0, d1, d2, d3 := a[i]-b[i], a[i+1]-b[i+1], a[i+2]-b[i+2], a[i+3]-b[i+3]
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for ; i < len(a); i++ {
+		d := a[i] - b[i]
+		s0 += d * d
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+func abs32(v float32) float32 {
+	return math.Float32frombits(math.Float32bits(v) &^ (1 << 31))
+}
+
+func l1Portable(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += abs32(a[i] - b[i])
+		s1 += abs32(a[i+1] - b[i+1])
+		s2 += abs32(a[i+2] - b[i+2])
+		s3 += abs32(a[i+3] - b[i+3])
+	}
+	for ; i < len(a); i++ {
+		s0 += abs32(a[i] - b[i])
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+func asumPortable(x []float32) float32 {
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(x); i += 4 {
+		s0 += abs32(x[i])
+		s1 += abs32(x[i+1])
+		s2 += abs32(x[i+2])
+		s3 += abs32(x[i+3])
+	}
+	for ; i < len(x); i++ {
+		s0 += abs32(x[i])
+	}
+	return (s0 + s1) + (s2 + s3)
+}
@@ -0,0 +1,54 @@
+//go:build amd64
+// +build amd64
+
+package backend
+
+import (
+	"golang.org/x/sys/cpu"
+)
+
+// the assembly kernels need both AVX2 and FMA, it's a variable so
+// that tests can check the portable kernels too.
+var hasAVX2FMA = cpu.X86.HasAVX2 && cpu.X86.HasFMA
+
+// implemented in simd_amd64.s, they read len(a) elements of b
+
+//go:noescape
+func dotAVX2(a, b []float32) float32
+
+//go:noescape
+func squaredL2AVX2(a, b []float32) float32
+
+//go:noescape
+func l1AVX2(a, b []float32) float32
+
+//go:noescape
+func asumAVX2(x []float32) float32
+
+func dotKernel(a, b []float32) float32 {
+	if hasAVX2FMA {
+		return dotAVX2(a, b[:len(a)])
+	}
+	return dotPortable(a, b)
+}
+
+func squaredL2Kernel(a, b []float32) float32 {
+	if hasAVX2FMA {
+		return squaredL2AVX2(a, b[:len(a)])
+	}
+	return squaredL2Portable(a, b)
+}
+
+func l1Kernel(a, b []float32) float32 {
+	if hasAVX2FMA {
+		return l1AVX2(a, b[:len(a)])
+	}
+	return l1Portable(a, b)
+}
+
+func asumKernel(x []float32) float32 {
+	if hasAVX2FMA {
+		return asumAVX2(x)
+	}
+	return asumPortable(x)
+}
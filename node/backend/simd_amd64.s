@@ -0,0 +1,251 @@
+//go:build amd64
+// +build amd64
+
+#include "textflag.h"
+
+// The kernels process 32 elements per iteration with four accumulators,
+// then 8 elements with one, then the remaining ones one at a time. The
+// accumulators are summed into the lowest lane of X0 before the tail.
+
+// sums the Y0-Y3 accumulators into the lowest lane of X0
+#define REDUCE \
+	VADDPS Y1, Y0, Y0 \
+	VADDPS Y3, Y2, Y2 \
+	VADDPS Y2, Y0, Y0 \
+	VEXTRACTF128 $1, Y0, X1 \
+	VADDPS X1, X0, X0 \
+	VHADDPS X0, X0, X0 \
+	VHADDPS X0, X0, X0
+
+#define ZERO \
+	VXORPS Y0, Y0, Y0 \
+	VXORPS Y1, Y1, Y1 \
+	VXORPS Y2, Y2, Y2 \
+	VXORPS Y3, Y3, Y3
+
+// Y8 = 0x7fffffff in every lane, and-ing with it clears the sign
+#define ABSMASK \
+	MOVL $0x7fffffff, AX \
+	MOVL AX, X8 \
+	VPBROADCASTD X8, Y8
+
+// func dotAVX2(a, b []float32) float32
+TEXT ·dotAVX2(SB), NOSPLIT, $0-52
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	MOVQ b_base+24(FP), DI
+	ZERO
+
+dot_loop32:
+	CMPQ CX, $32
+	JL   dot_loop8
+	VMOVUPS (SI), Y4
+	VMOVUPS 32(SI), Y5
+	VMOVUPS 64(SI), Y6
+	VMOVUPS 96(SI), Y7
+	VFMADD231PS (DI), Y4, Y0
+	VFMADD231PS 32(DI), Y5, Y1
+	VFMADD231PS 64(DI), Y6, Y2
+	VFMADD231PS 96(DI), Y7, Y3
+	ADDQ $128, SI
+	ADDQ $128, DI
+	SUBQ $32, CX
+	JMP  dot_loop32
+
+dot_loop8:
+	CMPQ CX, $8
+	JL   dot_reduce
+	VMOVUPS (SI), Y4
+	VFMADD231PS (DI), Y4, Y0
+	ADDQ $32, SI
+	ADDQ $32, DI
+	SUBQ $8, CX
+	JMP  dot_loop8
+
+dot_reduce:
+	REDUCE
+
+dot_tail:
+	CMPQ CX, $0
+	JE   dot_done
+	VMOVSS (SI), X1
+	VFMADD231SS (DI), X1, X0
+	ADDQ $4, SI
+	ADDQ $4, DI
+	DECQ CX
+	JMP  dot_tail
+
+dot_done:
+	VZEROUPPER
+	MOVSS X0, ret+48(FP)
+	RET
+
+// func squaredL2AVX2(a, b []float32) float32
+TEXT ·squaredL2AVX2(SB), NOSPLIT, $0-52
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	MOVQ b_base+24(FP), DI
+	ZERO
+
+sql2_loop32:
+	CMPQ CX, $32
+	JL   sql2_loop8
+	VMOVUPS (SI), Y4
+	VMOVUPS 32(SI), Y5
+	VMOVUPS 64(SI), Y6
+	VMOVUPS 96(SI), Y7
+	VSUBPS (DI), Y4, Y4
+	VSUBPS 32(DI), Y5, Y5
+	VSUBPS 64(DI), Y6, Y6
+	VSUBPS 96(DI), Y7, Y7
+	VFMADD231PS Y4, Y4, Y0
+	VFMADD231PS Y5, Y5, Y1
+	VFMADD231PS Y6, Y6, Y2
+	VFMADD231PS Y7, Y7, Y3
+	ADDQ $128, SI
+	ADDQ $128, DI
+	SUBQ $32, CX
+	JMP  sql2_loop32
+
+sql2_loop8:
+	CMPQ CX, $8
+	JL   sql2_reduce
+	VMOVUPS (SI), Y4
+	VSUBPS (DI), Y4, Y4
+	VFMADD231PS Y4, Y4, Y0
+	ADDQ $32, SI
+	ADDQ $32, DI
+	SUBQ $8, CX
+	JMP  sql2_loop8
+
+sql2_reduce:
+	REDUCE
+
+sql2_tail:
+	CMPQ CX, $0
+	JE   sql2_done
+	VMOVSS (SI), X1
+	VSUBSS (DI), X1, X1
+	VFMADD231SS X1, X1, X0
+	ADDQ $4, SI
+	ADDQ $4, DI
+	DECQ CX
+	JMP  sql2_tail
+
+sql2_done:
+	VZEROUPPER
+	MOVSS X0, ret+48(FP)
+	RET
+
+// func l1AVX2(a, b []float32) float32
+TEXT ·l1AVX2(SB), NOSPLIT, $0-52
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	MOVQ b_base+24(FP), DI
+	ABSMASK
+	ZERO
+
+l1_loop32:
+	CMPQ CX, $32
+	JL   l1_loop8
+	VMOVUPS (SI), Y4
+	VMOVUPS 32(SI), Y5
+	VMOVUPS 64(SI), Y6
+	VMOVUPS 96(SI), Y7
+	VSUBPS (DI), Y4, Y4
+	VSUBPS 32(DI), Y5, Y5
+	VSUBPS 64(DI), Y6, Y6
+	VSUBPS 96(DI), Y7, Y7
+	VANDPS Y8, Y4, Y4
+	VANDPS Y8, Y5, Y5
+	VANDPS Y8, Y6, Y6
+	VANDPS Y8, Y7, Y7
+	VADDPS Y4, Y0, Y0
+	VADDPS Y5, Y1, Y1
+	VADDPS Y6, Y2, Y2
+	VADDPS Y7, Y3, Y3
+	ADDQ $128, SI
+	ADDQ $128, DI
+	SUBQ $32, CX
+	JMP  l1_loop32
+
+l1_loop8:
+	CMPQ CX, $8
+	JL   l1_reduce
+	VMOVUPS (SI), Y4
+	VSUBPS (DI), Y4, Y4
+	VANDPS Y8, Y4, Y4
+	VADDPS Y4, Y0, Y0
+	ADDQ $32, SI
+	ADDQ $32, DI
+	SUBQ $8, CX
+	JMP  l1_loop8
+
+l1_reduce:
+	REDUCE
+
+l1_tail:
+	CMPQ CX, $0
+	JE   l1_done
+	VMOVSS (SI), X1
+	VSUBSS (DI), X1, X1
+	VANDPS X8, X1, X1
+	VADDSS X1, X0, X0
+	ADDQ $4, SI
+	ADDQ $4, DI
+	DECQ CX
+	JMP  l1_tail
+
+l1_done:
+	VZEROUPPER
+	MOVSS X0, ret+48(FP)
+	RET
+
+// func asumAVX2(x []float32) float32
+TEXT ·asumAVX2(SB), NOSPLIT, $0-28
+	MOVQ x_base+0(FP), SI
+	MOVQ x_len+8(FP), CX
+	ABSMASK
+	ZERO
+
+asum_loop32:
+	CMPQ CX, $32
+	JL   asum_loop8
+	VANDPS (SI), Y8, Y4
+	VANDPS 32(SI), Y8, Y5
+	VANDPS 64(SI), Y8, Y6
+	VANDPS 96(SI), Y8, Y7
+	VADDPS Y4, Y0, Y0
+	VADDPS Y5, Y1, Y1
+	VADDPS Y6, Y2, Y2
+	VADDPS Y7, Y3, Y3
+	ADDQ $128, SI
+	SUBQ $32, CX
+	JMP  asum_loop32
+
+asum_loop8:
+	CMPQ CX, $8
+	JL   asum_reduce
+	VANDPS (SI), Y8, Y4
+	VADDPS Y4, Y0, Y0
+	ADDQ $32, SI
+	SUBQ $8, CX
+	JMP  asum_loop8
+
+asum_reduce:
+	REDUCE
+
+asum_tail:
+	CMPQ CX, $0
+	JE   asum_done
+	VMOVSS (SI), X1
+	VANDPS X8, X1, X1
+	VADDSS X1, X0, X0
+	ADDQ $4, SI
+	DECQ CX
+	JMP  asum_tail
+
+asum_done:
+	VZEROUPPER
+	MOVSS X0, ret+24(FP)
+	RET
@@ -0,0 +1,15 @@
+//go:build amd64
+// +build amd64
+
+package backend
+
+import (
+	"testing"
+)
+
+func TestBackendSimdPortable(t *testing.T) {
+	defer func(avx bool) { hasAVX2FMA = avx }(hasAVX2FMA)
+	hasAVX2FMA = false
+
+	checkSimdProperties(t)
+}
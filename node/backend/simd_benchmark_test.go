@@ -0,0 +1,157 @@
+package backend
+
+import (
+	"testing"
+)
+
+func BenchmarkBackendSIMDWrap128(b *testing.B) {
+	wrapWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDWrap256(b *testing.B) {
+	wrapWithSize(simd{}, b, 256)
+}
+
+func BenchmarkBackendSIMDWrap512(b *testing.B) {
+	wrapWithSize(simd{}, b, 512)
+}
+
+func BenchmarkBackendSIMDWrap1024(b *testing.B) {
+	wrapWithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDDot128(b *testing.B) {
+	dotWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDDot256(b *testing.B) {
+	dotWithSize(simd{}, b, 256)
+}
+
+func BenchmarkBackendSIMDDot512(b *testing.B) {
+	dotWithSize(simd{}, b, 512)
+}
+
+func BenchmarkBackendSIMDDot1024(b *testing.B) {
+	dotWithSize(simd{}, b, 1014)
+}
+
+func BenchmarkBackendSIMDMatVec128(b *testing.B) {
+	matVecWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDMatVec512(b *testing.B) {
+	matVecWithSize(simd{}, b, 512)
+}
+
+func BenchmarkBackendSIMDMatMul64(b *testing.B) {
+	matMulWithSize(simd{}, b, 64)
+}
+
+func BenchmarkBackendSIMDMatMul256(b *testing.B) {
+	matMulWithSize(simd{}, b, 256)
+}
+
+func BenchmarkBackendSIMDAxpy128(b *testing.B) {
+	axpyWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDAxpy1024(b *testing.B) {
+	axpyWithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDScal128(b *testing.B) {
+	scalWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDScal1024(b *testing.B) {
+	scalWithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDNrm2128(b *testing.B) {
+	nrm2WithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDNrm21024(b *testing.B) {
+	nrm2WithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDAsum128(b *testing.B) {
+	asumWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDAsum1024(b *testing.B) {
+	asumWithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDL1128(b *testing.B) {
+	l1WithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDL11024(b *testing.B) {
+	l1WithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDL2128(b *testing.B) {
+	l2WithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDL21024(b *testing.B) {
+	l2WithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDSquaredL2128(b *testing.B) {
+	squaredL2WithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDSquaredL21024(b *testing.B) {
+	squaredL2WithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDHamming128(b *testing.B) {
+	hammingWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDHamming1024(b *testing.B) {
+	hammingWithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDJaccard128(b *testing.B) {
+	jaccardWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDJaccard1024(b *testing.B) {
+	jaccardWithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDAdd128(b *testing.B) {
+	addWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDAdd1024(b *testing.B) {
+	addWithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDSub128(b *testing.B) {
+	subWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDSub1024(b *testing.B) {
+	subWithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDMul128(b *testing.B) {
+	mulWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDMul1024(b *testing.B) {
+	mulWithSize(simd{}, b, 1024)
+}
+
+func BenchmarkBackendSIMDDiv128(b *testing.B) {
+	divWithSize(simd{}, b, 128)
+}
+
+func BenchmarkBackendSIMDDiv1024(b *testing.B) {
+	divWithSize(simd{}, b, 1024)
+}
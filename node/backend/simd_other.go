@@ -0,0 +1,20 @@
+//go:build !amd64
+// +build !amd64
+
+package backend
+
+func dotKernel(a, b []float32) float32 {
+	return dotPortable(a, b)
+}
+
+func squaredL2Kernel(a, b []float32) float32 {
+	return squaredL2Portable(a, b)
+}
+
+func l1Kernel(a, b []float32) float32 {
+	return l1Portable(a, b)
+}
+
+func asumKernel(x []float32) float32 {
+	return asumPortable(x)
+}
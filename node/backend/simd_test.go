@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"testing/quick"
+)
+
+// two random vectors of the same random size, sizes up to a few hundreds
+// cover all the loops of the kernels and all their tails
+func randomPair(r *rand.Rand) ([]float32, []float32) {
+	size := r.Intn(300)
+	a, b := make([]float32, size), make([]float32, size)
+	for i := range a {
+		a[i] = r.Float32()*2 - 1
+		b[i] = r.Float32()*2 - 1
+	}
+	return a, b
+}
+
+// float32 accumulators are less precise than the float64 naive ones, the
+// error is bounded by the sum of the absolute values of the terms
+func near(expected, got, scale float64) bool {
+	return math.Abs(expected-got) <= 1e-5*math.Max(1, scale)
+}
+
+func checkSimdProperties(t *testing.T) {
+	impl, ref := simd{}, naive{}
+	properties := map[string]func(a, b []float32) bool{
+		"dot": func(a, b []float32) bool {
+			return near(ref.Dot(a, b), impl.Dot(a, b), float64(len(a)))
+		},
+		"nrm2": func(a, b []float32) bool {
+			return near(ref.Nrm2(a), impl.Nrm2(a), ref.Nrm2(a))
+		},
+		"asum": func(a, b []float32) bool {
+			return near(ref.Asum(a), impl.Asum(a), ref.Asum(a))
+		},
+		"l1": func(a, b []float32) bool {
+			return near(ref.L1(a, b), impl.L1(a, b), ref.L1(a, b))
+		},
+		"l2": func(a, b []float32) bool {
+			return near(ref.L2(a, b), impl.L2(a, b), ref.L2(a, b))
+		},
+		"squared l2": func(a, b []float32) bool {
+			return near(ref.SquaredL2(a, b), impl.SquaredL2(a, b), ref.SquaredL2(a, b))
+		},
+		"matvec": func(a, b []float32) bool {
+			// a as a matrix with a single row and as one with a single column
+			rows := impl.MatVec(1, len(a), a, b)
+			cols := impl.MatVec(len(a), 1, a, []float32{2})
+			if !near(ref.Dot(a, b), float64(rows[0]), float64(len(a))) {
+				return false
+			}
+			for i, v := range cols {
+				if v != 2*a[i] {
+					return false
+				}
+			}
+			return true
+		},
+	}
+
+	for name, property := range properties {
+		err := quick.Check(func(seed int64) bool {
+			return property(randomPair(rand.New(rand.NewSource(seed))))
+		}, &quick.Config{MaxCount: 500})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestBackendSimd(t *testing.T) {
+	checkSimdProperties(t)
+}
+
+func TestBackendSimdUnaligned(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	a, b := randomFloats(r, 100), randomFloats(r, 100)
+	for off := 0; off < 8; off++ {
+		if !near(naive{}.Dot(a[off:], b[off:]), simd{}.Dot(a[off:], b[off:]), 100) {
+			t.Fatalf("unexpected dot product for offset %d", off)
+		}
+	}
+}
+
+func TestBackendSimdShortVector(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for short vector")
+		}
+	}()
+	simd{}.Dot([]float32{1, 2, 3}, []float32{1, 2})
+}
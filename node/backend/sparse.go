@@ -0,0 +1,204 @@
+package backend
+
+import (
+	"math"
+	"sort"
+)
+
+// Sparse is a vector of Size elements of which only the non zero ones
+// are stored, Indices are their positions in increasing order and
+// Values their values.
+//
+// The operations between a sparse vector and a dense one, or between
+// two sparse vectors, only consider the elements they have in common,
+// so vectors of different sizes are compared up to the shortest one.
+type Sparse struct {
+	Size    int
+	Indices []uint32
+	Values  []float32
+}
+
+// NewSparse creates a sparse vector of size elements from its non zero
+// elements.
+func NewSparse(size int, indices []uint32, values []float32) *Sparse {
+	return &Sparse{Size: size, Indices: indices, Values: values}
+}
+
+// Get returns the index-th element of the vector.
+func (s *Sparse) Get(index int) float32 {
+	at := sort.Search(len(s.Indices), func(i int) bool { return int(s.Indices[i]) >= index })
+	if at < len(s.Indices) && int(s.Indices[at]) == index {
+		return s.Values[at]
+	}
+	return 0
+}
+
+// Slice returns the elements from start to end as a new sparse vector
+// sharing the same values.
+func (s *Sparse) Slice(start, end int) *Sparse {
+	from := sort.Search(len(s.Indices), func(i int) bool { return int(s.Indices[i]) >= start })
+	to := sort.Search(len(s.Indices), func(i int) bool { return int(s.Indices[i]) >= end })
+
+	indices := make([]uint32, to-from)
+	for i, idx := range s.Indices[from:to] {
+		indices[i] = idx - uint32(start)
+	}
+
+	return &Sparse{Size: end - start, Indices: indices, Values: s.Values[from:to:to]}
+}
+
+// Dense returns the vector with all of its elements.
+func (s *Sparse) Dense() []float32 {
+	data := make([]float32, s.Size)
+	for i, idx := range s.Indices {
+		data[idx] = s.Values[i]
+	}
+	return data
+}
+
+// number of stored elements whose index is lower than size
+func (s *Sparse) within(size int) int {
+	return sort.Search(len(s.Indices), func(i int) bool { return int(s.Indices[i]) >= size })
+}
+
+// SparseDot returns the dot product of two sparse vectors.
+func SparseDot(a, b *Sparse) float64 {
+	dot := float64(0.0)
+	for i, j := 0, 0; i < len(a.Indices) && j < len(b.Indices); {
+		switch ia, ib := a.Indices[i], b.Indices[j]; {
+		case ia < ib:
+			i++
+		case ia > ib:
+			j++
+		default:
+			dot += float64(a.Values[i]) * float64(b.Values[j])
+			i++
+			j++
+		}
+	}
+	return dot
+}
+
+// SparseDenseDot returns the dot product of a sparse vector and a dense one.
+func SparseDenseDot(a *Sparse, b []float32) float64 {
+	dot := float64(0.0)
+	for i, idx := range a.Indices[:a.within(len(b))] {
+		dot += float64(a.Values[i]) * float64(b[idx])
+	}
+	return dot
+}
+
+func sparseNorm(a *Sparse) float64 {
+	norm := float64(0.0)
+	for _, v := range a.Values {
+		norm += float64(v) * float64(v)
+	}
+	return math.Sqrt(norm)
+}
+
+func denseNorm(a []float32) float64 {
+	norm := float64(0.0)
+	for _, v := range a {
+		norm += float64(v) * float64(v)
+	}
+	return math.Sqrt(norm)
+}
+
+func cosine(dot, den float64) float64 {
+	if den == 0.0 {
+		return 0.0
+	}
+	return dot / den
+}
+
+// SparseCosine returns the cosine similarity of two sparse vectors.
+func SparseCosine(a, b *Sparse) float64 {
+	return cosine(SparseDot(a, b), sparseNorm(a)*sparseNorm(b))
+}
+
+// SparseDenseCosine returns the cosine similarity of a sparse vector
+// and a dense one.
+func SparseDenseCosine(a *Sparse, b []float32) float64 {
+	return cosine(SparseDenseDot(a, b), sparseNorm(a)*denseNorm(b))
+}
+
+// returns the m11 and m10 terms of the Jaccard index for an
+// element of two vectors, the same way the dense implementation does.
+func jaccardTerms(va, vb float32) (float64, float64) {
+	m10 := 0.0
+	if (va + vb) == 1 {
+		m10 = 1
+	}
+	return float64(va * vb), m10
+}
+
+func jaccard(m11, m10 float64) float64 {
+	if (m10 + m11) == 0 {
+		return 0
+	}
+	return m11 / (m11 + m10)
+}
+
+// SparseJaccard returns the Jaccard index of two sparse vectors, since
+// elements that are zero in both vectors do not contribute to it, only
+// the stored elements are visited.
+func SparseJaccard(a, b *Sparse) float64 {
+	m11 := 0.0
+	m10 := 0.0
+
+	na, nb := len(a.Indices), len(b.Indices)
+	if a.Size < b.Size {
+		nb = b.within(a.Size)
+	} else if b.Size < a.Size {
+		na = a.within(b.Size)
+	}
+
+	for i, j := 0, 0; i < na || j < nb; {
+		va, vb := float32(0), float32(0)
+		switch {
+		case j == nb || (i < na && a.Indices[i] < b.Indices[j]):
+			va = a.Values[i]
+			i++
+		case i == na || b.Indices[j] < a.Indices[i]:
+			vb = b.Values[j]
+			j++
+		default:
+			va, vb = a.Values[i], b.Values[j]
+			i++
+			j++
+		}
+
+		t11, t10 := jaccardTerms(va, vb)
+		m11 += t11
+		m10 += t10
+	}
+
+	return jaccard(m11, m10)
+}
+
+// SparseDenseJaccard returns the Jaccard index of a sparse vector and a
+// dense one.
+func SparseDenseJaccard(a *Sparse, b []float32) float64 {
+	m11 := 0.0
+	m10 := 0.0
+
+	size := len(b)
+	if a.Size < size {
+		size = a.Size
+	}
+
+	next := 0
+	for i, vb := range b[:size] {
+		va := float32(0)
+		if next < len(a.Indices) && int(a.Indices[next]) == i {
+			va = a.Values[next]
+			next++
+		}
+
+		t11, t10 := jaccardTerms(va, vb)
+		m11 += t11
+		m10 += t10
+	}
+
+	return jaccard(m11, m10)
+}
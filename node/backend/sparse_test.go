@@ -0,0 +1,133 @@
+package backend
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// returns a random vector with about density non zero elements
+// in both its dense and sparse form
+func randomSparse(r *rand.Rand, size int, density float64) ([]float32, *Sparse) {
+	dense := make([]float32, size)
+	sparse := &Sparse{Size: size}
+	for i := range dense {
+		if r.Float64() < density {
+			// some ones to exercise the m10 term of the Jaccard index
+			v := float32(1)
+			if r.Intn(2) == 0 {
+				v = r.Float32()
+			}
+			dense[i] = v
+			sparse.Indices = append(sparse.Indices, uint32(i))
+			sparse.Values = append(sparse.Values, v)
+		}
+	}
+	return dense, sparse
+}
+
+func denseCosine(a, b []float32) float64 {
+	return cosine(naive{}.Dot(a, b), denseNorm(a)*denseNorm(b))
+}
+
+func assertClose(t *testing.T, what string, expected, got float64) {
+	if math.Abs(expected-got) > 1e-9 {
+		t.Fatalf("expected %s %f, got %f", what, expected, got)
+	}
+}
+
+func TestSparseGetAndDense(t *testing.T) {
+	s := NewSparse(6, []uint32{1, 4}, []float32{2, 3})
+	expected := []float32{0, 2, 0, 0, 3, 0}
+	for i, v := range expected {
+		if got := s.Get(i); got != v {
+			t.Fatalf("expected %f at index %d, got %f", v, i, got)
+		}
+	}
+
+	dense := s.Dense()
+	for i, v := range expected {
+		if dense[i] != v {
+			t.Fatalf("expected %f at index %d, got %f", v, i, dense[i])
+		}
+	}
+}
+
+func TestSparseSlice(t *testing.T) {
+	s := NewSparse(10, []uint32{0, 3, 5, 9}, []float32{1, 2, 3, 4})
+	sub := s.Slice(3, 9)
+	if sub.Size != 6 {
+		t.Fatalf("expected size 6, got %d", sub.Size)
+	} else if len(sub.Indices) != 2 || sub.Indices[0] != 0 || sub.Indices[1] != 2 {
+		t.Fatalf("unexpected indices %v", sub.Indices)
+	} else if sub.Values[0] != 2 || sub.Values[1] != 3 {
+		t.Fatalf("unexpected values %v", sub.Values)
+	}
+}
+
+func TestSparseOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 100; i++ {
+		da, sa := randomSparse(r, 200, 0.1)
+		db, sb := randomSparse(r, 200, 0.3)
+
+		assertClose(t, "sparse dot", naive{}.Dot(da, db), SparseDot(sa, sb))
+		assertClose(t, "sparse dense dot", naive{}.Dot(da, db), SparseDenseDot(sa, db))
+
+		assertClose(t, "sparse cosine", denseCosine(da, db), SparseCosine(sa, sb))
+		assertClose(t, "sparse dense cosine", denseCosine(da, db), SparseDenseCosine(sa, db))
+
+		assertClose(t, "sparse jaccard", denseJaccard(da, db), SparseJaccard(sa, sb))
+		assertClose(t, "sparse dense jaccard", denseJaccard(da, db), SparseDenseJaccard(sa, db))
+	}
+}
+
+func TestSparseOperationsWithEmpty(t *testing.T) {
+	empty := NewSparse(3, nil, nil)
+	other := NewSparse(3, []uint32{1}, []float32{1})
+
+	if dot := SparseDot(empty, other); dot != 0 {
+		t.Fatalf("expected dot 0, got %f", dot)
+	} else if cos := SparseCosine(empty, other); cos != 0 {
+		t.Fatalf("expected cosine 0, got %f", cos)
+	} else if cos := SparseDenseCosine(empty, []float32{0, 0, 0}); cos != 0 {
+		t.Fatalf("expected cosine 0, got %f", cos)
+	} else if jac := SparseJaccard(empty, empty); jac != 0 {
+		t.Fatalf("expected jaccard 0, got %f", jac)
+	}
+}
+
+func TestSparseOperationsWithDifferentSizes(t *testing.T) {
+	long := NewSparse(5, []uint32{0, 4}, []float32{1, 1})
+	short := []float32{1, 1}
+
+	if dot := SparseDenseDot(long, short); dot != 1 {
+		t.Fatalf("expected dot 1, got %f", dot)
+	} else if jac := SparseDenseJaccard(long, short); jac != 0.5 {
+		t.Fatalf("expected jaccard 0.5, got %f", jac)
+	} else if jac := SparseJaccard(long, NewSparse(2, []uint32{1}, []float32{1})); jac != 0 {
+		t.Fatalf("expected jaccard 0, got %f", jac)
+	}
+}
+
+func BenchmarkBackendSparseDot(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	_, sa := randomSparse(r, 100000, 0.01)
+	_, sb := randomSparse(r, 100000, 0.01)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = SparseDot(sa, sb)
+	}
+}
+
+func BenchmarkBackendSparseDenseDot(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	_, sa := randomSparse(r, 100000, 0.01)
+	db, _ := randomSparse(r, 100000, 0.5)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = SparseDenseDot(sa, db)
+	}
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/evilsocket/sum/node/storage"
+	pb "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/evilsocket/islazy/log"
+)
+
+const (
+	// the maximum number of rows and of bytes of the records
+	// created at once while importing an array
+	importBatchRows = 1024
+	importBatchSize = 16 * 1024 * 1024
+	// the number of records copied out of a collection at once
+	// while exporting it
+	exportBatchRows = 1024
+)
+
+func errImportResponse(format string, args ...interface{}) *pb.ImportResponse {
+	return &pb.ImportResponse{Success: false, Msg: fmt.Sprintf(format, args...)}
+}
+
+// ImportedArray is an array received from an import stream, whose rows
+// are read one at a time from temporary files.
+type ImportedArray struct {
+	*storage.ArrayReader
+	// Header is the header sent by the client.
+	Header *pb.ArrayHeader
+
+	meta  *storage.MetaLinesReader
+	files []*os.File
+}
+
+// ReceiveArray receives an array and its optional meta data from an import
+// stream into temporary files in tmpDir, the caller must close the returned
+// array to remove them.
+func ReceiveArray(stream pb.SumService_ImportServer, tmpDir string) (*ImportedArray, error) {
+	imported := &ImportedArray{}
+	if err := imported.receive(stream, tmpDir); err != nil {
+		imported.Close()
+		return nil, err
+	}
+	return imported, nil
+}
+
+func (a *ImportedArray) receive(stream pb.SumService_ImportServer, tmpDir string) error {
+	data, err := ioutil.TempFile(tmpDir, ".import.*"+storage.TmpFileExt)
+	if err != nil {
+		return err
+	}
+	a.files = append(a.files, data)
+
+	meta, err := ioutil.TempFile(tmpDir, ".import.meta.*"+storage.TmpFileExt)
+	if err != nil {
+		return err
+	}
+	a.files = append(a.files, meta)
+
+	dataSize, metaSize := int64(0), int64(0)
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if a.Header == nil {
+			a.Header = chunk.Header
+		}
+		if _, err = data.Write(chunk.Data); err != nil {
+			return err
+		} else if _, err = meta.Write(chunk.Meta); err != nil {
+			return err
+		}
+		dataSize += int64(len(chunk.Data))
+		metaSize += int64(len(chunk.Meta))
+	}
+
+	if a.Header == nil {
+		a.Header = &pb.ArrayHeader{}
+	}
+
+	if a.ArrayReader, err = storage.NewArrayReader(data, dataSize, a.Header.Format, a.Header.Name); err != nil {
+		return err
+	}
+
+	if metaSize > 0 {
+		if _, err = meta.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		a.meta = storage.NewMetaLinesReader(bufio.NewReader(meta))
+	}
+	return nil
+}
+
+// Next returns a new record for the next row of the array with its meta
+// data, or io.EOF after the last one.
+func (a *ImportedArray) Next() (*pb.Record, error) {
+	record, err := a.ArrayReader.Next()
+	if err == io.EOF && a.meta != nil {
+		if err := a.meta.Next(&pb.Record{}); err == nil {
+			return nil, fmt.Errorf("the meta data has more lines than the %d rows of the array", a.Rows())
+		} else if err != io.EOF {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if a.meta != nil {
+		if err := a.meta.Next(record); err == io.EOF {
+			return nil, fmt.Errorf("the meta data has %d lines instead of one for each of the %d rows of the array", a.meta.Rows(), a.Rows())
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	record.Collection = a.Header.Collection
+	return record, nil
+}
+
+// Close removes the temporary files of the array.
+func (a *ImportedArray) Close() error {
+	if a.ArrayReader != nil {
+		a.ArrayReader.Close()
+	}
+	for _, fp := range a.files {
+		fp.Close()
+		os.Remove(fp.Name())
+	}
+	return nil
+}
+
+// sends what is written to it as chunks of an export stream
+type chunkWriter struct {
+	stream pb.SumService_ExportServer
+	meta   bool
+}
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	chunk := &pb.ArrayChunk{}
+	if w.meta {
+		chunk.Meta = p
+	} else {
+		chunk.Data = p
+	}
+
+	if err := w.stream.Send(chunk); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// SendArray streams the records, that must be sorted by identifier, as
+// an array in the format of the header encoded one row at a time, followed
+// by their meta data if requested.
+func SendArray(stream pb.SumService_ExportServer, header *pb.ArrayHeader, records []*pb.Record) error {
+	return sendArray(stream, header, uint64(len(records)), func() func() (*pb.Record, error) {
+		return storage.RecordsIterator(records)
+	})
+}
+
+// streams the given number of records, iterate is called once for the
+// rows and once for the meta data and returns them one at a time
+func sendArray(stream pb.SumService_ExportServer, header *pb.ArrayHeader, rows uint64, iterate func() func() (*pb.Record, error)) error {
+	data := bufio.NewWriterSize(chunkWriter{stream: stream}, snapshotChunkSize)
+	if err := storage.WriteRecordsArrayFrom(data, header.Format, header.Name, rows, iterate()); err != nil {
+		return err
+	} else if err := data.Flush(); err != nil {
+		return err
+	}
+
+	if header.Meta {
+		meta := bufio.NewWriterSize(chunkWriter{stream: stream, meta: true}, snapshotChunkSize)
+		if err := storage.WriteMetaLinesFrom(meta, iterate()); err != nil {
+			return err
+		} else if err := meta.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Import creates a record for every row of an array streamed by the client,
+// the records have consecutive identifiers and are either all created or none.
+// Rows are decoded one at a time and created in batches, so that the whole
+// array is never held in memory.
+func (s *Service) Import(stream pb.SumService_ImportServer) error {
+	array, err := ReceiveArray(stream, s.datapath)
+	if err != nil {
+		return stream.SendAndClose(errImportResponse("%s", err))
+	}
+	defer array.Close()
+
+	records, release, err := s.collection(array.Header.Collection)
+	if err != nil {
+		return stream.SendAndClose(errImportResponse("%s", err))
+	}
+	defer release()
+
+	rows := int(array.Rows())
+	firstId, err := records.Reserve(rows)
+	if err != nil {
+		return stream.SendAndClose(errImportResponse("%s", err))
+	}
+
+	created := make([]uint64, 0)
+	fail := func(format string, args ...interface{}) error {
+		// none of the records is left behind, not even in the trash
+		records.DeleteMany(created)
+		records.PurgeTrash(created, 0)
+		records.Unreserve(firstId, rows)
+		return stream.SendAndClose(errImportResponse(format, args...))
+	}
+
+	batch, size := make([]*pb.Record, 0), 0
+	flush := func() error {
+		if err := records.CreateManyWIthId(batch); err != nil {
+			return err
+		}
+		for _, record := range batch {
+			created = append(created, record.Id)
+		}
+		batch, size = make([]*pb.Record, 0), 0
+		return nil
+	}
+
+	for {
+		record, err := array.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fail("%s", err)
+		}
+
+		record.Id = firstId + uint64(len(created)+len(batch))
+		recSize := proto.Size(record)
+		if len(batch) > 0 && (len(batch) == importBatchRows || size+recSize > importBatchSize) {
+			if err := flush(); err != nil {
+				return fail("%s", err)
+			}
+		}
+		batch = append(batch, record)
+		size += recSize
+	}
+
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return fail("%s", err)
+		}
+	}
+
+	resp := &pb.ImportResponse{Success: true, Records: uint64(len(created))}
+	if len(created) > 0 {
+		resp.FirstId = firstId
+	}
+
+	log.Info("imported %d records from a %s array", resp.Records, array.Header.Format)
+
+	return stream.SendAndClose(resp)
+}
+
+// Export streams the records of a collection as an array, sorted by
+// identifier, and optionally their meta data. The identifiers are taken
+// at once, while the records are copied out of the collection in batches
+// as they are sent, so that the collection is not held for the whole stream.
+func (s *Service) Export(arg *pb.ArrayHeader, stream pb.SumService_ExportServer) error {
+	records, release, err := s.collection(arg.Collection)
+	if err != nil {
+		return err
+	}
+	ids := records.Ids()
+	release()
+
+	return sendArray(stream, arg, uint64(len(ids)), func() func() (*pb.Record, error) {
+		return s.exportIterator(arg.Collection, ids)
+	})
+}
+
+// returns the records with the given identifiers one at a time, reading
+// them from the collection exportBatchRows at a time
+func (s *Service) exportIterator(collection string, ids []uint64) func() (*pb.Record, error) {
+	batch := make([]*pb.Record, 0)
+	return func() (*pb.Record, error) {
+		if len(batch) == 0 {
+			if len(ids) == 0 {
+				return nil, io.EOF
+			}
+
+			n := exportBatchRows
+			if n > len(ids) {
+				n = len(ids)
+			}
+
+			records, release, err := s.collection(collection)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range ids[:n] {
+				m := records.Index.Find(id)
+				if m == nil {
+					release()
+					return nil, fmt.Errorf("record %d has been deleted during the export", id)
+				}
+				batch = append(batch, readableRecord(m.(*pb.Record), false))
+			}
+			release()
+			ids = ids[n:]
+		}
+
+		record := batch[0]
+		batch = batch[1:]
+		return record, nil
+	}
+}
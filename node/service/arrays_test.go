@@ -0,0 +1,217 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/evilsocket/sum/node/storage"
+	pb "github.com/evilsocket/sum/proto"
+
+	"google.golang.org/grpc"
+)
+
+type importStream struct {
+	grpc.ServerStream
+	chunks []*pb.ArrayChunk
+	resp   *pb.ImportResponse
+}
+
+func (s *importStream) Context() context.Context {
+	return context.TODO()
+}
+
+func (s *importStream) Recv() (*pb.ArrayChunk, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func (s *importStream) SendAndClose(resp *pb.ImportResponse) error {
+	s.resp = resp
+	return nil
+}
+
+type exportStream struct {
+	grpc.ServerStream
+	data bytes.Buffer
+	meta bytes.Buffer
+}
+
+func (s *exportStream) Context() context.Context {
+	return context.TODO()
+}
+
+func (s *exportStream) Send(chunk *pb.ArrayChunk) error {
+	s.data.Write(chunk.Data)
+	s.meta.Write(chunk.Meta)
+	return nil
+}
+
+var testImportArray = storage.Array{
+	Shape: []uint64{3, 4},
+	Data:  []float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+}
+
+// splits the array and meta data in small chunks to exercise reassembly
+func makeImportStream(t *testing.T, header *pb.ArrayHeader, array *storage.Array, meta string) *importStream {
+	buf := bytes.Buffer{}
+	if err := storage.WriteArray(&buf, header.Format, header.Name, array); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &importStream{chunks: []*pb.ArrayChunk{{Header: header}}}
+	for data := buf.Bytes(); len(data) > 0; {
+		n := 64
+		if n > len(data) {
+			n = len(data)
+		}
+		s.chunks = append(s.chunks, &pb.ArrayChunk{Data: data[:n]})
+		data = data[n:]
+	}
+	if meta != "" {
+		s.chunks = append(s.chunks, &pb.ArrayChunk{Meta: []byte(meta)})
+	}
+	return s
+}
+
+func TestServiceImportExport(t *testing.T) {
+	setupFolders(t)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta := "{\"label\": \"a\"}\n{\"label\": \"b\"}\n{\"label\": \"c\", \"score\": 3}\n"
+	header := &pb.ArrayHeader{Format: pb.ArrayHeader_SAFETENSORS}
+	stream := makeImportStream(t, header, &testImportArray, meta)
+	if err := svc.Import(stream); err != nil {
+		t.Fatal(err)
+	} else if !stream.resp.Success {
+		t.Fatalf("expected success response: %v", stream.resp)
+	} else if stream.resp.Records != 3 {
+		t.Fatalf("expected 3 imported records, got %d", stream.resp.Records)
+	} else if svc.NumRecords() != 3 {
+		t.Fatalf("expected 3 records, got %d", svc.NumRecords())
+	}
+
+	for i, label := range []string{"a", "b", "c"} {
+		i := uint64(i)
+		rec := svc.records.Find(stream.resp.FirstId + i)
+		if rec == nil {
+			t.Fatalf("record %d not found", stream.resp.FirstId+i)
+		} else if !reflect.DeepEqual(rec.Data, testImportArray.Data[i*4:(i+1)*4]) {
+			t.Fatalf("unexpected data %v", rec.Data)
+		} else if rec.Meta["label"] != label {
+			t.Fatalf("unexpected meta %v", rec.Meta)
+		}
+	}
+
+	export := &exportStream{}
+	if err := svc.Export(&pb.ArrayHeader{Format: pb.ArrayHeader_NPZ, Meta: true}, export); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bytes.NewReader(export.data.Bytes())
+	if a, err := storage.ReadArray(r, r.Size(), pb.ArrayHeader_NPZ, storage.DefaultArrayName); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(a, &testImportArray) {
+		t.Fatalf("expected %v, got %v", testImportArray, a)
+	}
+
+	expected := "{\"label\":\"a\"}\n{\"label\":\"b\"}\n{\"label\":\"c\",\"score\":3}\n"
+	if export.meta.String() != expected {
+		t.Fatalf("expected meta %q, got %q", expected, export.meta.String())
+	}
+}
+
+func TestServiceImportInvalid(t *testing.T) {
+	setupFolders(t)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]*importStream{
+		"not an array":       {chunks: []*pb.ArrayChunk{{Header: &pb.ArrayHeader{}, Data: []byte("nope")}}},
+		"unknown collection": makeImportStream(t, &pb.ArrayHeader{Collection: "nope"}, &testImportArray, ""),
+		"meta mismatch":      makeImportStream(t, &pb.ArrayHeader{}, &testImportArray, "{}\n"),
+		"1 dimension":        makeImportStream(t, &pb.ArrayHeader{}, &storage.Array{Shape: []uint64{2}, Data: []float32{1, 2}}, ""),
+	}
+
+	for name, stream := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := svc.Import(stream); err != nil {
+				t.Fatal(err)
+			} else if stream.resp.Success {
+				t.Fatal("expected error response")
+			} else if svc.NumRecords() != 0 {
+				t.Fatalf("no records should be created, got %d", svc.NumRecords())
+			}
+		})
+	}
+}
+
+func TestServiceImportBatches(t *testing.T) {
+	setupFolders(t)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := 2*importBatchRows + 1
+	array := &storage.Array{Shape: []uint64{uint64(rows), 2}, Data: make([]float32, rows*2)}
+	for i := range array.Data {
+		array.Data[i] = float32(i)
+	}
+
+	// a failure after some batches have been created removes all of them
+	nextID := svc.records.GetNextId()
+	meta := bytes.Repeat([]byte("{}\n"), rows+1)
+	stream := makeImportStream(t, &pb.ArrayHeader{}, array, string(meta))
+	if err := svc.Import(stream); err != nil {
+		t.Fatal(err)
+	} else if stream.resp.Success {
+		t.Fatal("expected error response for meta mismatch")
+	} else if svc.NumRecords() != 0 {
+		t.Fatalf("no records should be left, got %d", svc.NumRecords())
+	} else if svc.records.TrashSize() != 0 {
+		t.Fatalf("no records should be left in the trash, got %d", svc.records.TrashSize())
+	} else if svc.records.GetNextId() != nextID {
+		t.Fatalf("expected next id %d, got %d", nextID, svc.records.GetNextId())
+	}
+
+	stream = makeImportStream(t, &pb.ArrayHeader{}, array, "")
+	if err := svc.Import(stream); err != nil {
+		t.Fatal(err)
+	} else if !stream.resp.Success {
+		t.Fatalf("expected success response: %v", stream.resp)
+	} else if stream.resp.Records != uint64(rows) || stream.resp.FirstId != nextID {
+		t.Fatalf("unexpected response %v", stream.resp)
+	} else if rec := svc.records.Find(nextID + uint64(rows) - 1); rec == nil || rec.Data[1] != float32(rows*2-1) {
+		t.Fatalf("unexpected last record %v", rec)
+	}
+
+	export := &exportStream{}
+	if err := svc.Export(&pb.ArrayHeader{}, export); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bytes.NewReader(export.data.Bytes())
+	if a, err := storage.ReadArray(r, r.Size(), pb.ArrayHeader_NPY, ""); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(a, array) {
+		t.Fatalf("unexpected exported array of shape %v", a.Shape)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/evilsocket/sum/node/backend"
+	"github.com/evilsocket/sum/node/storage"
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/evilsocket/islazy/log"
+	"github.com/golang/protobuf/proto"
+)
+
+// Backend returns the backend computing the vector operations of the
+// oracles running on a collection, which is the one of the service
+// unless the collection has its own.
+func (s *Service) Backend(collection string) *backend.Backend {
+	s.RLock()
+	defer s.RUnlock()
+
+	if b, found := s.backends[collection]; found {
+		return b
+	}
+	return s.backend
+}
+
+// SetBackend sets the backend of a named collection, or the one of the
+// service if the collection name is empty. An empty backend name makes
+// a collection use the backend of the service again.
+func (s *Service) SetBackend(collection, name string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if collection != "" {
+		if _, found := s.collections[collection]; !found {
+			return fmt.Errorf("collection %s not found.", collection)
+		} else if name == "" {
+			delete(s.backends, collection)
+			return nil
+		}
+	}
+
+	b, err := backend.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if collection == "" {
+		s.backend = b
+	} else {
+		s.backends[collection] = b
+	}
+	return nil
+}
+
+const (
+	// number of records of every collection looked at to find the
+	// most common size of the vectors
+	sizeSamples = 1000
+	// size of the vectors the backends are measured with when there
+	// are no records yet
+	defaultBenchmarkSize = 128
+)
+
+var errEnoughSamples = errors.New("enough samples")
+
+// returns the most common number of elements of the vectors among a
+// sample of the records of every collection, 0 if there are no records
+func (s *Service) dominantSize() int {
+	s.RLock()
+	all := []*storage.Records{s.records}
+	for _, records := range s.collections {
+		all = append(all, records)
+	}
+	s.RUnlock()
+
+	counts := make(map[uint64]int)
+	for _, records := range all {
+		sampled := 0
+		err := records.ForEach(func(m proto.Message) error {
+			if sampled++; sampled > sizeSamples {
+				return errEnoughSamples
+			}
+			counts[storage.VectorSize(m.(*pb.Record))]++
+			return nil
+		})
+		if err != nil && err != errEnoughSamples {
+			log.Warning("error while sampling the records: %v", err)
+		}
+	}
+
+	size, most := uint64(0), 0
+	for elems, count := range counts {
+		if count > most || (count == most && elems > size) {
+			size, most = elems, count
+		}
+	}
+	return int(size)
+}
+
+// SelectFastestBackend measures every available backend for about duration
+// with vectors of the most common size among the loaded records, and makes
+// the fastest one the backend of the service. The timings are returned from
+// the fastest backend to the slowest one and are reported by Info.
+func (s *Service) SelectFastestBackend(duration time.Duration) []backend.Timing {
+	size := s.dominantSize()
+	if size == 0 {
+		size = defaultBenchmarkSize
+	}
+
+	timings := backend.Benchmark(size, duration)
+	benchmarks := make([]*pb.BackendBenchmark, len(timings))
+	for i, timing := range timings {
+		log.Info("backend %s: %.1f ns per dot product of %d elements", timing.Name, timing.NsPerOp, timing.Size)
+		benchmarks[i] = &pb.BackendBenchmark{Name: timing.Name, Size: uint64(timing.Size), NsPerOp: timing.NsPerOp}
+	}
+
+	fastest, _ := backend.Get(timings[0].Name)
+	log.Info("selected the %s backend", fastest.Name())
+
+	s.Lock()
+	defer s.Unlock()
+	s.backend = fastest
+	s.benchmarks = benchmarks
+	return timings
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/evilsocket/sum/node/backend"
+	pb "github.com/evilsocket/sum/proto"
+	"golang.org/x/net/context"
+)
+
+func TestServiceBackends(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if resp, _ := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "small"}); !resp.Success {
+		t.Fatal(resp.Msg)
+	} else if resp, _ := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1}, Collection: "small"}); !resp.Success {
+		t.Fatal(resp.Msg)
+	}
+
+	oracle := pb.Oracle{Name: "backend", Code: "function backend(){ return records.All()[0].Backend(); }"}
+	resp, err := svc.CreateOracle(context.TODO(), &oracle)
+	if err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatal(resp.Msg)
+	}
+
+	oracleID, err := strconv.ParseUint(resp.Msg, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backendOf := func(collection string) string {
+		t.Helper()
+		resp, err := svc.Run(context.TODO(), &pb.Call{OracleId: oracleID, Collection: collection})
+		if err != nil {
+			t.Fatal(err)
+		} else if !resp.Success {
+			t.Fatal(resp.Msg)
+		}
+		return decompress(t, resp.Data)
+	}
+
+	if err := svc.SetBackend("", "nope"); err == nil {
+		t.Fatal("expected error for unknown backend")
+	} else if err := svc.SetBackend("nope", "naive"); err == nil {
+		t.Fatal("expected error for unknown collection")
+	} else if err := svc.SetBackend("", "naive"); err != nil {
+		t.Fatal(err)
+	} else if err := svc.SetBackend("small", "simd"); err != nil {
+		t.Fatal(err)
+	} else if b := backendOf(""); b != `"naive"` {
+		t.Fatalf("expected naive backend, got %s", b)
+	} else if b := backendOf("small"); b != `"simd"` {
+		t.Fatalf("expected simd backend, got %s", b)
+	} else if info, err := svc.Info(context.TODO(), &pb.Empty{}); err != nil {
+		t.Fatal(err)
+	} else if info.Backend != "naive" {
+		t.Fatalf("expected naive backend, got %s", info.Backend)
+	}
+
+	// back to the backend of the service
+	if err := svc.SetBackend("small", ""); err != nil {
+		t.Fatal(err)
+	} else if b := backendOf("small"); b != `"naive"` {
+		t.Fatalf("expected naive backend, got %s", b)
+	}
+}
+
+func TestServiceSelectFastestBackend(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if info, _ := svc.Info(context.TODO(), &pb.Empty{}); len(info.BackendBenchmarks) != 0 {
+		t.Fatalf("unexpected benchmarks %v", info.BackendBenchmarks)
+	}
+
+	// the test records have 3 elements
+	timings := svc.SelectFastestBackend(time.Millisecond)
+	if len(timings) != len(backend.Available()) {
+		t.Fatalf("expected %d timings, got %d", len(backend.Available()), len(timings))
+	} else if timings[0].Size != 3 {
+		t.Fatalf("expected vectors of 3 elements, got %d", timings[0].Size)
+	} else if name := svc.Backend("").Name(); name != timings[0].Name {
+		t.Fatalf("expected the %s backend, got %s", timings[0].Name, name)
+	}
+
+	info, err := svc.Info(context.TODO(), &pb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	} else if info.Backend != timings[0].Name {
+		t.Fatalf("expected the %s backend, got %s", timings[0].Name, info.Backend)
+	} else if len(info.BackendBenchmarks) != len(timings) {
+		t.Fatalf("expected %d benchmarks, got %v", len(timings), info.BackendBenchmarks)
+	}
+	for i, bench := range info.BackendBenchmarks {
+		if bench.Name != timings[i].Name || bench.Size != 3 || bench.NsPerOp != timings[i].NsPerOp {
+			t.Fatalf("unexpected benchmark %v for %+v", bench, timings[i])
+		}
+	}
+}
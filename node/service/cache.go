@@ -32,3 +32,9 @@ func (cc *compiledCache) Del(id uint64) {
 	defer cc.Unlock()
 	delete(cc.cache, id)
 }
+
+func (cc *compiledCache) Reset(cache map[uint64]*compiled) {
+	cc.Lock()
+	defer cc.Unlock()
+	cc.cache = cache
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/evilsocket/sum/node/storage"
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/evilsocket/islazy/log"
+	"golang.org/x/net/context"
+)
+
+const collectionsFolderName = "collections"
+
+var collectionNameParser = regexp.MustCompile(`^[a-zA-Z0-9_\-]{1,64}$`)
+
+func errCollectionResponse(format string, args ...interface{}) *pb.CollectionResponse {
+	return &pb.CollectionResponse{Success: false, Msg: fmt.Sprintf(format, args...)}
+}
+
+// ValidCollectionName returns an error if the name can't be used
+// for a collection.
+func ValidCollectionName(name string) error {
+	if !collectionNameParser.MatchString(name) {
+		return fmt.Errorf("invalid collection name '%s', only up to 64 letters, digits, '_' and '-' are allowed", name)
+	}
+	return nil
+}
+
+// loads every named collection found in the data path
+func loadCollections(dataPath string) (map[string]*storage.Records, error) {
+	collections := make(map[string]*storage.Records)
+	basePath := filepath.Join(dataPath, collectionsFolderName)
+
+	entries, err := ioutil.ReadDir(basePath)
+	if os.IsNotExist(err) {
+		return collections, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || ValidCollectionName(name) != nil {
+			continue
+		}
+
+		records, err := loadRecords(filepath.Join(basePath, name))
+		if err != nil {
+			return nil, fmt.Errorf("error while loading collection %s: %s", name, err)
+		}
+
+		log.Debug("loaded collection %s with %d records", name, records.Size())
+		collections[name] = records
+	}
+
+	return collections, nil
+}
+
+// returns the records of a collection given its name, or the
+// default collection if the name is empty, and the function to call
+// once done with them. The records are pinned until released, and a
+// named collection is not dropped until every user has released it,
+// users must not take the service lock before.
+func (s *Service) collection(name string) (*storage.Records, func(), error) {
+	if name == "" {
+		return s.records, s.records.Pin(), nil
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if records, found := s.collections[name]; found {
+		inUse := s.inUse[name]
+		inUse.RLock()
+		unpin := records.Pin()
+		return records, func() {
+			unpin()
+			inUse.RUnlock()
+		}, nil
+	}
+	return nil, nil, fmt.Errorf("collection %s not found.", name)
+}
+
+func (s *Service) _collectionsInfo() []*pb.Collection {
+	list := make([]*pb.Collection, 0, len(s.collections))
+	for name, records := range s.collections {
+		list = append(list, &pb.Collection{
+			Name:         name,
+			Records:      uint64(records.Size()),
+			NextRecordId: records.GetNextId(),
+			Schema:       records.Schema(),
+			Trashed:      uint64(records.TrashSize()),
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+func (s *Service) collectionsInfo() []*pb.Collection {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s._collectionsInfo()
+}
+
+// NumCollections returns the number of named collections of the service.
+func (s *Service) NumCollections() int {
+	s.RLock()
+	defer s.RUnlock()
+
+	return len(s.collections)
+}
+
+// CreateCollection creates a new and empty named collection of records.
+func (s *Service) CreateCollection(ctx context.Context, arg *pb.ByName) (*pb.CollectionResponse, error) {
+	if err := ValidCollectionName(arg.Name); err != nil {
+		return errCollectionResponse("%s", err), nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if _, found := s.collections[arg.Name]; found {
+		return errCollectionResponse("collection %s already exists.", arg.Name), nil
+	}
+
+	records, err := s._createCollection(arg.Name)
+	if err != nil {
+		return errCollectionResponse("%s", err), nil
+	}
+
+	return &pb.CollectionResponse{
+		Success:     true,
+		Collections: []*pb.Collection{{Name: arg.Name, NextRecordId: records.GetNextId()}},
+	}, nil
+}
+
+// DropCollection removes a named collection and all of its records,
+// once released by its current users.
+func (s *Service) DropCollection(ctx context.Context, arg *pb.ByName) (*pb.CollectionResponse, error) {
+	s.Lock()
+	if _, found := s.collections[arg.Name]; !found {
+		s.Unlock()
+		return errCollectionResponse("collection %s not found.", arg.Name), nil
+	}
+	drop := s._dropCollection(arg.Name)
+	s.Unlock()
+
+	// other collections can be used while waiting for the users of this one
+	if err := drop(); err != nil {
+		return errCollectionResponse("%s", err), nil
+	}
+
+	return &pb.CollectionResponse{Success: true}, nil
+}
+
+// creates the folder of a new named collection and loads it
+func (s *Service) _createCollection(name string) (*storage.Records, error) {
+	if s.dropping[name] {
+		return nil, fmt.Errorf("collection %s is being dropped.", name)
+	}
+
+	path := s.collectionPath(name)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+
+	records, err := loadRecords(path)
+	if err != nil {
+		return nil, err
+	}
+
+	records.SetFeed(s.feed, name)
+	s.collections[name] = records
+	s.inUse[name] = &sync.RWMutex{}
+	return records, nil
+}
+
+// removes a named collection so that new users can't find it and returns
+// the function closing it, once released by its current users, and
+// removing its folder and schema, that must be called without holding
+// the service lock
+func (s *Service) _dropCollection(name string) func() error {
+	records, inUse := s.collections[name], s.inUse[name]
+	delete(s.collections, name)
+	delete(s.inUse, name)
+	delete(s.backends, name)
+	s.dropping[name] = true
+
+	return func() error {
+		defer func() {
+			s.Lock()
+			delete(s.dropping, name)
+			s.Unlock()
+		}()
+
+		inUse.Lock()
+		defer inUse.Unlock()
+
+		if err := records.Close(); err != nil {
+			log.Error("error while closing collection %s: %s", name, err)
+		}
+
+		s.feed.Publish(&pb.Event{Type: pb.Event_RESET, Collection: name})
+
+		path := s.collectionPath(name)
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		return storage.SaveSchema(schemaFileOf(path), nil)
+	}
+}
+
+// ListCollections returns the named collections sorted by name.
+func (s *Service) ListCollections(ctx context.Context, dummy *pb.Empty) (*pb.CollectionResponse, error) {
+	return &pb.CollectionResponse{Success: true, Collections: s.collectionsInfo()}, nil
+}
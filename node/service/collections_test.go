@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/evilsocket/sum/node/storage"
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func TestServiceCollections(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"", "..", "a/b", "with space"} {
+		if resp, err := svc.CreateCollection(context.TODO(), &pb.ByName{Name: name}); err != nil {
+			t.Fatal(err)
+		} else if resp.Success {
+			t.Fatalf("collection '%s' should not be valid", name)
+		}
+	}
+
+	if resp, err := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if resp, err := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response for existing collection")
+	} else if _, err := os.Stat(filepath.Join(testFolder, collectionsFolderName, "images")); err != nil {
+		t.Fatal(err)
+	}
+
+	// ids are not shared with the default collection
+	record := pb.Record{Data: []float32{1, 2, 3}, Collection: "images"}
+	if resp, err := svc.CreateRecord(context.TODO(), &record); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if resp.Msg != "1" {
+		t.Fatalf("expected record 1, got %s", resp.Msg)
+	} else if svc.NumRecords() != testRecords {
+		t.Fatalf("expected %d records in the default collection, got %d", testRecords, svc.NumRecords())
+	}
+
+	if resp, err := svc.ReadRecord(context.TODO(), &pb.ById{Id: 1, Collection: "images"}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success || resp.Record.Data[2] != 3 {
+		t.Fatalf("unexpected response: %v", resp)
+	} else if resp, err := svc.ReadRecord(context.TODO(), &pb.ById{Id: 1, Collection: "nope"}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response for unknown collection")
+	}
+
+	if list, err := svc.ListRecords(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 10, Collection: "images"}); err != nil {
+		t.Fatal(err)
+	} else if list.Total != 1 {
+		t.Fatalf("expected 1 record, got %d", list.Total)
+	}
+
+	if resp, err := svc.ListCollections(context.TODO(), &pb.Empty{}); err != nil {
+		t.Fatal(err)
+	} else if len(resp.Collections) != 1 || resp.Collections[0].Name != "images" || resp.Collections[0].Records != 1 {
+		t.Fatalf("unexpected collections: %v", resp.Collections)
+	} else if info, err := svc.Info(context.TODO(), &pb.Empty{}); err != nil {
+		t.Fatal(err)
+	} else if len(info.Collections) != 1 || info.Collections[0].NextRecordId != 2 {
+		t.Fatalf("unexpected collections info: %v", info.Collections)
+	}
+
+	// collections are loaded at startup
+	if reloaded, err := New(testFolder, "", ""); err != nil {
+		t.Fatal(err)
+	} else if reloaded.NumCollections() != 1 {
+		t.Fatalf("expected 1 collection, got %d", reloaded.NumCollections())
+	}
+
+	if resp, err := svc.DropCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if resp, err := svc.DropCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response for dropped collection")
+	} else if _, err := os.Stat(filepath.Join(testFolder, collectionsFolderName, "images")); !os.IsNotExist(err) {
+		t.Fatal("expected collection folder to be removed")
+	} else if svc.NumCollections() != 0 {
+		t.Fatalf("expected no collections, got %d", svc.NumCollections())
+	}
+}
+
+func TestServiceRunOnCollection(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if resp, _ := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "small"}); !resp.Success {
+		t.Fatal(resp.Msg)
+	} else if resp, _ := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1}, Collection: "small"}); !resp.Success {
+		t.Fatal(resp.Msg)
+	}
+
+	oracle := pb.Oracle{Name: "count", Code: "function count(){ return records.All().length; }"}
+	resp, err := svc.CreateOracle(context.TODO(), &oracle)
+	if err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatal(resp.Msg)
+	}
+
+	oracleID, err := strconv.ParseUint(resp.Msg, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"": "5", "small": "1"}
+	for collection, count := range expected {
+		if resp, err := svc.Run(context.TODO(), &pb.Call{OracleId: oracleID, Collection: collection}); err != nil {
+			t.Fatal(err)
+		} else if !resp.Success {
+			t.Fatal(resp.Msg)
+		} else if data := decompress(t, resp.Data); data != count {
+			t.Fatalf("expected %s records in collection '%s', got %s", count, collection, data)
+		}
+	}
+
+	if resp, err := svc.Run(context.TODO(), &pb.Call{OracleId: oracleID, Collection: "nope"}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response for unknown collection")
+	}
+}
+
+func TestServiceDropCollectionInUse(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp, err := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	} else if resp, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 2, 3}, Collection: "images"}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	}
+
+	records, release, err := svc.collection("images")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dropped := make(chan *pb.CollectionResponse)
+	go func() {
+		resp, _ := svc.DropCollection(context.TODO(), &pb.ByName{Name: "images"})
+		dropped <- resp
+	}()
+
+	// the records are not closed while in use
+	select {
+	case resp := <-dropped:
+		t.Fatalf("the collection has been dropped while in use: %v", resp)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if rec := records.Find(1); rec == nil || rec.Data[2] != 3 {
+		t.Fatalf("unexpected record %v", rec)
+	}
+
+	// while the other collections can still be used
+	done := make(chan *pb.CollectionResponse)
+	go func() {
+		resp, _ := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "other"})
+		done <- resp
+	}()
+	select {
+	case resp := <-done:
+		if !resp.Success {
+			t.Fatalf("expected success response: %v", resp)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the service is locked while dropping a collection")
+	}
+
+	if resp, _ := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "images"}); resp.Success {
+		t.Fatal("a collection being dropped should not be created again")
+	}
+
+	release()
+	if resp := <-dropped; !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if _, _, err := svc.collection("images"); err == nil {
+		t.Fatal("the collection should have been dropped")
+	}
+}
+
+func TestServiceDropCollectionArena(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	storage.EnableArena(true)
+	defer storage.EnableArena(false)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp, err := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	} else if resp, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 2, 3}, Collection: "images"}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	}
+
+	read, err := svc.ReadRecord(context.TODO(), &pb.ById{Id: 1, Collection: "images"})
+	if err != nil || !read.Success {
+		t.Fatalf("unexpected response: %v %v", read, err)
+	}
+	list, err := svc.ListRecords(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 10, Collection: "images"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// responses are still marshaled after the arena is unmapped
+	if resp, err := svc.DropCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	} else if read.Record.Data[2] != 3 || list.Records[0].Data[2] != 3 {
+		t.Fatalf("unexpected records %v %v", read.Record, list.Records)
+	}
+}
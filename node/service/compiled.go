@@ -7,7 +7,6 @@ import (
 	"sync"
 
 	pb "github.com/evilsocket/sum/proto"
-	"github.com/evilsocket/sum/node/storage"
 	"github.com/evilsocket/sum/node/wrapper"
 
 	"github.com/robertkrimen/otto"
@@ -41,7 +40,7 @@ func dontPanic(err *error) {
 	}
 }
 
-func (c *compiled) Run(records *storage.Records, args []string) (ctx *wrapper.Context, raw []byte, err error) {
+func (c *compiled) Run(records wrapper.Records, args []string) (ctx *wrapper.Context, raw []byte, err error) {
 	ret, err := func() (v otto.Value, err error) {
 		defer dontPanic(&err)
 		var anyValue interface{}
@@ -60,7 +59,7 @@ func (c *compiled) Run(records *storage.Records, args []string) (ctx *wrapper.Co
 		vm := c.pool.Get()
 		defer vm.Release()
 		// define context and globals
-		vm.Set("records", wrapper.WrapRecords(records))
+		vm.Set("records", records)
 		vm.Set("ctx", ctx)
 		// define the arguments
 		for argIdx := 0; argIdx < c.argc; argIdx++ {
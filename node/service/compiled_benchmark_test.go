@@ -3,6 +3,7 @@ package service
 import (
 	"bytes"
 	"github.com/evilsocket/sum/node/storage"
+	"github.com/evilsocket/sum/node/wrapper"
 	"math/rand"
 	"testing"
 
@@ -101,7 +102,7 @@ func benchVM(b *testing.B, fname, code string, args []string, expected string, r
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		if ctx, ret, err := compiled.Run(records, args); err != nil {
+		if ctx, ret, err := compiled.Run(wrapper.WrapRecords(records), args); err != nil {
 			b.Fatal(err)
 		} else if ctx.IsError() {
 			b.Fatal(ctx.Message())
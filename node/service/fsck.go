@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/evilsocket/sum/node/storage"
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/evilsocket/islazy/log"
+	"golang.org/x/net/context"
+)
+
+func errCheckResponse(format string, args ...interface{}) *pb.CheckResponse {
+	return &pb.CheckResponse{Success: false, Msg: fmt.Sprintf(format, args...)}
+}
+
+func indexEntries(entries []storage.MetaIndexEntry) []*pb.IndexEntry {
+	list := make([]*pb.IndexEntry, len(entries))
+	for i, e := range entries {
+		list[i] = &pb.IndexEntry{Index: e.Index, Meta: e.Meta, Value: e.Value, Id: e.Id}
+	}
+	return list
+}
+
+// CheckIndexes compares the meta data indexes of a collection with its
+// records and, if requested, rebuilds them. The response reports the
+// discrepancies found before any rebuild.
+func (s *Service) CheckIndexes(ctx context.Context, arg *pb.CheckRequest) (*pb.CheckResponse, error) {
+	records, release, err := s.collection(arg.Collection)
+	if err != nil {
+		return errCheckResponse("%s", err), nil
+	}
+	defer release()
+
+	var report storage.MetaIndexReport
+	if arg.Rebuild {
+		report = records.RebuildMetaIndex()
+		log.Info("rebuilt the indexes of collection '%s': %s", arg.Collection, report)
+	} else {
+		report = records.CheckMetaIndex()
+	}
+
+	if !report.Consistent() {
+		log.Warning("the indexes of collection '%s' are inconsistent: %s", arg.Collection, report)
+	}
+
+	return &pb.CheckResponse{
+		Success:    true,
+		Msg:        report.String(),
+		Records:    uint64(report.Records),
+		Entries:    uint64(report.Entries),
+		Stale:      indexEntries(report.Stale),
+		Missing:    indexEntries(report.Missing),
+		Duplicated: indexEntries(report.Duplicated),
+	}, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func TestServiceCheckIndexes(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if resp, _ := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "tagged"}); !resp.Success {
+		t.Fatal(resp.Msg)
+	}
+
+	for _, key := range []string{"a", "b"} {
+		record := &pb.Record{Data: []float32{1}, Key: key, Meta: map[string]string{"label": key}, Collection: "tagged"}
+		if resp, _ := svc.CreateRecord(context.TODO(), record); !resp.Success {
+			t.Fatal(resp.Msg)
+		}
+	}
+
+	for _, rebuild := range []bool{false, true} {
+		if resp, err := svc.CheckIndexes(context.TODO(), &pb.CheckRequest{Collection: "tagged", Rebuild: rebuild}); err != nil {
+			t.Fatal(err)
+		} else if !resp.Success {
+			t.Fatal(resp.Msg)
+		} else if resp.Records != 2 || resp.Entries != 6 {
+			t.Fatalf("unexpected response %v", resp)
+		} else if len(resp.Stale) != 0 || len(resp.Missing) != 0 || len(resp.Duplicated) != 0 {
+			t.Fatalf("unexpected inconsistencies %v", resp)
+		}
+	}
+
+	if resp, _ := svc.ReadRecordByKey(context.TODO(), &pb.ByKey{Key: "b", Collection: "tagged"}); !resp.Success {
+		t.Fatalf("the keys should be rebuilt: %s", resp.Msg)
+	}
+
+	if resp, err := svc.CheckIndexes(context.TODO(), &pb.CheckRequest{Collection: "nope"}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response for unknown collection")
+	}
+}
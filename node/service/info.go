@@ -6,13 +6,14 @@ import (
 	"time"
 
 	"github.com/evilsocket/sum/node/backend"
+	"github.com/evilsocket/sum/node/storage"
 
 	pb "github.com/evilsocket/sum/proto"
 )
 
 // Info returns a *pb.ServerInfo object with various realtime information
 // about the service and its runtime.
-func Info(datapath, credspath, address string, started time.Time, records, oracles int, nextRecordId uint64) *pb.ServerInfo {
+func Info(datapath, credspath, address string, started time.Time, records, oracles int, nextRecordId uint64, cache storage.CacheStats) *pb.ServerInfo {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 
@@ -40,5 +41,11 @@ func Info(datapath, credspath, address string, started time.Time, records, oracl
 		Credspath:    credspath,
 		Address:      address,
 		NextRecordId: nextRecordId,
+
+		CacheBudget:    cache.Budget,
+		CacheUsed:      cache.Used,
+		CacheHits:      cache.Hits,
+		CacheMisses:    cache.Misses,
+		CacheEvictions: cache.Evictions,
 	}
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"github.com/evilsocket/sum/node/storage"
+	"strconv"
 	"testing"
 
 	pb "github.com/evilsocket/sum/proto"
@@ -340,3 +341,46 @@ func TestServiceDeleteOracleWithInvalidId(t *testing.T) {
 		t.Fatalf("unexpected message: %s", resp.Msg)
 	}
 }
+
+func TestServiceRunTensorOracle(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weights, _ := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 2, 3, 4, 5, 6}, Shape: []uint64{2, 3}})
+	input, _ := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 0, -1}})
+	if !weights.Success || !input.Success {
+		t.Fatalf("could not create records: %s %s", weights.Msg, input.Msg)
+	}
+
+	code := "function apply(w, x){ var m = records.Find(w); " +
+		"return [m.MatVec(records.Find(x)).Data(), m.At(1, 2), m.Transpose().Sum(0).Data()]; }"
+	resp, err := svc.CreateOracle(context.TODO(), &pb.Oracle{Name: "apply", Code: code})
+	if err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatal(resp.Msg)
+	}
+
+	oracleID, _ := strconv.ParseUint(resp.Msg, 10, 64)
+	call := &pb.Call{OracleId: oracleID, Args: []string{weights.Msg, input.Msg}}
+	if resp, err := svc.Run(context.TODO(), call); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatal(resp.Msg)
+	} else if data := decompress(t, resp.Data); data != "[[-2,-2],6,[6,15]]" {
+		t.Fatalf("unexpected result %s", data)
+	}
+
+	// invalid operations are reported as errors of the oracle
+	call.Args = []string{weights.Msg, weights.Msg}
+	if resp, err := svc.Run(context.TODO(), call); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error for incompatible shapes")
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/evilsocket/islazy/log"
+)
+
+// Reap deletes the expired records of every collection, purges the
+// ones that have been in the trash for too long and returns how many
+// expired records were deleted.
+func (s *Service) Reap() int {
+	reaped := s.records.Reap()
+	purged := s.records.ReapTrash()
+
+	s.RLock()
+	for _, records := range s.collections {
+		reaped += records.Reap()
+		purged += records.ReapTrash()
+	}
+	s.RUnlock()
+
+	if reaped > 0 {
+		log.Debug("reaped %d expired records", reaped)
+	}
+	if purged > 0 {
+		log.Debug("purged %d records from the trash", purged)
+	}
+	return reaped
+}
+
+// Reaper periodically deletes the expired records of the service
+// and purges its trash.
+func Reaper(ctx context.Context, s *Service, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Reap()
+		}
+	}
+}
@@ -4,19 +4,56 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/evilsocket/sum/node/storage"
 	pb "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 )
 
+// returns a copy of the record as sent to clients, responses are marshaled
+// after the collection is released so they must not point into its vector
+// arena. Quantized vectors are dequantized unless the client asked for them
+// as stored.
+func readableRecord(record *pb.Record, quantized bool) *pb.Record {
+	readable := proto.Clone(record).(*pb.Record)
+	if record.Quantized != nil && !quantized {
+		readable.Data = storage.DenseData(record)
+		readable.Quantized = nil
+	}
+	return readable
+}
+
+func readableRecords(records []*pb.Record, quantized bool) []*pb.Record {
+	readable := make([]*pb.Record, len(records))
+	for i, record := range records {
+		readable[i] = readableRecord(record, quantized)
+	}
+	return readable
+}
+
 func errRecordResponse(format string, args ...interface{}) *pb.RecordResponse {
 	return &pb.RecordResponse{Success: false, Msg: fmt.Sprintf(format, args...)}
 }
 
+// a conflict response carries the current version of the stored record
+func errConflictResponse(stored *pb.Record) *pb.RecordResponse {
+	resp := errRecordResponse("%s", storage.ErrVersionConflict)
+	if stored != nil {
+		resp.Record = &pb.Record{
+			Id:        stored.Id,
+			Version:   stored.Version,
+			UpdatedAt: stored.UpdatedAt,
+		}
+	}
+	return resp
+}
+
 func errFindResponse(format string, args ...interface{}) *pb.FindResponse {
 	return &pb.FindResponse{Success: false, Msg: fmt.Sprintf(format, args...)}
 }
 
-// NumRecords returns the number of records currently loaded by the service.
+// NumRecords returns the number of records currently loaded by the service
+// in the default collection.
 func (s *Service) NumRecords() int {
 	return s.records.Size()
 }
@@ -24,21 +61,49 @@ func (s *Service) NumRecords() int {
 // CreateRecord creates and stores a new *pb.Record object. If successful, the
 // identifier of the record is returned as the response message.
 func (s *Service) CreateRecord(ctx context.Context, record *pb.Record) (*pb.RecordResponse, error) {
-	if err := s.records.Create(record); err != nil {
+	records, release, err := s.collection(record.Collection)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	defer release()
+
+	if err := records.Create(record); err != nil {
 		return errRecordResponse("%s", err), nil
 	}
 	return &pb.RecordResponse{Success: true, Msg: fmt.Sprintf("%d", record.Id)}, nil
 }
 
 func (s *Service) CreateRecordWithId(ctx context.Context, record *pb.Record) (*pb.RecordResponse, error) {
-	if err := s.records.CreateWithId(record); err != nil {
+	records, release, err := s.collection(record.Collection)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	defer release()
+
+	if err := records.CreateWithId(record); err != nil {
 		return errRecordResponse("%s", err), nil
 	}
 	return &pb.RecordResponse{Success: true, Msg: fmt.Sprintf("%d", record.Id)}, nil
 }
 
-func (s *Service) CreateRecordsWithId(ctx context.Context, records *pb.Records) (*pb.RecordResponse, error) {
-	if err := s.records.CreateManyWIthId(records.Records); err != nil {
+func (s *Service) CreateRecordsWithId(ctx context.Context, arg *pb.Records) (*pb.RecordResponse, error) {
+	records, release, err := s.collection(arg.Collection)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	defer release()
+
+	for _, record := range arg.Records {
+		record.Collection = arg.Collection
+	}
+
+	if arg.Transfer {
+		err = records.TransferIn(arg.Records)
+	} else {
+		err = records.CreateManyWIthId(arg.Records)
+	}
+
+	if err != nil {
 		return errRecordResponse("%v", err), nil
 	}
 	return &pb.RecordResponse{Success: true}, nil
@@ -46,25 +111,89 @@ func (s *Service) CreateRecordsWithId(ctx context.Context, records *pb.Records)
 
 // UpdateRecord updates the contents of a record with the ones of a raw *pb.Record
 // object given its identifier.
+// If the record has a version, it must match the one of the stored record,
+// if successful the new version of the record is returned as the response message.
 func (s *Service) UpdateRecord(ctx context.Context, record *pb.Record) (*pb.RecordResponse, error) {
-	if err := s.records.Update(record); err != nil {
+	records, release, err := s.collection(record.Collection)
+	if err != nil {
 		return errRecordResponse("%s", err), nil
 	}
-	return &pb.RecordResponse{Success: true}, nil
+	defer release()
+
+	if err := records.Update(record); err == storage.ErrVersionConflict {
+		return errConflictResponse(records.Find(record.Id)), nil
+	} else if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+
+	version := uint64(0)
+	if stored := records.Find(record.Id); stored != nil {
+		version = stored.Version
+	}
+	return &pb.RecordResponse{Success: true, Msg: fmt.Sprintf("%d", version)}, nil
 }
 
 // ReadRecord returns a raw *pb.Record object given its identifier.
 func (s *Service) ReadRecord(ctx context.Context, query *pb.ById) (*pb.RecordResponse, error) {
-	record := s.records.Find(query.Id)
+	records, release, err := s.collection(query.Collection)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	defer release()
+
+	record := records.Find(query.Id)
 	if record == nil {
 		return errRecordResponse("record %d not found.", query.Id), nil
 	}
-	return &pb.RecordResponse{Success: true, Record: record}, nil
+	return &pb.RecordResponse{Success: true, Record: readableRecord(record, query.Quantized)}, nil
+}
+
+// ReadRecordByKey returns a raw *pb.Record object given its unique key.
+func (s *Service) ReadRecordByKey(ctx context.Context, query *pb.ByKey) (*pb.RecordResponse, error) {
+	records, release, err := s.collection(query.Collection)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	defer release()
+
+	record := records.FindByKey(query.Key)
+	if record == nil {
+		return errRecordResponse("record with key '%s' not found.", query.Key), nil
+	}
+	return &pb.RecordResponse{Success: true, Record: readableRecord(record, query.Quantized)}, nil
+}
+
+// UpsertRecord updates the record with the same key of a raw *pb.Record object,
+// or creates it if no record has that key. If successful, the identifier of the
+// record is returned as the response message.
+func (s *Service) UpsertRecord(ctx context.Context, record *pb.Record) (*pb.RecordResponse, error) {
+	records, release, err := s.collection(record.Collection)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	defer release()
+
+	if _, err := records.Upsert(record); err == storage.ErrVersionConflict {
+		return errConflictResponse(records.Find(record.Id)), nil
+	} else if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	return &pb.RecordResponse{Success: true, Msg: fmt.Sprintf("%d", record.Id)}, nil
 }
 
 // ListRecords returns list of records given a ListRequest object.
 func (s *Service) ListRecords(ctx context.Context, list *pb.ListRequest) (*pb.RecordListResponse, error) {
-	all := s.records.Objects()
+	records, release, err := s.collection(list.Collection)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return listPage(records.Objects(), list), nil
+}
+
+// returns a page of records sorted by identifier
+func listPage(all []proto.Message, list *pb.ListRequest) *pb.RecordListResponse {
 	total := uint64(len(all))
 
 	if list.Page < 1 {
@@ -84,7 +213,7 @@ func (s *Service) ListRecords(ctx context.Context, list *pb.ListRequest) (*pb.Re
 
 	// out of range
 	if total <= start {
-		return &pb.RecordListResponse{Total: total, Pages: npages}, nil
+		return &pb.RecordListResponse{Total: total, Pages: npages}
 	}
 
 	resp := pb.RecordListResponse{
@@ -101,37 +230,75 @@ func (s *Service) ListRecords(ctx context.Context, list *pb.ListRequest) (*pb.Re
 	if total <= end {
 		// partially filled page
 		for _, m := range all[start:] {
-			resp.Records = append(resp.Records, m.(*pb.Record))
+			resp.Records = append(resp.Records, readableRecord(m.(*pb.Record), list.Quantized))
 		}
 	} else {
 		// full page
 		for _, m := range all[start:end] {
-			resp.Records = append(resp.Records, m.(*pb.Record))
+			resp.Records = append(resp.Records, readableRecord(m.(*pb.Record), list.Quantized))
 		}
 	}
 
-	return &resp, nil
+	return &resp
 }
 
 // DeleteRecord removes a record from the storage given its identifier.
+// If a version is specified, it must match the one of the stored record.
 func (s *Service) DeleteRecord(ctx context.Context, query *pb.ById) (*pb.RecordResponse, error) {
-	record := s.records.Delete(query.Id)
-	if record == nil {
+	records, release, err := s.collection(query.Collection)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	defer release()
+
+	record, err := records.DeleteVersion(query.Id, query.Version)
+	if err == storage.ErrVersionConflict {
+		return errConflictResponse(record), nil
+	} else if record == nil {
 		return errRecordResponse("record %d not found.", query.Id), nil
 	}
 	return &pb.RecordResponse{Success: true}, nil
 }
 
 func (s *Service) DeleteRecords(ctx context.Context, ids *pb.RecordIds) (*pb.RecordResponse, error) {
-	s.records.DeleteMany(ids.Ids)
+	records, release, err := s.collection(ids.Collection)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	defer release()
+
+	if ids.Transfer {
+		records.TransferOut(ids.Ids)
+	} else {
+		records.DeleteMany(ids.Ids)
+	}
 	return &pb.RecordResponse{Success: true}, nil
 }
 
 // FindRecords returns a FindResponse object corresponding to the records that matched the search criteria.
 func (s *Service) FindRecords(ctx context.Context, query *pb.ByMeta) (*pb.FindResponse, error) {
-	records := s.records.FindBy(query.Meta, query.Value)
-	if records == nil {
+	records, release, err := s.collection(query.Collection)
+	if err != nil {
+		return errFindResponse("%s", err), nil
+	}
+	defer release()
+
+	if query.Query != nil || len(query.Filters) > 0 {
+		var found []*pb.Record
+		if query.Query != nil {
+			found, err = records.Query(query.Query)
+		} else {
+			found, err = records.FindWhere(query.Filters)
+		}
+		if err != nil {
+			return errFindResponse("%s", err), nil
+		}
+		return &pb.FindResponse{Success: true, Records: readableRecords(found, query.Quantized)}, nil
+	}
+
+	found := records.FindBy(query.Meta, query.Value)
+	if found == nil {
 		return errFindResponse("meta %s not indexed.", query.Meta), nil
 	}
-	return &pb.FindResponse{Success: true, Records: records}, nil
+	return &pb.FindResponse{Success: true, Records: readableRecords(found, query.Quantized)}, nil
 }
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"github.com/evilsocket/sum/node/storage"
+	"strconv"
 	"testing"
+	"time"
 
 	pb "github.com/evilsocket/sum/proto"
 )
@@ -362,3 +364,231 @@ func TestService_DeleteRecords(t *testing.T) {
 		t.Fatalf("Unexpected amount of records: expected %d, got %d", 0, svc.NumRecords())
 	}
 }
+
+func TestServiceUpdateRecordConflict(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := pb.Record{Id: 1, Data: []float32{1, 2, 3}, Version: 1}
+	if resp, err := svc.UpdateRecord(context.TODO(), &update); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if resp.Msg != "2" {
+		t.Fatalf("unexpected response message: %s", resp.Msg)
+	}
+
+	// same expected version, somebody else updated it already
+	if resp, err := svc.UpdateRecord(context.TODO(), &update); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response")
+	} else if resp.Msg != storage.ErrVersionConflict.Error() {
+		t.Fatalf("unexpected response message: %s", resp.Msg)
+	} else if resp.Record == nil || resp.Record.Version != 2 {
+		t.Fatalf("expected current version in response: %v", resp.Record)
+	} else if resp.Record.Data != nil {
+		t.Fatal("conflict response should not carry the record data")
+	}
+}
+
+func TestServiceDeleteRecordConflict(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp, err := svc.DeleteRecord(context.TODO(), &pb.ById{Id: 1, Version: 2}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response")
+	} else if resp.Msg != storage.ErrVersionConflict.Error() {
+		t.Fatalf("unexpected response message: %s", resp.Msg)
+	} else if svc.NumRecords() != testRecords {
+		t.Fatal("record should not be deleted")
+	} else if resp, err := svc.DeleteRecord(context.TODO(), &pb.ById{Id: 1, Version: 1}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if svc.NumRecords() != testRecords-1 {
+		t.Fatal("record should be deleted")
+	}
+}
+
+func TestServiceExpiredRecords(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired := pb.Record{
+		Data:      []float32{1, 2, 3},
+		Meta:      map[string]string{"666": "666"},
+		ExpiresAt: time.Now().Add(-time.Second).UnixNano(),
+	}
+	resp, err := svc.CreateRecord(context.TODO(), &expired)
+	if err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	}
+
+	id, err := strconv.ParseUint(resp.Msg, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp, err := svc.ReadRecord(context.TODO(), &pb.ById{Id: id}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expired record should not be readable")
+	} else if resp, err := svc.ListRecords(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 100}); err != nil {
+		t.Fatal(err)
+	} else if resp.Total != testRecords {
+		t.Fatalf("expected %d total records, got %d", testRecords, resp.Total)
+	} else if resp, err := svc.FindRecords(context.TODO(), &pb.ByMeta{Meta: "666", Value: "666"}); err != nil {
+		t.Fatal(err)
+	} else if len(resp.Records) != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, len(resp.Records))
+	}
+
+	if reaped := svc.Reap(); reaped != 1 {
+		t.Fatalf("expected 1 reaped record, got %d", reaped)
+	} else if svc.NumRecords() != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, svc.NumRecords())
+	}
+}
+
+func TestServiceFindRecordsWithFilters(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, score := range []float64{0.2, 0.9} {
+		record := pb.Record{
+			Data:      []float32{1, 2, 3},
+			TypedMeta: map[string]*pb.MetaValue{"score": {Value: &pb.MetaValue_Number{Number: score}}},
+		}
+		if resp, err := svc.CreateRecord(context.TODO(), &record); err != nil {
+			t.Fatal(err)
+		} else if !resp.Success {
+			t.Fatalf("expected success response: %v", resp)
+		}
+	}
+
+	query := pb.ByMeta{
+		Filters: []*pb.MetaFilter{{
+			Meta:  "score",
+			Op:    pb.MetaFilter_GT,
+			Value: &pb.MetaValue{Value: &pb.MetaValue_Number{Number: 0.8}},
+		}},
+	}
+
+	if resp, err := svc.FindRecords(context.TODO(), &query); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if len(resp.Records) != 1 || resp.Records[0].Id != testRecords+2 {
+		t.Fatalf("unexpected records: %v", resp.Records)
+	}
+
+	query.Filters[0].Op = pb.MetaFilter_PREFIX
+	if resp, err := svc.FindRecords(context.TODO(), &query); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response")
+	}
+}
+
+func TestServiceFindRecordsWithQuery(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every record but the first one
+	query := pb.ByMeta{
+		Query: &pb.MetaQuery{
+			Op: pb.MetaQuery_NOT,
+			Operands: []*pb.MetaQuery{{
+				Op: pb.MetaQuery_FILTER,
+				Filter: &pb.MetaFilter{
+					Meta:  "666",
+					Op:    pb.MetaFilter_LT,
+					Value: &pb.MetaValue{Value: &pb.MetaValue_Text{Text: "0"}},
+				},
+			}},
+		},
+	}
+
+	if resp, err := svc.FindRecords(context.TODO(), &query); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if len(resp.Records) != testRecords {
+		t.Fatalf("unexpected records: %v", resp.Records)
+	}
+
+	query.Query.Operands = nil
+	if resp, err := svc.FindRecords(context.TODO(), &query); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response")
+	}
+}
+
+func TestServiceRecordKeys(t *testing.T) {
+	setupFolders(t)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp, err := svc.UpsertRecord(context.TODO(), &pb.Record{Data: []float32{1}}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error for upsert without a key")
+	} else if resp, err := svc.UpsertRecord(context.TODO(), &pb.Record{Data: []float32{1}, Key: "k"}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success || resp.Msg != "1" {
+		t.Fatalf("unexpected response %v", resp)
+	} else if resp, err := svc.UpsertRecord(context.TODO(), &pb.Record{Data: []float32{2}, Key: "k"}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success || resp.Msg != "1" {
+		t.Fatalf("unexpected response %v", resp)
+	} else if resp, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{3}, Key: "k"}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error for duplicated key")
+	} else if resp, err := svc.ReadRecordByKey(context.TODO(), &pb.ByKey{Key: "k"}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success || resp.Record.Id != 1 || resp.Record.Data[0] != 2 {
+		t.Fatalf("unexpected response %v", resp)
+	} else if resp, err := svc.ReadRecordByKey(context.TODO(), &pb.ByKey{Key: "nope"}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error for unknown key")
+	} else if resp.Msg != "record with key 'nope' not found." {
+		t.Fatalf("unexpected message: %s", resp.Msg)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"path/filepath"
+
+	"github.com/evilsocket/sum/node/storage"
+	pb "github.com/evilsocket/sum/proto"
+
+	"golang.org/x/net/context"
+)
+
+// the schema of a collection is stored next to its folder
+func schemaFileOf(path string) string {
+	return path + storage.SchemaFileExt
+}
+
+// loads the records from a folder and the schema they must match
+func loadRecords(path string) (*storage.Records, error) {
+	records, err := storage.LoadRecords(path)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := storage.LoadSchema(schemaFileOf(path))
+	if err != nil {
+		return nil, err
+	} else if err = records.SetSchema(schema); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// returns the folder of a collection given its name, or the folder
+// of the default collection if the name is empty.
+func (s *Service) collectionPath(name string) string {
+	if name == "" {
+		return filepath.Join(s.datapath, dataFolderName)
+	}
+	return filepath.Join(s.datapath, collectionsFolderName, name)
+}
+
+// SetSchema sets the schema the records of a collection must match,
+// an empty schema removes any constraint.
+func (s *Service) SetSchema(ctx context.Context, arg *pb.Collection) (*pb.CollectionResponse, error) {
+	records, release, err := s.collection(arg.Name)
+	if err != nil {
+		return errCollectionResponse("%s", err), nil
+	}
+	defer release()
+
+	if err = records.SetSchema(arg.Schema); err != nil {
+		return errCollectionResponse("%s", err), nil
+	} else if err = storage.SaveSchema(schemaFileOf(s.collectionPath(arg.Name)), records.Schema()); err != nil {
+		return errCollectionResponse("%s", err), nil
+	}
+
+	return &pb.CollectionResponse{
+		Success: true,
+		Collections: []*pb.Collection{{
+			Name:         arg.Name,
+			Records:      uint64(records.Size()),
+			NextRecordId: records.GetNextId(),
+			Schema:       records.Schema(),
+		}},
+	}, nil
+}
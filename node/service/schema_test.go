@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func TestServiceSetSchema(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the test records have 3 elements
+	if resp, err := svc.SetSchema(context.TODO(), &pb.Collection{Schema: &pb.Schema{Dimension: 4}}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response for existing records not matching the schema")
+	} else if resp, err := svc.SetSchema(context.TODO(), &pb.Collection{Name: "nope", Schema: &pb.Schema{Dimension: 3}}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response for unknown collection")
+	} else if resp, err := svc.SetSchema(context.TODO(), &pb.Collection{Schema: &pb.Schema{Dimension: 3}}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	}
+
+	if resp, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 2}}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response for record not matching the schema")
+	} else if resp, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 2, 3}}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	}
+
+	// named collections have their own schema
+	if resp, err := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if resp, err := svc.SetSchema(context.TODO(), &pb.Collection{Name: "images", Schema: &pb.Schema{Shape: []uint64{2, 2}}}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if resp, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 2, 3}, Collection: "images"}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response for record not matching the schema")
+	}
+
+	// schemas are loaded at startup
+	svc, err = New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := svc.Info(context.TODO(), &pb.Empty{}); err != nil {
+		t.Fatal(err)
+	} else if info.Schema.GetDimension() != 3 {
+		t.Fatalf("unexpected schema %v", info.Schema)
+	} else if len(info.Collections) != 1 || len(info.Collections[0].Schema.GetShape()) != 2 {
+		t.Fatalf("unexpected collections %v", info.Collections)
+	}
+
+	// dropping a collection removes its schema too
+	if resp, err := svc.DropCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if _, err := os.Stat(filepath.Join(testFolder, collectionsFolderName, "images"+".schema")); !os.IsNotExist(err) {
+		t.Fatal("expected schema file to be removed")
+	}
+
+	// and an empty schema removes every constraint
+	if resp, err := svc.SetSchema(context.TODO(), &pb.Collection{Schema: &pb.Schema{}}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if _, err := os.Stat(filepath.Join(testFolder, dataFolderName+".schema")); !os.IsNotExist(err) {
+		t.Fatal("expected schema file to be removed")
+	} else if resp, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1, 2}}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	}
+}
+
+func TestServiceQuantizedCollection(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if resp, _ := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "small"}); !resp.Success {
+		t.Fatal(resp.Msg)
+	} else if resp, _ := svc.SetSchema(context.TODO(), &pb.Collection{Name: "small", Schema: &pb.Schema{Quantization: pb.QuantizedVector_INT8}}); !resp.Success {
+		t.Fatal(resp.Msg)
+	}
+
+	for _, data := range [][]float32{{1, 2, 3}, {2, 4, 6.5}} {
+		if resp, _ := svc.CreateRecord(context.TODO(), &pb.Record{Data: data, Meta: map[string]string{"kind": "small"}, Collection: "small"}); !resp.Success {
+			t.Fatal(resp.Msg)
+		}
+	}
+
+	// records are dequantized unless the quantized vector is requested
+	if resp, _ := svc.ReadRecord(context.TODO(), &pb.ById{Id: 1, Collection: "small"}); !resp.Success {
+		t.Fatal(resp.Msg)
+	} else if data := resp.Record.Data; resp.Record.Quantized != nil || len(data) != 3 || math.Abs(float64(data[2]-3)) > 0.05 {
+		t.Fatalf("unexpected record %v", resp.Record)
+	} else if resp, _ := svc.ReadRecord(context.TODO(), &pb.ById{Id: 1, Collection: "small", Quantized: true}); !resp.Success {
+		t.Fatal(resp.Msg)
+	} else if q := resp.Record.Quantized; resp.Record.Data != nil || q == nil || q.Type != pb.QuantizedVector_INT8 || q.Size != 3 {
+		t.Fatalf("unexpected record %v", resp.Record)
+	}
+
+	if list, err := svc.ListRecords(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 10, Collection: "small"}); err != nil {
+		t.Fatal(err)
+	} else if len(list.Records) != 2 || list.Records[1].Quantized != nil || len(list.Records[1].Data) != 3 {
+		t.Fatalf("unexpected records %v", list.Records)
+	}
+
+	find := &pb.ByMeta{Meta: "kind", Value: "small", Collection: "small"}
+	if found, _ := svc.FindRecords(context.TODO(), find); !found.Success {
+		t.Fatal(found.Msg)
+	} else if len(found.Records) != 2 || found.Records[0].Quantized != nil || len(found.Records[0].Data) != 3 {
+		t.Fatalf("unexpected records %v", found.Records)
+	}
+
+	find.Quantized = true
+	if found, _ := svc.FindRecords(context.TODO(), find); !found.Success {
+		t.Fatal(found.Msg)
+	} else if len(found.Records) != 2 || found.Records[0].Quantized == nil || found.Records[0].Data != nil {
+		t.Fatalf("unexpected records %v", found.Records)
+	}
+
+	// oracles see the same records as before
+	oracle := pb.Oracle{Name: "similar", Code: "function similar(){ var all = records.All(); return all[0].Cosine(all[1]) > 0.99 && all[0].Size == 3; }"}
+	resp, _ := svc.CreateOracle(context.TODO(), &oracle)
+	if !resp.Success {
+		t.Fatal(resp.Msg)
+	}
+
+	oracleID, err := strconv.ParseUint(resp.Msg, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	} else if resp, _ := svc.Run(context.TODO(), &pb.Call{OracleId: oracleID, Collection: "small"}); !resp.Success {
+		t.Fatal(resp.Msg)
+	} else if out := decompress(t, resp.Data); out != "true" {
+		t.Fatalf("unexpected oracle output %s", out)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/evilsocket/sum/node/backend"
 	"github.com/evilsocket/sum/node/storage"
+	"github.com/evilsocket/sum/node/wrapper"
 	pb "github.com/evilsocket/sum/proto"
 
 	"github.com/evilsocket/islazy/log"
@@ -44,6 +46,22 @@ type Service struct {
 	records   *storage.Records
 	oracles   *storage.Oracles
 	cache     *compiledCache
+	loadTime  time.Duration
+	// named collections of records
+	collections map[string]*storage.Records
+	// read locked while a named collection is in use, so that it is
+	// not closed by a drop meanwhile
+	inUse map[string]*sync.RWMutex
+	// named collections being dropped, they can't be created again
+	// until their last user has released them
+	dropping map[string]bool
+	// backend of the oracles, and the one of the collections having their own
+	backend  *backend.Backend
+	backends map[string]*backend.Backend
+	// timings of the backends if the fastest one was selected at startup
+	benchmarks []*pb.BackendBenchmark
+	// changes of the records of every collection
+	feed *storage.Feed
 }
 
 // New loads records and oracles from a given path and returns
@@ -55,7 +73,14 @@ func New(dataPath string, credsPath string, address string) (svc *Service, err e
 		return nil, err
 	}
 
-	records, err := storage.LoadRecords(filepath.Join(dataPath, dataFolderName))
+	started := time.Now()
+
+	records, err := loadRecords(filepath.Join(dataPath, dataFolderName))
+	if err != nil {
+		return nil, err
+	}
+
+	collections, err := loadCollections(dataPath)
 	if err != nil {
 		return nil, err
 	}
@@ -65,6 +90,17 @@ func New(dataPath string, credsPath string, address string) (svc *Service, err e
 		return nil, err
 	}
 
+	feed := storage.NewFeed()
+	records.SetFeed(feed, "")
+	for name, coll := range collections {
+		coll.SetFeed(feed, name)
+	}
+
+	inUse := make(map[string]*sync.RWMutex)
+	for name := range collections {
+		inUse[name] = &sync.RWMutex{}
+	}
+
 	svc = &Service{
 		datapath:  dataPath,
 		credspath: credsPath,
@@ -76,11 +112,18 @@ func New(dataPath string, credsPath string, address string) (svc *Service, err e
 		records:   records,
 		oracles:   oracles,
 		cache:     newCache(),
+
+		collections: collections,
+		inUse:       inUse,
+		dropping:    make(map[string]bool),
+		backend:     backend.Default(),
+		backends:    make(map[string]*backend.Backend),
+		feed:        feed,
 	}
 
 	if oracles.Size() > 0 {
 		log.Info("precompiling %d oracles ...", oracles.Size())
-		err := oracles.ForEach(func(m proto.Message) error {
+		err := oracles.ForEachParallel(func(m proto.Message) error {
 			oracle := m.(*pb.Oracle)
 			compiled, err := compile(oracle)
 			if err != nil {
@@ -94,13 +137,32 @@ func New(dataPath string, credsPath string, address string) (svc *Service, err e
 		}
 	}
 
+	svc.loadTime = time.Since(started)
+	log.Info("loaded %d records, %d collections and %d oracles in %s", records.Size(), len(collections), oracles.Size(), svc.loadTime)
+
 	return svc, nil
 }
 
 // Info returns a *pb.ServerInfo object with various realtime information
 // about the service and its runtime.
 func (s *Service) Info(ctx context.Context, dummy *pb.Empty) (*pb.ServerInfo, error) {
-	return Info(s.datapath, s.credspath, s.address, s.started, s.records.Size(), s.oracles.Size(), s.records.GetNextId()), nil
+	info := Info(s.datapath, s.credspath, s.address, s.started, s.records.Size(), s.oracles.Size(), s.records.GetNextId(), s.records.CacheStats())
+	info.LoadTime = uint64(s.loadTime / time.Millisecond)
+	info.RecordsQuarantined = uint64(len(s.records.Quarantined()))
+	info.OraclesQuarantined = uint64(len(s.oracles.Quarantined()))
+	info.Collections = s.collectionsInfo()
+	info.Schema = s.records.Schema()
+	info.RecordsTrashed = uint64(s.records.TrashSize())
+
+	s.RLock()
+	b := s.backend
+	info.BackendBenchmarks = s.benchmarks
+	s.RUnlock()
+
+	info.Backend = b.Name()
+	info.BackendSpace = b.Space()
+	info.BackendUsed = b.Used()
+	return info, nil
 }
 
 func BuildPayload(raw []byte) *pb.Data {
@@ -131,6 +193,16 @@ func (s *Service) Run(ctx context.Context, call *pb.Call) (resp *pb.CallResponse
 		return errCallResponse("oracle %d not found.", call.OracleId), nil
 	}
 
+	// the backend is read before using the collection since a drop
+	// holds the service lock while waiting for it to be released
+	be := s.Backend(call.Collection)
+	records, release, err := s.collection(call.Collection)
+	if err != nil {
+		return errCallResponse("%s", err), nil
+	}
+	defer release()
+	wrapped := wrapper.WrapRecordsWith(be, records)
+
 	defer func() {
 		if v := recover(); v != nil {
 			err = v.(error)
@@ -141,7 +213,7 @@ func (s *Service) Run(ctx context.Context, call *pb.Call) (resp *pb.CallResponse
 
 	log.Debug("call: %+v", call)
 
-	_, raw, err := compiled.Run(s.records, call.Args)
+	_, raw, err := compiled.Run(wrapped, call.Args)
 	if err != nil {
 		return errCallResponse("error while running oracle %d: %s", call.OracleId, err), nil
 	}
@@ -236,6 +236,61 @@ func TestServiceInfo(t *testing.T) {
 		t.Fatalf("wrong number of records: %d", info.Records)
 	} else if svc.NumOracles() != int(info.Oracles) {
 		t.Fatalf("wrong number of oracles: %d", info.Oracles)
+	} else if info.CacheBudget != 0 || info.CacheUsed != 0 {
+		t.Fatalf("unexpected cache stats: %d of %d", info.CacheUsed, info.CacheBudget)
+	}
+}
+
+func TestServiceInfoWithCache(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	storage.SetCacheBudget(1024)
+	defer storage.SetCacheBudget(0)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if resp, err := svc.ReadRecord(context.TODO(), &pb.ById{Id: 1}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if !reflect.DeepEqual(resp.Record.Data, testRecord.Data) {
+		t.Fatalf("unexpected record data: %v", resp.Record.Data)
+	}
+
+	if info, err := svc.Info(context.TODO(), nil); err != nil {
+		t.Fatal(err)
+	} else if info.CacheBudget != 1024 {
+		t.Fatalf("wrong cache budget: %d", info.CacheBudget)
+	} else if info.CacheMisses != 1 {
+		t.Fatalf("wrong cache misses: %d", info.CacheMisses)
+	} else if info.CacheUsed != uint64(len(testRecord.Data)*4) {
+		t.Fatalf("wrong cache usage: %d", info.CacheUsed)
+	}
+}
+
+func TestServiceInfoWithQuarantined(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	corrupted := filepath.Join(testFolder, dataFolderName, "666.dat")
+	if err := ioutil.WriteFile(corrupted, []byte("i'm corrupted inside"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if svc, err := New(testFolder, "", ""); err != nil {
+		t.Fatal(err)
+	} else if info, err := svc.Info(context.TODO(), nil); err != nil {
+		t.Fatal(err)
+	} else if info.RecordsQuarantined != 1 {
+		t.Fatalf("wrong number of quarantined records: %d", info.RecordsQuarantined)
+	} else if info.OraclesQuarantined != 0 {
+		t.Fatalf("wrong number of quarantined oracles: %d", info.OraclesQuarantined)
+	} else if svc.loadTime <= 0 || info.LoadTime != uint64(svc.loadTime/time.Millisecond) {
+		t.Fatalf("wrong load time: %d", info.LoadTime)
+	} else if svc.NumRecords() != testRecords {
+		t.Fatalf("wrong number of records: %d", svc.NumRecords())
 	}
 }
 
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/evilsocket/sum/node/storage"
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/evilsocket/islazy/log"
+)
+
+const (
+	snapshotChunkSize = 1024 * 1024
+)
+
+func errRestoreResponse(format string, args ...interface{}) *pb.RestoreResponse {
+	return &pb.RestoreResponse{Success: false, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Snapshot writes a consistent snapshot of the records, the schemas and
+// the trash of every collection and of the oracles to a temporary file
+// and then streams it to the client in chunks.
+func (s *Service) Snapshot(_ *pb.Empty, stream pb.SumService_SnapshotServer) error {
+	tmp, err := ioutil.TempFile(s.datapath, ".snapshot.*"+storage.TmpFileExt)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	// collections can't be created or dropped while writing
+	s.RLock()
+	header, err := storage.WriteSnapshot(tmp, s.records, s.oracles, s.collections)
+	s.RUnlock()
+	if err != nil {
+		return fmt.Errorf("error while creating snapshot: %s", err)
+	} else if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	log.Info("streaming snapshot with %d records, %d oracles and %d collections ...", header.Records, header.Oracles, header.Collections)
+
+	buf := make([]byte, snapshotChunkSize)
+	for {
+		n, err := tmp.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.SnapshotChunk{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// Restore receives a snapshot from the client and, if valid, replaces
+// every collection and every oracle of this node with the ones in the
+// snapshot. Snapshots of version 1 only have the records of the default
+// collection, in which case its schema, its trash and the named
+// collections are left as they are.
+func (s *Service) Restore(stream pb.SumService_RestoreServer) error {
+	// dropped collections are closed once the service lock is released
+	drops := make(map[string]func() error)
+	defer func() {
+		for name, drop := range drops {
+			if err := drop(); err != nil {
+				log.Error("error while dropping collection %s: %s", name, err)
+			}
+		}
+	}()
+
+	// the snapshot is received, parsed and validated before locking
+	// the service, which is only held to swap the data in
+	tmp, err := ioutil.TempFile(s.datapath, ".restore.*"+storage.TmpFileExt)
+	if err != nil {
+		return stream.SendAndClose(errRestoreResponse("%s", err))
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		} else if _, err = tmp.Write(chunk.Data); err != nil {
+			return stream.SendAndClose(errRestoreResponse("%s", err))
+		}
+	}
+
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return stream.SendAndClose(errRestoreResponse("%s", err))
+	}
+
+	snap, err := storage.ReadSnapshot(tmp)
+	if err != nil {
+		return stream.SendAndClose(errRestoreResponse("%s", err))
+	}
+
+	// make sure every oracle compiles before touching anything
+	precompiled := make(map[uint64]*compiled)
+	for _, oracle := range snap.Oracles {
+		if precompiled[oracle.Id], err = compile(oracle); err != nil {
+			return stream.SendAndClose(errRestoreResponse("error while compiling oracle %d: %s", oracle.Id, err))
+		}
+	}
+
+	restored := make(map[string]*storage.CollectionSnapshot)
+	for _, coll := range snap.Collections {
+		if err := ValidCollectionName(coll.Info.Name); err != nil {
+			return stream.SendAndClose(errRestoreResponse("%s", err))
+		} else if _, found := restored[coll.Info.Name]; found {
+			return stream.SendAndClose(errRestoreResponse("collection %s is duplicated", coll.Info.Name))
+		}
+		restored[coll.Info.Name] = coll
+	}
+
+	log.Info("restoring snapshot with %d records, %d oracles and %d collections ...",
+		snap.Header.Records, snap.Header.Oracles, snap.Header.Collections)
+
+	s.Lock()
+	defer s.Unlock()
+
+	if snap.Header.Version < 2 {
+		if err := s.records.Replace(snap.Records, snap.Header.NextRecordId); err != nil {
+			return stream.SendAndClose(errRestoreResponse("error while restoring records: %s", err))
+		}
+	} else if err := s.restoreCollection("", s.records, &storage.CollectionSnapshot{
+		Info:    &pb.Collection{NextRecordId: snap.Header.NextRecordId, Schema: snap.Header.Schema},
+		Records: snap.Records,
+		Trash:   snap.Trash,
+	}); err != nil {
+		return stream.SendAndClose(errRestoreResponse("error while restoring records: %s", err))
+	}
+
+	if err := s.oracles.Replace(snap.Oracles, snap.Header.NextOracleId); err != nil {
+		return stream.SendAndClose(errRestoreResponse("error while restoring oracles: %s", err))
+	}
+
+	if snap.Header.Version >= 2 {
+		for name := range s.collections {
+			if _, found := restored[name]; !found {
+				drops[name] = s._dropCollection(name)
+			}
+		}
+
+		for name, coll := range restored {
+			records, found := s.collections[name]
+			if !found {
+				if records, err = s._createCollection(name); err != nil {
+					return stream.SendAndClose(errRestoreResponse("error while creating collection %s: %s", name, err))
+				}
+			}
+			if err := s.restoreCollection(name, records, coll); err != nil {
+				return stream.SendAndClose(errRestoreResponse("error while restoring collection %s: %s", name, err))
+			}
+		}
+	}
+
+	s.cache.Reset(precompiled)
+
+	return stream.SendAndClose(&pb.RestoreResponse{Success: true, Header: snap.Header})
+}
+
+// replaces the records, the schema and the trash of a collection
+// with the ones of a snapshot
+func (s *Service) restoreCollection(name string, records *storage.Records, coll *storage.CollectionSnapshot) error {
+	if err := records.Replace(coll.Records, coll.Info.NextRecordId); err != nil {
+		return err
+	} else if err := records.SetSchema(coll.Info.Schema); err != nil {
+		return err
+	} else if err := storage.SaveSchema(schemaFileOf(s.collectionPath(name)), records.Schema()); err != nil {
+		return err
+	}
+	return records.ReplaceTrash(coll.Trash)
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"google.golang.org/grpc"
+)
+
+type snapshotStream struct {
+	grpc.ServerStream
+	buf bytes.Buffer
+}
+
+func (s *snapshotStream) Context() context.Context {
+	return context.TODO()
+}
+
+func (s *snapshotStream) Send(chunk *pb.SnapshotChunk) error {
+	_, err := s.buf.Write(chunk.Data)
+	return err
+}
+
+type restoreStream struct {
+	grpc.ServerStream
+	data []byte
+	resp *pb.RestoreResponse
+	// if set, the first Recv signals it and then waits on it
+	wait chan struct{}
+}
+
+func (s *restoreStream) Context() context.Context {
+	return context.TODO()
+}
+
+func (s *restoreStream) Recv() (*pb.SnapshotChunk, error) {
+	if s.wait != nil {
+		s.wait <- struct{}{}
+		<-s.wait
+		s.wait = nil
+	}
+	if len(s.data) == 0 {
+		return nil, io.EOF
+	}
+	// send in small pieces to exercise reassembly
+	n := 64
+	if n > len(s.data) {
+		n = len(s.data)
+	}
+	chunk := &pb.SnapshotChunk{Data: s.data[:n]}
+	s.data = s.data[n:]
+	return chunk, nil
+}
+
+func (s *restoreStream) SendAndClose(resp *pb.RestoreResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestServiceSnapshotRestore(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap := &snapshotStream{}
+	if err := svc.Snapshot(&pb.Empty{}, snap); err != nil {
+		t.Fatal(err)
+	} else if snap.buf.Len() == 0 {
+		t.Fatal("expected snapshot data")
+	}
+
+	// change the state after the snapshot
+	if resp, err := svc.DeleteRecord(context.TODO(), &byID); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	} else if resp, err := svc.CreateRecord(context.TODO(), &updatedRecord); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	}
+
+	restore := &restoreStream{data: snap.buf.Bytes()}
+	if err := svc.Restore(restore); err != nil {
+		t.Fatal(err)
+	} else if !restore.resp.Success {
+		t.Fatalf("expected success response: %v", restore.resp)
+	} else if restore.resp.Header.Records != testRecords {
+		t.Fatalf("expected %d restored records, got %d", testRecords, restore.resp.Header.Records)
+	} else if svc.NumRecords() != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, svc.NumRecords())
+	} else if svc.NumOracles() != testOracles {
+		t.Fatalf("expected %d oracles, got %d", testOracles, svc.NumOracles())
+	} else if svc.records.Find(byID.Id) == nil {
+		t.Fatal("deleted record should have been restored")
+	}
+
+	// the oracles cache must be usable after a restore
+	if resp, err := svc.Run(context.TODO(), &testCall); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected success response: %v", resp)
+	}
+
+	// and the restored state must survive a restart
+	if reloaded, err := New(testFolder, "", ""); err != nil {
+		t.Fatal(err)
+	} else if reloaded.NumRecords() != testRecords {
+		t.Fatalf("expected %d records after reload, got %d", testRecords, reloaded.NumRecords())
+	}
+}
+
+func TestServiceRestoreInvalid(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restore := &restoreStream{data: []byte("i'm not a snapshot")}
+	if err := svc.Restore(restore); err != nil {
+		t.Fatal(err)
+	} else if restore.resp.Success {
+		t.Fatal("expected error response")
+	} else if svc.NumRecords() != testRecords {
+		t.Fatalf("records should be untouched, got %d", svc.NumRecords())
+	}
+}
+
+func TestServiceRestoreNotLockedWhileReceiving(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap := &snapshotStream{}
+	if err := svc.Snapshot(&pb.Empty{}, snap); err != nil {
+		t.Fatal(err)
+	}
+
+	wait := make(chan struct{})
+	restore := &restoreStream{data: snap.buf.Bytes(), wait: wait}
+	done := make(chan error)
+	go func() {
+		done <- svc.Restore(restore)
+	}()
+	<-wait
+
+	// the service can be used while the snapshot is being received
+	created := make(chan *pb.CollectionResponse)
+	go func() {
+		resp, _ := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "other"})
+		created <- resp
+	}()
+	select {
+	case resp := <-created:
+		if !resp.Success {
+			t.Fatalf("expected success response: %v", resp)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the service is locked while receiving a snapshot")
+	}
+
+	wait <- struct{}{}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	} else if !restore.resp.Success {
+		t.Fatalf("expected success response: %v", restore.resp)
+	} else if svc.NumCollections() != 0 {
+		t.Fatalf("collections not in the snapshot should be dropped, got %d", svc.NumCollections())
+	}
+}
+
+func TestServiceSnapshotCollections(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := &pb.Schema{Dimension: 3}
+	keyed := pb.Record{Data: []float32{1, 2, 3}, Key: "first", Collection: "images"}
+	if resp, err := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	} else if resp, err := svc.SetSchema(context.TODO(), &pb.Collection{Name: "images", Schema: schema}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	} else if resp, err := svc.CreateRecord(context.TODO(), &keyed); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	} else if resp, err := svc.DeleteRecords(context.TODO(), &pb.RecordIds{Ids: []uint64{1, 2}}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	}
+
+	snap := &snapshotStream{}
+	if err := svc.Snapshot(&pb.Empty{}, snap); err != nil {
+		t.Fatal(err)
+	}
+
+	// change the state after the snapshot
+	if resp, err := svc.DropCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	} else if resp, err := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "other"}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	} else if resp, err := svc.PurgeTrash(context.TODO(), &pb.PurgeRequest{}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	}
+
+	restore := &restoreStream{data: snap.buf.Bytes()}
+	if err := svc.Restore(restore); err != nil {
+		t.Fatal(err)
+	} else if !restore.resp.Success {
+		t.Fatalf("expected success response: %v", restore.resp)
+	}
+
+	check := func(svc *Service) {
+		t.Helper()
+		if list := svc.collectionsInfo(); len(list) != 1 || list[0].Name != "images" {
+			t.Fatalf("unexpected collections %v", list)
+		} else if list[0].Records != 1 || list[0].NextRecordId != 2 || list[0].Schema.GetDimension() != 3 {
+			t.Fatalf("unexpected collection %v", list[0])
+		} else if svc.records.TrashSize() != 2 {
+			t.Fatalf("expected 2 trashed records, got %d", svc.records.TrashSize())
+		} else if resp, err := svc.ReadRecordByKey(context.TODO(), &pb.ByKey{Key: "first", Collection: "images"}); err != nil {
+			t.Fatal(err)
+		} else if !resp.Success || resp.Record.Id != 1 {
+			t.Fatalf("unexpected response: %v", resp)
+		}
+	}
+
+	check(svc)
+	// and the restored state must survive a restart
+	if reloaded, err := New(testFolder, "", ""); err != nil {
+		t.Fatal(err)
+	} else {
+		check(reloaded)
+	}
+}
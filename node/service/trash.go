@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"golang.org/x/net/context"
+)
+
+// ListTrash returns a page of the deleted records of a collection
+// that are still in the trash.
+func (s *Service) ListTrash(ctx context.Context, list *pb.ListRequest) (*pb.RecordListResponse, error) {
+	records, release, err := s.collection(list.Collection)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return listPage(records.Trash(), list), nil
+}
+
+// Undelete moves records from the trash back to their collection, the
+// response message is the number of records restored.
+func (s *Service) Undelete(ctx context.Context, ids *pb.RecordIds) (*pb.RecordResponse, error) {
+	records, release, err := s.collection(ids.Collection)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	defer release()
+
+	restored, err := records.Undelete(ids.Ids)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	return &pb.RecordResponse{Success: true, Msg: fmt.Sprintf("%d", len(restored))}, nil
+}
+
+// PurgeTrash permanently removes deleted records from the trash, the
+// response message is the number of records purged.
+func (s *Service) PurgeTrash(ctx context.Context, arg *pb.PurgeRequest) (*pb.RecordResponse, error) {
+	records, release, err := s.collection(arg.Collection)
+	if err != nil {
+		return errRecordResponse("%s", err), nil
+	}
+	defer release()
+
+	purged := records.PurgeTrash(arg.Ids, arg.Before)
+	return &pb.RecordResponse{Success: true, Msg: fmt.Sprintf("%d", purged)}, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func TestServiceTrash(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp, err := svc.DeleteRecords(context.TODO(), &pb.RecordIds{Ids: []uint64{1, 2}}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	} else if resp, err := svc.ReadRecord(context.TODO(), &pb.ById{Id: 1}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("deleted records should not be found")
+	}
+
+	// trashed records are not visible to oracles
+	oracle := pb.Oracle{Name: "count", Code: "function count(){ return records.All().length; }"}
+	if resp, err := svc.CreateOracle(context.TODO(), &oracle); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatal(resp.Msg)
+	} else if oracleID, err := strconv.ParseUint(resp.Msg, 10, 64); err != nil {
+		t.Fatal(err)
+	} else if resp, err := svc.Run(context.TODO(), &pb.Call{OracleId: oracleID}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatal(resp.Msg)
+	} else if data := decompress(t, resp.Data); data != strconv.Itoa(testRecords-2) {
+		t.Fatalf("expected %d records, got %s", testRecords-2, data)
+	}
+
+	if list, err := svc.ListTrash(context.TODO(), &pb.ListRequest{Page: 1, PerPage: 10}); err != nil {
+		t.Fatal(err)
+	} else if list.Total != 2 || list.Records[0].Id != 1 || list.Records[1].Id != 2 {
+		t.Fatalf("unexpected trash %v", list)
+	} else if info, err := svc.Info(context.TODO(), &pb.Empty{}); err != nil {
+		t.Fatal(err)
+	} else if info.RecordsTrashed != 2 || info.Records != testRecords-2 {
+		t.Fatalf("unexpected info %d records, %d trashed", info.Records, info.RecordsTrashed)
+	}
+
+	if resp, err := svc.Undelete(context.TODO(), &pb.RecordIds{Ids: []uint64{1}}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success || resp.Msg != "1" {
+		t.Fatalf("unexpected response %v", resp)
+	} else if resp, err := svc.Undelete(context.TODO(), &pb.RecordIds{Ids: []uint64{1}}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("expected error response for record not in the trash")
+	} else if resp, err := svc.ReadRecord(context.TODO(), &pb.ById{Id: 1}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success {
+		t.Fatalf("expected restored record: %v", resp)
+	}
+
+	if resp, err := svc.PurgeTrash(context.TODO(), &pb.PurgeRequest{}); err != nil {
+		t.Fatal(err)
+	} else if !resp.Success || resp.Msg != "1" {
+		t.Fatalf("unexpected response %v", resp)
+	} else if resp, err := svc.Undelete(context.TODO(), &pb.RecordIds{Ids: []uint64{2}}); err != nil {
+		t.Fatal(err)
+	} else if resp.Success {
+		t.Fatal("purged records can not be restored")
+	}
+}
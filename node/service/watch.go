@@ -0,0 +1,57 @@
+package service
+
+import (
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// EventFilter returns a function that tells if an event is about one
+// of the given collections, or any collection if the list is empty.
+func EventFilter(collections []string) func(*pb.Event) bool {
+	if len(collections) == 0 {
+		return func(*pb.Event) bool { return true }
+	}
+
+	wanted := make(map[string]bool, len(collections))
+	for _, name := range collections {
+		wanted[name] = true
+	}
+	return func(ev *pb.Event) bool {
+		return wanted[ev.Collection]
+	}
+}
+
+// names of the collections, the default one first
+func (s *Service) collectionNames() []string {
+	names := []string{""}
+	for _, c := range s.collectionsInfo() {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// Watch streams the changes of the records, starting after the sequence
+// number of the request. If those changes are not available anymore, a
+// RESET event is sent for every collection before the new changes.
+func (s *Service) Watch(arg *pb.WatchRequest, stream pb.SumService_WatchServer) error {
+	match := EventFilter(arg.Collections)
+
+	send := func(ev *pb.Event) error {
+		if !match(ev) {
+			return nil
+		}
+		return stream.Send(ev)
+	}
+
+	reset := func(seq uint64) error {
+		for _, name := range s.collectionNames() {
+			if err := send(&pb.Event{Seq: seq, Type: pb.Event_RESET, Collection: name, Timestamp: time.Now().UnixNano()}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return s.feed.Follow(stream.Context(), arg.Since, send, reset)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"google.golang.org/grpc"
+)
+
+type watchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *pb.Event
+}
+
+func newWatchStream() (*watchStream, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &watchStream{ctx: ctx, events: make(chan *pb.Event, 128)}, cancel
+}
+
+func (s *watchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *watchStream) Send(ev *pb.Event) error {
+	s.events <- ev
+	return nil
+}
+
+func (s *watchStream) next(t *testing.T) *pb.Event {
+	t.Helper()
+	select {
+	case ev := <-s.events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not received")
+	}
+	return nil
+}
+
+func TestServiceWatch(t *testing.T) {
+	setup(t, true, true)
+	defer teardown(t)
+
+	svc, err := New(testFolder, "", "")
+	if err != nil {
+		t.Fatal(err)
+	} else if resp, err := svc.CreateCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil || !resp.Success {
+		t.Fatalf("unexpected response: %v %v", resp, err)
+	}
+
+	if _, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// only watch the named collection, starting from now
+	stream, cancel := newWatchStream()
+	since := svc.feed.Last()
+	done := make(chan error)
+	go func() {
+		done <- svc.Watch(&pb.WatchRequest{Since: since, Collections: []string{"images"}}, stream)
+	}()
+
+	if _, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1}}); err != nil {
+		t.Fatal(err)
+	} else if _, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{1}, Collection: "images"}); err != nil {
+		t.Fatal(err)
+	} else if _, err := svc.UpdateRecord(context.TODO(), &pb.Record{Id: 1, Data: []float32{2}, Collection: "images"}); err != nil {
+		t.Fatal(err)
+	} else if _, err := svc.DeleteRecord(context.TODO(), &pb.ById{Id: 1, Collection: "images"}); err != nil {
+		t.Fatal(err)
+	} else if _, err := svc.DropCollection(context.TODO(), &pb.ByName{Name: "images"}); err != nil {
+		t.Fatal(err)
+	}
+
+	last := uint64(0)
+	for _, expected := range []pb.Event_Type{pb.Event_CREATE, pb.Event_UPDATE, pb.Event_DELETE, pb.Event_RESET} {
+		ev := stream.next(t)
+		if ev.Type != expected || ev.Collection != "images" {
+			t.Fatalf("expected %s event, got %v", expected, ev)
+		} else if ev.Seq <= last {
+			t.Fatalf("sequence number %d is not after %d", ev.Seq, last)
+		}
+		last = ev.Seq
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// resuming from a sequence number ahead of the feed resets it
+	stream, cancel = newWatchStream()
+	defer cancel()
+	go svc.Watch(&pb.WatchRequest{Since: last + 1000}, stream)
+
+	if ev := stream.next(t); ev.Type != pb.Event_RESET || ev.Collection != "" {
+		t.Fatalf("expected reset of the default collection, got %v", ev)
+	}
+
+	if _, err := svc.CreateRecord(context.TODO(), &pb.Record{Data: []float32{3}}); err != nil {
+		t.Fatal(err)
+	} else if ev := stream.next(t); ev.Type != pb.Event_CREATE || ev.Record.Data[0] != 3 {
+		t.Fatalf("unexpected event %v", ev)
+	}
+}
@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+)
+
+const (
+	// ArenaFolderName is the name of the folder, inside the data path
+	// of the records, where the vector arena files are created.
+	ArenaFolderName = "arena"
+	// ArenaFileExt is the file extension of the arena chunk files.
+	ArenaFileExt = ".f32"
+	// DefaultArenaChunkSize is the default size in bytes of every
+	// memory mapped chunk of the arena.
+	DefaultArenaChunkSize = 64 * 1024 * 1024
+
+	float32Size = 4
+	// the biggest slice of float32 we can safely address
+	maxArenaElems = math.MaxInt32 / float32Size
+)
+
+var (
+	arenaLock      = sync.RWMutex{}
+	arenaEnabled   = false
+	arenaChunkSize = int64(DefaultArenaChunkSize)
+)
+
+// EnableArena enables or disables the contiguous vector arena for
+// the records loaded from now on.
+func EnableArena(enabled bool) {
+	arenaLock.Lock()
+	defer arenaLock.Unlock()
+	arenaEnabled = enabled
+}
+
+// SetArenaChunkSize sets the size in bytes of every memory mapped
+// chunk of the arena.
+func SetArenaChunkSize(size int64) {
+	arenaLock.Lock()
+	defer arenaLock.Unlock()
+	arenaChunkSize = size
+}
+
+func arenaConfig() (bool, int64) {
+	arenaLock.RLock()
+	defer arenaLock.RUnlock()
+	return arenaEnabled, arenaChunkSize
+}
+
+// a single memory mapped file holding a fixed number of vectors
+type arenaChunk struct {
+	fp   *os.File
+	mem  []byte
+	data []float32
+}
+
+// a slot released at a generation of the records
+type freedSlot struct {
+	slot       int
+	generation uint64
+}
+
+// all the vectors with the same dimension
+type arenaColumn struct {
+	dim      int
+	perChunk int
+	chunks   []*arenaChunk
+	slots    map[uint64]int
+	free     []int
+	// released slots that readers might still be using
+	pending []freedSlot
+	next    int
+}
+
+func (c *arenaColumn) view(slot int) []float32 {
+	chunk := c.chunks[slot/c.perChunk]
+	start := (slot % c.perChunk) * c.dim
+	return chunk.data[start : start+c.dim : start+c.dim]
+}
+
+// Arena keeps the vectors of the records with the same dimension in
+// contiguous float32 buffers, memory mapped from files in its folder,
+// so that scanning them has good cache locality. The arena files are
+// not a source of truth and are rebuilt from the records at each load.
+// Chunks are never remapped or unmapped while the arena is open, so
+// slices pointing into the arena remain valid, and released slots are
+// only reused once no reader pinned before their release is left.
+type Arena struct {
+	path      string
+	chunkSize int64
+	columns   map[int]*arenaColumn
+	dims      map[uint64]int
+	// number of active readers by the generation they were pinned at
+	pins    map[uint64]int
+	pinLock sync.Mutex
+}
+
+func newArena(path string, chunkSize int64) (*Arena, error) {
+	// start from a clean state, the contents are rebuilt from the records
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	} else if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Arena{
+		path:      path,
+		chunkSize: chunkSize,
+		columns:   make(map[int]*arenaColumn),
+		dims:      make(map[uint64]int),
+		pins:      make(map[uint64]int),
+	}, nil
+}
+
+// pin keeps the slots released from the given generation on from being
+// reused until the returned function is called.
+func (a *Arena) pin(generation uint64) func() {
+	a.pinLock.Lock()
+	defer a.pinLock.Unlock()
+
+	a.pins[generation]++
+	return func() {
+		a.pinLock.Lock()
+		defer a.pinLock.Unlock()
+
+		if a.pins[generation]--; a.pins[generation] <= 0 {
+			delete(a.pins, generation)
+		}
+	}
+}
+
+// oldest returns the generation of the oldest active reader, if any.
+func (a *Arena) oldest() (uint64, bool) {
+	a.pinLock.Lock()
+	defer a.pinLock.Unlock()
+
+	oldest, found := uint64(0), false
+	for generation := range a.pins {
+		if !found || generation < oldest {
+			oldest, found = generation, true
+		}
+	}
+	return oldest, found
+}
+
+// moves the released slots no active reader can be using to the free list
+func (a *Arena) reclaim(c *arenaColumn) {
+	oldest, pinned := a.oldest()
+	kept := c.pending[:0]
+	for _, freed := range c.pending {
+		if pinned && oldest <= freed.generation {
+			kept = append(kept, freed)
+		} else {
+			c.free = append(c.free, freed.slot)
+		}
+	}
+	c.pending = kept
+}
+
+func (a *Arena) newChunk(c *arenaColumn) error {
+	fileName := filepath.Join(a.path, fmt.Sprintf("%d_%d%s", c.dim, len(c.chunks), ArenaFileExt))
+	fp, err := os.OpenFile(fileName, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	size := c.perChunk * c.dim * float32Size
+	if err = fp.Truncate(int64(size)); err != nil {
+		fp.Close()
+		return err
+	}
+
+	mem, err := mapFile(fp, size)
+	if err != nil {
+		fp.Close()
+		return fmt.Errorf("error while mapping %s: %s", fileName, err)
+	}
+
+	elems := c.perChunk * c.dim
+	c.chunks = append(c.chunks, &arenaChunk{
+		fp:   fp,
+		mem:  mem,
+		data: (*[maxArenaElems]float32)(unsafe.Pointer(&mem[0]))[:elems:elems],
+	})
+	return nil
+}
+
+func (a *Arena) column(dim int) (*arenaColumn, error) {
+	if c, found := a.columns[dim]; found {
+		return c, nil
+	} else if dim > maxArenaElems {
+		return nil, fmt.Errorf("vectors of %d elements are too big for the arena", dim)
+	}
+
+	perChunk := int(a.chunkSize / int64(dim*float32Size))
+	if perChunk < 1 {
+		perChunk = 1
+	} else if perChunk*dim > maxArenaElems {
+		perChunk = maxArenaElems / dim
+	}
+
+	c := &arenaColumn{
+		dim:      dim,
+		perChunk: perChunk,
+		slots:    make(map[uint64]int),
+	}
+	a.columns[dim] = c
+	return c, nil
+}
+
+// put copies the vector of the object with the given id in a new slot
+// of the arena and returns a slice pointing to it. The previous slot of
+// the object is released at the current generation of the records, so
+// that its old vector is not overwritten while readers pinned before
+// might still be using it.
+func (a *Arena) put(id uint64, data []float32, generation uint64) ([]float32, error) {
+	dim := len(data)
+	if dim == 0 {
+		a.release(id, generation)
+		return data, nil
+	}
+
+	c, err := a.column(dim)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.free) == 0 {
+		a.reclaim(c)
+	}
+
+	slot := 0
+	if n := len(c.free); n > 0 {
+		slot = c.free[n-1]
+		c.free = c.free[:n-1]
+	} else {
+		if c.next == len(c.chunks)*c.perChunk {
+			if err := a.newChunk(c); err != nil {
+				return nil, err
+			}
+		}
+		slot = c.next
+		c.next++
+	}
+
+	view := c.view(slot)
+	copy(view, data)
+
+	a.release(id, generation)
+	c.slots[id] = slot
+	a.dims[id] = dim
+
+	return view, nil
+}
+
+// release marks the slot of the object with the given id as released
+// at the given generation of the records.
+func (a *Arena) release(id uint64, generation uint64) {
+	dim, found := a.dims[id]
+	if !found {
+		return
+	}
+
+	c := a.columns[dim]
+	c.pending = append(c.pending, freedSlot{slot: c.slots[id], generation: generation})
+	delete(c.slots, id)
+	delete(a.dims, id)
+}
+
+// reset releases every slot of the arena at the given generation without
+// unmapping its chunks, so that they can be reused.
+func (a *Arena) reset(generation uint64) {
+	for _, c := range a.columns {
+		for _, slot := range c.slots {
+			c.pending = append(c.pending, freedSlot{slot: slot, generation: generation})
+		}
+		c.slots = make(map[uint64]int)
+	}
+	a.dims = make(map[uint64]int)
+}
+
+// Size returns the number of bytes mapped by the arena.
+func (a *Arena) Size() uint64 {
+	size := uint64(0)
+	for _, c := range a.columns {
+		size += uint64(len(c.chunks) * c.perChunk * c.dim * float32Size)
+	}
+	return size
+}
+
+// Close unmaps and removes every file of the arena, slices
+// pointing into the arena must not be used after this.
+func (a *Arena) Close() error {
+	for _, c := range a.columns {
+		for _, chunk := range c.chunks {
+			if err := unmapFile(chunk.mem); err != nil {
+				return err
+			} else if err := chunk.fp.Close(); err != nil {
+				return err
+			}
+		}
+	}
+
+	a.columns = make(map[int]*arenaColumn)
+	a.dims = make(map[uint64]int)
+
+	return os.RemoveAll(a.path)
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+const benchArenaRecords = 10000
+
+func benchmarkScan(b *testing.B, withArena bool) {
+	setupRecords(b, false, false)
+	defer teardownArena(b)
+
+	EnableArena(withArena)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	data := make([]float32, 128)
+	for i := range data {
+		data[i] = float32(i)
+	}
+
+	for i := 0; i < benchArenaRecords; i++ {
+		if err := records.Create(&pb.Record{Data: append([]float32(nil), data...)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	all := records.Objects()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sum := float32(0)
+		for _, m := range all {
+			for _, v := range m.(*pb.Record).Data {
+				sum += v
+			}
+		}
+	}
+}
+
+func BenchmarkScanWithoutArena(b *testing.B) {
+	benchmarkScan(b, false)
+}
+
+func BenchmarkScanWithArena(b *testing.B) {
+	benchmarkScan(b, true)
+}
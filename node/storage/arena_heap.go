@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package storage
+
+import (
+	"os"
+)
+
+// memory mapping is not supported on this platform, so the
+// arena falls back to plain, but still contiguous, heap memory.
+func mapFile(fp *os.File, size int) ([]byte, error) {
+	return make([]byte, size), nil
+}
+
+func unmapFile(mem []byte) error {
+	return nil
+}
@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func setupArena(t testing.TB, chunkSize int64) *Records {
+	setupRecords(t, true, false)
+
+	EnableArena(true)
+	SetArenaChunkSize(chunkSize)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func teardownArena(t testing.TB) {
+	EnableArena(false)
+	SetArenaChunkSize(DefaultArenaChunkSize)
+	teardownRecords(t)
+}
+
+func isInArena(a *Arena, data []float32) bool {
+	if len(data) == 0 {
+		return false
+	}
+
+	ptr := uintptr(unsafe.Pointer(&data[0]))
+	for _, c := range a.columns {
+		for _, chunk := range c.chunks {
+			start := uintptr(unsafe.Pointer(&chunk.data[0]))
+			end := start + uintptr(len(chunk.data)*float32Size)
+			if ptr >= start && ptr < end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func released(c *arenaColumn) int {
+	return len(c.free) + len(c.pending)
+}
+
+func TestArenaLoad(t *testing.T) {
+	records := setupArena(t, DefaultArenaChunkSize)
+	defer teardownArena(t)
+
+	arena := records.Arena()
+	if arena == nil {
+		t.Fatal("expected arena to be enabled")
+	} else if _, err := os.Stat(filepath.Join(testFolder, ArenaFolderName)); err != nil {
+		t.Fatal(err)
+	}
+
+	for id := uint64(1); id <= testRecords; id++ {
+		if !isInArena(arena, records.Find(id).Data) {
+			t.Fatalf("record %d data is not in the arena", id)
+		}
+	}
+
+	EnableArena(false)
+	if disabled, err := LoadRecords(testFolder); err != nil {
+		t.Fatal(err)
+	} else if disabled.Arena() != nil {
+		t.Fatal("expected arena to be disabled")
+	}
+}
+
+func TestArenaContiguous(t *testing.T) {
+	records := setupArena(t, DefaultArenaChunkSize)
+	defer teardownArena(t)
+
+	arena := records.Arena()
+	dim := len(testRecord.Data)
+	if len(arena.columns) != 1 {
+		t.Fatalf("expected 1 column, got %d", len(arena.columns))
+	}
+
+	column := arena.columns[dim]
+	for id := uint64(1); id <= testRecords; id++ {
+		record := records.Find(id)
+		if !isInArena(arena, record.Data) {
+			t.Fatalf("record %d data is not in the arena", id)
+		} else if !reflect.DeepEqual(record.Data, testRecord.Data) {
+			t.Fatalf("unexpected data for record %d: %v", id, record.Data)
+		}
+
+		slot := column.slots[id]
+		if &record.Data[0] != &column.chunks[0].data[slot*dim] {
+			t.Fatalf("record %d is not stored in slot %d", id, slot)
+		}
+	}
+}
+
+func TestArenaUpdate(t *testing.T) {
+	records := setupArena(t, DefaultArenaChunkSize)
+	defer teardownArena(t)
+
+	arena := records.Arena()
+	before := records.Find(1).Data
+
+	// same dimension, the vector moves to a new slot and the old one
+	// is not changed while readers might still be using it
+	if err := records.Update(&pb.Record{Id: 1, Data: []float32{4, 5, 6}}); err != nil {
+		t.Fatal(err)
+	} else if after := records.Find(1).Data; &after[0] == &before[0] {
+		t.Fatal("expected the vector to move to a new slot")
+	} else if !reflect.DeepEqual(after, []float32{4, 5, 6}) {
+		t.Fatalf("unexpected data %v", after)
+	} else if !reflect.DeepEqual(before, testRecord.Data) {
+		t.Fatalf("the previous vector has been overwritten: %v", before)
+	} else if n := released(arena.columns[3]); n != 1 {
+		t.Fatalf("expected 1 released slot, got %d", n)
+	}
+
+	// different dimension, the record moves to another column
+	if err := records.Update(&updatedRecord); err != ErrRecordNotFound {
+		t.Fatalf("expected record not found error, got %v", err)
+	} else if err := records.Update(&pb.Record{Id: 1, Data: []float32{1, 2, 3, 4}}); err != nil {
+		t.Fatal(err)
+	} else if data := records.Find(1).Data; !isInArena(arena, data) {
+		t.Fatal("record data is not in the arena")
+	} else if len(arena.columns) != 2 {
+		t.Fatalf("expected 2 columns, got %d", len(arena.columns))
+	} else if n := released(arena.columns[3]); n != 2 {
+		t.Fatalf("expected 2 released slots, got %d", n)
+	}
+}
+
+func TestArenaDelete(t *testing.T) {
+	records := setupArena(t, DefaultArenaChunkSize)
+	defer teardownArena(t)
+
+	arena := records.Arena()
+	if deleted := records.Delete(1); deleted == nil {
+		t.Fatal("expected record to be deleted")
+	} else if isInArena(arena, deleted.Data) {
+		t.Fatal("deleted record should be detached from the arena")
+	} else if !reflect.DeepEqual(deleted.Data, testRecord.Data) {
+		t.Fatalf("unexpected data %v", deleted.Data)
+	}
+
+	// the free slot is reused
+	if err := records.Create(&pb.Record{Data: []float32{7, 8, 9}}); err != nil {
+		t.Fatal(err)
+	} else if column := arena.columns[3]; column.next != testRecords || len(column.free) != 0 {
+		t.Fatalf("expected the free slot to be reused: next=%d free=%d", column.next, len(column.free))
+	}
+
+	if deleted := records.DeleteMany([]uint64{2, 3}); len(deleted) != 2 {
+		t.Fatalf("expected 2 deleted records, got %d", len(deleted))
+	} else if isInArena(arena, deleted[0].Data) || isInArena(arena, deleted[1].Data) {
+		t.Fatal("deleted records should be detached from the arena")
+	}
+}
+
+func TestArenaChunks(t *testing.T) {
+	// room for 2 vectors of 3 elements per chunk
+	records := setupArena(t, 24)
+	defer teardownArena(t)
+
+	arena := records.Arena()
+	if chunks := len(arena.columns[3].chunks); chunks != 3 {
+		t.Fatalf("expected 3 chunks, got %d", chunks)
+	} else if size := arena.Size(); size != 3*24 {
+		t.Fatalf("unexpected arena size %d", size)
+	}
+
+	for id := uint64(1); id <= testRecords; id++ {
+		if record := records.Find(id); !isInArena(arena, record.Data) {
+			t.Fatalf("record %d data is not in the arena", id)
+		} else if !reflect.DeepEqual(record.Data, testRecord.Data) {
+			t.Fatalf("unexpected data for record %d: %v", id, record.Data)
+		}
+	}
+}
+
+func TestArenaPersistence(t *testing.T) {
+	records := setupArena(t, DefaultArenaChunkSize)
+	defer teardownArena(t)
+
+	if err := records.Update(&pb.Record{Id: 1, Data: []float32{4, 5, 6}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// arena files are rebuilt from the records on load
+	reloaded, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	} else if reloaded.Size() != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, reloaded.Size())
+	} else if data := reloaded.Find(1).Data; !reflect.DeepEqual(data, []float32{4, 5, 6}) {
+		t.Fatalf("unexpected data %v", data)
+	} else if !isInArena(reloaded.Arena(), data) {
+		t.Fatal("record data is not in the arena")
+	}
+}
+
+func TestArenaReplace(t *testing.T) {
+	records := setupArena(t, DefaultArenaChunkSize)
+	defer teardownArena(t)
+
+	replacement := []*pb.Record{
+		{Id: 100, Data: []float32{1, 1, 1}},
+		{Id: 200, Data: []float32{2, 2}},
+	}
+	if err := records.Replace(replacement, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	arena := records.Arena()
+	for _, r := range replacement {
+		if stored := records.Find(r.Id); stored == nil {
+			t.Fatalf("record %d not found", r.Id)
+		} else if !isInArena(arena, stored.Data) {
+			t.Fatalf("record %d data is not in the arena", r.Id)
+		}
+	}
+}
+
+func TestArenaGeneration(t *testing.T) {
+	records := setupArena(t, DefaultArenaChunkSize)
+	defer teardownArena(t)
+
+	gen := records.Generation()
+	if err := records.Create(&pb.Record{Data: []float32{1, 2, 3}}); err != nil {
+		t.Fatal(err)
+	} else if next := records.Generation(); next == gen {
+		t.Fatal("expected generation to change after create")
+	} else if records.Delete(1); records.Generation() == next {
+		t.Fatal("expected generation to change after delete")
+	}
+}
+
+func TestArenaPin(t *testing.T) {
+	records := setupArena(t, DefaultArenaChunkSize)
+	defer teardownArena(t)
+
+	arena := records.Arena()
+	unpin := records.Pin()
+	before := records.Find(1).Data
+
+	// released slots are not reused while a reader pinned before is active
+	for n := float32(0); n < 3; n++ {
+		if err := records.Update(&pb.Record{Id: 1, Data: []float32{n, n, n}}); err != nil {
+			t.Fatal(err)
+		} else if err := records.Update(&pb.Record{Id: 2, Data: []float32{n, n, n}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !reflect.DeepEqual(before, testRecord.Data) {
+		t.Fatalf("the vector of a pinned reader has been overwritten: %v", before)
+	} else if column := arena.columns[3]; len(column.free) != 0 || len(column.pending) != 6 {
+		t.Fatalf("expected 6 pending slots: free=%d pending=%d", len(column.free), len(column.pending))
+	}
+
+	unpin()
+
+	next := arena.columns[3].next
+	if err := records.Update(&pb.Record{Id: 1, Data: []float32{7, 8, 9}}); err != nil {
+		t.Fatal(err)
+	} else if column := arena.columns[3]; column.next != next || len(column.pending) != 1 {
+		t.Fatalf("expected the released slots to be reused: next=%d pending=%d", column.next, len(column.pending))
+	}
+}
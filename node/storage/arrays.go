@@ -0,0 +1,435 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// DefaultArrayName is the name of the array exported to .npz archives and
+// safetensors files if none is given.
+const DefaultArrayName = "embeddings"
+
+// Array is a dense array of values in row major order, every row of its
+// first dimension is a record.
+type Array struct {
+	Shape []uint64
+	Data  []float32
+}
+
+// element type of the values of an array file
+type dtype struct {
+	// f for floats, i for signed integers, u for unsigned integers,
+	// b for booleans and B for bfloat16
+	kind  byte
+	size  int
+	order binary.ByteOrder
+}
+
+func numElements(shape []uint64) (uint64, error) {
+	n := uint64(1)
+	for _, dim := range shape {
+		if dim > 0 && n > math.MaxInt32/dim {
+			return 0, fmt.Errorf("array of shape %v is too big", shape)
+		}
+		n *= dim
+	}
+	return n, nil
+}
+
+// converts a half precision float to a single precision one
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+
+	switch {
+	case exp == 0x1f:
+		// inf and nan
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	case exp == 0 && frac == 0:
+		return math.Float32frombits(sign)
+	case exp == 0:
+		// subnormal, normalize it
+		exp = 127 - 15 + 1
+		for frac&0x400 == 0 {
+			frac <<= 1
+			exp--
+		}
+		return math.Float32frombits(sign | exp<<23 | (frac&0x3ff)<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+}
+
+// converts the raw values of an array file to float32
+func (t dtype) decode(raw []byte, n uint64) ([]float32, error) {
+	if uint64(len(raw)) != n*uint64(t.size) {
+		return nil, fmt.Errorf("expected %d bytes of data, got %d", n*uint64(t.size), len(raw))
+	}
+
+	data := make([]float32, n)
+	for i := range data {
+		v := raw[i*t.size : (i+1)*t.size]
+		switch {
+		case t.kind == 'f' && t.size == 4:
+			data[i] = math.Float32frombits(t.order.Uint32(v))
+		case t.kind == 'f' && t.size == 8:
+			data[i] = float32(math.Float64frombits(t.order.Uint64(v)))
+		case t.kind == 'f' && t.size == 2:
+			data[i] = float16ToFloat32(t.order.Uint16(v))
+		case t.kind == 'B' && t.size == 2:
+			data[i] = math.Float32frombits(uint32(t.order.Uint16(v)) << 16)
+		case (t.kind == 'u' || t.kind == 'b') && t.size == 1:
+			data[i] = float32(v[0])
+		case t.kind == 'u' && t.size == 2:
+			data[i] = float32(t.order.Uint16(v))
+		case t.kind == 'u' && t.size == 4:
+			data[i] = float32(t.order.Uint32(v))
+		case t.kind == 'u' && t.size == 8:
+			data[i] = float32(t.order.Uint64(v))
+		case t.kind == 'i' && t.size == 1:
+			data[i] = float32(int8(v[0]))
+		case t.kind == 'i' && t.size == 2:
+			data[i] = float32(int16(t.order.Uint16(v)))
+		case t.kind == 'i' && t.size == 4:
+			data[i] = float32(int32(t.order.Uint32(v)))
+		case t.kind == 'i' && t.size == 8:
+			data[i] = float32(int64(t.order.Uint64(v)))
+		default:
+			return nil, fmt.Errorf("unsupported element type %c%d", t.kind, t.size)
+		}
+	}
+	return data, nil
+}
+
+// writes float32 values in little endian order
+func writeFloats(w io.Writer, data []float32) error {
+	buf := make([]byte, 0, 64*1024)
+	for _, v := range data {
+		if len(buf)+4 > cap(buf) {
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+		buf = append(buf, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(buf[len(buf)-4:], math.Float32bits(v))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// the header of an array file and the reader of its raw values
+type arrayData struct {
+	shape   []uint64
+	t       dtype
+	fortran bool
+	r       io.Reader
+	closer  io.Closer
+}
+
+func openArray(r io.ReaderAt, size int64, format pb.ArrayHeader_Format, name string) (*arrayData, error) {
+	switch format {
+	case pb.ArrayHeader_NPY:
+		return readNpy(io.NewSectionReader(r, 0, size))
+	case pb.ArrayHeader_NPZ:
+		return readNpz(r, size, name)
+	case pb.ArrayHeader_SAFETENSORS:
+		return readSafetensors(r, size, name)
+	}
+	return nil, fmt.Errorf("unknown array format %d", format)
+}
+
+// reads and converts the next n values, the buffer grows only as the
+// data is actually read so that the shape in the header of the file
+// can't make it allocate more memory than the size of the file.
+func (d *arrayData) read(buf *bytes.Buffer, n uint64) ([]float32, error) {
+	expected := int64(n) * int64(d.t.size)
+
+	buf.Reset()
+	if read, err := buf.ReadFrom(io.LimitReader(d.r, expected)); err != nil {
+		return nil, fmt.Errorf("error while reading the array data: %s", err)
+	} else if read != expected {
+		return nil, fmt.Errorf("expected %d bytes of data, got %d", expected, read)
+	}
+	return d.t.decode(buf.Bytes(), n)
+}
+
+func (d *arrayData) close() error {
+	if d.closer != nil {
+		return d.closer.Close()
+	}
+	return nil
+}
+
+// ReadArray reads an array from a file of the given format, name selects
+// the array of a .npz archive or the tensor of a safetensors file and can
+// be empty if the file only has one.
+func ReadArray(r io.ReaderAt, size int64, format pb.ArrayHeader_Format, name string) (*Array, error) {
+	d, err := openArray(r, size, format, name)
+	if err != nil {
+		return nil, err
+	}
+	defer d.close()
+
+	n, err := numElements(d.shape)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Array{Shape: d.shape}
+	if a.Data, err = d.read(&bytes.Buffer{}, n); err != nil {
+		return nil, err
+	} else if d.fortran {
+		a.Data = fromFortranOrder(a.Shape, a.Data)
+	}
+	return a, nil
+}
+
+// ArrayReader reads the rows of an array file one at a time, as new
+// records whose shape is the one of the rows.
+type ArrayReader struct {
+	// Shape is the shape of the whole array, its first dimension
+	// is the number of rows.
+	Shape []uint64
+
+	data    *arrayData
+	rowSize uint64
+	read    uint64
+	buf     bytes.Buffer
+	// arrays in column major order are read all at once
+	all    []float32
+	loaded bool
+}
+
+// NewArrayReader opens an array with at least 2 dimensions from a file of
+// the given format, name selects the array of a .npz archive or the tensor
+// of a safetensors file and can be empty if the file only has one.
+func NewArrayReader(r io.ReaderAt, size int64, format pb.ArrayHeader_Format, name string) (*ArrayReader, error) {
+	d, err := openArray(r, size, format, name)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &ArrayReader{Shape: d.shape, data: d}
+	if len(a.Shape) < 2 {
+		err = fmt.Errorf("expected an array with at least 2 dimensions, got shape %v", a.Shape)
+	} else if _, err = numElements(a.Shape); err == nil {
+		if a.rowSize, _ = numElements(a.Shape[1:]); a.rowSize == 0 && a.Rows() > 0 {
+			err = fmt.Errorf("array of shape %v has empty rows", a.Shape)
+		}
+	}
+
+	if err != nil {
+		d.close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// Rows returns the number of rows of the array.
+func (a *ArrayReader) Rows() uint64 {
+	return a.Shape[0]
+}
+
+// Next returns a new record for the next row of the array, or io.EOF
+// after the last one.
+func (a *ArrayReader) Next() (*pb.Record, error) {
+	if a.read == a.Rows() {
+		return nil, io.EOF
+	}
+
+	var row []float32
+	if a.data.fortran {
+		if !a.loaded {
+			all, err := a.data.read(&a.buf, a.Rows()*a.rowSize)
+			if err != nil {
+				return nil, err
+			}
+			a.all, a.loaded = fromFortranOrder(a.Shape, all), true
+		}
+		start := a.read * a.rowSize
+		end := start + a.rowSize
+		row = a.all[start:end:end]
+	} else {
+		var err error
+		if row, err = a.data.read(&a.buf, a.rowSize); err != nil {
+			return nil, err
+		}
+	}
+
+	a.read++
+	return &pb.Record{
+		Data:  row,
+		Shape: append([]uint64(nil), a.Shape[1:]...),
+	}, nil
+}
+
+// Close releases the file of the array.
+func (a *ArrayReader) Close() error {
+	return a.data.close()
+}
+
+// writes an array of the given shape in the given format, data writes
+// its float32 values in row major order
+func writeArray(w io.Writer, format pb.ArrayHeader_Format, name string, shape []uint64, data func(w io.Writer) error) error {
+	if name == "" {
+		name = DefaultArrayName
+	}
+
+	switch format {
+	case pb.ArrayHeader_NPY:
+		return writeNpy(w, shape, data)
+	case pb.ArrayHeader_NPZ:
+		return writeNpz(w, name, shape, data)
+	case pb.ArrayHeader_SAFETENSORS:
+		return writeSafetensors(w, name, shape, data)
+	}
+	return fmt.Errorf("unknown array format %d", format)
+}
+
+// WriteArray writes an array of float32 values to w in the given format,
+// name is the name of the array in .npz archives and safetensors files.
+func WriteArray(w io.Writer, format pb.ArrayHeader_Format, name string, a *Array) error {
+	return writeArray(w, format, name, a.Shape, func(w io.Writer) error {
+		return writeFloats(w, a.Data)
+	})
+}
+
+// WriteRecordsArray writes to w an array with a row for every record in
+// the given format, the rows are encoded one at a time as they are written.
+// The records must all have the same shape.
+func WriteRecordsArray(w io.Writer, format pb.ArrayHeader_Format, name string, records []*pb.Record) error {
+	shape := []uint64{0}
+	if len(records) > 0 {
+		shape = shapeOf(records[0])
+	}
+
+	size, err := numElements(shape)
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if s := shapeOf(rec); !sameShape(s, shape) {
+			return fmt.Errorf("record %d has shape %v while the others have shape %v", rec.Id, s, shape)
+		} else if VectorSize(rec) != size {
+			return fmt.Errorf("record %d has %d elements instead of %d", rec.Id, VectorSize(rec), size)
+		}
+	}
+
+	return WriteRecordsArrayFrom(w, format, name, uint64(len(records)), RecordsIterator(records))
+}
+
+// RecordsIterator returns a function returning the given records one
+// at a time and io.EOF after the last one.
+func RecordsIterator(records []*pb.Record) func() (*pb.Record, error) {
+	return func() (*pb.Record, error) {
+		if len(records) == 0 {
+			return nil, io.EOF
+		}
+		rec := records[0]
+		records = records[1:]
+		return rec, nil
+	}
+}
+
+// WriteRecordsArrayFrom writes to w an array with the given number of
+// rows, one for every record returned by next, so that the records are
+// read one at a time as they are written. The records must all have the
+// same shape, the one of the first record.
+func WriteRecordsArrayFrom(w io.Writer, format pb.ArrayHeader_Format, name string, rows uint64, next func() (*pb.Record, error)) error {
+	read := func(n uint64) (*pb.Record, error) {
+		rec, err := next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("expected %d records, got %d", rows, n)
+		}
+		return rec, err
+	}
+
+	shape := []uint64{0}
+	var first *pb.Record
+	if rows > 0 {
+		var err error
+		if first, err = read(0); err != nil {
+			return err
+		}
+		shape = shapeOf(first)
+	}
+
+	size, err := numElements(shape)
+	if err != nil {
+		return err
+	}
+
+	return writeArray(w, format, name, append([]uint64{rows}, shape...), func(w io.Writer) error {
+		rec := first
+		for n := uint64(0); n < rows; n++ {
+			if n > 0 {
+				if rec, err = read(n); err != nil {
+					return err
+				}
+			}
+
+			if s := shapeOf(rec); !sameShape(s, shape) {
+				return fmt.Errorf("record %d has shape %v while the others have shape %v", rec.Id, s, shape)
+			} else if VectorSize(rec) != size {
+				return fmt.Errorf("record %d has %d elements instead of %d", rec.Id, VectorSize(rec), size)
+			} else if err := writeFloats(w, DenseData(rec)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Records returns a record for every row of the array, the shape of the
+// records is the one of the rows.
+func (a *Array) Records() ([]*pb.Record, error) {
+	if len(a.Shape) < 2 {
+		return nil, fmt.Errorf("expected an array with at least 2 dimensions, got shape %v", a.Shape)
+	}
+
+	rows := a.Shape[0]
+	shape := a.Shape[1:]
+	size, err := numElements(shape)
+	if err != nil {
+		return nil, err
+	} else if size == 0 && rows > 0 {
+		return nil, fmt.Errorf("array of shape %v has empty rows", a.Shape)
+	}
+
+	records := make([]*pb.Record, rows)
+	for i := range records {
+		start := uint64(i) * size
+		end := start + size
+		records[i] = &pb.Record{
+			Data:  a.Data[start:end:end],
+			Shape: append([]uint64(nil), shape...),
+		}
+	}
+	return records, nil
+}
+
+func shapeOf(rec *pb.Record) []uint64 {
+	if rec.Shape == nil {
+		return []uint64{VectorSize(rec)}
+	}
+	return rec.Shape
+}
+
+func sameShape(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
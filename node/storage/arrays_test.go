@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+var testArray = Array{
+	Shape: []uint64{3, 2, 2},
+	Data:  []float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+}
+
+func TestArrayFormats(t *testing.T) {
+	for format := range pb.ArrayHeader_Format_name {
+		format := pb.ArrayHeader_Format(format)
+		t.Run(format.String(), func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := WriteArray(&buf, format, "", &testArray); err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(buf.Bytes())
+			if a, err := ReadArray(r, r.Size(), format, ""); err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(a, &testArray) {
+				t.Fatalf("expected %v, got %v", testArray, a)
+			}
+
+			if format != pb.ArrayHeader_NPY {
+				if a, err := ReadArray(r, r.Size(), format, DefaultArrayName); err != nil {
+					t.Fatal(err)
+				} else if !reflect.DeepEqual(a, &testArray) {
+					t.Fatalf("expected %v, got %v", testArray, a)
+				} else if _, err := ReadArray(r, r.Size(), format, "nope"); err == nil {
+					t.Fatal("expected error for unknown array name")
+				}
+			}
+		})
+	}
+}
+
+func TestArrayRecords(t *testing.T) {
+	records, err := testArray.Records()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	for i, rec := range records {
+		if !reflect.DeepEqual(rec.Shape, []uint64{2, 2}) {
+			t.Fatalf("unexpected shape %v", rec.Shape)
+		} else if !reflect.DeepEqual(rec.Data, testArray.Data[i*4:(i+1)*4]) {
+			t.Fatalf("unexpected data %v", rec.Data)
+		}
+	}
+
+	// rows must not share their capacity
+	records[0].Data = append(records[0].Data, 42)
+	if records[1].Data[0] != 5 {
+		t.Fatal("appending to a row changed the next one")
+	}
+	records[0].Data = records[0].Data[:4]
+
+	buf := bytes.Buffer{}
+	if err := WriteRecordsArray(&buf, pb.ArrayHeader_NPY, "", records); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bytes.NewReader(buf.Bytes())
+	if a, err := ReadArray(r, r.Size(), pb.ArrayHeader_NPY, ""); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(a, &testArray) {
+		t.Fatalf("expected %v, got %v", testArray, a)
+	}
+
+	records[1].Shape = []uint64{4}
+	if err := WriteRecordsArray(&bytes.Buffer{}, pb.ArrayHeader_NPY, "", records); err == nil {
+		t.Fatal("expected error for records with different shapes")
+	} else if _, err := (&Array{Shape: []uint64{4}, Data: make([]float32, 4)}).Records(); err == nil {
+		t.Fatal("expected error for 1 dimensional array")
+	}
+
+	buf.Reset()
+	if err := WriteRecordsArray(&buf, pb.ArrayHeader_NPY, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	r = bytes.NewReader(buf.Bytes())
+	if a, err := ReadArray(r, r.Size(), pb.ArrayHeader_NPY, ""); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(a.Shape, []uint64{0, 0}) {
+		t.Fatalf("unexpected shape %v for no records", a.Shape)
+	}
+}
+
+func TestArrayReader(t *testing.T) {
+	for format := range pb.ArrayHeader_Format_name {
+		format := pb.ArrayHeader_Format(format)
+		t.Run(format.String(), func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := WriteArray(&buf, format, "", &testArray); err != nil {
+				t.Fatal(err)
+			}
+
+			r := bytes.NewReader(buf.Bytes())
+			array, err := NewArrayReader(r, r.Size(), format, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer array.Close()
+
+			if array.Rows() != 3 {
+				t.Fatalf("expected 3 rows, got %d", array.Rows())
+			}
+
+			for i := 0; ; i++ {
+				rec, err := array.Next()
+				if err == io.EOF {
+					if i != 3 {
+						t.Fatalf("expected 3 rows, read %d", i)
+					}
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				} else if !reflect.DeepEqual(rec.Shape, []uint64{2, 2}) {
+					t.Fatalf("unexpected shape %v", rec.Shape)
+				} else if !reflect.DeepEqual(rec.Data, testArray.Data[i*4:(i+1)*4]) {
+					t.Fatalf("unexpected data %v", rec.Data)
+				}
+			}
+		})
+	}
+
+	fortran := makeNpy(1, "{'descr': '<f4', 'fortran_order': True, 'shape': (2, 3), }\n", []float32{1, 4, 2, 5, 3, 6})
+	r := bytes.NewReader(fortran)
+	array, err := NewArrayReader(r, r.Size(), pb.ArrayHeader_NPY, "")
+	if err != nil {
+		t.Fatal(err)
+	} else if rec, err := array.Next(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(rec.Data, []float32{1, 2, 3}) {
+		t.Fatalf("unexpected data %v", rec.Data)
+	} else if rec, err := array.Next(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(rec.Data, []float32{4, 5, 6}) {
+		t.Fatalf("unexpected data %v", rec.Data)
+	}
+}
+
+func TestArrayReaderShortData(t *testing.T) {
+	// the header claims the biggest array possible but the file has
+	// a single row, reading it must not allocate the whole array
+	huge := makeNpy(1, "{'descr': '<f4', 'fortran_order': False, 'shape': (2097151, 1024), }\n", []float32{1, 2})
+	r := bytes.NewReader(huge)
+	array, err := NewArrayReader(r, r.Size(), pb.ArrayHeader_NPY, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer array.Close()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	if _, err := array.Next(); err == nil {
+		t.Fatal("expected error for short data")
+	} else if _, err := ReadArray(r, r.Size(), pb.ArrayHeader_NPY, ""); err == nil {
+		t.Fatal("expected error for short data")
+	}
+
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1024*1024 {
+		t.Fatalf("%d bytes allocated for a file of %d bytes", allocated, len(huge))
+	}
+}
+
+func TestFloat16ToFloat32(t *testing.T) {
+	tests := map[uint16]float32{
+		0x0000: 0,
+		0x3c00: 1,
+		0xc000: -2,
+		0x3555: 0.33325195,
+		0x7bff: 65504,
+		0x0001: 5.9604645e-08,
+		0x7c00: float32(math.Inf(1)),
+	}
+
+	for h, expected := range tests {
+		if got := float16ToFloat32(h); got != expected {
+			t.Fatalf("expected %v for %04x, got %v", expected, h, got)
+		}
+	}
+
+	if got := float16ToFloat32(0x7e00); !math.IsNaN(float64(got)) {
+		t.Fatalf("expected nan, got %v", got)
+	}
+}
+
+func TestRecordsCreateMany(t *testing.T) {
+	setupRecords(t, false, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records.NextID(10)
+	next := records.GetNextId()
+	imported, err := testArray.Records()
+	if err != nil {
+		t.Fatal(err)
+	} else if err := records.CreateMany(imported); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, rec := range imported {
+		if rec.Id != next+uint64(i) {
+			t.Fatalf("expected id %d, got %d", next+uint64(i), rec.Id)
+		} else if stored := records.Find(rec.Id); stored == nil || stored.Version != 1 {
+			t.Fatalf("record %d not stored", rec.Id)
+		}
+	}
+
+	size := records.Size()
+	if err := records.SetSchema(&pb.Schema{RequiredMeta: []string{"label"}}); err == nil {
+		t.Fatal("records without meta should not match the schema")
+	} else if err := records.SetSchema(&pb.Schema{Dimension: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := []*pb.Record{{Data: []float32{1, 2, 3, 4}}, {Data: []float32{1}}}
+	if err := records.CreateMany(invalid); err == nil || !strings.Contains(err.Error(), "schema") {
+		t.Fatalf("expected schema error, got %v", err)
+	} else if records.Size() != size {
+		t.Fatal("records should not be partially created")
+	} else if records.GetNextId() != next+uint64(len(imported)) {
+		t.Fatal("identifiers of failed creations should be released")
+	}
+}
@@ -27,3 +27,14 @@ type Driver interface {
 	// the index that something went wrong during the copy.
 	Copy(dst proto.Message, src proto.Message) error
 }
+
+// PayloadDriver is implemented by drivers of protobuf objects with a
+// payload that can be evicted from memory and paged in from disk when
+// needed, while the rest of the object stays resident.
+type PayloadDriver interface {
+	Driver
+	// Strip must return a copy of the object without its payload.
+	Strip(m proto.Message) proto.Message
+	// PayloadSize must return the size in bytes of the object payload.
+	PayloadSize(m proto.Message) int64
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
+)
+
+var errNotExpired = errors.New("record not expired")
+
+// Expired returns true if the record has an expiration time and
+// it is not after now, expressed as unix time in nanoseconds.
+func Expired(record *pb.Record, now int64) bool {
+	return record.ExpiresAt != 0 && record.ExpiresAt <= now
+}
+
+// converts the TTL of a record being created or updated, if any,
+// into an absolute expiration time, a negative expiration time
+// means the record never expires.
+func applyTTL(record *pb.Record, now int64) {
+	if record.Ttl > 0 {
+		record.ExpiresAt = now + int64(time.Duration(record.Ttl)*time.Second)
+		record.Ttl = 0
+	} else if record.ExpiresAt < 0 {
+		record.ExpiresAt = 0
+	}
+}
+
+func (r *Records) _expiryUpdate(rec *pb.Record) {
+	if rec.ExpiresAt != 0 {
+		r.expiring[rec.Id] = rec.ExpiresAt
+	} else {
+		delete(r.expiring, rec.Id)
+	}
+}
+
+func (r *Records) expiryUpdate(rec *pb.Record) {
+	r.Lock()
+	defer r.Unlock()
+
+	r._expiryUpdate(rec)
+}
+
+// Expiring returns the number of records with an expiration time.
+func (r *Records) Expiring() int {
+	r.RLock()
+	defer r.RUnlock()
+	return len(r.expiring)
+}
+
+// Reap removes every expired record from the index, the meta index
+// and the disk, returning the number of records that were removed.
+func (r *Records) Reap() int {
+	now := time.Now().UnixNano()
+
+	r.RLock()
+	expired := make([]uint64, 0)
+	for id, expiresAt := range r.expiring {
+		if expiresAt <= now {
+			expired = append(expired, id)
+		}
+	}
+	r.RUnlock()
+
+	reaped := 0
+	for _, id := range expired {
+		// the record might have been updated in the meantime
+		if _, err := r.deleteIf(id, pb.Event_EXPIRE, func(rec *pb.Record) error {
+			if !Expired(rec, now) {
+				return errNotExpired
+			}
+			return nil
+		}); err == nil {
+			reaped++
+		}
+	}
+
+	return reaped
+}
+
+// ForEach executes a callback passing as argument every record that
+// is not expired, it interrupts the loop if the callback returns an
+// error, the same error will be returned.
+func (r *Records) ForEach(cb func(record proto.Message) error) error {
+	now := time.Now().UnixNano()
+	return r.Index.ForEach(func(m proto.Message) error {
+		if Expired(m.(*pb.Record), now) {
+			return nil
+		}
+		return cb(m)
+	})
+}
+
+// Objects returns the list of the records that are not expired.
+func (r *Records) Objects() []proto.Message {
+	now := time.Now().UnixNano()
+	all := r.Index.Objects()
+	objects := all[:0]
+	for _, m := range all {
+		if !Expired(m.(*pb.Record), now) {
+			objects = append(objects, m)
+		}
+	}
+	return objects
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
+)
+
+func setupExpiring(t testing.TB) (*Records, *pb.Record, *pb.Record) {
+	setupRecords(t, true, false)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired := &pb.Record{
+		Data:      []float32{1, 2, 3},
+		Meta:      map[string]string{"expiry": "yes"},
+		ExpiresAt: time.Now().Add(-time.Second).UnixNano(),
+	}
+	alive := &pb.Record{
+		Data: []float32{1, 2, 3},
+		Meta: map[string]string{"expiry": "yes"},
+		Ttl:  3600,
+	}
+
+	if err := records.Create(expired); err != nil {
+		t.Fatal(err)
+	} else if err := records.Create(alive); err != nil {
+		t.Fatal(err)
+	}
+
+	return records, expired, alive
+}
+
+func TestRecordsTTL(t *testing.T) {
+	records, _, alive := setupExpiring(t)
+	defer teardownRecords(t)
+
+	if alive.Ttl != 0 {
+		t.Fatalf("expected ttl to be converted, got %d", alive.Ttl)
+	} else if delta := time.Duration(alive.ExpiresAt - time.Now().UnixNano()); delta <= 0 || delta > time.Hour {
+		t.Fatalf("unexpected expiration time %d", alive.ExpiresAt)
+	} else if records.Expiring() != 2 {
+		t.Fatalf("expected 2 expiring records, got %d", records.Expiring())
+	}
+
+	// extend the expiration
+	before := alive.ExpiresAt
+	if err := records.Update(&pb.Record{Id: alive.Id, Ttl: 7200}); err != nil {
+		t.Fatal(err)
+	} else if stored := records.Find(alive.Id); stored == nil {
+		t.Fatal("record not found")
+	} else if stored.ExpiresAt <= before {
+		t.Fatalf("expected expiration to be extended, got %d", stored.ExpiresAt)
+	}
+
+	// remove the expiration
+	if err := records.Update(&pb.Record{Id: alive.Id, ExpiresAt: -1}); err != nil {
+		t.Fatal(err)
+	} else if records.Expiring() != 1 {
+		t.Fatalf("expected 1 expiring record, got %d", records.Expiring())
+	}
+}
+
+func TestRecordsExpiredAreHidden(t *testing.T) {
+	records, expired, alive := setupExpiring(t)
+	defer teardownRecords(t)
+
+	if records.Find(expired.Id) != nil {
+		t.Fatal("expired record should not be found")
+	} else if records.Find(alive.Id) == nil {
+		t.Fatal("record not found")
+	} else if found := records.FindBy("expiry", "yes"); len(found) != 1 || found[0].Id != alive.Id {
+		t.Fatalf("unexpected meta lookup result %v", found)
+	} else if objs := records.Objects(); len(objs) != testRecords+1 {
+		t.Fatalf("expected %d objects, got %d", testRecords+1, len(objs))
+	}
+
+	err := records.ForEach(func(m proto.Message) error {
+		if m.(*pb.Record).Id == expired.Id {
+			t.Fatal("expired record should not be visited")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordsReap(t *testing.T) {
+	records, expired, alive := setupExpiring(t)
+	defer teardownRecords(t)
+
+	if reaped := records.Reap(); reaped != 1 {
+		t.Fatalf("expected 1 reaped record, got %d", reaped)
+	} else if reaped := records.Reap(); reaped != 0 {
+		t.Fatalf("expected 0 reaped records, got %d", reaped)
+	} else if records.Size() != testRecords+1 {
+		t.Fatalf("expected %d records, got %d", testRecords+1, records.Size())
+	} else if records.Expiring() != 1 {
+		t.Fatalf("expected 1 expiring record, got %d", records.Expiring())
+	} else if found := records.FindBy("expiry", "yes"); len(found) != 1 || found[0].Id != alive.Id {
+		t.Fatalf("unexpected meta lookup result %v", found)
+	} else if ids := records.metaBy["expiry"]["yes"]; len(ids) != 1 || ids[0] != alive.Id {
+		t.Fatalf("unexpected meta index %v", ids)
+	}
+
+	reloaded, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	} else if reloaded.Size() != testRecords+1 {
+		t.Fatalf("expected %d records, got %d", testRecords+1, reloaded.Size())
+	} else if reloaded.Index.Find(expired.Id) != nil {
+		t.Fatal("reaped record should not be on disk")
+	} else if ids := reloaded.metaBy["expiry"]["yes"]; len(ids) != 1 || ids[0] != alive.Id {
+		t.Fatalf("unexpected meta index %v", ids)
+	} else if reloaded.Expiring() != 1 {
+		t.Fatalf("expected 1 expiring record, got %d", reloaded.Expiring())
+	}
+}
+
+func TestRecordsReapSkipsExtended(t *testing.T) {
+	records, expired, _ := setupExpiring(t)
+	defer teardownRecords(t)
+
+	if err := records.Update(&pb.Record{Id: expired.Id, Ttl: 3600}); err != nil {
+		t.Fatal(err)
+	} else if reaped := records.Reap(); reaped != 0 {
+		t.Fatalf("expected 0 reaped records, got %d", reaped)
+	} else if records.Find(expired.Id) == nil {
+		t.Fatal("record not found")
+	}
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// DefaultFeedSize is the default number of events kept in memory
+// by a feed for watchers to resume from.
+const DefaultFeedSize = 4096
+
+var (
+	// ErrEventsLost is returned when the events after a given sequence
+	// number are not available anymore.
+	ErrEventsLost = errors.New("events lost")
+
+	feedSize = DefaultFeedSize
+	// max number of events returned by a single read
+	feedReadBatch = 256
+)
+
+// SetFeedSize sets the number of events kept in memory by the
+// feeds created from now on.
+func SetFeedSize(size int) {
+	feedSize = size
+}
+
+// Feed keeps the most recent changes of the records and lets watchers
+// follow them. Every event gets a sequence number that can be used to
+// resume watching, sequence numbers are not persisted and restart from
+// 1 when a new feed is created.
+type Feed struct {
+	sync.Mutex
+	// ring buffer of the most recent events
+	events []*pb.Event
+	// position of the oldest event in the ring
+	head int
+	// sequence number of the last event
+	seq uint64
+	// closed and replaced every time a new event is published
+	notify chan struct{}
+}
+
+// NewFeed creates a new and empty feed.
+func NewFeed() *Feed {
+	size := feedSize
+	if size < 1 {
+		size = 1
+	}
+	return &Feed{
+		events: make([]*pb.Event, 0, size),
+		notify: make(chan struct{}),
+	}
+}
+
+// Last returns the sequence number of the last event published.
+func (f *Feed) Last() uint64 {
+	f.Lock()
+	defer f.Unlock()
+	return f.seq
+}
+
+// Publish assigns the next sequence number to an event, stamps it
+// if needed and notifies the watchers.
+func (f *Feed) Publish(ev *pb.Event) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.seq++
+	ev.Seq = f.seq
+	if ev.Timestamp == 0 {
+		ev.Timestamp = time.Now().UnixNano()
+	}
+
+	if len(f.events) < cap(f.events) {
+		f.events = append(f.events, ev)
+	} else {
+		f.events[f.head] = ev
+		f.head = (f.head + 1) % len(f.events)
+	}
+
+	close(f.notify)
+	f.notify = make(chan struct{})
+}
+
+// Read returns the events published after the given sequence number
+// and a channel that will be closed when new events are published.
+// It returns ErrEventsLost if some of those events are not available
+// anymore or if the sequence number is ahead of the feed, which happens
+// when resuming from a feed that has been recreated.
+func (f *Feed) Read(since uint64) ([]*pb.Event, <-chan struct{}, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	oldest := f.seq - uint64(len(f.events)) + 1
+	if since > f.seq || since+1 < oldest {
+		return nil, nil, ErrEventsLost
+	}
+
+	n := int(f.seq - since)
+	if n > feedReadBatch {
+		n = feedReadBatch
+	}
+
+	events := make([]*pb.Event, 0, n)
+	for i := 0; i < n; i++ {
+		pos := (f.head + int(since+1-oldest) + i) % len(f.events)
+		events = append(events, f.events[pos])
+	}
+
+	return events, f.notify, nil
+}
+
+// Follow sends every event published after the given sequence number,
+// or after the last one if zero, until the context is done or send fails.
+// If the events to send are not available anymore, the reset callback is
+// called with the sequence number the feed is followed from afterwards.
+func (f *Feed) Follow(ctx context.Context, since uint64, send func(*pb.Event) error, reset func(seq uint64) error) error {
+	if since == 0 {
+		since = f.Last()
+	}
+
+	for {
+		events, wait, err := f.Read(since)
+		if err == ErrEventsLost {
+			since = f.Last()
+			if err = reset(since); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, ev := range events {
+			if err := send(ev); err != nil {
+				return err
+			}
+			since = ev.Seq
+		}
+
+		if len(events) == 0 {
+			select {
+			case <-wait:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// publishes a change of the records if they are part of a feed,
+// created and updated records are copied since they are going to be
+// modified while the event is still in the feed
+func (r *Records) publish(t pb.Event_Type, rec *pb.Record, transfer bool) {
+	if r.feed == nil {
+		return
+	}
+
+	if t == pb.Event_CREATE || t == pb.Event_UPDATE {
+		rec = proto.Clone(rec).(*pb.Record)
+	}
+
+	ev := &pb.Event{
+		Type:       t,
+		Collection: r.collection,
+		Transfer:   transfer,
+	}
+	if rec != nil {
+		ev.RecordId = rec.Id
+		ev.Record = rec
+	}
+
+	r.feed.Publish(ev)
+}
+
+// SetFeed sets the feed the changes of the records are published to
+// and the name of their collection, empty for the default one.
+func (r *Records) SetFeed(feed *Feed, collection string) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.feed = feed
+	r.collection = collection
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func seqsOf(events []*pb.Event) []uint64 {
+	seqs := make([]uint64, len(events))
+	for i, ev := range events {
+		seqs[i] = ev.Seq
+	}
+	return seqs
+}
+
+func TestFeedRead(t *testing.T) {
+	defer SetFeedSize(DefaultFeedSize)
+	SetFeedSize(4)
+
+	feed := NewFeed()
+	if events, _, err := feed.Read(0); err != nil {
+		t.Fatal(err)
+	} else if len(events) != 0 {
+		t.Fatalf("unexpected events %v", events)
+	}
+
+	for i := 0; i < 6; i++ {
+		feed.Publish(&pb.Event{RecordId: uint64(i)})
+	}
+
+	if feed.Last() != 6 {
+		t.Fatalf("expected last sequence number 6, got %d", feed.Last())
+	} else if events, _, err := feed.Read(2); err != nil {
+		t.Fatal(err)
+	} else if seqs := seqsOf(events); len(seqs) != 4 || seqs[0] != 3 || seqs[3] != 6 {
+		t.Fatalf("unexpected events %v", seqs)
+	} else if events[0].Timestamp == 0 {
+		t.Fatal("expected event to be stamped")
+	} else if _, _, err := feed.Read(1); err != ErrEventsLost {
+		t.Fatalf("expected lost events, got %v", err)
+	} else if _, _, err := feed.Read(7); err != ErrEventsLost {
+		t.Fatalf("expected lost events for a sequence number ahead of the feed, got %v", err)
+	}
+
+	events, wait, err := feed.Read(6)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) != 0 {
+		t.Fatalf("unexpected events %v", events)
+	}
+
+	feed.Publish(&pb.Event{})
+	select {
+	case <-wait:
+	case <-time.After(time.Second):
+		t.Fatal("watchers were not notified")
+	}
+}
+
+func TestFeedFollow(t *testing.T) {
+	feed := NewFeed()
+	feed.Publish(&pb.Event{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan *pb.Event)
+	done := make(chan error)
+
+	go func() {
+		done <- feed.Follow(ctx, 1, func(ev *pb.Event) error {
+			received <- ev
+			return nil
+		}, func(seq uint64) error {
+			t.Errorf("unexpected reset at %d", seq)
+			return nil
+		})
+	}()
+
+	for i := 2; i <= 4; i++ {
+		feed.Publish(&pb.Event{})
+		select {
+		case ev := <-received:
+			if ev.Seq != uint64(i) {
+				t.Fatalf("expected event %d, got %d", i, ev.Seq)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not received", i)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordsPublish(t *testing.T) {
+	records, expired, alive := setupExpiring(t)
+	defer teardownRecords(t)
+
+	feed := NewFeed()
+	records.SetFeed(feed, "test")
+
+	rec := &pb.Record{Data: []float32{1, 2, 3}}
+	if err := records.Create(rec); err != nil {
+		t.Fatal(err)
+	} else if err := records.Update(&pb.Record{Id: rec.Id, Data: []float32{4, 5, 6}}); err != nil {
+		t.Fatal(err)
+	} else if records.Delete(rec.Id) == nil {
+		t.Fatal("record not deleted")
+	} else if records.Reap() != 1 {
+		t.Fatal("expected one record to be reaped")
+	} else if err := records.TransferIn([]*pb.Record{{Id: 100, Data: []float32{1}}}); err != nil {
+		t.Fatal(err)
+	} else if len(records.TransferOut([]uint64{100, alive.Id})) != 2 {
+		t.Fatal("expected two records to be transferred")
+	}
+
+	events, _, err := feed.Read(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		t        pb.Event_Type
+		id       uint64
+		transfer bool
+	}{
+		{pb.Event_CREATE, rec.Id, false},
+		{pb.Event_UPDATE, rec.Id, false},
+		{pb.Event_DELETE, rec.Id, false},
+		{pb.Event_EXPIRE, expired.Id, false},
+		{pb.Event_CREATE, 100, true},
+		{pb.Event_DELETE, 100, true},
+		{pb.Event_DELETE, alive.Id, true},
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(events))
+	}
+
+	for i, ev := range events {
+		exp := expected[i]
+		if ev.Type != exp.t || ev.RecordId != exp.id || ev.Transfer != exp.transfer || ev.Collection != "test" {
+			t.Fatalf("unexpected event %d: %v", i, ev)
+		}
+	}
+
+	// events keep the record as it was after the change
+	if events[0].Record.Data[0] != 1 || events[1].Record.Data[0] != 4 {
+		t.Fatalf("unexpected records %v %v", events[0].Record, events[1].Record)
+	}
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// names of the indexes checked by CheckMetaIndex
+const (
+	// the index of the meta values used by FindBy
+	MetaIndexName = "meta"
+	// the index of the meta and typed meta values used by queries
+	SortedIndexName = "sorted"
+	// the index of the unique keys of the records
+	KeysIndexName = "keys"
+)
+
+// MetaIndexEntry is a single meta value of a record as found
+// in one of the indexes or in the record itself.
+type MetaIndexEntry struct {
+	Index string
+	Meta  string
+	Value string
+	Id    uint64
+}
+
+func (e MetaIndexEntry) String() string {
+	return fmt.Sprintf("record %d %s=%s (%s index)", e.Id, e.Meta, e.Value, e.Index)
+}
+
+// MetaIndexReport is the result of an integrity check of the meta, sorted
+// and keys indexes against the stored records.
+type MetaIndexReport struct {
+	// number of records checked
+	Records int
+	// number of entries in the indexes
+	Entries int
+	// entries of the index pointing to a record that does not exist
+	// anymore or that does not have that meta value
+	Stale []MetaIndexEntry
+	// meta values of the records that are not indexed
+	Missing []MetaIndexEntry
+	// entries of the index found more than once
+	Duplicated []MetaIndexEntry
+}
+
+// Consistent returns true if no discrepancies have been found.
+func (r MetaIndexReport) Consistent() bool {
+	return len(r.Stale) == 0 && len(r.Missing) == 0 && len(r.Duplicated) == 0
+}
+
+func (r MetaIndexReport) String() string {
+	return fmt.Sprintf("%d records, %d index entries, %d stale, %d missing, %d duplicated",
+		r.Records, r.Entries, len(r.Stale), len(r.Missing), len(r.Duplicated))
+}
+
+// sorts the entries so that reports are deterministic
+func sortEntries(entries []MetaIndexEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		} else if a.Id != b.Id {
+			return a.Id < b.Id
+		} else if a.Meta != b.Meta {
+			return a.Meta < b.Meta
+		}
+		return a.Value < b.Value
+	})
+}
+
+func (r *Records) _checkMetaIndex() MetaIndexReport {
+	report := MetaIndexReport{Records: len(r.index)}
+
+	r._checkMetaBy(&report)
+	r._checkSortedBy(&report)
+	r._checkKeys(&report)
+
+	sortEntries(report.Stale)
+	sortEntries(report.Missing)
+	sortEntries(report.Duplicated)
+
+	return report
+}
+
+func (r *Records) _checkMetaBy(report *MetaIndexReport) {
+	// number of valid entries pointing to each record
+	valid := make(map[uint64]int, len(r.index))
+
+	// every entry of the index must point to a record with that meta value
+	for key, metaIdx := range r.metaBy {
+		for val, bucket := range metaIdx {
+			seen := make(map[uint64]bool, len(bucket))
+			for _, id := range bucket {
+				entry := MetaIndexEntry{Index: MetaIndexName, Meta: key, Value: val, Id: id}
+				report.Entries++
+
+				if seen[id] {
+					report.Duplicated = append(report.Duplicated, entry)
+					continue
+				}
+				seen[id] = true
+
+				m, found := r.index[id]
+				if !found {
+					report.Stale = append(report.Stale, entry)
+				} else if stored, has := m.(*pb.Record).Meta[key]; !has || stored != val {
+					report.Stale = append(report.Stale, entry)
+				} else {
+					valid[id]++
+				}
+			}
+		}
+	}
+
+	// every meta value of a record must be indexed
+	for id, m := range r.index {
+		meta := m.(*pb.Record).Meta
+		if valid[id] == len(meta) {
+			continue
+		}
+
+		for key, val := range meta {
+			indexed := false
+			for _, elemID := range r.metaBy[key][val] {
+				if elemID == id {
+					indexed = true
+					break
+				}
+			}
+
+			if !indexed {
+				report.Missing = append(report.Missing, MetaIndexEntry{Index: MetaIndexName, Meta: key, Value: val, Id: id})
+			}
+		}
+	}
+}
+
+func (r *Records) _checkSortedBy(report *MetaIndexReport) {
+	// what the index should contain for every meta key
+	expected := make(map[string]map[sortedEntry]bool)
+	for _, m := range r.index {
+		rec := m.(*pb.Record)
+		forEachMetaKey(rec.Meta, rec.TypedMeta, func(name string, key metaKey) {
+			if expected[name] == nil {
+				expected[name] = make(map[sortedEntry]bool)
+			}
+			expected[name][sortedEntry{key: key, id: rec.Id}] = true
+		})
+	}
+
+	for name, idx := range r.sortedBy {
+		seen := make(map[sortedEntry]bool, len(idx))
+		for _, e := range idx {
+			entry := MetaIndexEntry{Index: SortedIndexName, Meta: name, Value: e.key.String(), Id: e.id}
+			report.Entries++
+
+			if seen[e] {
+				report.Duplicated = append(report.Duplicated, entry)
+			} else if seen[e] = true; !expected[name][e] {
+				report.Stale = append(report.Stale, entry)
+			}
+		}
+
+		for e := range expected[name] {
+			if !seen[e] {
+				report.Missing = append(report.Missing, MetaIndexEntry{Index: SortedIndexName, Meta: name, Value: e.key.String(), Id: e.id})
+			}
+		}
+	}
+
+	for name, entries := range expected {
+		if _, found := r.sortedBy[name]; found {
+			continue
+		}
+		for e := range entries {
+			report.Missing = append(report.Missing, MetaIndexEntry{Index: SortedIndexName, Meta: name, Value: e.key.String(), Id: e.id})
+		}
+	}
+}
+
+func (r *Records) _checkKeys(report *MetaIndexReport) {
+	for key, id := range r.keys {
+		report.Entries++
+		if m, found := r.index[id]; !found || m.(*pb.Record).Key != key {
+			report.Stale = append(report.Stale, MetaIndexEntry{Index: KeysIndexName, Meta: "key", Value: key, Id: id})
+		}
+	}
+
+	for id, m := range r.index {
+		if key := m.(*pb.Record).Key; key != "" {
+			if owner, found := r.keys[key]; !found || owner != id {
+				report.Missing = append(report.Missing, MetaIndexEntry{Index: KeysIndexName, Meta: "key", Value: key, Id: id})
+			}
+		}
+	}
+}
+
+// CheckMetaIndex compares the meta, sorted and keys indexes with the
+// stored records and returns a report of the discrepancies found, if any.
+func (r *Records) CheckMetaIndex() MetaIndexReport {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r._checkMetaIndex()
+}
+
+// RebuildMetaIndex checks the meta, sorted and keys indexes and rebuilds
+// them from scratch from the stored records, returning the report of the
+// check done before the rebuild. The indexes are swapped while holding the
+// records lock so the operation is atomic and can be performed on a live node.
+func (r *Records) RebuildMetaIndex() MetaIndexReport {
+	r.Lock()
+	defer r.Unlock()
+
+	report := r._checkMetaIndex()
+
+	r.metaBy = make(map[string]metaIndex)
+	r.sortedBy = make(map[string]sortedIndex)
+	r.keys = make(map[string]uint64)
+	for _, m := range r.index {
+		rec := m.(*pb.Record)
+		r._metaIndexCreate(rec)
+		r._sortedIndexCreate(rec)
+		r._keyIndexCreate(rec)
+	}
+
+	return report
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func setupMetaIndexBenchmark(b *testing.B) *Records {
+	setupRecords(b, false, false)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		err := records.Create(&pb.Record{
+			Data: []float32{1},
+			Meta: map[string]string{"bucket": string(rune('a' + i%10)), "unique": string(rune(i))},
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return records
+}
+
+func BenchmarkRecordsCheckMetaIndex(b *testing.B) {
+	records := setupMetaIndexBenchmark(b)
+	defer teardownRecords(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		records.CheckMetaIndex()
+	}
+}
+
+func BenchmarkRecordsRebuildMetaIndex(b *testing.B) {
+	records := setupMetaIndexBenchmark(b)
+	defer teardownRecords(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		records.RebuildMetaIndex()
+	}
+}
@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func checkMetaIndex(t *testing.T, records *Records) {
+	t.Helper()
+	if report := records.CheckMetaIndex(); !report.Consistent() {
+		t.Fatalf("inconsistent meta index: %s (stale=%v missing=%v duplicated=%v)",
+			report, report.Stale, report.Missing, report.Duplicated)
+	}
+}
+
+func metaIDs(records *Records, meta, value string) []uint64 {
+	records.RLock()
+	defer records.RUnlock()
+	return append([]uint64{}, records.metaBy[meta][value]...)
+}
+
+func setupTagged(t *testing.T) *Records {
+	setupRecords(t, false, false)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		err := records.Create(&pb.Record{
+			Data: []float32{1, 2, 3},
+			Meta: map[string]string{
+				"parity": []string{"odd", "even"}[i%2],
+				"group":  "all",
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkMetaIndex(t, records)
+	return records
+}
+
+func TestMetaIndexUpdate(t *testing.T) {
+	records := setupTagged(t)
+	defer teardownRecords(t)
+
+	// record 1 is odd, make it even and drop its group
+	if err := records.Update(&pb.Record{Id: 1, Meta: map[string]string{"parity": "even"}}); err != nil {
+		t.Fatal(err)
+	} else if ids := metaIDs(records, "parity", "odd"); !reflect.DeepEqual(ids, []uint64{3, 5, 7, 9}) {
+		t.Fatalf("unexpected odd records %v", ids)
+	} else if ids := metaIDs(records, "parity", "even"); !reflect.DeepEqual(ids, []uint64{1, 2, 4, 6, 8, 10}) {
+		t.Fatalf("unexpected even records %v", ids)
+	} else if found := records.FindBy("group", "all"); len(found) != 9 {
+		t.Fatalf("expected 9 records in group, got %d", len(found))
+	}
+	checkMetaIndex(t, records)
+
+	// updating the data only keeps the meta index untouched
+	if err := records.Update(&pb.Record{Id: 2, Data: []float32{3, 2, 1}}); err != nil {
+		t.Fatal(err)
+	} else if ids := metaIDs(records, "parity", "even"); !reflect.DeepEqual(ids, []uint64{1, 2, 4, 6, 8, 10}) {
+		t.Fatalf("unexpected even records %v", ids)
+	}
+	checkMetaIndex(t, records)
+
+	// the same meta value set again is not duplicated
+	if err := records.Update(&pb.Record{Id: 2, Meta: map[string]string{"parity": "even", "group": "all"}}); err != nil {
+		t.Fatal(err)
+	}
+	checkMetaIndex(t, records)
+}
+
+func TestMetaIndexDelete(t *testing.T) {
+	records := setupTagged(t)
+	defer teardownRecords(t)
+
+	if records.Delete(3) == nil {
+		t.Fatal("expected record to be deleted")
+	} else if ids := metaIDs(records, "parity", "odd"); !reflect.DeepEqual(ids, []uint64{1, 5, 7, 9}) {
+		t.Fatalf("unexpected odd records %v", ids)
+	}
+	checkMetaIndex(t, records)
+
+	if deleted := records.DeleteMany([]uint64{2, 4, 6, 8, 10}); len(deleted) != 5 {
+		t.Fatalf("expected 5 deleted records, got %d", len(deleted))
+	} else if _, found := records.metaBy["parity"]["even"]; found {
+		t.Fatal("empty buckets should be removed")
+	}
+	checkMetaIndex(t, records)
+
+	if deleted := records.DeleteMany([]uint64{1, 5, 7, 9}); len(deleted) != 4 {
+		t.Fatalf("expected 4 deleted records, got %d", len(deleted))
+	} else if len(records.metaBy) != 0 {
+		t.Fatalf("expected empty meta index, got %v", records.metaBy)
+	}
+	checkMetaIndex(t, records)
+}
+
+func TestMetaIndexCreateManyOverwrites(t *testing.T) {
+	records := setupTagged(t)
+	defer teardownRecords(t)
+
+	err := records.CreateManyWIthId([]*pb.Record{
+		{Id: 1, Data: []float32{1}, Meta: map[string]string{"parity": "none"}},
+		{Id: 11, Data: []float32{1}, Meta: map[string]string{"parity": "odd"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if ids := metaIDs(records, "parity", "odd"); !reflect.DeepEqual(ids, []uint64{3, 5, 7, 9, 11}) {
+		t.Fatalf("unexpected odd records %v", ids)
+	}
+	checkMetaIndex(t, records)
+}
+
+func TestMetaIndexCheck(t *testing.T) {
+	records := setupTagged(t)
+	defer teardownRecords(t)
+
+	// corrupt the index on purpose
+	records.Lock()
+	records.metaBy["parity"]["odd"] = append(records.metaBy["parity"]["odd"], 666, 3)
+	records.metaBy["parity"]["even"] = records.metaBy["parity"]["even"][1:]
+	records.metaBy["group"]["none"] = []uint64{4}
+	records.Unlock()
+
+	report := records.CheckMetaIndex()
+	if report.Consistent() {
+		t.Fatal("expected inconsistent meta index")
+	} else if report.Records != 10 {
+		t.Fatalf("expected 10 records checked, got %d", report.Records)
+	} else if expected := []MetaIndexEntry{
+		{Index: MetaIndexName, Meta: "group", Value: "none", Id: 4},
+		{Index: MetaIndexName, Meta: "parity", Value: "odd", Id: 666},
+	}; !reflect.DeepEqual(report.Stale, expected) {
+		t.Fatalf("unexpected stale entries %v", report.Stale)
+	} else if expected := []MetaIndexEntry{{Index: MetaIndexName, Meta: "parity", Value: "even", Id: 2}}; !reflect.DeepEqual(report.Missing, expected) {
+		t.Fatalf("unexpected missing entries %v", report.Missing)
+	} else if expected := []MetaIndexEntry{{Index: MetaIndexName, Meta: "parity", Value: "odd", Id: 3}}; !reflect.DeepEqual(report.Duplicated, expected) {
+		t.Fatalf("unexpected duplicated entries %v", report.Duplicated)
+	}
+
+	if fixed := records.RebuildMetaIndex(); !reflect.DeepEqual(fixed, report) {
+		t.Fatalf("expected the rebuild to report %v, got %v", report, fixed)
+	}
+	checkMetaIndex(t, records)
+
+	if found := records.FindBy("parity", "even"); len(found) != 5 {
+		t.Fatalf("expected 5 even records, got %d", len(found))
+	} else if found := records.FindBy("group", "none"); len(found) != 0 {
+		t.Fatalf("unexpected records %v", found)
+	}
+}
+
+func TestSortedAndKeysIndexCheck(t *testing.T) {
+	records := setupTagged(t)
+	defer teardownRecords(t)
+
+	if err := records.Update(&pb.Record{Id: 1, Key: "one", TypedMeta: map[string]*pb.MetaValue{
+		"score": {Value: &pb.MetaValue_Number{Number: 1.5}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	checkMetaIndex(t, records)
+
+	// corrupt the indexes on purpose
+	records.Lock()
+	records.sortedBy["score"] = records.sortedBy["score"].remove(metaKey{kind: metaNumber, num: 1.5}, 1)
+	records.sortedBy["parity"] = append(records.sortedBy["parity"], records.sortedBy["parity"][0])
+	records.sortedBy["group"] = records.sortedBy["group"].insert(metaKey{kind: metaText, text: "none"}, 4)
+	delete(records.keys, "one")
+	records.keys["two"] = 2
+	records.Unlock()
+
+	report := records.CheckMetaIndex()
+	if report.Consistent() {
+		t.Fatal("expected inconsistent indexes")
+	} else if expected := []MetaIndexEntry{
+		{Index: KeysIndexName, Meta: "key", Value: "two", Id: 2},
+		{Index: SortedIndexName, Meta: "group", Value: "none", Id: 4},
+	}; !reflect.DeepEqual(report.Stale, expected) {
+		t.Fatalf("unexpected stale entries %v", report.Stale)
+	} else if expected := []MetaIndexEntry{
+		{Index: KeysIndexName, Meta: "key", Value: "one", Id: 1},
+		{Index: SortedIndexName, Meta: "score", Value: "1.5", Id: 1},
+	}; !reflect.DeepEqual(report.Missing, expected) {
+		t.Fatalf("unexpected missing entries %v", report.Missing)
+	} else if len(report.Duplicated) != 1 || report.Duplicated[0].Index != SortedIndexName || report.Duplicated[0].Meta != "parity" {
+		t.Fatalf("unexpected duplicated entries %v", report.Duplicated)
+	}
+
+	records.RebuildMetaIndex()
+	checkMetaIndex(t, records)
+
+	if rec := records.FindByKey("one"); rec == nil || rec.Id != 1 {
+		t.Fatalf("unexpected record %v", rec)
+	} else if rec := records.FindByKey("two"); rec != nil {
+		t.Fatalf("unexpected record %v", rec)
+	}
+}
+
+func TestMetaIndexRebuildWhileLive(t *testing.T) {
+	records := setupTagged(t)
+	defer teardownRecords(t)
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			rec := &pb.Record{Data: []float32{1}, Meta: map[string]string{"parity": "odd"}}
+			if err := records.Create(rec); err != nil {
+				t.Error(err)
+				return
+			} else if i%2 == 0 {
+				records.Delete(rec.Id)
+			} else if err := records.Update(&pb.Record{Id: rec.Id, Meta: map[string]string{"parity": "even"}}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			records.RebuildMetaIndex()
+		}
+	}()
+
+	wg.Wait()
+
+	checkMetaIndex(t, records)
+	if found := records.FindBy("parity", "even"); len(found) != 55 {
+		t.Fatalf("expected 55 even records, got %d", len(found))
+	}
+}
@@ -2,10 +2,13 @@ package storage
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 
@@ -21,19 +24,26 @@ var (
 	// ErrRecordNotFound is the 404 of Sum, it is returned when the storage
 	// manager can't find an object mapped to the queried identifier.
 	ErrRecordNotFound = errors.New("record not found")
+	// ErrVersionConflict is returned when an object is being updated or
+	// deleted with an expected version that doesn't match the stored one.
+	ErrVersionConflict = errors.New("version conflict")
 
 	pathSep = string(os.PathSeparator)
 )
 
 // Index is a generic data structure used to map any types of protobuf
 // encoded messages to unique integer identifiers and persist them on
-// disk transparently.
+// disk transparently in an append only log of segment files.
 type Index struct {
 	sync.RWMutex
-	dataPath string
-	index    map[uint64]proto.Message
-	nextID   uint64
-	driver   Driver
+	dataPath    string
+	index       map[uint64]proto.Message
+	nextID      uint64
+	driver      Driver
+	segments    *segmentLog
+	pager       *pager
+	quarantined []string
+	loadTime    time.Duration
 }
 
 func (i *Index) GetNextId() uint64 {
@@ -44,6 +54,8 @@ func (i *Index) GetNextId() uint64 {
 
 // NOTE: pathSep is added if needed when the index object is created,
 // this spares us a third string concatenation or worse a Sprintf call.
+// Objects are not stored in their own data files anymore, this is only
+// used to locate legacy files that still need to be migrated.
 func (i *Index) pathForID(id uint64) string {
 	return i.dataPath + strconv.FormatUint(id, 10) + DatFileExt
 }
@@ -59,16 +71,104 @@ func WithDriver(dataPath string, driver Driver) *Index {
 	if !strings.HasSuffix(dataPath, pathSep) {
 		dataPath += pathSep
 	}
-	return &Index{
+	i := &Index{
 		dataPath: dataPath,
 		index:    make(map[uint64]proto.Message),
 		nextID:   1,
 		driver:   driver,
+		segments: newSegmentLog(dataPath),
+	}
+	// only objects with a payload can be paged
+	if _, ok := driver.(PayloadDriver); ok {
+		if budget := getCacheBudget(); budget > 0 {
+			i.pager = newPager(budget)
+		}
+	}
+	return i
+}
+
+func (i *Index) persist(record proto.Message) error {
+	recID := i.driver.GetID(record)
+	data, err := proto.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error while serializing object %d: %s", recID, err)
+	} else if err = i.segments.put(recID, data); err != nil {
+		return fmt.Errorf("error while saving object %d: %s", recID, err)
+	}
+	return nil
+}
+
+func (i *Index) unpersist(id uint64) {
+	if err := i.segments.del(id); err != nil {
+		log.Error("error while removing object %d: %s", id, err)
+	}
+}
+
+// deletions do not fail, if their tombstones can not be synced
+// the objects may come back after a power failure
+func (i *Index) syncDeletes() {
+	if err := i.segments.sync(); err != nil {
+		log.Error("error while syncing the deletions from %s: %s", i.dataPath, err)
+	}
+}
+
+// store maps the object in the index, if paging is enabled only its
+// stripped version is kept resident while the whole object is cached.
+func (i *Index) store(record proto.Message) {
+	recID := i.driver.GetID(record)
+	if i.pager == nil {
+		i.index[recID] = record
+		return
+	}
+
+	driver := i.driver.(PayloadDriver)
+	i.index[recID] = driver.Strip(record)
+	i.pager.add(recID, record, driver.PayloadSize(record))
+}
+
+// resident returns the whole object given its stripped version, paging
+// it in from disk if it is not cached.
+func (i *Index) resident(id uint64, stored proto.Message) (proto.Message, error) {
+	if i.pager == nil {
+		return stored, nil
+	} else if record, found := i.pager.get(id); found {
+		return record, nil
+	}
+
+	data, err := i.segments.get(id)
+	if err != nil {
+		return nil, fmt.Errorf("error while paging in object %d: %s", id, err)
+	}
+
+	record := i.driver.Make()
+	if err = proto.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("error while paging in object %d: %s", id, err)
+	}
+
+	i.pager.add(id, record, i.driver.(PayloadDriver).PayloadSize(record))
+	return record, nil
+}
+
+func (i *Index) add(record proto.Message) {
+	recID := i.driver.GetID(record)
+	if i.pager == nil {
+		i.index[recID] = record
+	} else {
+		// do not fill the cache while loading
+		i.index[recID] = i.driver.(PayloadDriver).Strip(record)
+	}
+	// objects are not loaded in order, so make sure the
+	// next identifier is greater than any loaded one.
+	if recID >= i.nextID {
+		i.nextID = recID + 1
 	}
 }
 
-// Load enumerates files in the data folder while deserializing them
-// and mapping them into the index by their identifiers.
+// Load replays the segments in the data folder while deserializing
+// objects and mapping them into the index by their identifiers, legacy
+// data files found in the same folder are migrated to the log.
+// Unreadable objects and files are moved to the quarantine folder
+// instead of aborting the whole loading process.
 func (i *Index) Load() error {
 	i.Lock()
 	defer i.Unlock()
@@ -76,39 +176,172 @@ func (i *Index) Load() error {
 	absPath, files, err := ListPath(i.dataPath)
 	if err != nil {
 		return err
+	} else if err = removeTempFiles(absPath); err != nil {
+		return err
 	}
 
 	i.dataPath = absPath + pathSep
 	i.nextID = 1
+	i.quarantined = nil
+
+	started := time.Now()
+	defer func() { i.loadTime = time.Since(started) }()
+
+	// objects are deserialized concurrently
+	mu := sync.Mutex{}
+	unreadable := make([]uint64, 0)
+	err = i.segments.load(absPath, func(id uint64, data []byte) error {
+		record := i.driver.Make()
+		if err := proto.Unmarshal(data, record); err != nil {
+			dest, qerr := QuarantineData(absPath, strconv.FormatUint(id, 10)+DatFileExt, data)
+			if qerr != nil {
+				return fmt.Errorf("error while quarantining object %d: %s", id, qerr)
+			}
+			log.Error("error while deserializing object %d (%s), moved to %s", id, err, dest)
+			mu.Lock()
+			defer mu.Unlock()
+			i.quarantined = append(i.quarantined, dest)
+			unreadable = append(unreadable, id)
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		i.add(record)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	i.quarantined = append(i.quarantined, i.segments.quarantined...)
+	for _, id := range unreadable {
+		i.unpersist(id)
+	}
+
+	// identifiers of deleted objects are never reused
+	if i.segments.highest >= i.nextID {
+		i.nextID = i.segments.highest + 1
+	}
+
 	if nfiles := len(files); nfiles > 0 {
-		log.Info("loading %d data files from %s ...", len(files), i.dataPath)
+		log.Info("migrating %d data files from %s ...", nfiles, i.dataPath)
+		fileNames := make([]string, 0, nfiles)
 		for _, fileName := range files {
-			record := i.driver.Make()
-			if err := Load(fileName, record); err != nil {
+			fileNames = append(fileNames, fileName)
+		}
+
+		// read in parallel, append to the log sequentially
+		loaded := make([]proto.Message, nfiles)
+		errs := make([]error, nfiles)
+		prog := startProgress("reading data files from "+i.dataPath, nfiles)
+		parallel(nfiles, func(n int) error {
+			defer prog.inc()
+			loaded[n] = i.driver.Make()
+			errs[n] = Load(fileNames[n], loaded[n])
+			return nil
+		})
+		prog.end()
+
+		migrated := make([]string, 0, nfiles)
+		for n, fileName := range fileNames {
+			record := loaded[n]
+			if err := errs[n]; err != nil {
+				dest, qerr := Quarantine(absPath, fileName)
+				if qerr != nil {
+					return fmt.Errorf("error while quarantining %s: %s", fileName, qerr)
+				}
+				log.Error("%s, moved to %s", err, dest)
+				i.quarantined = append(i.quarantined, dest)
+				continue
+			} else if err := i.persist(record); err != nil {
 				return err
 			}
-			recID := i.driver.GetID(record)
-			i.index[recID] = record
-			// the list 'files' returned by ListPath is not sorted,
-			// so if the last 2 loaded files have the last sequential ids (4 and 5 for example)
-			// the id will be increased with the second-last record but not with the last one.
-			if recID >= i.nextID {
-				i.nextID = recID + 1
+			i.add(record)
+			migrated = append(migrated, fileName)
+		}
+
+		// only remove the old files once the log is safely on disk
+		if err := i.segments.sync(); err != nil {
+			return err
+		}
+		for _, fileName := range migrated {
+			if err := os.Remove(fileName); err != nil {
+				return err
 			}
 		}
 	}
 
+	if nbad := len(i.quarantined); nbad > 0 {
+		log.Warning("%d unreadable objects in %s have been quarantined", nbad, i.dataPath)
+	}
+
+	log.Info("loaded %d objects from %s in %s", len(i.index), i.dataPath, time.Since(started))
+
 	return nil
 }
 
+// LoadTime returns how long the last Load took.
+func (i *Index) LoadTime() time.Duration {
+	i.RLock()
+	defer i.RUnlock()
+	return i.loadTime
+}
+
+// Quarantined returns the paths of the unreadable files that have
+// been moved to the quarantine folder during the last Load.
+func (i *Index) Quarantined() []string {
+	i.RLock()
+	defer i.RUnlock()
+	return append([]string(nil), i.quarantined...)
+}
+
+// Paged returns true if object payloads are paged in from disk
+// on demand instead of being kept in memory.
+func (i *Index) Paged() bool {
+	return i.pager != nil
+}
+
+// CacheStats returns the counters of the payloads cache.
+func (i *Index) CacheStats() CacheStats {
+	if i.pager == nil {
+		return CacheStats{}
+	}
+	return i.pager.stats()
+}
+
+// Compact reclaims the space used by updated and deleted objects by
+// copying live objects from the sealed segments at the tail of the
+// log and removing the old segment files.
+func (i *Index) Compact() error {
+	i.Lock()
+	defer i.Unlock()
+	return i.segments.compact()
+}
+
+func (i *Index) _close() error {
+	i.index = make(map[uint64]proto.Message)
+	return i.segments.close()
+}
+
+// Close unloads every object and closes the active segment file,
+// the index should not be used after this.
+func (i *Index) Close() error {
+	i.Lock()
+	defer i.Unlock()
+	return i._close()
+}
+
 // ForEach executes a callback passing as argument every
 // element of the index, it interrupts the loop if the
 // callback returns an error, the same error will be returned.
 func (i *Index) ForEach(cb func(record proto.Message) error) error {
 	i.RLock()
 	defer i.RUnlock()
-	for _, record := range i.index {
-		if err := cb(record); err != nil {
+	for id, record := range i.index {
+		record, err := i.resident(id, record)
+		if err != nil {
+			return err
+		} else if err := cb(record); err != nil {
 			return err
 		}
 	}
@@ -122,15 +355,30 @@ func (i *Index) Objects() []proto.Message {
 	defer i.RUnlock()
 
 	numObjects := len(i.index)
-	asSlice := make([]proto.Message, numObjects)
-	idx := 0
-	for _, record := range i.index {
-		asSlice[idx] = record
-		idx++
+	asSlice := make([]proto.Message, 0, numObjects)
+	for id, record := range i.index {
+		if record, err := i.resident(id, record); err != nil {
+			log.Error("%s", err)
+		} else {
+			asSlice = append(asSlice, record)
+		}
 	}
 	return asSlice
 }
 
+// Ids returns the sorted identifiers of the objects stored in this index.
+func (i *Index) Ids() []uint64 {
+	i.RLock()
+	defer i.RUnlock()
+
+	ids := make([]uint64, 0, len(i.index))
+	for id := range i.index {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	return ids
+}
+
 // Size returns the number of elements stored in this index.
 func (i *Index) Size() int {
 	i.RLock()
@@ -161,12 +409,14 @@ func (i *Index) Create(record proto.Message) error {
 	i.driver.SetID(record, recID)
 	if _, found := i.index[recID]; found {
 		return ErrInvalidID
-	} else if err := Flush(record, i.pathForID(recID)); err != nil {
+	} else if err := i.persist(record); err != nil {
+		return err
+	} else if err := i.segments.sync(); err != nil {
 		return err
 	}
 
 	i.nextID++
-	i.index[recID] = record
+	i.store(record)
 
 	return nil
 }
@@ -178,15 +428,25 @@ func (i *Index) CreateWithId(record proto.Message) error {
 	recID := i.driver.GetID(record)
 	if _, found := i.index[recID]; found {
 		return ErrInvalidID
-	} else if err := Flush(record, i.pathForID(recID)); err != nil {
+	} else if err := i.persist(record); err != nil {
+		return err
+	} else if err := i.segments.sync(); err != nil {
 		return err
 	}
 
-	i.index[recID] = record
+	i.store(record)
+	i.reserve(recID)
 
 	return nil
 }
 
+// makes sure the next identifier is greater than the given one
+func (i *Index) reserve(id uint64) {
+	if id >= i.nextID {
+		i.nextID = id + 1
+	}
+}
+
 func (i *Index) CreateManyWIthId(records []proto.Message) (err error) {
 	rollbackOnError := func(e *error) {
 		if *e == nil {
@@ -196,7 +456,10 @@ func (i *Index) CreateManyWIthId(records []proto.Message) (err error) {
 		for _, record := range records {
 			id := i.driver.GetID(record)
 			delete(i.index, id)
-			os.Remove(i.pathForID(id))
+			if i.pager != nil {
+				i.pager.remove(id)
+			}
+			i.unpersist(id)
 		}
 	}
 
@@ -206,14 +469,17 @@ func (i *Index) CreateManyWIthId(records []proto.Message) (err error) {
 	defer rollbackOnError(&err)
 
 	for _, record := range records {
-		id := i.driver.GetID(record)
-		if err = Flush(record, i.pathForID(id)); err != nil {
+		if err = i.persist(record); err != nil {
 			break
 		}
 
-		i.index[id] = record
+		i.store(record)
+		i.reserve(i.driver.GetID(record))
 	}
 
+	if err == nil {
+		err = i.segments.sync()
+	}
 	return
 }
 
@@ -228,10 +494,18 @@ func (i *Index) Update(record proto.Message) error {
 	stored, found := i.index[recID]
 	if !found {
 		return ErrRecordNotFound
+	} else if stored, err := i.resident(recID, stored); err != nil {
+		return err
 	} else if err := i.driver.Copy(stored, record); err != nil {
 		return err
+	} else if err := i.persist(stored); err != nil {
+		return err
+	} else if err := i.segments.sync(); err != nil {
+		return err
+	} else if i.pager != nil {
+		i.store(stored)
 	}
-	return Flush(stored, i.pathForID(recID))
+	return nil
 }
 
 // Find returns the instance of a stored object given its identifier,
@@ -241,29 +515,90 @@ func (i *Index) Find(id uint64) proto.Message {
 	defer i.RUnlock()
 
 	record, found := i.index[id]
-	if found {
+	if !found {
+		return nil
+	} else if record, err := i.resident(id, record); err != nil {
+		log.Error("%s", err)
+		return nil
+	} else {
 		return record
 	}
-	return nil
+}
+
+// Replace removes every stored object from the index and replaces them
+// with the given ones, the identifier of the next object created will be
+// the highest between nextID and the ones of the new objects.
+func (i *Index) Replace(objects []proto.Message, nextID uint64) error {
+	i.Lock()
+	defer i.Unlock()
+
+	for id := range i.index {
+		if err := i.segments.del(id); err != nil {
+			return err
+		}
+	}
+
+	i.index = make(map[uint64]proto.Message)
+	i.nextID = 1
+	if i.pager != nil {
+		i.pager.reset()
+	}
+	for _, record := range objects {
+		if err := i.persist(record); err != nil {
+			return err
+		}
+		i.add(record)
+	}
+
+	if nextID > i.nextID {
+		i.nextID = nextID
+		if err := i.segments.mark(nextID - 1); err != nil {
+			return err
+		}
+	}
+
+	return i.segments.sync()
 }
 
 // Delete removes a stored object from the index given its identifier,
 // it will return the removed object itself if found, or nil.
-// This operation will also remove the object data file from disk.
+// This operation will also append a tombstone for the object to the log.
 func (i *Index) Delete(id uint64) proto.Message {
+	record, _ := i.DeleteIf(id, nil)
+	return record
+}
+
+// DeleteIf removes a stored object from the index given its identifier
+// if the check callback, when not nil, doesn't return an error for it.
+// It returns ErrRecordNotFound if the object can not be found.
+func (i *Index) DeleteIf(id uint64, check func(record proto.Message) error) (proto.Message, error) {
 	i.Lock()
 	defer i.Unlock()
 
 	record, found := i.index[id]
 	if !found {
-		return nil
+		return nil, ErrRecordNotFound
+	} else if whole, err := i.resident(id, record); err != nil {
+		log.Error("%s", err)
+	} else {
+		record = whole
+	}
+
+	if check != nil {
+		if err := check(record); err != nil {
+			return record, err
+		}
 	}
 
 	delete(i.index, id)
+	if i.pager != nil {
+		i.pager.remove(id)
+	}
 
-	os.Remove(i.pathForID(id))
+	i.unpersist(id)
+	i.syncDeletes()
 
-	return record
+	return record, nil
 }
 
 // Delete multiple records at once
@@ -277,11 +612,19 @@ func (i *Index) DeleteMany(ids []uint64) []proto.Message {
 		record, found := i.index[id]
 		if !found {
 			continue
+		} else if whole, err := i.resident(id, record); err != nil {
+			log.Error("%s", err)
+		} else {
+			record = whole
 		}
 		delete(i.index, id)
-		os.Remove(i.pathForID(id))
+		if i.pager != nil {
+			i.pager.remove(id)
+		}
+		i.unpersist(id)
 		res = append(res, record)
 	}
+	i.syncDeletes()
 
 	return res
 }
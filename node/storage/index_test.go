@@ -359,6 +359,33 @@ func TestIndexDeleteRecord(t *testing.T) {
 	}
 }
 
+func TestIndexDeletedIdsAreNotReused(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownRecords(t)
+
+	i := setupIndex(testFolder)
+	if err := i.Load(); err != nil {
+		t.Fatal(err)
+	} else if m := i.Delete(testRecords); m == nil {
+		t.Fatalf("record %d not found", testRecords)
+	} else if err := i.CreateWithId(&pb.Record{Id: 100}); err != nil {
+		t.Fatal(err)
+	} else if next := i.GetNextId(); next != 101 {
+		t.Fatalf("expected next id 101 after creating record 100, got %d", next)
+	} else if m := i.Delete(100); m == nil {
+		t.Fatal("record 100 not found")
+	} else if err := i.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := setupIndex(testFolder)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	} else if next := reloaded.GetNextId(); next != 101 {
+		t.Fatalf("expected next id 101 after reloading, got %d", next)
+	}
+}
+
 func TestIndexDeleteRecordWithInvalidId(t *testing.T) {
 	setupRecords(t, false, false)
 	defer teardownRecords(t)
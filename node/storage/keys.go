@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+var (
+	// ErrKeyExists is returned when a record is created or updated with
+	// a key that another record already has.
+	ErrKeyExists = errors.New("key is not unique")
+	// ErrMissingKey is returned when upserting a record without a key.
+	ErrMissingKey = errors.New("record has no key")
+	// ErrClearKey is returned when updating a record with both a key
+	// and the flag to remove it.
+	ErrClearKey = errors.New("a key can not be both set and cleared")
+)
+
+// records with a key are created and updated one at a time, so that
+// checking the key and storing the record are atomic.
+func (r *Records) lockKey(key string) func() {
+	if key == "" {
+		return func() {}
+	}
+	r.keyLock.Lock()
+	return r.keyLock.Unlock
+}
+
+// returns an error if the key belongs to a record other than id
+func (r *Records) _checkKey(key string, id uint64) error {
+	if key == "" {
+		return nil
+	} else if owner, found := r.keys[key]; found && owner != id {
+		return fmt.Errorf("%s: %v (record %d)", key, ErrKeyExists, owner)
+	}
+	return nil
+}
+
+func (r *Records) checkKey(key string, id uint64) error {
+	r.RLock()
+	defer r.RUnlock()
+	return r._checkKey(key, id)
+}
+
+func (r *Records) _keyIndexCreate(rec *pb.Record) {
+	if rec.Key != "" {
+		r.keys[rec.Key] = rec.Id
+	}
+}
+
+func (r *Records) keyIndexCreate(rec *pb.Record) {
+	r.Lock()
+	defer r.Unlock()
+	r._keyIndexCreate(rec)
+}
+
+func (r *Records) _keyIndexRemove(id uint64, key string) {
+	if owner, found := r.keys[key]; found && owner == id {
+		delete(r.keys, key)
+	}
+}
+
+// FindByKey returns the record with the given key or nil if the
+// object can not be found. Expired records are never returned.
+func (r *Records) FindByKey(key string) *pb.Record {
+	r.RLock()
+	id, found := r.keys[key]
+	r.RUnlock()
+
+	if !found {
+		return nil
+	}
+	return r.Find(id)
+}
+
+// Upsert updates the record with the same key, or creates it if no record
+// has that key. It returns true if the record has been created. As for
+// updates, only the fields that are set replace the stored ones.
+func (r *Records) Upsert(record *pb.Record) (bool, error) {
+	if record.Key == "" {
+		return false, ErrMissingKey
+	}
+
+	r.keyLock.Lock()
+	defer r.keyLock.Unlock()
+
+	r.RLock()
+	id, found := r.keys[record.Key]
+	r.RUnlock()
+
+	if found {
+		record.Id = id
+		return false, r.update(record)
+	}
+
+	record.Id = 0
+	return true, r.create(record)
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func TestRecordsKeys(t *testing.T) {
+	setupRecords(t, false, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := &pb.Record{Data: []float32{1}, Key: "first"}
+	if err := records.Create(first); err != nil {
+		t.Fatal(err)
+	} else if found := records.FindByKey("first"); found == nil || found.Id != first.Id {
+		t.Fatalf("unexpected record for key first: %v", found)
+	} else if records.FindByKey("nope") != nil {
+		t.Fatal("unexpected record for unknown key")
+	} else if err := records.Create(&pb.Record{Data: []float32{2}, Key: "first"}); err == nil {
+		t.Fatal("expected error for duplicated key")
+	} else if err := records.CreateWithId(&pb.Record{Id: 100, Data: []float32{2}, Key: "first"}); err == nil {
+		t.Fatal("expected error for duplicated key")
+	}
+
+	second := &pb.Record{Data: []float32{2}, Key: "second"}
+	if err := records.Create(second); err != nil {
+		t.Fatal(err)
+	} else if err := records.Update(&pb.Record{Id: second.Id, Key: "first"}); err == nil {
+		t.Fatal("expected error for update to a duplicated key")
+	} else if err := records.Update(&pb.Record{Id: second.Id, Key: "renamed", Data: []float32{3}}); err != nil {
+		t.Fatal(err)
+	} else if records.FindByKey("second") != nil {
+		t.Fatal("the old key should have been removed")
+	} else if found := records.FindByKey("renamed"); found == nil || found.Id != second.Id {
+		t.Fatalf("unexpected record for key renamed: %v", found)
+	} else if err := records.Update(&pb.Record{Id: second.Id, Data: []float32{4}}); err != nil {
+		t.Fatal(err)
+	} else if found := records.FindByKey("renamed"); found == nil || found.Data[0] != 4 {
+		t.Fatalf("updates without a key should keep it: %v", found)
+	}
+
+	// deleted keys can be used again, but the record can't be undeleted then
+	if records.Delete(second.Id) == nil {
+		t.Fatal("record not found")
+	} else if records.FindByKey("renamed") != nil {
+		t.Fatal("the key of a deleted record should have been removed")
+	} else if err := records.Create(&pb.Record{Data: []float32{5}, Key: "renamed"}); err != nil {
+		t.Fatal(err)
+	} else if _, err := records.Undelete([]uint64{second.Id}); err == nil {
+		t.Fatal("expected error for undeleting a record with a duplicated key")
+	}
+
+	batch := []*pb.Record{{Id: 200, Data: []float32{1}, Key: "dup"}, {Id: 201, Data: []float32{1}, Key: "dup"}}
+	if err := records.CreateManyWIthId(batch); err == nil {
+		t.Fatal("expected error for duplicated keys in a batch")
+	} else if err := records.CreateManyWIthId([]*pb.Record{{Id: 202, Data: []float32{1}, Key: "first"}}); err == nil {
+		t.Fatal("expected error for a batch with an existing key")
+	} else if err := records.CreateManyWIthId([]*pb.Record{{Id: first.Id, Data: []float32{1}, Key: "overwritten"}}); err != nil {
+		t.Fatal(err)
+	} else if records.FindByKey("first") != nil || records.FindByKey("overwritten") == nil {
+		t.Fatal("overwriting a record should replace its key")
+	}
+
+	if err := records.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	} else if found := reloaded.FindByKey("overwritten"); found == nil || found.Id != first.Id {
+		t.Fatalf("unexpected record for key overwritten after reload: %v", found)
+	}
+}
+
+func TestRecordsClearKey(t *testing.T) {
+	setupRecords(t, false, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyed := &pb.Record{Data: []float32{1}, Key: "keyed"}
+	if err := records.Create(keyed); err != nil {
+		t.Fatal(err)
+	} else if err := records.Update(&pb.Record{Id: keyed.Id, Key: "other", ClearKey: true}); err != ErrClearKey {
+		t.Fatalf("expected ErrClearKey, got %v", err)
+	} else if err := records.Update(&pb.Record{Id: keyed.Id, ClearKey: true}); err != nil {
+		t.Fatal(err)
+	} else if records.FindByKey("keyed") != nil {
+		t.Fatal("the cleared key should have been removed")
+	} else if stored := records.Find(keyed.Id); stored.Key != "" || stored.ClearKey {
+		t.Fatalf("unexpected record after clearing its key: %v", stored)
+	} else if err := records.Create(&pb.Record{Data: []float32{2}, Key: "keyed"}); err != nil {
+		t.Fatalf("the cleared key should be available again: %v", err)
+	}
+
+	// the key is still cleared after a reload
+	reloaded, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	} else if stored := reloaded.Find(keyed.Id); stored.Key != "" {
+		t.Fatalf("unexpected key %s after reload", stored.Key)
+	}
+}
+
+func TestRecordsUpsert(t *testing.T) {
+	setupRecords(t, false, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := records.Upsert(&pb.Record{Data: []float32{1}}); err != ErrMissingKey {
+		t.Fatalf("expected %v, got %v", ErrMissingKey, err)
+	}
+
+	created, err := records.Upsert(&pb.Record{Data: []float32{1}, Meta: map[string]string{"a": "b"}, Key: "k"})
+	if err != nil {
+		t.Fatal(err)
+	} else if !created {
+		t.Fatal("expected the record to be created")
+	}
+
+	id := records.FindByKey("k").Id
+	update := &pb.Record{Data: []float32{2}, Key: "k"}
+	if created, err := records.Upsert(update); err != nil {
+		t.Fatal(err)
+	} else if created {
+		t.Fatal("expected the record to be updated")
+	} else if update.Id != id {
+		t.Fatalf("expected id %d, got %d", id, update.Id)
+	} else if stored := records.Find(id); stored.Data[0] != 2 || stored.Meta["a"] != "b" || stored.Version != 2 {
+		t.Fatalf("unexpected record after upsert %v", stored)
+	} else if records.Size() != 1 {
+		t.Fatalf("expected 1 record, got %d", records.Size())
+	}
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// maximum size of a line of meta data
+const metaLineMaxSize = 16 * 1024 * 1024
+
+// MetaLinesReader reads meta data from JSON lines, one object per record.
+// Strings and booleans are stored as meta, numbers as typed meta and null
+// values are ignored.
+type MetaLinesReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+// NewMetaLinesReader returns a reader of the JSON lines of r.
+func NewMetaLinesReader(r io.Reader) *MetaLinesReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), metaLineMaxSize)
+	return &MetaLinesReader{scanner: scanner}
+}
+
+// Rows returns the number of lines read so far.
+func (m *MetaLinesReader) Rows() int {
+	return m.row
+}
+
+// Next sets the meta data of the record from the next line, it returns
+// io.EOF if there are no more lines.
+func (m *MetaLinesReader) Next(rec *pb.Record) error {
+	var line []byte
+	for len(line) == 0 {
+		if !m.scanner.Scan() {
+			if err := m.scanner.Err(); err != nil {
+				return err
+			}
+			return io.EOF
+		}
+		line = bytes.TrimSpace(m.scanner.Bytes())
+	}
+
+	values := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return fmt.Errorf("meta data of row %d: %s", m.row, err)
+	}
+
+	for key, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			if rec.Meta == nil {
+				rec.Meta = make(map[string]string)
+			}
+			rec.Meta[key] = v
+		case bool:
+			if rec.Meta == nil {
+				rec.Meta = make(map[string]string)
+			}
+			rec.Meta[key] = fmt.Sprintf("%t", v)
+		case json.Number:
+			num, err := v.Float64()
+			if err != nil {
+				return fmt.Errorf("meta data of row %d: %s", m.row, err)
+			}
+			if rec.TypedMeta == nil {
+				rec.TypedMeta = make(map[string]*pb.MetaValue)
+			}
+			rec.TypedMeta[key] = &pb.MetaValue{Value: &pb.MetaValue_Number{Number: num}}
+		default:
+			return fmt.Errorf("meta data of row %d: unsupported value for %s", m.row, key)
+		}
+	}
+
+	m.row++
+	return nil
+}
+
+// ReadMetaLines sets the meta data of the records from JSON lines, one
+// object per record in the same order.
+func ReadMetaLines(r io.Reader, records []*pb.Record) error {
+	m := NewMetaLinesReader(r)
+	for _, rec := range records {
+		if err := m.Next(rec); err == io.EOF {
+			return fmt.Errorf("the meta data has %d lines instead of one for each of the %d rows of the array", m.Rows(), len(records))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if err := m.Next(&pb.Record{}); err == nil {
+		return fmt.Errorf("the meta data has more lines than the %d rows of the array", len(records))
+	} else if err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// WriteMetaLines writes the meta data of the records as JSON lines, one
+// object per record in the same order. Typed numbers are written as
+// numbers and times as RFC3339 strings.
+func WriteMetaLines(w io.Writer, records []*pb.Record) error {
+	return WriteMetaLinesFrom(w, RecordsIterator(records))
+}
+
+// WriteMetaLinesFrom writes the meta data of the records returned by next
+// as JSON lines, until it returns io.EOF.
+func WriteMetaLinesFrom(w io.Writer, next func() (*pb.Record, error)) error {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	for {
+		rec, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		values := make(map[string]interface{}, len(rec.Meta)+len(rec.TypedMeta))
+		for key, value := range rec.Meta {
+			values[key] = value
+		}
+		for key, value := range rec.TypedMeta {
+			switch v := value.GetValue().(type) {
+			case *pb.MetaValue_Text:
+				values[key] = v.Text
+			case *pb.MetaValue_Number:
+				values[key] = v.Number
+			case *pb.MetaValue_Time:
+				values[key] = time.Unix(0, v.Time).UTC().Format(time.RFC3339Nano)
+			}
+		}
+
+		if err := encoder.Encode(values); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func TestReadMetaLines(t *testing.T) {
+	records := []*pb.Record{{}, {}, {}}
+	lines := `{"label": "cat", "score": 0.5, "cute": true}
+
+{}
+{"label": "dog", "missing": null}
+`
+	if err := ReadMetaLines(strings.NewReader(lines), records); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := map[string]string{"label": "cat", "cute": "true"}; !reflect.DeepEqual(records[0].Meta, expected) {
+		t.Fatalf("expected %v, got %v", expected, records[0].Meta)
+	} else if records[0].TypedMeta["score"].GetNumber() != 0.5 {
+		t.Fatalf("unexpected typed meta %v", records[0].TypedMeta)
+	} else if records[1].Meta != nil || records[1].TypedMeta != nil {
+		t.Fatalf("unexpected meta for empty object %v", records[1])
+	} else if expected := map[string]string{"label": "dog"}; !reflect.DeepEqual(records[2].Meta, expected) {
+		t.Fatalf("expected %v, got %v", expected, records[2].Meta)
+	}
+
+	tests := map[string]string{
+		"too few lines":  "{}\n{}\n",
+		"too many lines": "{}\n{}\n{}\n{}\n",
+		"not json":       "{}\nnope\n{}\n",
+		"nested object":  "{}\n{\"a\": {\"b\": 1}}\n{}\n",
+	}
+	for name, lines := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := ReadMetaLines(strings.NewReader(lines), []*pb.Record{{}, {}, {}}); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestWriteMetaLines(t *testing.T) {
+	records := []*pb.Record{
+		{Meta: map[string]string{"label": "cat"}, TypedMeta: map[string]*pb.MetaValue{
+			"score": {Value: &pb.MetaValue_Number{Number: 0.5}},
+			"seen":  {Value: &pb.MetaValue_Time{Time: 0}},
+		}},
+		{},
+	}
+
+	buf := bytes.Buffer{}
+	if err := WriteMetaLines(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"label":"cat","score":0.5,"seen":"1970-01-01T00:00:00Z"}` + "\n{}\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+
+	// numbers are read back as typed meta
+	read := []*pb.Record{{}, {}}
+	if err := ReadMetaLines(&buf, read); err != nil {
+		t.Fatal(err)
+	} else if read[0].TypedMeta["score"].GetNumber() != 0.5 || read[0].Meta["label"] != "cat" {
+		t.Fatalf("unexpected meta %v", read[0])
+	}
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const npyMagic = "\x93NUMPY"
+
+var (
+	npyDescrParser   = regexp.MustCompile(`['"]descr['"]\s*:\s*['"]([^'"]+)['"]`)
+	npyFortranParser = regexp.MustCompile(`['"]fortran_order['"]\s*:\s*(True|False)`)
+	npyShapeParser   = regexp.MustCompile(`['"]shape['"]\s*:\s*\(([^)]*)\)`)
+)
+
+// parses a numpy type descriptor such as <f4
+func npyDtype(descr string) (dtype, error) {
+	if len(descr) < 3 {
+		return dtype{}, fmt.Errorf("unsupported numpy type %s", descr)
+	}
+
+	t := dtype{kind: descr[1], order: binary.LittleEndian}
+	switch descr[0] {
+	case '<', '|', '=':
+	case '>':
+		t.order = binary.BigEndian
+	default:
+		return dtype{}, fmt.Errorf("unsupported numpy type %s", descr)
+	}
+
+	size, err := strconv.Atoi(descr[2:])
+	if err != nil || !strings.ContainsRune("fiub", rune(t.kind)) {
+		return dtype{}, fmt.Errorf("unsupported numpy type %s", descr)
+	}
+	t.size = size
+	return t, nil
+}
+
+// converts the data of an array in column major order to row major order
+func fromFortranOrder(shape []uint64, data []float32) []float32 {
+	out := make([]float32, len(data))
+	index := make([]uint64, len(shape))
+	for f := range data {
+		// index of the element in the c ordered array
+		c := uint64(0)
+		for i := range shape {
+			c = c*shape[i] + index[i]
+		}
+		out[c] = data[f]
+
+		// the first index changes the fastest
+		for i := range index {
+			if index[i]++; index[i] < shape[i] {
+				break
+			}
+			index[i] = 0
+		}
+	}
+	return out
+}
+
+// reads the header of a npy file, leaving r at the start of its data
+func readNpy(r io.Reader) (*arrayData, error) {
+	head := make([]byte, len(npyMagic)+2)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, fmt.Errorf("error while reading the npy header: %s", err)
+	} else if string(head[:len(npyMagic)]) != npyMagic {
+		return nil, fmt.Errorf("not a npy file")
+	}
+
+	var headerSize uint32
+	switch major := head[len(npyMagic)]; major {
+	case 1:
+		var size uint16
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, err
+		}
+		headerSize = uint32(size)
+	case 2, 3:
+		if err := binary.Read(r, binary.LittleEndian, &headerSize); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported npy version %d", major)
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("error while reading the npy header: %s", err)
+	}
+
+	descr := npyDescrParser.FindSubmatch(header)
+	fortran := npyFortranParser.FindSubmatch(header)
+	shapeDef := npyShapeParser.FindSubmatch(header)
+	if descr == nil || fortran == nil || shapeDef == nil {
+		return nil, fmt.Errorf("unexpected npy header %q", header)
+	}
+
+	t, err := npyDtype(string(descr[1]))
+	if err != nil {
+		return nil, err
+	}
+
+	d := &arrayData{shape: make([]uint64, 0), t: t, fortran: string(fortran[1]) == "True", r: r}
+	for _, dim := range strings.Split(string(shapeDef[1]), ",") {
+		if dim = strings.TrimSpace(dim); dim == "" {
+			continue
+		} else if n, err := strconv.ParseUint(dim, 10, 64); err != nil {
+			return nil, fmt.Errorf("unexpected npy shape (%s)", shapeDef[1])
+		} else {
+			d.shape = append(d.shape, n)
+		}
+	}
+
+	if _, err := numElements(d.shape); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func writeNpy(w io.Writer, shape []uint64, data func(w io.Writer) error) error {
+	dims := make([]string, len(shape))
+	for i, dim := range shape {
+		dims[i] = strconv.FormatUint(dim, 10)
+	}
+	shapeDef := strings.Join(dims, ", ")
+	if len(dims) == 1 {
+		shapeDef += ","
+	}
+
+	header := bytes.NewBufferString(fmt.Sprintf("{'descr': '<f4', 'fortran_order': False, 'shape': (%s), }", shapeDef))
+	// the data must be aligned to 64 bytes and the header end with a new line
+	for (len(npyMagic)+4+header.Len()+1)%64 != 0 {
+		header.WriteByte(' ')
+	}
+	header.WriteByte('\n')
+
+	if header.Len() > 0xffff {
+		return fmt.Errorf("array of shape %v has a too big npy header", shape)
+	}
+
+	buf := bufio.NewWriter(w)
+	buf.WriteString(npyMagic)
+	buf.Write([]byte{1, 0})
+	binary.Write(buf, binary.LittleEndian, uint16(header.Len()))
+	buf.Write(header.Bytes())
+	if err := data(buf); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// builds a npy file the way numpy does
+func makeNpy(version byte, header string, data interface{}) []byte {
+	buf := bytes.Buffer{}
+	buf.WriteString(npyMagic)
+	buf.Write([]byte{version, 0})
+	if version == 1 {
+		binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	} else {
+		binary.Write(&buf, binary.LittleEndian, uint32(len(header)))
+	}
+	buf.WriteString(header)
+	binary.Write(&buf, binary.LittleEndian, data)
+	return buf.Bytes()
+}
+
+func TestReadNpy(t *testing.T) {
+	tests := []struct {
+		name     string
+		npy      []byte
+		expected *Array
+	}{
+		{
+			"float64",
+			makeNpy(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (2, 2), }\n", []float64{1.5, 2, 3, 4}),
+			&Array{Shape: []uint64{2, 2}, Data: []float32{1.5, 2, 3, 4}},
+		},
+		{
+			"fortran order",
+			makeNpy(1, "{'descr': '<f4', 'fortran_order': True, 'shape': (2, 3), }\n", []float32{1, 4, 2, 5, 3, 6}),
+			&Array{Shape: []uint64{2, 3}, Data: []float32{1, 2, 3, 4, 5, 6}},
+		},
+		{
+			"int8",
+			makeNpy(1, "{'descr': '|i1', 'fortran_order': False, 'shape': (1, 3), }\n", []int8{-1, 0, 1}),
+			&Array{Shape: []uint64{1, 3}, Data: []float32{-1, 0, 1}},
+		},
+		{
+			"big endian int16",
+			makeNpy(1, "{'descr': '>i2', 'fortran_order': False, 'shape': (1, 2), }\n", []byte{0xff, 0xfe, 0x01, 0x00}),
+			&Array{Shape: []uint64{1, 2}, Data: []float32{-2, 256}},
+		},
+		{
+			"float16 version 2",
+			makeNpy(2, "{'descr': '<f2', 'fortran_order': False, 'shape': (1, 2), }\n", []uint16{0x3c00, 0xc000}),
+			&Array{Shape: []uint64{1, 2}, Data: []float32{1, -2}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := bytes.NewReader(test.npy)
+			if a, err := ReadArray(r, r.Size(), pb.ArrayHeader_NPY, ""); err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(a, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, a)
+			}
+		})
+	}
+}
+
+func TestReadNpyErrors(t *testing.T) {
+	tests := map[string][]byte{
+		"not a npy":   []byte("hello world"),
+		"bad type":    makeNpy(1, "{'descr': '<c8', 'fortran_order': False, 'shape': (1, 1), }\n", []float64{1}),
+		"bad header":  makeNpy(1, "{'descr': '<f4'}\n", []float32{1}),
+		"short data":  makeNpy(1, "{'descr': '<f4', 'fortran_order': False, 'shape': (2, 2), }\n", []float32{1, 2}),
+		"bad version": makeNpy(9, "{'descr': '<f4', 'fortran_order': False, 'shape': (1,), }\n", []float32{1}),
+	}
+
+	for name, npy := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := bytes.NewReader(npy)
+			if _, err := ReadArray(r, r.Size(), pb.ArrayHeader_NPY, ""); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestWriteNpy(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := WriteArray(&buf, pb.ArrayHeader_NPY, "", &Array{Shape: []uint64{3}, Data: []float32{1, 2, math.MaxFloat32}}); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := buf.Bytes()
+	headerSize := int(binary.LittleEndian.Uint16(raw[8:10]))
+	header := string(raw[10 : 10+headerSize])
+	if (10+headerSize)%64 != 0 {
+		t.Fatalf("data is not aligned to 64 bytes: %d", 10+headerSize)
+	} else if header[len(header)-1] != '\n' {
+		t.Fatal("header should end with a new line")
+	} else if !bytes.Contains(raw, []byte("'shape': (3,)")) {
+		t.Fatalf("unexpected header %q", header)
+	} else if len(raw) != 10+headerSize+12 {
+		t.Fatalf("unexpected size %d", len(raw))
+	}
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const npyFileExt = ".npy"
+
+func readNpz(r io.ReaderAt, size int64, name string) (*arrayData, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading the npz archive: %s", err)
+	}
+
+	names := make([]string, 0)
+	var selected, last *zip.File
+	for _, f := range archive.File {
+		if !strings.HasSuffix(f.Name, npyFileExt) {
+			continue
+		}
+
+		last = f
+		arrayName := strings.TrimSuffix(f.Name, npyFileExt)
+		names = append(names, arrayName)
+		if arrayName == name {
+			selected = f
+		}
+	}
+
+	if name == "" && len(names) == 1 {
+		selected = last
+	}
+
+	if selected == nil {
+		if name == "" {
+			return nil, fmt.Errorf("the archive has %d arrays, choose one of: %s", len(names), strings.Join(names, ", "))
+		}
+		return nil, fmt.Errorf("array %s not found, choose one of: %s", name, strings.Join(names, ", "))
+	}
+
+	fp, err := selected.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := readNpy(fp)
+	if err != nil {
+		fp.Close()
+		return nil, err
+	}
+	d.closer = fp
+	return d, nil
+}
+
+// arrays are stored uncompressed like numpy.savez does
+func writeNpz(w io.Writer, name string, shape []uint64, data func(w io.Writer) error) error {
+	archive := zip.NewWriter(w)
+	fp, err := archive.CreateHeader(&zip.FileHeader{Name: name + npyFileExt, Method: zip.Store})
+	if err != nil {
+		return err
+	} else if err = writeNpy(fp, shape, data); err != nil {
+		return err
+	}
+	return archive.Close()
+}
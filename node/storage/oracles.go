@@ -2,6 +2,7 @@ package storage
 
 import (
 	pb "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
 )
 
 // Oracles is specialized version of a storage.Index
@@ -42,3 +43,13 @@ func (o *Oracles) Delete(id uint64) *pb.Oracle {
 	}
 	return nil
 }
+
+// Replace removes every stored pb.Oracle and replaces them
+// with the given ones.
+func (o *Oracles) Replace(oracles []*pb.Oracle, nextID uint64) error {
+	arg := make([]proto.Message, 0, len(oracles))
+	for _, o := range oracles {
+		arg = append(arg, o)
+	}
+	return o.Index.Replace(arg, nextID)
+}
@@ -112,10 +112,16 @@ func TestLoadOraclesWithCorruptedData(t *testing.T) {
 		t.Fatal("expected error")
 	} else if oracles != nil {
 		t.Fatal("expected no storage loaded")
-	} else if oracles, err := LoadOracles(testFolder); err == nil {
-		t.Fatal("expected error due to broken oracle dat file")
-	} else if oracles != nil {
-		t.Fatal("expected no storage loaded due to corrupted oracle dat file")
+	} else if oracles, err := LoadOracles(testFolder); err != nil {
+		t.Fatal(err)
+	} else if oracles.Size() != testOracles {
+		t.Fatalf("expected %d oracles, got %d", testOracles, oracles.Size())
+	} else if bad := oracles.Quarantined(); len(bad) != 1 {
+		t.Fatalf("expected 1 quarantined file, got %d", len(bad))
+	} else if _, err := os.Stat(testCorruptedOracle); err == nil {
+		t.Fatal("expected corrupted oracle dat file to be moved")
+	} else if _, err := os.Stat(bad[0]); err != nil {
+		t.Fatal(err)
 	}
 }
 
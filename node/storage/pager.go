@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	cacheLock   = sync.RWMutex{}
+	cacheBudget = int64(0)
+)
+
+// SetCacheBudget sets the maximum number of bytes of object payloads
+// that indexes created from now on will keep in memory, payloads over
+// this budget are evicted and paged in from disk when needed. A budget
+// of 0 disables paging and keeps every object in memory.
+func SetCacheBudget(budget int64) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	cacheBudget = budget
+}
+
+func getCacheBudget() int64 {
+	cacheLock.RLock()
+	defer cacheLock.RUnlock()
+	return cacheBudget
+}
+
+// CacheStats holds the counters of the payloads cache of an index.
+type CacheStats struct {
+	Budget    uint64
+	Used      uint64
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type pagerEntry struct {
+	id     uint64
+	object proto.Message
+	size   int64
+}
+
+// pager is a LRU cache of the objects of an index whose payload has
+// been stripped from memory.
+type pager struct {
+	sync.Mutex
+	budget    int64
+	used      int64
+	entries   map[uint64]*list.Element
+	lru       *list.List
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func newPager(budget int64) *pager {
+	return &pager{
+		budget:  budget,
+		entries: make(map[uint64]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (p *pager) get(id uint64) (proto.Message, bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	if elem, found := p.entries[id]; found {
+		p.hits++
+		p.lru.MoveToFront(elem)
+		return elem.Value.(*pagerEntry).object, true
+	}
+
+	p.misses++
+	return nil, false
+}
+
+func (p *pager) _remove(elem *list.Element) {
+	entry := elem.Value.(*pagerEntry)
+	p.used -= entry.size
+	p.lru.Remove(elem)
+	delete(p.entries, entry.id)
+}
+
+func (p *pager) add(id uint64, object proto.Message, size int64) {
+	p.Lock()
+	defer p.Unlock()
+
+	if elem, found := p.entries[id]; found {
+		p._remove(elem)
+	}
+
+	p.entries[id] = p.lru.PushFront(&pagerEntry{
+		id:     id,
+		object: object,
+		size:   size,
+	})
+	p.used += size
+
+	// the object just added is always kept
+	for p.used > p.budget && p.lru.Len() > 1 {
+		p._remove(p.lru.Back())
+		p.evictions++
+	}
+}
+
+func (p *pager) remove(id uint64) {
+	p.Lock()
+	defer p.Unlock()
+
+	if elem, found := p.entries[id]; found {
+		p._remove(elem)
+	}
+}
+
+func (p *pager) reset() {
+	p.Lock()
+	defer p.Unlock()
+
+	p.entries = make(map[uint64]*list.Element)
+	p.lru.Init()
+	p.used = 0
+}
+
+func (p *pager) stats() CacheStats {
+	p.Lock()
+	defer p.Unlock()
+
+	return CacheStats{
+		Budget:    uint64(p.budget),
+		Used:      uint64(p.used),
+		Hits:      p.hits,
+		Misses:    p.misses,
+		Evictions: p.evictions,
+	}
+}
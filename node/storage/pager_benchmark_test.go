@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"testing"
+)
+
+func benchmarkPagedFind(b *testing.B, budget int64) {
+	records := setupPaged(b, budget)
+	defer teardownPaged(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id := uint64(i%testRecords) + 1
+		if record := records.Find(id); record == nil {
+			b.Fatalf("record with id %d not found", id)
+		}
+	}
+}
+
+func BenchmarkPagedRecordsFindHit(b *testing.B) {
+	benchmarkPagedFind(b, testRecords*testPayloadSize)
+}
+
+func BenchmarkPagedRecordsFindMiss(b *testing.B) {
+	benchmarkPagedFind(b, testPayloadSize)
+}
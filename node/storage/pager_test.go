@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
+)
+
+// every test record has a payload of 3 float32
+const testPayloadSize = 12
+
+func setupPaged(t testing.TB, budget int64) *Records {
+	setupRecords(t, true, false)
+	SetCacheBudget(budget)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	} else if !records.Paged() {
+		t.Fatal("expected records to be paged")
+	}
+	return records
+}
+
+func teardownPaged(t testing.TB) {
+	SetCacheBudget(0)
+	teardownRecords(t)
+}
+
+func TestPagerLRU(t *testing.T) {
+	p := newPager(30)
+
+	p.add(1, &pb.Record{Id: 1}, 10)
+	p.add(2, &pb.Record{Id: 2}, 10)
+	p.add(3, &pb.Record{Id: 3}, 10)
+
+	// 1 becomes the most recently used
+	if _, found := p.get(1); !found {
+		t.Fatal("expected object 1 to be cached")
+	}
+
+	p.add(4, &pb.Record{Id: 4}, 10)
+	if _, found := p.get(2); found {
+		t.Fatal("expected object 2 to be evicted")
+	} else if _, found := p.get(1); !found {
+		t.Fatal("expected object 1 to be cached")
+	}
+
+	stats := p.stats()
+	if stats.Used != 30 {
+		t.Fatalf("expected 30 bytes used, got %d", stats.Used)
+	} else if stats.Hits != 2 || stats.Misses != 1 || stats.Evictions != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// objects bigger than the budget are still added
+	p.add(5, &pb.Record{Id: 5}, 100)
+	if _, found := p.get(5); !found {
+		t.Fatal("expected object 5 to be cached")
+	} else if stats := p.stats(); stats.Used != 100 || len(p.entries) != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	p.remove(5)
+	if stats := p.stats(); stats.Used != 0 || p.lru.Len() != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestPagedRecordsLoad(t *testing.T) {
+	records := setupPaged(t, 2*testPayloadSize)
+	defer teardownPaged(t)
+
+	if records.Size() != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, records.Size())
+	}
+
+	for id, m := range records.index {
+		if stub := m.(*pb.Record); stub.Data != nil {
+			t.Fatalf("record %d payload should not be resident", id)
+		} else if !reflect.DeepEqual(stub.Meta, testRecord.Meta) {
+			t.Fatalf("record %d meta should be resident", id)
+		}
+	}
+
+	if stats := records.CacheStats(); stats.Used != 0 || stats.Budget != 2*testPayloadSize {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestPagedRecordsFind(t *testing.T) {
+	records := setupPaged(t, 2*testPayloadSize)
+	defer teardownPaged(t)
+
+	for id := uint64(1); id <= testRecords; id++ {
+		testRecord.Id = id
+		if record := records.Find(id); record == nil {
+			t.Fatalf("record %d not found", id)
+		} else if !sameRecord(*record, testRecord) {
+			t.Fatal("records should match")
+		}
+	}
+
+	stats := records.CacheStats()
+	if stats.Misses != testRecords {
+		t.Fatalf("expected %d misses, got %d", testRecords, stats.Misses)
+	} else if stats.Evictions != testRecords-2 {
+		t.Fatalf("expected %d evictions, got %d", testRecords-2, stats.Evictions)
+	} else if stats.Used > stats.Budget {
+		t.Fatalf("cache is over budget: %+v", stats)
+	}
+
+	// the last record is still cached
+	if records.Find(testRecords) == nil {
+		t.Fatal("record not found")
+	} else if hits := records.CacheStats().Hits; hits != 1 {
+		t.Fatalf("expected 1 hit, got %d", hits)
+	}
+}
+
+func TestPagedRecordsForEach(t *testing.T) {
+	records := setupPaged(t, testPayloadSize)
+	defer teardownPaged(t)
+
+	seen := 0
+	err := records.ForEach(func(m proto.Message) error {
+		if r := m.(*pb.Record); !reflect.DeepEqual(r.Data, testRecord.Data) {
+			t.Fatalf("unexpected data for record %d: %v", r.Id, r.Data)
+		}
+		seen++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if seen != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, seen)
+	} else if objs := records.Objects(); len(objs) != testRecords {
+		t.Fatalf("expected %d objects, got %d", testRecords, len(objs))
+	}
+}
+
+func TestPagedRecordsUpdateAndDelete(t *testing.T) {
+	records := setupPaged(t, testPayloadSize)
+	defer teardownPaged(t)
+
+	updatedRecord.Id = 1
+	if err := records.Update(&updatedRecord); err != nil {
+		t.Fatal(err)
+	}
+
+	// push the updated record out of the cache
+	records.Find(2)
+	records.Find(3)
+
+	if record := records.Find(1); record == nil {
+		t.Fatal("record not found")
+	} else if !sameRecord(*record, updatedRecord) {
+		t.Fatal("records should match")
+	} else if found := records.FindBy("555", "555"); len(found) != 1 || found[0].Data == nil {
+		t.Fatalf("unexpected meta lookup result %v", found)
+	}
+
+	if deleted := records.Delete(2); deleted == nil {
+		t.Fatal("expected record to be deleted")
+	} else if !reflect.DeepEqual(deleted.Data, testRecord.Data) {
+		t.Fatalf("unexpected deleted data %v", deleted.Data)
+	} else if records.Find(2) != nil {
+		t.Fatal("deleted record found")
+	}
+
+	if err := records.Create(&pb.Record{Data: []float32{1, 2, 3}}); err != nil {
+		t.Fatal(err)
+	} else if stats := records.CacheStats(); stats.Used > stats.Budget {
+		t.Fatalf("cache is over budget: %+v", stats)
+	}
+}
+
+func TestPagedRecordsSnapshot(t *testing.T) {
+	records := setupPaged(t, testPayloadSize)
+	defer teardownPaged(t)
+
+	oraclesPath := filepath.Join(testFolder, "oracles")
+	if err := os.MkdirAll(oraclesPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	oracles, err := LoadOracles(oraclesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := bytes.Buffer{}
+	if _, err := WriteSnapshot(&buf, records, oracles, nil); err != nil {
+		t.Fatal(err)
+	} else if snap, err := ReadSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	} else if len(snap.Records) != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, len(snap.Records))
+	} else {
+		for _, r := range snap.Records {
+			if !reflect.DeepEqual(r.Data, testRecord.Data) {
+				t.Fatalf("record %d payload missing from snapshot", r.Id)
+			}
+		}
+	}
+}
+
+func TestPagedRecordsWithArena(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownArena(t)
+	defer teardownPaged(t)
+
+	SetCacheBudget(testPayloadSize)
+	EnableArena(true)
+
+	if _, err := LoadRecords(testFolder); err == nil {
+		t.Fatal("expected error")
+	}
+}
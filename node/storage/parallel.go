@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/evilsocket/islazy/log"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	loadWorkers    = runtime.NumCPU()
+	progressPeriod = 5 * time.Second
+)
+
+// SetLoadWorkers sets the number of goroutines used to read, verify
+// and deserialize objects while loading, values lower than 1 mean
+// one worker per CPU.
+func SetLoadWorkers(workers int) {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	loadWorkers = workers
+}
+
+// parallel calls cb for every integer in [0, n) using up to
+// loadWorkers goroutines, it stops scheduling new jobs as soon
+// as one of them fails and returns the first error.
+func parallel(n int, cb func(job int) error) error {
+	workers := loadWorkers
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	errs := make(chan error, workers)
+	stop := make(chan struct{})
+	once := sync.Once{}
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if err := cb(job); err != nil {
+					errs <- err
+					once.Do(func() { close(stop) })
+					return
+				}
+			}
+		}()
+	}
+
+	func() {
+		defer close(jobs)
+		for job := 0; job < n; job++ {
+			select {
+			case <-stop:
+				return
+			case jobs <- job:
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// progress periodically logs how many of a known number of
+// units of work have been completed.
+type progress struct {
+	what  string
+	total int64
+	done  int64
+	stop  chan struct{}
+}
+
+func startProgress(what string, total int) *progress {
+	p := &progress{
+		what:  what,
+		total: int64(total),
+		stop:  make(chan struct{}),
+	}
+
+	go func() {
+		ticker := time.NewTicker(progressPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				done := atomic.LoadInt64(&p.done)
+				log.Info("%s: %d/%d (%.1f%%) ...", p.what, done, p.total, float64(done)*100.0/float64(p.total))
+			}
+		}
+	}()
+
+	return p
+}
+
+func (p *progress) inc() {
+	atomic.AddInt64(&p.done, 1)
+}
+
+func (p *progress) end() {
+	close(p.stop)
+}
+
+// ForEachParallel executes a callback passing as argument every
+// element of the index, the callback is called concurrently by
+// multiple goroutines. It returns the first error returned by
+// the callback, if any.
+func (i *Index) ForEachParallel(cb func(record proto.Message) error) error {
+	i.RLock()
+	defer i.RUnlock()
+
+	ids := make([]uint64, 0, len(i.index))
+	for id := range i.index {
+		ids = append(ids, id)
+	}
+
+	return parallel(len(ids), func(job int) error {
+		record, err := i.resident(ids[job], i.index[ids[job]])
+		if err != nil {
+			return err
+		}
+		return cb(record)
+	})
+}
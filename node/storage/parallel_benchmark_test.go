@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func benchmarkParallelLoad(b *testing.B, workers int) {
+	i := setupSegments(b, 1024*1024)
+	defer teardownSegments(b)
+
+	data := make([]float32, 256)
+	for n := 0; n < 10000; n++ {
+		if err := i.Create(&pb.Record{Data: data, Meta: map[string]string{"n": fmt.Sprintf("%d", n)}}); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	SetLoadWorkers(workers)
+	defer SetLoadWorkers(0)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := setupIndex(testFolder).Load(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParallelLoadSingleWorker(b *testing.B) {
+	benchmarkParallelLoad(b, 1)
+}
+
+func BenchmarkParallelLoadAllCores(b *testing.B) {
+	benchmarkParallelLoad(b, runtime.NumCPU())
+}
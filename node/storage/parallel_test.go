@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestParallel(t *testing.T) {
+	const jobs = 1000
+
+	done := make([]int32, jobs)
+	if err := parallel(jobs, func(job int) error {
+		atomic.AddInt32(&done[job], 1)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for job, times := range done {
+		if times != 1 {
+			t.Fatalf("job %d executed %d times", job, times)
+		}
+	}
+
+	if err := parallel(0, func(job int) error {
+		t.Fatal("no job expected")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParallelWithError(t *testing.T) {
+	expected := errors.New("job failed")
+	executed := int32(0)
+	err := parallel(1000, func(job int) error {
+		atomic.AddInt32(&executed, 1)
+		return expected
+	})
+	if err != expected {
+		t.Fatalf("expected '%v', got '%v'", expected, err)
+	} else if executed == 1000 {
+		t.Fatal("expected jobs to stop being scheduled after the first error")
+	}
+}
+
+func TestSetLoadWorkers(t *testing.T) {
+	defer SetLoadWorkers(0)
+
+	SetLoadWorkers(3)
+	if loadWorkers != 3 {
+		t.Fatalf("expected 3 workers, got %d", loadWorkers)
+	}
+
+	SetLoadWorkers(-1)
+	if loadWorkers != runtime.NumCPU() {
+		t.Fatalf("expected %d workers, got %d", runtime.NumCPU(), loadWorkers)
+	}
+}
+
+func TestParallelLoad(t *testing.T) {
+	i := setupSegments(t, 256)
+	defer teardownSegments(t)
+
+	for n := 0; n < 100; n++ {
+		if err := i.Create(&pb.Record{Data: []float32{float32(n)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for id := uint64(1); id <= 100; id += 10 {
+		i.Delete(id)
+	}
+
+	if countSegments(t) < 2 {
+		t.Fatalf("expected multiple segments, got %d", countSegments(t))
+	}
+
+	// every number of workers must yield the same index
+	for _, workers := range []int{1, 4, 32} {
+		SetLoadWorkers(workers)
+		reloaded := setupIndex(testFolder)
+		if err := reloaded.Load(); err != nil {
+			t.Fatal(err)
+		} else if reloaded.Size() != 90 {
+			t.Fatalf("expected 90 records with %d workers, got %d", workers, reloaded.Size())
+		} else if reloaded.GetNextId() != 101 {
+			t.Fatalf("expected next id 101 with %d workers, got %d", workers, reloaded.GetNextId())
+		} else if reloaded.LoadTime() <= 0 {
+			t.Fatal("expected load time to be measured")
+		}
+
+		for id := uint64(1); id <= 100; id++ {
+			m := reloaded.Find(id)
+			if id%10 == 1 {
+				if m != nil {
+					t.Fatalf("record %d should be deleted", id)
+				}
+			} else if m == nil {
+				t.Fatalf("record %d not found with %d workers", id, workers)
+			} else if data := m.(*pb.Record).Data; data[0] != float32(id-1) {
+				t.Fatalf("unexpected data for record %d: %v", id, data)
+			}
+		}
+	}
+	SetLoadWorkers(0)
+}
+
+func TestParallelLoadChecksum(t *testing.T) {
+	i := setupSegments(t, 256)
+	defer teardownSegments(t)
+
+	for n := 0; n < 20; n++ {
+		if err := i.Create(&pb.Record{Data: []float32{1, 2, 3}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if countSegments(t) < 2 {
+		t.Fatalf("expected multiple segments, got %d", countSegments(t))
+	}
+
+	// flip a byte of the payload of the first object
+	path := i.segments.pathForSegment(1)
+	fp, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := fp.WriteAt([]byte{0xff}, frameHeaderSize+2); err != nil {
+		t.Fatal(err)
+	}
+	fp.Close()
+
+	reloaded := setupIndex(testFolder)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	} else if bad := reloaded.Quarantined(); len(bad) != 1 {
+		t.Fatalf("expected 1 quarantined file, got %d", len(bad))
+	} else if reloaded.Find(1) != nil {
+		t.Fatal("record with a wrong checksum should not be loaded")
+	}
+}
+
+func TestIndexForEachParallel(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownRecords(t)
+
+	i := setupIndex(testFolder)
+	if err := i.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := int32(0)
+	if err := i.ForEachParallel(func(m proto.Message) error {
+		atomic.AddInt32(&seen, 1)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	} else if seen != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, seen)
+	}
+
+	expected := errors.New("callback failed")
+	if err := i.ForEachParallel(func(m proto.Message) error {
+		return expected
+	}); err != expected {
+		t.Fatalf("expected '%v', got '%v'", expected, err)
+	}
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/evilsocket/sum/node/backend"
+	pb "github.com/evilsocket/sum/proto"
+)
+
+var (
+	quantizationLock = sync.RWMutex{}
+	quantization     = pb.QuantizedVector_FLOAT32
+)
+
+// SetQuantization sets how the dense vectors of new records are stored
+// in the collections whose schema does not set it, FLOAT32 to keep them
+// as they are. Quantized vectors are not moved in the arena and stored
+// records are not converted when the setting changes.
+func SetQuantization(t pb.QuantizedVector_Type) {
+	quantizationLock.Lock()
+	defer quantizationLock.Unlock()
+	quantization = t
+}
+
+// ParseQuantization returns the quantization with the given name, either
+// float32, int8 or float16.
+func ParseQuantization(name string) (pb.QuantizedVector_Type, error) {
+	if t, found := pb.QuantizedVector_Type_value[strings.ToUpper(name)]; found {
+		return pb.QuantizedVector_Type(t), nil
+	}
+	return pb.QuantizedVector_FLOAT32, fmt.Errorf("unknown quantization '%s'", name)
+}
+
+// returns the quantization of a collection, the one of the node
+// unless its schema sets one
+func quantizationOf(schema *pb.Schema) pb.QuantizedVector_Type {
+	if schema != nil && schema.Quantization != pb.QuantizedVector_FLOAT32 {
+		return schema.Quantization
+	}
+
+	quantizationLock.RLock()
+	defer quantizationLock.RUnlock()
+	return quantization
+}
+
+// replaces the dense vector of a record with its quantized form, sparse
+// and already quantized records are stored as they are
+func quantize(t pb.QuantizedVector_Type, record *pb.Record) {
+	if t == pb.QuantizedVector_FLOAT32 || record.Data == nil {
+		return
+	}
+
+	q := backend.Quantize(backend.Quantization(t), record.Data)
+	record.Quantized = &pb.QuantizedVector{
+		Type:   t,
+		Size:   uint64(q.Size),
+		Data:   q.Data,
+		Scale:  q.Scale,
+		Offset: q.Offset,
+	}
+	record.Data = nil
+}
+
+func dequantize(q *pb.QuantizedVector) []float32 {
+	return backend.NewQuantized(backend.Quantization(q.Type), int(q.Size), q.Data, q.Scale, q.Offset).Dense()
+}
+
+// ValidateQuantized returns an error if the record has a quantized vector
+// and another one, or if its quantized vector is inconsistent.
+func ValidateQuantized(record *pb.Record) error {
+	q := record.Quantized
+	if q == nil {
+		return nil
+	} else if len(record.Data) > 0 || record.Sparse != nil {
+		return fmt.Errorf("a record can not have both a quantized vector and another one")
+	}
+
+	width := uint64(0)
+	switch q.Type {
+	case pb.QuantizedVector_INT8:
+		width = 1
+	case pb.QuantizedVector_FLOAT16:
+		width = 2
+	default:
+		return fmt.Errorf("unsupported quantization %s", q.Type)
+	}
+
+	if uint64(len(q.Data)) != q.Size*width {
+		return fmt.Errorf("quantized vector of %d elements has %d bytes instead of %d", q.Size, len(q.Data), q.Size*width)
+	}
+	return nil
+}
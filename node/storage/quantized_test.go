@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"math"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
+)
+
+func assertDequantized(t *testing.T, rec *pb.Record, kind pb.QuantizedVector_Type, expected []float32) {
+	t.Helper()
+	if rec.Data != nil || rec.Quantized == nil || rec.Quantized.Type != kind {
+		t.Fatalf("expected a %s vector, got %v", kind, rec)
+	}
+
+	data := DenseData(rec)
+	if len(data) != len(expected) || VectorSize(rec) != uint64(len(expected)) {
+		t.Fatalf("expected %d elements, got %v", len(expected), data)
+	}
+	for i, v := range expected {
+		if math.Abs(float64(data[i]-v)) > 0.01 {
+			t.Fatalf("expected %v, got %v", expected, data)
+		}
+	}
+}
+
+func TestParseQuantization(t *testing.T) {
+	if q, err := ParseQuantization("int8"); err != nil || q != pb.QuantizedVector_INT8 {
+		t.Fatalf("unexpected %v %v", q, err)
+	} else if q, err := ParseQuantization("FLOAT16"); err != nil || q != pb.QuantizedVector_FLOAT16 {
+		t.Fatalf("unexpected %v %v", q, err)
+	} else if _, err := ParseQuantization("int4"); err == nil {
+		t.Fatal("expected error for unknown quantization")
+	}
+}
+
+func TestValidateQuantized(t *testing.T) {
+	valid := []*pb.Record{
+		{Data: []float32{1}},
+		{Quantized: &pb.QuantizedVector{Type: pb.QuantizedVector_INT8, Size: 2, Data: []byte{1, 2}}},
+		{Quantized: &pb.QuantizedVector{Type: pb.QuantizedVector_FLOAT16, Size: 1, Data: []byte{0, 0x3c}}},
+	}
+	for _, rec := range valid {
+		if err := ValidateQuantized(rec); err != nil {
+			t.Fatalf("unexpected error for %v: %v", rec, err)
+		}
+	}
+
+	invalid := []*pb.Record{
+		{Data: []float32{1}, Quantized: &pb.QuantizedVector{Type: pb.QuantizedVector_INT8, Size: 1, Data: []byte{1}}},
+		{Sparse: &testSparse, Quantized: &pb.QuantizedVector{Type: pb.QuantizedVector_INT8}},
+		{Quantized: &pb.QuantizedVector{Type: pb.QuantizedVector_FLOAT32}},
+		{Quantized: &pb.QuantizedVector{Type: pb.QuantizedVector_INT8, Size: 2, Data: []byte{1}}},
+		{Quantized: &pb.QuantizedVector{Type: pb.QuantizedVector_FLOAT16, Size: 2, Data: []byte{1, 2}}},
+	}
+	for _, rec := range invalid {
+		if err := ValidateQuantized(rec); err == nil {
+			t.Fatalf("expected error for %v", rec)
+		}
+	}
+}
+
+func TestRecordsQuantized(t *testing.T) {
+	setupRecords(t, false, false)
+	defer teardownRecords(t)
+
+	SetQuantization(pb.QuantizedVector_INT8)
+	defer SetQuantization(pb.QuantizedVector_FLOAT32)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []float32{1, -2, 3, 0.5}
+	dense := &pb.Record{Data: append([]float32{}, data...)}
+	sparse := &pb.Record{Sparse: &testSparse}
+	if err := records.Create(dense); err != nil {
+		t.Fatal(err)
+	} else if err := records.Create(sparse); err != nil {
+		t.Fatal(err)
+	}
+	assertDequantized(t, records.Find(dense.Id), pb.QuantizedVector_INT8, data)
+	if stored := records.Find(sparse.Id); stored.Quantized != nil || !proto.Equal(stored.Sparse, &testSparse) {
+		t.Fatalf("sparse records should not be quantized: %v", stored)
+	}
+
+	// the schema of the collection has precedence over the node setting
+	if err := records.SetSchema(&pb.Schema{Quantization: pb.QuantizedVector_FLOAT16}); err != nil {
+		t.Fatal(err)
+	} else if err := records.Update(&pb.Record{Id: dense.Id, Data: []float32{4, 5, 6, 7}}); err != nil {
+		t.Fatal(err)
+	}
+	assertDequantized(t, records.Find(dense.Id), pb.QuantizedVector_FLOAT16, []float32{4, 5, 6, 7})
+
+	quantized := &pb.Record{Data: append([]float32{}, data...)}
+	if err := records.Create(quantized); err != nil {
+		t.Fatal(err)
+	}
+
+	// quantized vectors are replaced by any other vector
+	if err := records.Update(&pb.Record{Id: dense.Id, Sparse: &testSparse}); err != nil {
+		t.Fatal(err)
+	} else if stored := records.Find(dense.Id); stored.Quantized != nil || stored.Sparse == nil {
+		t.Fatalf("unexpected record after update %v", stored)
+	}
+
+	if err := records.SetSchema(&pb.Schema{Quantization: 7}); err == nil {
+		t.Fatal("expected error for unknown quantization")
+	} else if err := records.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDequantized(t, reloaded.Find(quantized.Id), pb.QuantizedVector_FLOAT16, data)
+}
+
+func TestRecordDriverQuantizedPayload(t *testing.T) {
+	d := RecordDriver{}
+	rec := &pb.Record{Id: 1, Quantized: &pb.QuantizedVector{Type: pb.QuantizedVector_INT8, Size: 3, Data: []byte{1, 2, 3}}}
+	if size := d.PayloadSize(rec); size != 3 {
+		t.Fatalf("expected payload of 3 bytes, got %d", size)
+	} else if stripped := d.Strip(rec).(*pb.Record); stripped.Quantized != nil {
+		t.Fatal("expected the quantized vector to be stripped")
+	}
+
+	dst := &pb.Record{Data: []float32{1}}
+	if err := d.Copy(dst, rec); err != nil {
+		t.Fatal(err)
+	} else if dst.Data != nil || dst.Quantized != rec.Quantized {
+		t.Fatalf("unexpected copy %v", dst)
+	}
+}
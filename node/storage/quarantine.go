@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// QuarantineFolderName is the name of the folder, inside the data path
+	// of an index, where unreadable files are moved to during Load.
+	QuarantineFolderName = "quarantine"
+)
+
+func quarantinePathFor(dataPath, fileName string) (string, error) {
+	folder := filepath.Join(dataPath, QuarantineFolderName)
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(folder, filepath.Base(fileName))
+	if _, err := os.Stat(dest); err == nil {
+		// never overwrite something that was quarantined before
+		dest = fmt.Sprintf("%s.%d", dest, time.Now().UnixNano())
+	}
+	return dest, nil
+}
+
+// Quarantine moves an unreadable file from the data path to its
+// quarantine folder and returns the new path of the file.
+func Quarantine(dataPath, fileName string) (string, error) {
+	dest, err := quarantinePathFor(dataPath, fileName)
+	if err != nil {
+		return "", err
+	} else if err = os.Rename(fileName, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// QuarantineData saves raw unreadable data with the given name in the
+// quarantine folder of the data path and returns its path.
+func QuarantineData(dataPath, name string, data []byte) (string, error) {
+	dest, err := quarantinePathFor(dataPath, name)
+	if err != nil {
+		return "", err
+	} else if err = WriteAtomic(dest, data, 0644); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// removeTempFiles deletes leftovers of atomic writes that have been
+// interrupted before the final rename.
+func removeTempFiles(dataPath string) error {
+	files, err := ioutil.ReadDir(dataPath)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if fileName := file.Name(); strings.HasPrefix(fileName, ".") && filepath.Ext(fileName) == TmpFileExt {
+			if err := os.Remove(filepath.Join(dataPath, fileName)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuarantine(t *testing.T) {
+	setupRecords(t, false, true)
+	defer teardownRecords(t)
+
+	dest, err := Quarantine(testFolder, testCorruptedRecord)
+	if err != nil {
+		t.Fatal(err)
+	} else if expected := filepath.Join(testFolder, QuarantineFolderName, "666.dat"); dest != expected {
+		t.Fatalf("expected %s, got %s", expected, dest)
+	} else if _, err := os.Stat(testCorruptedRecord); err == nil {
+		t.Fatal("expected file to be moved")
+	}
+
+	// quarantining a file with the same name must not overwrite the previous one
+	if err := ioutil.WriteFile(testCorruptedRecord, []byte("again"), 0755); err != nil {
+		t.Fatal(err)
+	} else if other, err := Quarantine(testFolder, testCorruptedRecord); err != nil {
+		t.Fatal(err)
+	} else if other == dest {
+		t.Fatal("previously quarantined file overwritten")
+	} else if _, err := Quarantine(testFolder, testCorruptedRecord); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestQuarantineData(t *testing.T) {
+	setupRecords(t, false, false)
+	defer teardownRecords(t)
+
+	if dest, err := QuarantineData(testFolder, "1.dat", []byte("broken")); err != nil {
+		t.Fatal(err)
+	} else if data, err := ioutil.ReadFile(dest); err != nil {
+		t.Fatal(err)
+	} else if string(data) != "broken" {
+		t.Fatalf("unexpected data %s", data)
+	}
+}
+
+func TestLoadRemovesTempFiles(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownRecords(t)
+
+	leftover := filepath.Join(testFolder, ".1.dat.12345"+TmpFileExt)
+	if err := ioutil.WriteFile(leftover, []byte("half written"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	i := setupIndex(testFolder)
+	if err := i.Load(); err != nil {
+		t.Fatal(err)
+	} else if i.Size() != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, i.Size())
+	} else if _, err := os.Stat(leftover); err == nil {
+		t.Fatal("expected temporary file to be removed")
+	}
+}
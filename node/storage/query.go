@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// MaxQueryDepth is the maximum nesting level of a meta query.
+const MaxQueryDepth = 32
+
+// idSet is a sorted list of unique record identifiers.
+type idSet []uint64
+
+func newIDSet(ids []uint64) idSet {
+	set := append(idSet(nil), ids...)
+	sort.Slice(set, func(i, j int) bool { return set[i] < set[j] })
+	// remove duplicates
+	unique := set[:0]
+	for n, id := range set {
+		if n == 0 || id != set[n-1] {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func (a idSet) intersect(b idSet) idSet {
+	res := make(idSet, 0)
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if a[i] < b[j] {
+			i++
+		} else if a[i] > b[j] {
+			j++
+		} else {
+			res = append(res, a[i])
+			i++
+			j++
+		}
+	}
+	return res
+}
+
+func (a idSet) union(b idSet) idSet {
+	res := make(idSet, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] < b[j] {
+			res = append(res, a[i])
+			i++
+		} else if a[i] > b[j] {
+			res = append(res, b[j])
+			j++
+		} else {
+			res = append(res, a[i])
+			i++
+			j++
+		}
+	}
+	res = append(res, a[i:]...)
+	return append(res, b[j:]...)
+}
+
+func (a idSet) difference(b idSet) idSet {
+	res := make(idSet, 0, len(a))
+	for i, j := 0, 0; i < len(a); {
+		if j == len(b) || a[i] < b[j] {
+			res = append(res, a[i])
+			i++
+		} else if a[i] > b[j] {
+			j++
+		} else {
+			i++
+			j++
+		}
+	}
+	return res
+}
+
+// evaluates a single filter with the sorted index, which has both the
+// text meta data and the typed one, the meta hash index only has the
+// former.
+func (r *Records) _filter(f *pb.MetaFilter) (idSet, error) {
+	if f == nil {
+		return nil, fmt.Errorf("filter expected")
+	}
+
+	value, to, err := validateFilter(f)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := r.sortedBy[f.Meta].match(f, value, to)
+	ids := make([]uint64, len(entries))
+	for n, entry := range entries {
+		ids[n] = entry.id
+	}
+	return newIDSet(ids), nil
+}
+
+func (r *Records) _all() idSet {
+	ids := make([]uint64, 0, len(r.index))
+	for id := range r.index {
+		ids = append(ids, id)
+	}
+	return newIDSet(ids)
+}
+
+func (r *Records) _eval(q *pb.MetaQuery, depth int) (idSet, error) {
+	if q == nil {
+		return nil, fmt.Errorf("empty query")
+	} else if depth > MaxQueryDepth {
+		return nil, fmt.Errorf("query is nested more than %d levels", MaxQueryDepth)
+	}
+
+	switch q.Op {
+	case pb.MetaQuery_FILTER:
+		return r._filter(q.Filter)
+
+	case pb.MetaQuery_AND, pb.MetaQuery_OR:
+		if len(q.Operands) == 0 {
+			return nil, fmt.Errorf("%s requires at least one operand", q.Op)
+		}
+
+		var res idSet
+		for n, operand := range q.Operands {
+			ids, err := r._eval(operand, depth+1)
+			if err != nil {
+				return nil, err
+			} else if n == 0 {
+				res = ids
+			} else if q.Op == pb.MetaQuery_AND {
+				res = res.intersect(ids)
+			} else {
+				res = res.union(ids)
+			}
+		}
+		return res, nil
+
+	case pb.MetaQuery_NOT:
+		if len(q.Operands) != 1 {
+			return nil, fmt.Errorf("NOT requires exactly one operand")
+		}
+
+		ids, err := r._eval(q.Operands[0], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return r._all().difference(ids), nil
+	}
+
+	return nil, fmt.Errorf("unknown query operator %d", q.Op)
+}
+
+// Query returns the list of pb.Record objects, sorted by identifier,
+// matching a boolean expression of filters on their meta values.
+func (r *Records) Query(q *pb.MetaQuery) ([]*pb.Record, error) {
+	r.RLock()
+	ids, err := r._eval(q, 0)
+	r.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*pb.Record, 0, len(ids))
+	for _, id := range ids {
+		if rec := r.Find(id); rec != nil {
+			records = append(records, rec)
+		}
+	}
+	return records, nil
+}
+
+// FindWhere returns the list of pb.Record objects, sorted by identifier,
+// matching every one of the given filters on their meta values.
+func (r *Records) FindWhere(filters []*pb.MetaFilter) ([]*pb.Record, error) {
+	if len(filters) == 0 {
+		return nil, fmt.Errorf("no filters specified")
+	}
+
+	q := &pb.MetaQuery{Op: pb.MetaQuery_AND}
+	for _, f := range filters {
+		q.Operands = append(q.Operands, &pb.MetaQuery{Op: pb.MetaQuery_FILTER, Filter: f})
+	}
+	return r.Query(q)
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func filterOf(meta string, op pb.MetaFilter_Op, value *pb.MetaValue) *pb.MetaQuery {
+	return &pb.MetaQuery{Op: pb.MetaQuery_FILTER, Filter: &pb.MetaFilter{Meta: meta, Op: op, Value: value}}
+}
+
+func and(operands ...*pb.MetaQuery) *pb.MetaQuery {
+	return &pb.MetaQuery{Op: pb.MetaQuery_AND, Operands: operands}
+}
+
+func or(operands ...*pb.MetaQuery) *pb.MetaQuery {
+	return &pb.MetaQuery{Op: pb.MetaQuery_OR, Operands: operands}
+}
+
+func not(operand *pb.MetaQuery) *pb.MetaQuery {
+	return &pb.MetaQuery{Op: pb.MetaQuery_NOT, Operands: []*pb.MetaQuery{operand}}
+}
+
+func TestIDSet(t *testing.T) {
+	a := newIDSet([]uint64{5, 1, 3, 3, 7})
+	b := newIDSet([]uint64{3, 4, 5})
+
+	if !reflect.DeepEqual(a, idSet{1, 3, 5, 7}) {
+		t.Fatalf("unexpected set %v", a)
+	} else if res := a.intersect(b); !reflect.DeepEqual(res, idSet{3, 5}) {
+		t.Fatalf("unexpected intersection %v", res)
+	} else if res := a.union(b); !reflect.DeepEqual(res, idSet{1, 3, 4, 5, 7}) {
+		t.Fatalf("unexpected union %v", res)
+	} else if res := a.difference(b); !reflect.DeepEqual(res, idSet{1, 7}) {
+		t.Fatalf("unexpected difference %v", res)
+	} else if res := a.intersect(nil); len(res) != 0 {
+		t.Fatalf("unexpected intersection %v", res)
+	} else if res := a.difference(nil); !reflect.DeepEqual(res, a) {
+		t.Fatalf("unexpected difference %v", res)
+	}
+}
+
+func TestRecordsQuery(t *testing.T) {
+	records := setupSorted(t)
+	defer teardownRecords(t)
+
+	cases := []struct {
+		query    *pb.MetaQuery
+		expected []uint64
+	}{
+		{filterOf("name", pb.MetaFilter_EQ, text("itemb")), []uint64{2}},
+		{or(
+			filterOf("name", pb.MetaFilter_EQ, text("itemb")),
+			filterOf("name", pb.MetaFilter_EQ, text("itemd")),
+		), []uint64{2, 4}},
+		{and(
+			filterOf("score", pb.MetaFilter_GT, number(0.5)),
+			filterOf("created", pb.MetaFilter_LT, timestamp(sortedEpoch.AddDate(0, 0, 9))),
+		), []uint64{6, 7, 8}},
+		{not(filterOf("score", pb.MetaFilter_GT, number(0.2))), []uint64{1, 2}},
+		{and(
+			filterOf("score", pb.MetaFilter_GTE, number(0.5)),
+			not(or(
+				filterOf("name", pb.MetaFilter_EQ, text("itemf")),
+				filterOf("name", pb.MetaFilter_PREFIX, text("itemj")),
+			)),
+		), []uint64{5, 7, 8, 9}},
+		// records without the meta match its negation
+		{not(filterOf("unknown", pb.MetaFilter_EQ, text("x"))), []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{and(
+			filterOf("score", pb.MetaFilter_GT, number(0.5)),
+			filterOf("score", pb.MetaFilter_LT, number(0.5)),
+		), []uint64{}},
+	}
+
+	for n, c := range cases {
+		found, err := records.Query(c.query)
+		if err != nil {
+			t.Fatalf("case %d: %s", n, err)
+		} else if ids := idsOf(found); !reflect.DeepEqual(ids, c.expected) {
+			t.Fatalf("case %d: expected %v, got %v", n, c.expected, ids)
+		}
+	}
+}
+
+func TestRecordsQueryTypedText(t *testing.T) {
+	records := setupSorted(t)
+	defer teardownRecords(t)
+
+	typed := &pb.Record{Data: []float32{1, 2, 3}, TypedMeta: map[string]*pb.MetaValue{"name": text("typed")}}
+	if err := records.Create(typed); err != nil {
+		t.Fatal(err)
+	}
+
+	// typed text values are found by every operator, as text meta data
+	for _, op := range []pb.MetaFilter_Op{pb.MetaFilter_EQ, pb.MetaFilter_GTE, pb.MetaFilter_PREFIX} {
+		if found, err := records.Query(filterOf("name", op, text("typed"))); err != nil {
+			t.Fatal(err)
+		} else if ids := idsOf(found); !reflect.DeepEqual(ids, []uint64{typed.Id}) {
+			t.Fatalf("%s: expected [%d], got %v", op, typed.Id, ids)
+		}
+	}
+
+	if records.Delete(typed.Id) == nil {
+		t.Fatal("record not found")
+	} else if found, err := records.Query(filterOf("name", pb.MetaFilter_EQ, text("typed"))); err != nil {
+		t.Fatal(err)
+	} else if len(found) != 0 {
+		t.Fatalf("deleted record should not be found: %v", idsOf(found))
+	}
+}
+
+func TestRecordsQueryInvalid(t *testing.T) {
+	records := setupSorted(t)
+	defer teardownRecords(t)
+
+	deep := filterOf("score", pb.MetaFilter_GT, number(0))
+	for n := 0; n <= MaxQueryDepth; n++ {
+		deep = not(deep)
+	}
+
+	invalid := []*pb.MetaQuery{
+		nil,
+		{Op: pb.MetaQuery_FILTER},
+		{Op: pb.MetaQuery_AND},
+		{Op: pb.MetaQuery_OR},
+		{Op: pb.MetaQuery_NOT},
+		{Op: pb.MetaQuery_Op(666)},
+		not(filterOf("score", pb.MetaFilter_PREFIX, number(0))),
+		and(filterOf("score", pb.MetaFilter_GT, number(0)), nil),
+		deep,
+	}
+
+	for n, q := range invalid {
+		if _, err := records.Query(q); err == nil {
+			t.Fatalf("case %d: expected error", n)
+		}
+	}
+}
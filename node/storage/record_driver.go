@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"time"
+
 	"github.com/golang/protobuf/proto"
 
 	pb "github.com/evilsocket/sum/proto"
@@ -27,19 +29,76 @@ func (d RecordDriver) SetID(m proto.Message, id uint64) {
 	m.(*pb.Record).Id = id
 }
 
-// Copy copies the Shape, Meta and Data fields, if filled, from the
-// source object to the destination one.
+// Copy copies the Shape, Meta, Key and either the Data, Sparse or Quantized fields,
+// if filled, from the source object to the destination one, the Key is removed
+// if the source object has ClearKey set. If the source object has a
+// version, it must match the one of the destination object or
+// ErrVersionConflict is returned. On success the version of the
+// destination object is incremented and its update time refreshed.
+// The expiration time is changed if the source object has one, either
+// absolute or as a TTL.
 func (d RecordDriver) Copy(mdst proto.Message, msrc proto.Message) error {
 	dst := mdst.(*pb.Record)
 	src := msrc.(*pb.Record)
+	if src.Version != 0 && src.Version != dst.Version {
+		return ErrVersionConflict
+	}
 	if src.Meta != nil {
 		dst.Meta = src.Meta
 	}
+	if src.TypedMeta != nil {
+		dst.TypedMeta = src.TypedMeta
+	}
+	if src.ClearKey {
+		dst.Key = ""
+	} else if src.Key != "" {
+		dst.Key = src.Key
+	}
 	if src.Data != nil {
 		dst.Data = src.Data
+		dst.Sparse = nil
+		dst.Quantized = nil
+	} else if src.Sparse != nil {
+		dst.Data = nil
+		dst.Sparse = src.Sparse
+		dst.Quantized = nil
+	} else if src.Quantized != nil {
+		dst.Data = nil
+		dst.Sparse = nil
+		dst.Quantized = src.Quantized
 	}
 	if src.Shape != nil {
 		dst.Shape = src.Shape
 	}
+	now := time.Now().UnixNano()
+	if src.Ttl > 0 || src.ExpiresAt != 0 {
+		dst.ExpiresAt = src.ExpiresAt
+		dst.Ttl = src.Ttl
+		applyTTL(dst, now)
+	}
+	dst.Version++
+	dst.UpdatedAt = now
 	return nil
 }
+
+// Strip returns a shallow copy of the pb.Record object without
+// the Data, Sparse and Quantized vectors.
+func (d RecordDriver) Strip(m proto.Message) proto.Message {
+	stripped := *m.(*pb.Record)
+	stripped.Data = nil
+	stripped.Sparse = nil
+	stripped.Quantized = nil
+	return &stripped
+}
+
+// PayloadSize returns the size in bytes of the Data, Sparse or Quantized
+// vector of the pb.Record object.
+func (d RecordDriver) PayloadSize(m proto.Message) int64 {
+	record := m.(*pb.Record)
+	if record.Sparse != nil {
+		return int64(len(record.Sparse.Indices)*4 + len(record.Sparse.Values)*4)
+	} else if record.Quantized != nil {
+		return int64(len(record.Quantized.Data))
+	}
+	return int64(len(record.Data) * 4)
+}
@@ -1,6 +1,13 @@
 package storage
 
 import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	pb "github.com/evilsocket/sum/proto"
 	"github.com/golang/protobuf/proto"
 )
@@ -12,38 +19,181 @@ type metaIndex map[string][]uint64
 type Records struct {
 	*Index
 
-	metaBy map[string]metaIndex
+	generation uint64
+	metaBy     map[string]metaIndex
+	sortedBy   map[string]sortedIndex
+	expiring   map[uint64]int64
+	arena      *Arena
+	schema     *pb.Schema
+	feed       *Feed
+	collection string
+	trash      *Index
+	// unique keys of the records
+	keys    map[string]uint64
+	keyLock sync.Mutex
+	// view of the records kept by the oracles, dropped on Close
+	view     interface{}
+	viewLock sync.Mutex
 }
 
 // LoadRecords loads and indexes raw protobuf records from
 // the data files found in a given path.
 func LoadRecords(dataPath string) (*Records, error) {
 	recs := &Records{
-		Index:  WithDriver(dataPath, RecordDriver{}),
-		metaBy: make(map[string]metaIndex),
+		Index:    WithDriver(dataPath, RecordDriver{}),
+		metaBy:   make(map[string]metaIndex),
+		sortedBy: make(map[string]sortedIndex),
+		expiring: make(map[uint64]int64),
+		keys:     make(map[string]uint64),
 	}
 
 	if err := recs.Load(); err != nil {
 		return nil, err
 	}
 
+	trash, err := loadTrash(filepath.Join(dataPath, TrashFolderName))
+	if err != nil {
+		return nil, err
+	}
+	recs.trash = trash
+
 	for _, m := range recs.index {
 		recs.metaIndexCreate(m.(*pb.Record))
+		recs.sortedIndexCreate(m.(*pb.Record))
+		recs.expiryUpdate(m.(*pb.Record))
+		recs.keyIndexCreate(m.(*pb.Record))
+	}
+
+	if enabled, chunkSize := arenaConfig(); enabled {
+		if recs.Paged() {
+			return nil, fmt.Errorf("the vector arena can not be used when payloads are paged")
+		}
+
+		arena, err := newArena(filepath.Join(dataPath, ArenaFolderName), chunkSize)
+		if err != nil {
+			return nil, err
+		}
+
+		recs.Lock()
+		defer recs.Unlock()
+
+		recs.arena = arena
+		for _, m := range recs.index {
+			if err := recs._arenaPut(m.(*pb.Record)); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return recs, nil
 }
 
+// Generation returns a counter that changes every time a record is
+// created, updated or deleted, it can be used to invalidate views
+// of the records.
+func (r *Records) Generation() uint64 {
+	return atomic.LoadUint64(&r.generation)
+}
+
+func (r *Records) touch() {
+	atomic.AddUint64(&r.generation, 1)
+}
+
+// View returns the view of the records that other packages keep along
+// with them, creating it the first time. The view is dropped when the
+// records are closed so that it never outlives them.
+func (r *Records) View(create func() interface{}) interface{} {
+	r.viewLock.Lock()
+	defer r.viewLock.Unlock()
+
+	if r.view == nil {
+		r.view = create()
+	}
+	return r.view
+}
+
+// Pin keeps the vectors of the records read from now on valid, even if
+// the records are updated or deleted meanwhile, until the returned
+// function is called.
+func (r *Records) Pin() func() {
+	r.RLock()
+	arena := r.arena
+	r.RUnlock()
+
+	if arena == nil {
+		return func() {}
+	}
+	return arena.pin(r.Generation())
+}
+
+// Arena returns the contiguous vector arena of the records
+// or nil if it is not enabled.
+func (r *Records) Arena() *Arena {
+	return r.arena
+}
+
+// moves the vector of the record in the arena
+func (r *Records) _arenaPut(rec *pb.Record) (err error) {
+	if r.arena != nil {
+		rec.Data, err = r.arena.put(rec.Id, rec.Data, r.Generation())
+	}
+	return
+}
+
+func (r *Records) arenaPut(rec *pb.Record) error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r._arenaPut(rec)
+}
+
+// detaches the vector of a record that is being removed from the arena
+// since its slot is going to be reused
+func (r *Records) _arenaRemove(rec *pb.Record) {
+	if r.arena != nil {
+		rec.Data = append([]float32(nil), rec.Data...)
+		r.arena.release(rec.Id, r.Generation())
+	}
+}
+
+// buckets of the meta index are kept sorted and without duplicates,
+// this makes inserting and removing an id idempotent.
+func (idx metaIndex) insert(val string, id uint64) {
+	bucket := idx[val]
+	at := sort.Search(len(bucket), func(i int) bool { return bucket[i] >= id })
+	if at < len(bucket) && bucket[at] == id {
+		return
+	}
+	bucket = append(bucket, 0)
+	copy(bucket[at+1:], bucket[at:])
+	bucket[at] = id
+	idx[val] = bucket
+}
+
+func (idx metaIndex) remove(val string, id uint64) {
+	bucket, found := idx[val]
+	if !found {
+		return
+	}
+	at := sort.Search(len(bucket), func(i int) bool { return bucket[i] >= id })
+	if at == len(bucket) || bucket[at] != id {
+		return
+	} else if len(bucket) == 1 {
+		delete(idx, val)
+		return
+	}
+	idx[val] = append(bucket[:at], bucket[at+1:]...)
+}
+
 func (r *Records) _metaIndexCreate(rec *pb.Record) {
 	for key, val := range rec.Meta {
 		// create the index by this key if not there already
-		if metaIdx, found := r.metaBy[key]; !found {
-			r.metaBy[key] = metaIndex{
-				val: []uint64{rec.Id},
-			}
-		} else {
-			metaIdx[val] = append(metaIdx[val], rec.Id)
+		metaIdx, found := r.metaBy[key]
+		if !found {
+			metaIdx = make(metaIndex)
+			r.metaBy[key] = metaIdx
 		}
+		metaIdx.insert(val, rec.Id)
 	}
 }
 
@@ -54,29 +204,19 @@ func (r *Records) metaIndexCreate(rec *pb.Record) {
 	r._metaIndexCreate(rec)
 }
 
-func (r *Records) _metaIndexUpdate(rec *pb.Record) {
-	r._metaIndexRemove(rec)
+// replaces the old meta data of a record with the new one in the index
+func (r *Records) _metaIndexUpdate(id uint64, oldMeta map[string]string, rec *pb.Record) {
+	r._metaIndexRemove(id, oldMeta)
 	r._metaIndexCreate(rec)
 }
 
-func (r *Records) metaIndexUpdate(rec *pb.Record) {
-	r.metaIndexRemove(rec)
-	r.metaIndexCreate(rec)
-}
-
-func (r *Records) _metaIndexRemove(rec *pb.Record) {
-	for key, val := range rec.Meta {
+func (r *Records) _metaIndexRemove(id uint64, meta map[string]string) {
+	for key, val := range meta {
 		// find the bucket for this meta
-		if metaIdx, found := r.metaBy[key]; !found {
-			// find the bucket by value
-			bucket := metaIdx[val]
-			for i, elemID := range bucket {
-				// find the record by id
-				if elemID == rec.Id {
-					// remove it
-					metaIdx[val] = append(bucket[:i], bucket[i+1:]...)
-					break
-				}
+		if metaIdx, found := r.metaBy[key]; found {
+			metaIdx.remove(val, id)
+			if len(metaIdx) == 0 {
+				delete(r.metaBy, key)
 			}
 		}
 	}
@@ -86,14 +226,17 @@ func (r *Records) metaIndexRemove(rec *pb.Record) {
 	r.Lock()
 	defer r.Unlock()
 
-	r._metaIndexRemove(rec)
+	r._metaIndexRemove(rec.Id, rec.Meta)
 }
 
 // Find returns the instance of a stored pb.Record given its
 // identifier or nil if the object can not be found.
+// Expired records are never returned.
 func (r *Records) Find(id uint64) *pb.Record {
 	if m := r.Index.Find(id); m != nil {
-		return m.(*pb.Record)
+		if rec := m.(*pb.Record); !Expired(rec, time.Now().UnixNano()) {
+			return rec
+		}
 	}
 	return nil
 }
@@ -109,11 +252,12 @@ func (r *Records) FindBy(meta string, val string) []*pb.Record {
 		return nil
 	}
 
+	now := time.Now().UnixNano()
 	records := []*pb.Record{}
 	if bucket, found := metaIdx[val]; found {
 		for _, recID := range bucket {
 			m := r.Index.Find(recID)
-			if m != nil {
+			if m != nil && !Expired(m.(*pb.Record), now) {
 				records = append(records, m.(*pb.Record))
 			}
 		}
@@ -122,70 +266,476 @@ func (r *Records) FindBy(meta string, val string) []*pb.Record {
 	return records
 }
 
+// sets the version and update time of a record being created,
+// unless it is being moved with its history from somewhere else.
+func stamp(record *pb.Record, reset bool) {
+	now := time.Now().UnixNano()
+	if reset || record.Version == 0 {
+		record.Version = 1
+	}
+	if reset || record.UpdatedAt == 0 {
+		record.UpdatedAt = now
+	}
+	// only meaningful for updates
+	record.ClearKey = false
+	applyTTL(record, now)
+}
+
+// Schema returns the schema the records must match, or nil.
+func (r *Records) Schema() *pb.Schema {
+	r.RLock()
+	defer r.RUnlock()
+	return r.schema
+}
+
+// SetSchema sets the schema the records must match from now on, it
+// returns an error if any of the stored records does not match it.
+func (r *Records) SetSchema(schema *pb.Schema) error {
+	if err := CheckSchema(schema); err != nil {
+		return err
+	} else if EmptySchema(schema) {
+		schema = nil
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if schema != nil {
+		for id, m := range r.index {
+			rec, err := r.resident(id, m)
+			if err != nil {
+				return err
+			} else if err = ValidateRecord(schema, rec.(*pb.Record)); err != nil {
+				return fmt.Errorf("record %d does not match the schema: %s", id, err)
+			}
+		}
+	}
+
+	r.schema = schema
+	return nil
+}
+
+// validates a new record and quantizes its vector if needed
+func (r *Records) validate(record *pb.Record) error {
+	schema := r.Schema()
+	if err := ValidateSparse(record); err != nil {
+		return err
+	} else if err := ValidateQuantized(record); err != nil {
+		return err
+	} else if err := ValidateRecord(schema, record); err != nil {
+		return fmt.Errorf("record does not match the schema: %s", err)
+	}
+	quantize(quantizationOf(schema), record)
+	return nil
+}
+
 func (r *Records) Create(record *pb.Record) error {
+	defer r.lockKey(record.Key)()
+	return r.create(record)
+}
+
+func (r *Records) create(record *pb.Record) error {
 	// if the shape was not provide, it is 1d
 	if record.Shape == nil {
-		record.Shape = []uint64{uint64(len(record.Data))}
+		record.Shape = []uint64{VectorSize(record)}
 	}
 
+	if err := r.validate(record); err != nil {
+		return err
+	} else if err := r.checkKey(record.Key, 0); err != nil {
+		return err
+	}
+
+	stamp(record, true)
+
 	if err := r.Index.Create(record); err != nil {
 		return err
 	}
+	defer r.touch()
 	// create the meta index for this new record
 	r.metaIndexCreate(record)
+	r.keyIndexCreate(record)
+	r.sortedIndexCreate(record)
+	r.expiryUpdate(record)
+	if err := r.arenaPut(record); err != nil {
+		return err
+	}
+	r.publish(pb.Event_CREATE, record, false)
 	return nil
 }
 
 func (r *Records) CreateWithId(record *pb.Record) error {
+	defer r.lockKey(record.Key)()
+
+	if err := r.validate(record); err != nil {
+		return err
+	} else if err := r.checkKey(record.Key, record.Id); err != nil {
+		return err
+	}
+
+	stamp(record, false)
 	if err := r.Index.CreateWithId(record); err != nil {
 		return err
 	}
+	defer r.touch()
 	r.metaIndexCreate(record)
+	r.keyIndexCreate(record)
+	r.sortedIndexCreate(record)
+	r.expiryUpdate(record)
+	if err := r.arenaPut(record); err != nil {
+		return err
+	}
+	r.publish(pb.Event_CREATE, record, false)
 	return nil
 }
 
+// CreateManyWIthId stores the records with their identifiers, records
+// with an existing identifier are overwritten.
 func (r *Records) CreateManyWIthId(records []*pb.Record) error {
+	return r.createMany(records, false)
+}
+
+// CreateMany stores new records with consecutive identifiers, either
+// all of them are created or none.
+func (r *Records) CreateMany(records []*pb.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	for _, record := range records {
+		if record.Shape == nil {
+			record.Shape = []uint64{VectorSize(record)}
+		}
+		// these are new records
+		record.Version = 0
+		record.UpdatedAt = 0
+	}
+
+	first, err := r.Reserve(len(records))
+	if err != nil {
+		return err
+	}
+
+	for i, record := range records {
+		record.Id = first + uint64(i)
+	}
+
+	if err = r.createMany(records, false); err != nil {
+		r.Unreserve(first, len(records))
+	}
+	return err
+}
+
+// Reserve reserves n consecutive identifiers for new records and returns
+// the first one, the records can then be stored with CreateManyWIthId.
+func (r *Records) Reserve(n int) (uint64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	first := r.nextID
+	last := first + uint64(n)
+	for id := first; id < last; id++ {
+		if _, found := r.index[id]; found {
+			return 0, fmt.Errorf("record %d: %s", id, ErrInvalidID)
+		}
+	}
+	r.nextID = last
+	return first, nil
+}
+
+// Unreserve releases the n identifiers reserved from first on, if no
+// other identifier has been used meanwhile.
+func (r *Records) Unreserve(first uint64, n int) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.nextID == first+uint64(n) {
+		r.nextID = first
+	}
+}
+
+// TransferIn stores the records being moved from another node, the
+// changes are published as part of a transfer.
+func (r *Records) TransferIn(records []*pb.Record) error {
+	return r.createMany(records, true)
+}
+
+func (r *Records) createMany(records []*pb.Record, transfer bool) error {
+	r.keyLock.Lock()
+	defer r.keyLock.Unlock()
+
+	batchKeys := make(map[string]uint64)
+	for _, record := range records {
+		if err := r.validate(record); err != nil {
+			return fmt.Errorf("record %d: %s", record.Id, err)
+		} else if err := r.checkKey(record.Key, record.Id); err != nil {
+			return fmt.Errorf("record %d: %s", record.Id, err)
+		} else if record.Key == "" {
+			continue
+		} else if other, found := batchKeys[record.Key]; found && other != record.Id {
+			return fmt.Errorf("record %d: %s: %v (record %d)", record.Id, record.Key, ErrKeyExists, other)
+		}
+		batchKeys[record.Key] = record.Id
+	}
+
 	arg := make([]proto.Message, 0, len(records))
 	for _, r := range records {
+		stamp(r, false)
 		arg = append(arg, r)
 	}
 
+	// records with an existing identifier are overwritten
+	oldMeta := make(map[uint64]map[string]string)
+	oldTyped := make(map[uint64]map[string]*pb.MetaValue)
+	oldKey := make(map[uint64]string)
+	existed := make(map[uint64]bool)
+	r.RLock()
+	for _, record := range records {
+		if m, found := r.index[record.Id]; found {
+			oldMeta[record.Id] = m.(*pb.Record).Meta
+			oldTyped[record.Id] = m.(*pb.Record).TypedMeta
+			oldKey[record.Id] = m.(*pb.Record).Key
+			existed[record.Id] = true
+		}
+	}
+	r.RUnlock()
+
 	if err := r.Index.CreateManyWIthId(arg); err != nil {
 		return err
 	}
 
 	r.Lock()
 	defer r.Unlock()
+	defer r.touch()
 
 	for _, record := range records {
-		r._metaIndexUpdate(record)
+		r._metaIndexUpdate(record.Id, oldMeta[record.Id], record)
+		r._keyIndexRemove(record.Id, oldKey[record.Id])
+		r._keyIndexCreate(record)
+		r._sortedIndexRemove(record.Id, oldMeta[record.Id], oldTyped[record.Id])
+		r._sortedIndexCreate(record)
+		r._expiryUpdate(record)
+		if err := r._arenaPut(record); err != nil {
+			return err
+		}
+		if existed[record.Id] {
+			r.publish(pb.Event_UPDATE, record, transfer)
+		} else {
+			r.publish(pb.Event_CREATE, record, transfer)
+		}
 	}
 
 	return nil
 }
 
 func (r *Records) Update(record *pb.Record) error {
+	defer r.lockKey(record.Key)()
+	return r.update(record)
+}
+
+func (r *Records) update(record *pb.Record) error {
+	// meta data maps are replaced and not modified by updates
+	var oldMeta map[string]string
+	var oldTyped map[string]*pb.MetaValue
+	var oldKey string
+	r.RLock()
+	schema := r.schema
+	if m, found := r.index[record.Id]; found {
+		oldMeta, oldTyped = m.(*pb.Record).Meta, m.(*pb.Record).TypedMeta
+		oldKey = m.(*pb.Record).Key
+	}
+	keyErr := r._checkKey(record.Key, record.Id)
+	r.RUnlock()
+
+	if err := ValidateSparse(record); err != nil {
+		return err
+	} else if err := ValidateQuantized(record); err != nil {
+		return err
+	} else if record.ClearKey && record.Key != "" {
+		return ErrClearKey
+	} else if keyErr != nil {
+		return keyErr
+	}
+
+	if schema != nil {
+		// the required meta keys are checked against what the record
+		// will have after the update
+		newMeta, newTyped := oldMeta, oldTyped
+		if record.Meta != nil {
+			newMeta = record.Meta
+		}
+		if record.TypedMeta != nil {
+			newTyped = record.TypedMeta
+		}
+
+		if err := ValidateUpdate(schema, record); err != nil {
+			return fmt.Errorf("record does not match the schema: %s", err)
+		} else if err := validateRequiredMeta(schema, newMeta, newTyped); err != nil {
+			return fmt.Errorf("record does not match the schema: %s", err)
+		}
+	}
+
+	quantize(quantizationOf(schema), record)
 	if err := r.Index.Update(record); err != nil {
 		return err
 	}
-	// update the meta index for this record
-	r.metaIndexUpdate(record)
+	defer r.touch()
+	// the stored object now points to the new data
+	if m := r.Index.Find(record.Id); m != nil {
+		stored := m.(*pb.Record)
+		if record.Meta != nil || record.TypedMeta != nil {
+			r.Lock()
+			r._metaIndexUpdate(stored.Id, oldMeta, stored)
+			r._sortedIndexRemove(stored.Id, oldMeta, oldTyped)
+			r._sortedIndexCreate(stored)
+			r.Unlock()
+		}
+		if stored.Key != oldKey {
+			r.Lock()
+			r._keyIndexRemove(stored.Id, oldKey)
+			r._keyIndexCreate(stored)
+			r.Unlock()
+		}
+		r.expiryUpdate(stored)
+		if err := r.arenaPut(stored); err != nil {
+			return err
+		}
+		r.publish(pb.Event_UPDATE, stored, false)
+	}
 	return nil
 }
 
+// Replace removes every stored pb.Record and replaces them with the given
+// ones, rebuilding the meta index.
+func (r *Records) Replace(records []*pb.Record, nextID uint64) error {
+	arg := make([]proto.Message, 0, len(records))
+	for _, r := range records {
+		arg = append(arg, r)
+	}
+
+	err := r.Index.Replace(arg, nextID)
+
+	r.Lock()
+	defer r.Unlock()
+	defer r.touch()
+
+	if r.arena != nil {
+		r.arena.reset(r.Generation())
+	}
+
+	r.metaBy = make(map[string]metaIndex)
+	r.sortedBy = make(map[string]sortedIndex)
+	r.expiring = make(map[uint64]int64)
+	r.keys = make(map[string]uint64)
+	for _, m := range r.index {
+		rec := m.(*pb.Record)
+		r._metaIndexCreate(rec)
+		r._keyIndexCreate(rec)
+		r._sortedIndexCreate(rec)
+		r._expiryUpdate(rec)
+		if aerr := r._arenaPut(rec); aerr != nil && err == nil {
+			err = aerr
+		}
+	}
+
+	r.publish(pb.Event_RESET, nil, false)
+
+	return err
+}
+
+// Close unloads the records and releases their arena if enabled,
+// the records should not be used after this.
+func (r *Records) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.metaBy = make(map[string]metaIndex)
+	r.sortedBy = make(map[string]sortedIndex)
+	r.expiring = make(map[uint64]int64)
+	r.keys = make(map[string]uint64)
+
+	r.viewLock.Lock()
+	r.view = nil
+	r.viewLock.Unlock()
+
+	err := r._close()
+	if r.trash != nil {
+		if terr := r.trash.Close(); terr != nil && err == nil {
+			err = terr
+		}
+	}
+	if r.arena != nil {
+		if aerr := r.arena.Close(); aerr != nil && err == nil {
+			err = aerr
+		}
+		r.arena = nil
+	}
+	return err
+}
+
 // Delete removes a stored pb.Record from the index given its identifier,
 // it will return the removed object itself if found, or nil.
 func (r *Records) Delete(id uint64) *pb.Record {
-	if m := r.Index.Delete(id); m != nil {
-		rec := m.(*pb.Record)
-		// remove the record from the meta index
-		r.metaIndexRemove(rec)
-		return rec
+	rec, _ := r.DeleteVersion(id, 0)
+	return rec
+}
+
+// DeleteVersion removes a stored pb.Record from the index given its
+// identifier and, if not 0, the version it is expected to have. It
+// returns ErrVersionConflict and the stored record if the versions
+// don't match, or ErrRecordNotFound if the record can not be found.
+func (r *Records) DeleteVersion(id uint64, version uint64) (*pb.Record, error) {
+	return r.deleteIf(id, pb.Event_DELETE, func(rec *pb.Record) error {
+		if version != 0 && rec.Version != version {
+			return ErrVersionConflict
+		}
+		return nil
+	})
+}
+
+func (r *Records) deleteIf(id uint64, t pb.Event_Type, check func(rec *pb.Record) error) (*pb.Record, error) {
+	m, err := r.Index.DeleteIf(id, func(m proto.Message) error {
+		return check(m.(*pb.Record))
+	})
+	if m == nil {
+		return nil, err
 	}
-	return nil
+
+	rec := m.(*pb.Record)
+	if err != nil {
+		return rec, err
+	}
+
+	defer r.touch()
+	// remove the record from the meta index
+	r.metaIndexRemove(rec)
+
+	r.Lock()
+	defer r.Unlock()
+	r._sortedIndexRemove(rec.Id, rec.Meta, rec.TypedMeta)
+	r._keyIndexRemove(rec.Id, rec.Key)
+	delete(r.expiring, rec.Id)
+	r._arenaRemove(rec)
+	if t == pb.Event_DELETE {
+		r.toTrash([]*pb.Record{rec})
+	}
+	r.publish(t, rec, false)
+	return rec, nil
 }
 
+// DeleteMany removes the stored records with the given identifiers
+// and returns the ones that were found.
 func (r *Records) DeleteMany(ids []uint64) []*pb.Record {
+	return r.deleteMany(ids, false)
+}
+
+// TransferOut removes the records that have been moved to another node,
+// the changes are published as part of a transfer.
+func (r *Records) TransferOut(ids []uint64) []*pb.Record {
+	return r.deleteMany(ids, true)
+}
+
+func (r *Records) deleteMany(ids []uint64, transfer bool) []*pb.Record {
 	res := make([]*pb.Record, 0, len(ids))
 
 	deleted := r.Index.DeleteMany(ids)
@@ -196,12 +746,23 @@ func (r *Records) DeleteMany(ids []uint64) []*pb.Record {
 
 	r.Lock()
 	defer r.Unlock()
+	defer r.touch()
 
 	for _, record := range deleted {
 		rec := record.(*pb.Record)
-		r._metaIndexRemove(rec)
+		r._metaIndexRemove(rec.Id, rec.Meta)
+		r._sortedIndexRemove(rec.Id, rec.Meta, rec.TypedMeta)
+		r._keyIndexRemove(rec.Id, rec.Key)
+		delete(r.expiring, rec.Id)
+		r._arenaRemove(rec)
+		r.publish(pb.Event_DELETE, rec, transfer)
 		res = append(res, rec)
 	}
 
+	// transferred records still exist on another node
+	if !transfer {
+		r.toTrash(res)
+	}
+
 	return res
 }
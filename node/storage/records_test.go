@@ -81,10 +81,16 @@ func TestLoadRecordsWithCorruptedData(t *testing.T) {
 		t.Fatal("expected error")
 	} else if records != nil {
 		t.Fatal("expected no storage loaded")
-	} else if records, err := LoadRecords(testFolder); err == nil {
-		t.Fatal("expected error due to broken record dat file")
-	} else if records != nil {
-		t.Fatal("expected no storage loaded due to corrupted record dat file")
+	} else if records, err := LoadRecords(testFolder); err != nil {
+		t.Fatal(err)
+	} else if records.Size() != 0 {
+		t.Fatalf("expected empty records storage, found %d instead", records.Size())
+	} else if bad := records.Quarantined(); len(bad) != 1 {
+		t.Fatalf("expected 1 quarantined file, got %d", len(bad))
+	} else if _, err := os.Stat(testCorruptedRecord); err == nil {
+		t.Fatal("expected corrupted record dat file to be moved")
+	} else if _, err := os.Stat(bad[0]); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -185,3 +191,122 @@ func TestRecordsDeleteWithInvalidId(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordsVersion(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := records.Find(1)
+	if stored.Version != 1 {
+		t.Fatalf("expected version 1, got %d", stored.Version)
+	} else if stored.UpdatedAt == 0 {
+		t.Fatal("expected update time to be set")
+	}
+
+	created := stored.UpdatedAt
+
+	// unconditional update
+	if err := records.Update(&pb.Record{Id: 1, Data: []float32{1}}); err != nil {
+		t.Fatal(err)
+	} else if stored = records.Find(1); stored.Version != 2 {
+		t.Fatalf("expected version 2, got %d", stored.Version)
+	} else if stored.UpdatedAt < created {
+		t.Fatal("expected update time to be refreshed")
+	}
+
+	// conditional update with the right version
+	if err := records.Update(&pb.Record{Id: 1, Data: []float32{2}, Version: 2}); err != nil {
+		t.Fatal(err)
+	} else if stored = records.Find(1); stored.Version != 3 {
+		t.Fatalf("expected version 3, got %d", stored.Version)
+	}
+
+	// conditional update with a stale version
+	if err := records.Update(&pb.Record{Id: 1, Data: []float32{3}, Version: 2}); err != ErrVersionConflict {
+		t.Fatalf("expected version conflict, got %v", err)
+	} else if stored = records.Find(1); stored.Version != 3 || stored.Data[0] != 2 {
+		t.Fatalf("record should not be changed: %v", stored)
+	}
+
+	// versions are persisted
+	if reloaded, err := LoadRecords(testFolder); err != nil {
+		t.Fatal(err)
+	} else if stored = reloaded.Find(1); stored.Version != 3 {
+		t.Fatalf("expected version 3 after reload, got %d", stored.Version)
+	}
+}
+
+func TestRecordsDeleteVersion(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := records.DeleteVersion(666, 1); err != ErrRecordNotFound {
+		t.Fatalf("expected record not found, got %v", err)
+	} else if stored, err := records.DeleteVersion(1, 2); err != ErrVersionConflict {
+		t.Fatalf("expected version conflict, got %v", err)
+	} else if stored == nil || stored.Version != 1 {
+		t.Fatalf("expected stored record with version 1, got %v", stored)
+	} else if records.Find(1) == nil {
+		t.Fatal("record should not be deleted")
+	} else if deleted, err := records.DeleteVersion(1, 1); err != nil {
+		t.Fatal(err)
+	} else if deleted.Id != 1 {
+		t.Fatalf("unexpected deleted record %d", deleted.Id)
+	} else if records.Find(1) != nil {
+		t.Fatal("record should be deleted")
+	}
+}
+
+func TestRecordsCreateWithIdKeepsVersion(t *testing.T) {
+	setupRecords(t, false, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moved := &pb.Record{Id: 10, Data: []float32{1}, Version: 7, UpdatedAt: 123}
+	if err := records.CreateWithId(moved); err != nil {
+		t.Fatal(err)
+	} else if stored := records.Find(10); stored.Version != 7 || stored.UpdatedAt != 123 {
+		t.Fatalf("expected version and update time to be kept: %v", stored)
+	} else if err := records.CreateWithId(&pb.Record{Id: 11, Data: []float32{1}}); err != nil {
+		t.Fatal(err)
+	} else if stored := records.Find(11); stored.Version != 1 || stored.UpdatedAt == 0 {
+		t.Fatalf("expected first version: %v", stored)
+	}
+}
+
+func TestRecordsViewDroppedOnClose(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := 0
+	create := func() interface{} {
+		created++
+		return &created
+	}
+	if first, second := records.View(create), records.View(create); first != second || created != 1 {
+		t.Fatalf("expected the view to be created once, got %d", created)
+	} else if err := records.Close(); err != nil {
+		t.Fatal(err)
+	} else if records.View(create); created != 2 {
+		t.Fatal("expected the view to be dropped on close")
+	}
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// the header of a safetensors file can not be bigger than this
+const safetensorsMaxHeader = 100 * 1024 * 1024
+
+const safetensorsMetadata = "__metadata__"
+
+var safetensorsTypes = map[string]dtype{
+	"F64":  {kind: 'f', size: 8, order: binary.LittleEndian},
+	"F32":  {kind: 'f', size: 4, order: binary.LittleEndian},
+	"F16":  {kind: 'f', size: 2, order: binary.LittleEndian},
+	"BF16": {kind: 'B', size: 2, order: binary.LittleEndian},
+	"I64":  {kind: 'i', size: 8, order: binary.LittleEndian},
+	"I32":  {kind: 'i', size: 4, order: binary.LittleEndian},
+	"I16":  {kind: 'i', size: 2, order: binary.LittleEndian},
+	"I8":   {kind: 'i', size: 1, order: binary.LittleEndian},
+	"U64":  {kind: 'u', size: 8, order: binary.LittleEndian},
+	"U32":  {kind: 'u', size: 4, order: binary.LittleEndian},
+	"U16":  {kind: 'u', size: 2, order: binary.LittleEndian},
+	"U8":   {kind: 'u', size: 1, order: binary.LittleEndian},
+	"BOOL": {kind: 'b', size: 1, order: binary.LittleEndian},
+}
+
+type safetensorsInfo struct {
+	Dtype       string    `json:"dtype"`
+	Shape       []uint64  `json:"shape"`
+	DataOffsets [2]uint64 `json:"data_offsets"`
+}
+
+func readSafetensors(r io.ReaderAt, size int64, name string) (*arrayData, error) {
+	head := make([]byte, 8)
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return nil, fmt.Errorf("error while reading the safetensors header: %s", err)
+	}
+
+	headerSize := binary.LittleEndian.Uint64(head)
+	if headerSize > safetensorsMaxHeader || int64(headerSize) > size-8 {
+		return nil, fmt.Errorf("unexpected safetensors header size %d", headerSize)
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, 8); err != nil {
+		return nil, fmt.Errorf("error while reading the safetensors header: %s", err)
+	}
+
+	entries := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(header, &entries); err != nil {
+		return nil, fmt.Errorf("error while parsing the safetensors header: %s", err)
+	}
+	delete(entries, safetensorsMetadata)
+
+	names := make([]string, 0, len(entries))
+	for tensor := range entries {
+		names = append(names, tensor)
+	}
+	sort.Strings(names)
+
+	if name == "" && len(names) == 1 {
+		name = names[0]
+	}
+
+	raw, found := entries[name]
+	if !found {
+		if name == "" {
+			return nil, fmt.Errorf("the file has %d tensors, choose one of: %s", len(names), strings.Join(names, ", "))
+		}
+		return nil, fmt.Errorf("tensor %s not found, choose one of: %s", name, strings.Join(names, ", "))
+	}
+
+	var info safetensorsInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return nil, fmt.Errorf("error while parsing tensor %s: %s", name, err)
+	}
+
+	t, found := safetensorsTypes[info.Dtype]
+	if !found {
+		return nil, fmt.Errorf("unsupported safetensors type %s", info.Dtype)
+	}
+
+	n, err := numElements(info.Shape)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := info.DataOffsets[0], info.DataOffsets[1]
+	dataStart := uint64(8 + headerSize)
+	if end < start || dataStart+end > uint64(size) {
+		return nil, fmt.Errorf("tensor %s has invalid data offsets %v", name, info.DataOffsets)
+	}
+
+	if end-start != n*uint64(t.size) {
+		return nil, fmt.Errorf("tensor %s: expected %d bytes of data, got %d", name, n*uint64(t.size), end-start)
+	}
+
+	return &arrayData{
+		shape: info.Shape,
+		t:     t,
+		r:     io.NewSectionReader(r, int64(dataStart+start), int64(end-start)),
+	}, nil
+}
+
+func writeSafetensors(w io.Writer, name string, shape []uint64, data func(w io.Writer) error) error {
+	n, err := numElements(shape)
+	if err != nil {
+		return err
+	}
+
+	header, err := json.Marshal(map[string]safetensorsInfo{
+		name: {
+			Dtype:       "F32",
+			Shape:       shape,
+			DataOffsets: [2]uint64{0, n * 4},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// the data is aligned to 8 bytes padding the header with spaces
+	for len(header)%8 != 0 {
+		header = append(header, ' ')
+	}
+
+	buf := bufio.NewWriter(w)
+	binary.Write(buf, binary.LittleEndian, uint64(len(header)))
+	buf.Write(header)
+	if err := data(buf); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// builds a safetensors file with a F16 and a BF16 tensor
+func makeSafetensors() []byte {
+	header := `{"__metadata__":{"format":"pt"},` +
+		`"half":{"dtype":"F16","shape":[2,1],"data_offsets":[0,4]},` +
+		`"brain":{"dtype":"BF16","shape":[1,2],"data_offsets":[4,8]}}`
+
+	buf := bytes.Buffer{}
+	binary.Write(&buf, binary.LittleEndian, uint64(len(header)))
+	buf.WriteString(header)
+	binary.Write(&buf, binary.LittleEndian, []uint16{0x3c00, 0xc000, 0x3f80, 0xc040})
+	return buf.Bytes()
+}
+
+func TestReadSafetensors(t *testing.T) {
+	raw := makeSafetensors()
+	r := bytes.NewReader(raw)
+
+	if a, err := ReadArray(r, r.Size(), pb.ArrayHeader_SAFETENSORS, "half"); err != nil {
+		t.Fatal(err)
+	} else if expected := (&Array{Shape: []uint64{2, 1}, Data: []float32{1, -2}}); !reflect.DeepEqual(a, expected) {
+		t.Fatalf("expected %v, got %v", expected, a)
+	}
+
+	if a, err := ReadArray(r, r.Size(), pb.ArrayHeader_SAFETENSORS, "brain"); err != nil {
+		t.Fatal(err)
+	} else if expected := (&Array{Shape: []uint64{1, 2}, Data: []float32{1, -3}}); !reflect.DeepEqual(a, expected) {
+		t.Fatalf("expected %v, got %v", expected, a)
+	}
+
+	if _, err := ReadArray(r, r.Size(), pb.ArrayHeader_SAFETENSORS, ""); err == nil {
+		t.Fatal("expected error when not choosing one of many tensors")
+	}
+
+	// header bigger than the file
+	binary.LittleEndian.PutUint64(raw, uint64(len(raw)))
+	r = bytes.NewReader(raw)
+	if _, err := ReadArray(r, r.Size(), pb.ArrayHeader_SAFETENSORS, "half"); err == nil {
+		t.Fatal("expected error for invalid header size")
+	}
+}
+
+func TestWriteSafetensors(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := WriteArray(&buf, pb.ArrayHeader_SAFETENSORS, "test", &testArray); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := buf.Bytes()
+	headerSize := binary.LittleEndian.Uint64(raw)
+	if headerSize%8 != 0 {
+		t.Fatalf("data is not aligned to 8 bytes: %d", headerSize)
+	} else if uint64(len(raw)) != 8+headerSize+uint64(len(testArray.Data))*4 {
+		t.Fatalf("unexpected size %d", len(raw))
+	}
+}
@@ -3,17 +3,78 @@ package storage
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"github.com/golang/protobuf/proto"
 )
 
-// Flush serializes and saves to file a generic protobuf message.
+const (
+	// TmpFileExt holds the file extension of the temporary files used
+	// while atomically writing data files.
+	TmpFileExt = ".tmp"
+)
+
+// WriteAtomic writes data to a temporary file in the same folder of
+// fileName, syncs it to disk and then renames it to fileName, so that
+// a crash during the write never leaves a truncated file behind.
+// Records are not saved with it but appended to the segment log, which
+// is synced once per write operation and whose torn frames are detected
+// by their checksum and dropped when loading.
+func WriteAtomic(fileName string, data []byte, perm os.FileMode) (err error) {
+	dir, base := filepath.Split(fileName)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+base+".*"+TmpFileExt)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	} else if err = tmp.Sync(); err != nil {
+		return err
+	} else if err = tmp.Chmod(perm); err != nil {
+		return err
+	} else if err = tmp.Close(); err != nil {
+		return err
+	} else if err = os.Rename(tmp.Name(), fileName); err != nil {
+		return err
+	}
+
+	// make the rename itself durable
+	syncDir(dir)
+
+	return nil
+}
+
+// syncDir makes the creation, rename and removal of the files of
+// a folder durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// Flush serializes and atomically saves to file a generic protobuf message.
 // It returns an error if unsuccessful.
 func Flush(m proto.Message, fileName string) error {
 	data, err := proto.Marshal(m)
 	if err != nil {
 		return fmt.Errorf("Error while serializing message to %s: %s", fileName, err)
-	} else if err = ioutil.WriteFile(fileName, data, 0755); err != nil {
+	} else if err = WriteAtomic(fileName, data, 0755); err != nil {
 		return fmt.Errorf("Error while saving message to %s: %s", fileName, err)
 	}
 	return nil
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -47,3 +48,23 @@ func TestStorageFlushAndBack(t *testing.T) {
 		t.Fatal("records should be the same")
 	}
 }
+
+func TestStorageFlushIsAtomic(t *testing.T) {
+	var rec pb.Record
+	if err := Flush(&testRecord, testDatFile); err != nil {
+		t.Fatal(err)
+	} else if err := Flush(&updatedRecord, testDatFile); err != nil {
+		t.Fatal(err)
+	} else if err := Load(testDatFile, &rec); err != nil {
+		t.Fatal(err)
+	} else if !sameRecord(rec, updatedRecord) {
+		t.Fatal("records should be the same")
+	}
+
+	leftovers, err := filepath.Glob(filepath.Join(filepath.Dir(testDatFile), ".*"+TmpFileExt))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(leftovers) != 0 {
+		t.Fatalf("unexpected temporary files left: %v", leftovers)
+	}
+}
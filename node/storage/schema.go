@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// SchemaFileExt holds the file extension of the schema files.
+const SchemaFileExt = ".schema"
+
+// EmptySchema returns true if the schema does not enforce or configure anything.
+func EmptySchema(schema *pb.Schema) bool {
+	return schema == nil || (schema.Dimension == 0 && len(schema.Shape) == 0 &&
+		len(schema.RequiredMeta) == 0 && len(schema.MetaTypes) == 0 &&
+		schema.Quantization == pb.QuantizedVector_FLOAT32)
+}
+
+// CheckSchema returns an error if the schema is inconsistent.
+func CheckSchema(schema *pb.Schema) error {
+	if schema == nil {
+		return nil
+	} else if len(schema.Shape) > 0 {
+		size := uint64(1)
+		for _, dim := range schema.Shape {
+			size *= dim
+		}
+		if size == 0 {
+			return fmt.Errorf("schema shape %v has a zero dimension", schema.Shape)
+		} else if schema.Dimension > 0 && schema.Dimension != size {
+			return fmt.Errorf("schema shape %v does not have %d elements", schema.Shape, schema.Dimension)
+		}
+	}
+
+	for key, t := range schema.MetaTypes {
+		if _, found := pb.Schema_Type_name[int32(t)]; !found {
+			return fmt.Errorf("unknown type %d for meta %s", t, key)
+		}
+	}
+
+	if _, found := pb.QuantizedVector_Type_name[int32(schema.Quantization)]; !found {
+		return fmt.Errorf("unknown quantization %d", schema.Quantization)
+	}
+
+	return nil
+}
+
+func validateData(schema *pb.Schema, elems uint64, shape []uint64) error {
+	if schema.Dimension > 0 && elems != schema.Dimension {
+		return fmt.Errorf("expected a vector of %d elements, got %d", schema.Dimension, elems)
+	} else if len(schema.Shape) == 0 {
+		return nil
+	}
+
+	// records created without a shape are 1d
+	if len(shape) == 0 {
+		shape = []uint64{elems}
+	}
+
+	if len(shape) != len(schema.Shape) {
+		return fmt.Errorf("expected a vector of shape %v, got %v", schema.Shape, shape)
+	}
+
+	size := uint64(1)
+	for i, dim := range shape {
+		if dim != schema.Shape[i] {
+			return fmt.Errorf("expected a vector of shape %v, got %v", schema.Shape, shape)
+		}
+		size *= dim
+	}
+
+	if elems != size {
+		return fmt.Errorf("expected a vector of %d elements for shape %v, got %d", size, shape, elems)
+	}
+
+	return nil
+}
+
+func metaType(value *pb.MetaValue) pb.Schema_Type {
+	switch value.GetValue().(type) {
+	case *pb.MetaValue_Text:
+		return pb.Schema_TEXT
+	case *pb.MetaValue_Number:
+		return pb.Schema_NUMBER
+	case *pb.MetaValue_Time:
+		return pb.Schema_TIME
+	}
+	return pb.Schema_ANY
+}
+
+func validateMetaTypes(schema *pb.Schema, meta map[string]string, typed map[string]*pb.MetaValue) error {
+	for key := range meta {
+		if t, found := schema.MetaTypes[key]; found && t != pb.Schema_ANY && t != pb.Schema_TEXT {
+			return fmt.Errorf("meta %s must be of type %s, got %s", key, t, pb.Schema_TEXT)
+		}
+	}
+
+	for key, value := range typed {
+		if t, found := schema.MetaTypes[key]; found && t != pb.Schema_ANY && t != metaType(value) {
+			return fmt.Errorf("meta %s must be of type %s, got %s", key, t, metaType(value))
+		}
+	}
+
+	return nil
+}
+
+func validateRequiredMeta(schema *pb.Schema, meta map[string]string, typed map[string]*pb.MetaValue) error {
+	for _, key := range schema.RequiredMeta {
+		_, isText := meta[key]
+		_, isTyped := typed[key]
+		if !isText && !isTyped {
+			return fmt.Errorf("missing required meta %s", key)
+		}
+	}
+	return nil
+}
+
+// ValidateRecord returns an error if a record being created does
+// not match the schema.
+func ValidateRecord(schema *pb.Schema, record *pb.Record) error {
+	if EmptySchema(schema) {
+		return nil
+	} else if err := validateData(schema, VectorSize(record), record.Shape); err != nil {
+		return err
+	} else if err := validateMetaTypes(schema, record.Meta, record.TypedMeta); err != nil {
+		return err
+	}
+	return validateRequiredMeta(schema, record.Meta, record.TypedMeta)
+}
+
+// ValidateUpdate returns an error if the fields set by an update
+// do not match the schema. Since updates only replace the fields
+// they set, the required meta keys are not checked.
+func ValidateUpdate(schema *pb.Schema, update *pb.Record) error {
+	if EmptySchema(schema) {
+		return nil
+	} else if update.Data != nil || update.Sparse != nil || update.Quantized != nil || update.Shape != nil {
+		if err := validateData(schema, VectorSize(update), update.Shape); err != nil {
+			return err
+		}
+	}
+	return validateMetaTypes(schema, update.Meta, update.TypedMeta)
+}
+
+// LoadSchema loads a schema from file, it returns nil if the
+// file does not exist.
+func LoadSchema(fileName string) (*pb.Schema, error) {
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	schema := new(pb.Schema)
+	if err := Load(fileName, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// SaveSchema saves a schema to file, or removes the file if the
+// schema is empty.
+func SaveSchema(fileName string, schema *pb.Schema) error {
+	if EmptySchema(schema) {
+		if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return Flush(schema, fileName)
+}
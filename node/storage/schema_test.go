@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+var testSchema = pb.Schema{
+	Dimension:    6,
+	Shape:        []uint64{2, 3},
+	RequiredMeta: []string{"label"},
+	MetaTypes: map[string]pb.Schema_Type{
+		"label": pb.Schema_TEXT,
+		"score": pb.Schema_NUMBER,
+	},
+}
+
+func TestCheckSchema(t *testing.T) {
+	if err := CheckSchema(nil); err != nil {
+		t.Fatal(err)
+	} else if err := CheckSchema(&testSchema); err != nil {
+		t.Fatal(err)
+	} else if err := CheckSchema(&pb.Schema{Shape: []uint64{2, 0}}); err == nil {
+		t.Fatal("expected error for zero dimension")
+	} else if err := CheckSchema(&pb.Schema{Dimension: 5, Shape: []uint64{2, 3}}); err == nil {
+		t.Fatal("expected error for shape not matching the dimension")
+	} else if err := CheckSchema(&pb.Schema{MetaTypes: map[string]pb.Schema_Type{"x": 42}}); err == nil {
+		t.Fatal("expected error for unknown meta type")
+	}
+}
+
+func TestValidateRecord(t *testing.T) {
+	data := []float32{1, 2, 3, 4, 5, 6}
+	label := map[string]string{"label": "cat"}
+	score := map[string]*pb.MetaValue{"score": {Value: &pb.MetaValue_Number{Number: 0.5}}}
+
+	tests := []struct {
+		name   string
+		record pb.Record
+		valid  bool
+	}{
+		{"valid", pb.Record{Data: data, Shape: []uint64{2, 3}, Meta: label, TypedMeta: score}, true},
+		{"typed label", pb.Record{Data: data, Shape: []uint64{2, 3},
+			TypedMeta: map[string]*pb.MetaValue{"label": {Value: &pb.MetaValue_Text{Text: "cat"}}}}, true},
+		{"wrong dimension", pb.Record{Data: data[:5], Shape: []uint64{5}, Meta: label}, false},
+		{"wrong shape", pb.Record{Data: data, Shape: []uint64{3, 2}, Meta: label}, false},
+		{"no shape", pb.Record{Data: data, Meta: label}, false},
+		{"missing meta", pb.Record{Data: data, Shape: []uint64{2, 3}}, false},
+		{"text score", pb.Record{Data: data, Shape: []uint64{2, 3}, Meta: map[string]string{"label": "cat", "score": "high"}}, false},
+		{"time score", pb.Record{Data: data, Shape: []uint64{2, 3}, Meta: label,
+			TypedMeta: map[string]*pb.MetaValue{"score": {Value: &pb.MetaValue_Time{Time: 1}}}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := ValidateRecord(&testSchema, &test.record); test.valid && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			} else if !test.valid && err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+
+	if err := ValidateRecord(nil, &pb.Record{}); err != nil {
+		t.Fatalf("unexpected error without a schema: %s", err)
+	} else if err := ValidateUpdate(&testSchema, &pb.Record{Id: 1, TypedMeta: score}); err != nil {
+		t.Fatalf("unexpected error for partial update: %s", err)
+	} else if err := ValidateUpdate(&testSchema, &pb.Record{Id: 1, Data: data[:3]}); err == nil {
+		t.Fatal("expected error for update with the wrong dimension")
+	}
+}
+
+func TestRecordsSchema(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the stored records have 3 elements
+	if err := records.SetSchema(&testSchema); err == nil {
+		t.Fatal("expected error for existing records not matching the schema")
+	} else if records.Schema() != nil {
+		t.Fatalf("unexpected schema %v", records.Schema())
+	} else if err := records.SetSchema(&pb.Schema{Dimension: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := records.Create(&pb.Record{Data: []float32{1, 2}}); err == nil {
+		t.Fatal("expected error for record with the wrong dimension")
+	} else if err := records.CreateManyWIthId([]*pb.Record{{Id: 1000, Data: []float32{1, 2, 3}}, {Id: 1001}}); err == nil {
+		t.Fatal("expected error for records with the wrong dimension")
+	} else if records.Find(1000) != nil {
+		t.Fatal("records should not be partially created")
+	} else if err := records.Update(&pb.Record{Id: testRecord.Id, Data: []float32{1}}); err == nil {
+		t.Fatal("expected error for update with the wrong dimension")
+	}
+
+	if err := records.SetSchema(&pb.Schema{Dimension: 3, RequiredMeta: []string{"666"}}); err != nil {
+		t.Fatal(err)
+	} else if err := records.Update(&pb.Record{Id: testRecord.Id, Data: []float32{1, 2, 3}}); err != nil {
+		t.Fatalf("the required meta is kept by the update: %s", err)
+	} else if err := records.Update(&pb.Record{Id: testRecord.Id, Meta: map[string]string{"other": "x"}}); err == nil {
+		t.Fatal("expected error for update removing the required meta")
+	}
+
+	// an empty schema removes every constraint
+	if err := records.SetSchema(&pb.Schema{}); err != nil {
+		t.Fatal(err)
+	} else if records.Schema() != nil {
+		t.Fatalf("unexpected schema %v", records.Schema())
+	} else if err := records.Create(&pb.Record{Data: []float32{1}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveLoadSchema(t *testing.T) {
+	setupRecords(t, false, false)
+	defer teardownRecords(t)
+
+	fileName := filepath.Join(testFolder, "test"+SchemaFileExt)
+	if schema, err := LoadSchema(fileName); err != nil {
+		t.Fatal(err)
+	} else if schema != nil {
+		t.Fatalf("unexpected schema %v", schema)
+	}
+
+	if err := SaveSchema(fileName, &testSchema); err != nil {
+		t.Fatal(err)
+	} else if schema, err := LoadSchema(fileName); err != nil {
+		t.Fatal(err)
+	} else if schema.Dimension != testSchema.Dimension || len(schema.MetaTypes) != 2 || schema.RequiredMeta[0] != "label" {
+		t.Fatalf("unexpected schema %v", schema)
+	}
+
+	if err := SaveSchema(fileName, nil); err != nil {
+		t.Fatal(err)
+	} else if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+		t.Fatal("expected schema file to be removed")
+	}
+}
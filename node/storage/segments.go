@@ -0,0 +1,588 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/evilsocket/islazy/log"
+)
+
+const (
+	// SegFileExt holds the file extension for log segment files.
+	SegFileExt = ".seg"
+	// DefaultSegmentSize is the size in bytes after which a segment
+	// is sealed and a new one is started.
+	DefaultSegmentSize = 64 * 1024 * 1024
+	// DefaultCompactionRatio is the ratio of garbage bytes over the
+	// total size of the log that triggers a compaction.
+	DefaultCompactionRatio = 0.5
+
+	// size (4) + crc32 (4) + id (8) + op (1)
+	frameHeaderSize = 17
+
+	opPut = byte(1)
+	opDel = byte(2)
+	// records the highest identifier ever used, written when compacting
+	// since the tombstones in the compacted segments are dropped
+	opMark = byte(3)
+)
+
+var (
+	// ErrCorruptedFrame is returned when a frame of a segment can not be
+	// decoded or its checksum does not match its contents.
+	ErrCorruptedFrame = errors.New("corrupted segment frame")
+
+	segmentSize     = int64(DefaultSegmentSize)
+	compactionRatio = DefaultCompactionRatio
+
+	// removes the sealed segments once compacted, replaced by the tests
+	// to interrupt a compaction
+	removeSegment = os.Remove
+)
+
+// SetSegmentSize sets the size in bytes after which a segment is
+// sealed and a new one is started.
+func SetSegmentSize(size int64) {
+	segmentSize = size
+}
+
+// SetCompactionRatio sets the ratio of garbage bytes over the total
+// size of the log that triggers an automatic compaction.
+func SetCompactionRatio(ratio float64) {
+	compactionRatio = ratio
+}
+
+type segment struct {
+	id   uint64
+	path string
+	size int64
+	live int64
+}
+
+type location struct {
+	segment uint64
+	offset  int64
+	size    int64
+}
+
+// segmentLog is an append only log of protobuf encoded objects, split
+// into numbered segment files and indexed in memory by an offset table.
+// Appending a frame does not sync it, the storage.Index calls sync once
+// at the end of every write operation before acknowledging it, so each
+// single write costs an fsync and each batch costs only one.
+// NOTE: it is not thread safe, the storage.Index is responsible of
+// serializing access to it.
+type segmentLog struct {
+	dataPath    string
+	segments    map[uint64]*segment
+	offsets     map[uint64]location
+	active      *segment
+	fp          *os.File
+	compacting  bool
+	quarantined []string
+	// highest identifier ever written to the log, deleted ones included
+	highest uint64
+}
+
+func newSegmentLog(dataPath string) *segmentLog {
+	return &segmentLog{
+		dataPath: dataPath,
+		segments: make(map[uint64]*segment),
+		offsets:  make(map[uint64]location),
+	}
+}
+
+func (l *segmentLog) pathForSegment(id uint64) string {
+	return filepath.Join(l.dataPath, fmt.Sprintf("%08d%s", id, SegFileExt))
+}
+
+func encodeFrame(op byte, id uint64, data []byte) []byte {
+	frame := make([]byte, frameHeaderSize+len(data))
+	binary.LittleEndian.PutUint32(frame[0:], uint32(len(data)))
+	binary.LittleEndian.PutUint64(frame[8:], id)
+	frame[16] = op
+	copy(frame[frameHeaderSize:], data)
+	binary.LittleEndian.PutUint32(frame[4:], crc32.ChecksumIEEE(frame[8:]))
+	return frame
+}
+
+func decodeFrame(buf []byte) (op byte, id uint64, data []byte, size int64, err error) {
+	if len(buf) < frameHeaderSize {
+		return 0, 0, nil, 0, ErrCorruptedFrame
+	}
+
+	dataSize := int64(binary.LittleEndian.Uint32(buf[0:]))
+	size = frameHeaderSize + dataSize
+	if int64(len(buf)) < size {
+		return 0, 0, nil, 0, ErrCorruptedFrame
+	}
+
+	// the op is checked before the crc since it is way cheaper and
+	// this is called at every byte while resyncing after a bad frame
+	op = buf[16]
+	if op != opPut && op != opDel && op != opMark {
+		return 0, 0, nil, 0, ErrCorruptedFrame
+	} else if crc32.ChecksumIEEE(buf[8:size]) != binary.LittleEndian.Uint32(buf[4:]) {
+		return 0, 0, nil, 0, ErrCorruptedFrame
+	}
+
+	return op, binary.LittleEndian.Uint64(buf[8:]), buf[frameHeaderSize:size], size, nil
+}
+
+// listSegments returns the sorted identifiers of the segment files
+// found in a given folder.
+func listSegments(dataPath string) ([]uint64, error) {
+	files, err := ioutil.ReadDir(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0)
+	for _, file := range files {
+		fileName := file.Name()
+		if filepath.Ext(fileName) != SegFileExt {
+			continue
+		}
+
+		id, err := strconv.ParseUint(strings.TrimSuffix(fileName, SegFileExt), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected segment file name %s", fileName)
+		}
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
+
+func (l *segmentLog) close() error {
+	if l.fp == nil {
+		return nil
+	}
+	err := l.fp.Close()
+	l.fp = nil
+	return err
+}
+
+type frame struct {
+	op     byte
+	id     uint64
+	data   []byte
+	offset int64
+	size   int64
+}
+
+// a range of bytes of a segment that can not be decoded
+type damage struct {
+	offset int64
+	size   int64
+}
+
+// scan is the result of reading and verifying a segment file, frames
+// holds every valid frame and damaged the ranges of bytes between them
+// that could not be decoded, size is the end of the last valid frame.
+type scan struct {
+	buf     []byte
+	frames  []frame
+	damaged []damage
+	size    int64
+	err     error
+}
+
+// returns the offset of the first frame that can be decoded after
+// the given one, or the size of the buffer if there's none
+func resync(buf []byte, offset int64) int64 {
+	for ; offset < int64(len(buf)); offset++ {
+		if _, _, _, _, err := decodeFrame(buf[offset:]); err == nil {
+			break
+		}
+	}
+	return offset
+}
+
+func scanSegment(path string) (*scan, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading %s: %s", path, err)
+	}
+
+	s := &scan{buf: buf}
+	for offset := int64(0); offset < int64(len(buf)); {
+		op, id, data, size, err := decodeFrame(buf[offset:])
+		if err != nil {
+			// skip the bad bytes and keep reading from the next frame
+			// so that a single damaged frame does not lose the others
+			next := resync(buf, offset+1)
+			s.damaged = append(s.damaged, damage{offset: offset, size: next - offset})
+			s.err = err
+			offset = next
+			continue
+		}
+		s.frames = append(s.frames, frame{op: op, id: id, data: data, offset: offset, size: size})
+		offset += size
+		s.size = offset
+	}
+	return s, nil
+}
+
+// rewrites a segment with only its valid frames, so that its damaged
+// bytes are not found again by the next load, and updates their offsets
+func rewriteSegment(path string, s *scan) error {
+	buf := make([]byte, 0, s.size)
+	for i := range s.frames {
+		f := &s.frames[i]
+		offset := int64(len(buf))
+		buf = append(buf, s.buf[f.offset:f.offset+f.size]...)
+		f.offset = offset
+	}
+
+	if err := WriteAtomic(path, buf, 0644); err != nil {
+		return err
+	}
+
+	s.buf = buf
+	s.damaged = nil
+	s.size = int64(len(buf))
+	return nil
+}
+
+// load replays the segments found in the data path, rebuilding the
+// offsets table and calling cb once for every live object. Segments
+// are read and verified in parallel and cb is called concurrently by
+// multiple goroutines.
+func (l *segmentLog) load(dataPath string, cb func(id uint64, data []byte) error) error {
+	l.close()
+
+	l.dataPath = dataPath
+	l.segments = make(map[uint64]*segment)
+	l.offsets = make(map[uint64]location)
+	l.active = nil
+	l.quarantined = nil
+	l.highest = 0
+
+	ids, err := listSegments(dataPath)
+	if err != nil {
+		return err
+	} else if len(ids) == 0 {
+		return nil
+	}
+
+	log.Info("loading %d segments from %s ...", len(ids), dataPath)
+
+	scans := make([]*scan, len(ids))
+	prog := startProgress("verifying segments of "+dataPath, len(ids))
+	err = parallel(len(ids), func(n int) (err error) {
+		scans[n], err = scanSegment(l.pathForSegment(ids[n]))
+		prog.inc()
+		return
+	})
+	prog.end()
+	if err != nil {
+		return err
+	}
+
+	// frames must be replayed in order for updates and
+	// tombstones to override previous versions.
+	latest := make(map[uint64][]byte)
+	for n, segID := range ids {
+		seg := &segment{id: segID, path: l.pathForSegment(segID)}
+		scan := scans[n]
+
+		l.segments[segID] = seg
+		l.active = seg
+
+		seg.size = int64(len(scan.buf))
+		quarantined := false
+		for _, bad := range scan.damaged {
+			// a torn frame at the tail of the last segment is the result
+			// of an interrupted write and it's truncated so that new frames
+			// can follow, anywhere else it's corruption and a copy of the
+			// bad bytes is kept before rewriting the segment without them.
+			if bad.offset+bad.size == seg.size && n == len(ids)-1 {
+				log.Warning("truncating %s at offset %d: %s", seg.path, bad.offset, scan.err)
+				seg.size = scan.size
+				if err := os.Truncate(seg.path, seg.size); err != nil {
+					return err
+				}
+				continue
+			}
+
+			name := fmt.Sprintf("%s.%d", filepath.Base(seg.path), bad.offset)
+			dest, qerr := QuarantineData(dataPath, name, scan.buf[bad.offset:bad.offset+bad.size])
+			if qerr != nil {
+				return fmt.Errorf("error while quarantining %s: %s", seg.path, qerr)
+			}
+			log.Error("%s is corrupted from offset %d to %d (%s), a copy of the bytes has been moved to %s",
+				seg.path, bad.offset, bad.offset+bad.size, scan.err, dest)
+			l.quarantined = append(l.quarantined, dest)
+			quarantined = true
+		}
+
+		if quarantined {
+			if err := rewriteSegment(seg.path, scan); err != nil {
+				return fmt.Errorf("error while rewriting %s: %s", seg.path, err)
+			}
+			seg.size = scan.size
+		}
+
+		for _, f := range scan.frames {
+			if f.id > l.highest {
+				l.highest = f.id
+			}
+			if f.op == opMark {
+				continue
+			}
+
+			if old, found := l.offsets[f.id]; found {
+				l.segments[old.segment].live -= old.size
+			}
+
+			if f.op == opPut {
+				l.offsets[f.id] = location{segment: segID, offset: f.offset, size: f.size}
+				seg.live += f.size
+				latest[f.id] = f.data
+			} else {
+				delete(l.offsets, f.id)
+				delete(latest, f.id)
+			}
+		}
+	}
+
+	objIDs := make([]uint64, 0, len(latest))
+	for id := range latest {
+		objIDs = append(objIDs, id)
+	}
+
+	prog = startProgress("loading objects from "+dataPath, len(objIDs))
+	defer prog.end()
+
+	return parallel(len(objIDs), func(n int) error {
+		defer prog.inc()
+		return cb(objIDs[n], latest[objIDs[n]])
+	})
+}
+
+// writable makes sure the active segment is open and has room
+// for more frames, starting a new segment if needed.
+func (l *segmentLog) writable() (err error) {
+	if l.fp != nil && l.active.size < segmentSize {
+		return nil
+	}
+
+	next := l.active
+	if next == nil {
+		next = &segment{id: 1, path: l.pathForSegment(1)}
+	} else if next.size >= segmentSize {
+		next = &segment{id: next.id + 1, path: l.pathForSegment(next.id + 1)}
+	}
+
+	fp, err := os.OpenFile(next.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	} else if err = syncDir(l.dataPath); err != nil {
+		log.Warning("error while syncing %s, a new segment may not survive a power failure: %s", l.dataPath, err)
+	}
+
+	sealed := l.active != nil && l.active != next
+	if l.fp != nil {
+		l.fp.Sync()
+		l.close()
+	}
+
+	l.fp = fp
+	l.active = next
+	l.segments[next.id] = next
+
+	if sealed && !l.compacting && l.garbage() >= compactionRatio {
+		if err := l.compact(); err != nil {
+			log.Error("error while compacting %s: %v", l.dataPath, err)
+		}
+	}
+
+	return nil
+}
+
+func (l *segmentLog) append(op byte, id uint64, data []byte) (location, error) {
+	if err := l.writable(); err != nil {
+		return location{}, err
+	}
+
+	frame := encodeFrame(op, id, data)
+	if _, err := l.fp.Write(frame); err != nil {
+		// do not leave half written frames behind
+		l.fp.Truncate(l.active.size)
+		return location{}, err
+	}
+
+	loc := location{segment: l.active.id, offset: l.active.size, size: int64(len(frame))}
+	l.active.size += loc.size
+	if id > l.highest {
+		l.highest = id
+	}
+	return loc, nil
+}
+
+// mark records that identifiers up to the given one have been used,
+// even if no object with that identifier is in the log anymore.
+func (l *segmentLog) mark(id uint64) error {
+	_, err := l.append(opMark, id, nil)
+	return err
+}
+
+// put appends the serialized object to the log, making it the
+// current version of the object with the given identifier.
+func (l *segmentLog) put(id uint64, data []byte) error {
+	loc, err := l.append(opPut, id, data)
+	if err != nil {
+		return err
+	}
+
+	if old, found := l.offsets[id]; found {
+		l.segments[old.segment].live -= old.size
+	}
+	l.offsets[id] = loc
+	l.segments[loc.segment].live += loc.size
+
+	return nil
+}
+
+// get reads the current version of the object with the given identifier.
+func (l *segmentLog) get(id uint64) ([]byte, error) {
+	loc, found := l.offsets[id]
+	if !found {
+		return nil, ErrRecordNotFound
+	}
+
+	fp, err := os.Open(l.pathForSegment(loc.segment))
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	buf := make([]byte, loc.size)
+	if _, err := fp.ReadAt(buf, loc.offset); err != nil {
+		return nil, fmt.Errorf("error while reading %s at offset %d: %s", fp.Name(), loc.offset, err)
+	}
+
+	_, _, data, _, err := decodeFrame(buf)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading %s at offset %d: %s", fp.Name(), loc.offset, err)
+	}
+	return data, nil
+}
+
+// del appends a tombstone for the object with the given identifier.
+func (l *segmentLog) del(id uint64) error {
+	old, found := l.offsets[id]
+	if !found {
+		return nil
+	} else if _, err := l.append(opDel, id, nil); err != nil {
+		return err
+	}
+
+	l.segments[old.segment].live -= old.size
+	delete(l.offsets, id)
+
+	return nil
+}
+
+func (l *segmentLog) sync() error {
+	if l.fp == nil {
+		return nil
+	}
+	return l.fp.Sync()
+}
+
+// garbage returns the ratio of bytes in the log that do not belong
+// to live objects anymore.
+func (l *segmentLog) garbage() float64 {
+	total, live := int64(0), int64(0)
+	for _, seg := range l.segments {
+		total += seg.size
+		live += seg.live
+	}
+	if total == 0 {
+		return 0.0
+	}
+	return float64(total-live) / float64(total)
+}
+
+// compact copies the live objects of every sealed segment at the tail
+// of the log and then removes the sealed segment files.
+func (l *segmentLog) compact() error {
+	sealed := make(map[uint64]*os.File)
+	for id, seg := range l.segments {
+		if l.active == nil || id != l.active.id {
+			fp, err := os.Open(seg.path)
+			if err != nil {
+				return err
+			}
+			defer fp.Close()
+			sealed[id] = fp
+		}
+	}
+
+	if len(sealed) == 0 {
+		return nil
+	}
+
+	l.compacting = true
+	defer func() { l.compacting = false }()
+
+	log.Debug("compacting %d segments in %s (%.2f%% garbage) ...", len(sealed), l.dataPath, l.garbage()*100.0)
+
+	for id, loc := range l.offsets {
+		fp, found := sealed[loc.segment]
+		if !found {
+			continue
+		}
+
+		buf := make([]byte, loc.size)
+		if _, err := fp.ReadAt(buf, loc.offset); err != nil {
+			return fmt.Errorf("error while reading %s at offset %d: %s", fp.Name(), loc.offset, err)
+		}
+
+		_, _, data, _, err := decodeFrame(buf)
+		if err != nil {
+			return fmt.Errorf("error while reading %s at offset %d: %s", fp.Name(), loc.offset, err)
+		} else if err = l.put(id, data); err != nil {
+			return err
+		}
+	}
+
+	// the tombstones of the sealed segments are dropped with them
+	if err := l.mark(l.highest); err != nil {
+		return err
+	}
+
+	// make sure the copies are on disk before removing the originals
+	if err := l.sync(); err != nil {
+		return err
+	}
+
+	// older segments go first, if interrupted the tombstones of the
+	// remaining ones still override the puts of the removed ones
+	ids := make([]uint64, 0, len(sealed))
+	for id := range sealed {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		if err := removeSegment(l.segments[id].path); err != nil {
+			return err
+		}
+		delete(l.segments, id)
+	}
+
+	return syncDir(l.dataPath)
+}
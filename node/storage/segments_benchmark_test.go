@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"testing"
+)
+
+func BenchmarkSegmentsPut(b *testing.B) {
+	i := setupSegments(b, DefaultSegmentSize)
+	defer teardownSegments(b)
+
+	data := make([]byte, 1024)
+	for n := 0; n < b.N; n++ {
+		if err := i.segments.put(uint64(n%666)+1, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSegmentsLoad(b *testing.B) {
+	setupRecords(b, true, false)
+	defer teardownRecords(b)
+
+	l := newSegmentLog(testFolder)
+	for n := 0; n < b.N; n++ {
+		if err := l.load(testFolder, func(id uint64, data []byte) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSegmentsCompact(b *testing.B) {
+	i := setupSegments(b, 4096)
+	defer teardownSegments(b)
+
+	SetCompactionRatio(2.0)
+
+	data := make([]byte, 128)
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		for id := uint64(1); id <= 100; id++ {
+			if err := i.segments.put(id, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.StartTimer()
+
+		if err := i.Compact(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
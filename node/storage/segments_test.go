@@ -0,0 +1,445 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func setupSegments(t testing.TB, size int64) *Index {
+	teardownRecords(t)
+
+	if err := os.MkdirAll(testFolder, 0755); err != nil {
+		t.Fatalf("Error creating %s: %s", testFolder, err)
+	}
+
+	SetSegmentSize(size)
+
+	i := setupIndex(testFolder)
+	if err := i.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return i
+}
+
+func teardownSegments(t testing.TB) {
+	SetSegmentSize(DefaultSegmentSize)
+	SetCompactionRatio(DefaultCompactionRatio)
+	teardownRecords(t)
+}
+
+func countSegments(t testing.TB) int {
+	ids, err := listSegments(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(ids)
+}
+
+func TestSegmentsFrame(t *testing.T) {
+	data := []byte("hello world")
+	frame := encodeFrame(opPut, 1234, data)
+	if op, id, decoded, size, err := decodeFrame(frame); err != nil {
+		t.Fatal(err)
+	} else if op != opPut {
+		t.Fatalf("unexpected op %d", op)
+	} else if id != 1234 {
+		t.Fatalf("unexpected id %d", id)
+	} else if string(decoded) != string(data) {
+		t.Fatalf("unexpected data %s", decoded)
+	} else if size != int64(len(frame)) {
+		t.Fatalf("unexpected size %d", size)
+	}
+
+	frame[len(frame)-1] ^= 0xff
+	if _, _, _, _, err := decodeFrame(frame); err != ErrCorruptedFrame {
+		t.Fatalf("expected corrupted frame error, got %v", err)
+	} else if _, _, _, _, err := decodeFrame(frame[:5]); err != ErrCorruptedFrame {
+		t.Fatalf("expected corrupted frame error, got %v", err)
+	}
+}
+
+func TestSegmentsPersistence(t *testing.T) {
+	i := setupSegments(t, DefaultSegmentSize)
+	defer teardownSegments(t)
+
+	for n := 0; n < testRecords; n++ {
+		if err := i.Create(&pb.Record{Data: []float32{1, 2, 3}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	updatedRecord.Id = 2
+	if err := i.Update(&updatedRecord); err != nil {
+		t.Fatal(err)
+	} else if i.Delete(3) == nil {
+		t.Fatal("expected record 3 to be deleted")
+	}
+
+	reloaded := setupIndex(testFolder)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	} else if reloaded.Size() != testRecords-1 {
+		t.Fatalf("expected %d records, got %d", testRecords-1, reloaded.Size())
+	} else if reloaded.Find(3) != nil {
+		t.Fatal("deleted record found after reload")
+	} else if m := reloaded.Find(2); m == nil {
+		t.Fatal("updated record not found after reload")
+	} else if !sameRecord(*m.(*pb.Record), updatedRecord) {
+		t.Fatal("records should match")
+	} else if next := reloaded.GetNextId(); next != testRecords+1 {
+		t.Fatalf("expected next id %d, got %d", testRecords+1, next)
+	}
+}
+
+func TestSegmentsMigration(t *testing.T) {
+	setupSegments(t, DefaultSegmentSize)
+	defer teardownSegments(t)
+
+	for n := 1; n <= testRecords; n++ {
+		testRecord.Id = uint64(n)
+		fileName := filepath.Join(testFolder, fmt.Sprintf("%d.dat", n))
+		if err := Flush(&testRecord, fileName); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	i := setupIndex(testFolder)
+	if err := i.Load(); err != nil {
+		t.Fatal(err)
+	} else if i.Size() != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, i.Size())
+	} else if _, files, err := ListPath(testFolder); err != nil {
+		t.Fatal(err)
+	} else if len(files) != 0 {
+		t.Fatalf("expected data files to be removed, %d left", len(files))
+	} else if countSegments(t) != 1 {
+		t.Fatalf("expected 1 segment, got %d", countSegments(t))
+	}
+
+	reloaded := setupIndex(testFolder)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	} else if reloaded.Size() != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, reloaded.Size())
+	}
+
+	for id := uint64(1); id <= testRecords; id++ {
+		testRecord.Id = id
+		if m := reloaded.Find(id); m == nil {
+			t.Fatalf("expected record %d not found", id)
+		} else if !sameRecord(*m.(*pb.Record), testRecord) {
+			t.Fatal("records should match")
+		}
+	}
+}
+
+func TestSegmentsTornWrite(t *testing.T) {
+	i := setupSegments(t, DefaultSegmentSize)
+	defer teardownSegments(t)
+
+	for n := 0; n < testRecords; n++ {
+		if err := i.Create(&pb.Record{Data: []float32{1, 2, 3}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	segPath := i.segments.pathForSegment(1)
+	before, err := os.Stat(segPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a frame interrupted halfway
+	frame := encodeFrame(opPut, 666, []byte("i'm a partial frame"))
+	fp, err := os.OpenFile(segPath, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err = fp.Write(frame[:len(frame)/2]); err != nil {
+		t.Fatal(err)
+	}
+	fp.Close()
+
+	reloaded := setupIndex(testFolder)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	} else if reloaded.Size() != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, reloaded.Size())
+	} else if after, err := os.Stat(segPath); err != nil {
+		t.Fatal(err)
+	} else if after.Size() != before.Size() {
+		t.Fatalf("expected segment to be truncated to %d bytes, got %d", before.Size(), after.Size())
+	}
+}
+
+func TestSegmentsCorruptedSealedSegment(t *testing.T) {
+	i := setupSegments(t, 64)
+	defer teardownSegments(t)
+
+	for n := 0; n < testRecords; n++ {
+		if err := i.Create(&pb.Record{Data: []float32{1, 2, 3}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lost := 0
+	for _, loc := range i.segments.offsets {
+		if loc.segment == 1 {
+			lost++
+		}
+	}
+
+	if countSegments(t) < 2 {
+		t.Fatalf("expected multiple segments, got %d", countSegments(t))
+	} else if err := os.Truncate(i.segments.pathForSegment(1), 10); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := setupIndex(testFolder)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	} else if bad := reloaded.Quarantined(); len(bad) != 1 {
+		t.Fatalf("expected 1 quarantined file, got %d", len(bad))
+	} else if reloaded.Find(1) != nil {
+		t.Fatal("record from the corrupted segment should not be loaded")
+	} else if reloaded.Size() != testRecords-lost {
+		t.Fatalf("expected %d records, got %d", testRecords-lost, reloaded.Size())
+	}
+}
+
+func TestSegmentsCorruptedFrame(t *testing.T) {
+	i := setupSegments(t, DefaultSegmentSize)
+	defer teardownSegments(t)
+
+	for n := 0; n < testRecords; n++ {
+		if err := i.Create(&pb.Record{Data: []float32{1, 2, 3}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// flip a byte in the payload of the second record
+	segPath := i.segments.pathForSegment(1)
+	loc := i.segments.offsets[2]
+	data, err := ioutil.ReadFile(segPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := append([]byte(nil), data[loc.offset:loc.offset+loc.size]...)
+	data[loc.offset+frameHeaderSize] ^= 0xff
+	bad[frameHeaderSize] ^= 0xff
+	if err := ioutil.WriteFile(segPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := setupIndex(testFolder)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	} else if reloaded.Find(2) != nil {
+		t.Fatal("the corrupted record should not be loaded")
+	} else if reloaded.Size() != testRecords-1 {
+		t.Fatalf("expected %d records, got %d", testRecords-1, reloaded.Size())
+	} else if quarantined := reloaded.Quarantined(); len(quarantined) != 1 {
+		t.Fatalf("expected 1 quarantined file, got %d", len(quarantined))
+	} else if saved, err := ioutil.ReadFile(quarantined[0]); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(saved, bad) {
+		t.Fatalf("expected only the bad frame to be quarantined, got %d bytes", len(saved))
+	} else if after, err := os.Stat(segPath); err != nil {
+		t.Fatal(err)
+	} else if expected := int64(len(data) - len(bad)); after.Size() != expected {
+		t.Fatalf("expected the segment to be rewritten without the bad frame, got %d bytes instead of %d", after.Size(), expected)
+	}
+
+	// the bad frame is not quarantined again at the next load
+	again := setupIndex(testFolder)
+	if err := again.Load(); err != nil {
+		t.Fatal(err)
+	} else if quarantined := again.Quarantined(); len(quarantined) != 0 {
+		t.Fatalf("expected no quarantined files, got %d", len(quarantined))
+	} else if again.Size() != testRecords-1 {
+		t.Fatalf("expected %d records, got %d", testRecords-1, again.Size())
+	} else if files, err := filepath.Glob(filepath.Join(testFolder, QuarantineFolderName, "*")); err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 {
+		t.Fatalf("expected 1 file in quarantine, got %d", len(files))
+	}
+
+	// and new frames follow the valid ones
+	if err := again.Create(&pb.Record{Data: []float32{4, 5, 6}}); err != nil {
+		t.Fatal(err)
+	} else if last := setupIndex(testFolder); last.Load() != nil {
+		t.Fatal("unexpected error while reloading")
+	} else if last.Size() != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, last.Size())
+	} else if last.Find(2) != nil || last.Find(3) == nil {
+		t.Fatal("unexpected records after the rewrite")
+	}
+}
+
+func TestSegmentsUnreadableObject(t *testing.T) {
+	i := setupSegments(t, DefaultSegmentSize)
+	defer teardownSegments(t)
+
+	if err := i.segments.put(1, []byte("i'm not a protobuf")); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := setupIndex(testFolder)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	} else if reloaded.Size() != 0 {
+		t.Fatalf("expected empty index, got %d", reloaded.Size())
+	} else if bad := reloaded.Quarantined(); len(bad) != 1 {
+		t.Fatalf("expected 1 quarantined file, got %d", len(bad))
+	} else if data, err := ioutil.ReadFile(bad[0]); err != nil {
+		t.Fatal(err)
+	} else if string(data) != "i'm not a protobuf" {
+		t.Fatalf("unexpected quarantined data: %s", data)
+	}
+
+	// a tombstone was appended, so it won't be quarantined again
+	again := setupIndex(testFolder)
+	if err := again.Load(); err != nil {
+		t.Fatal(err)
+	} else if bad := again.Quarantined(); len(bad) != 0 {
+		t.Fatalf("expected no quarantined files, got %d", len(bad))
+	}
+}
+
+func TestSegmentsCompaction(t *testing.T) {
+	i := setupSegments(t, 256)
+	defer teardownSegments(t)
+
+	// disable automatic compaction
+	SetCompactionRatio(2.0)
+
+	numRecords := 50
+	for n := 0; n < numRecords; n++ {
+		if err := i.Create(&pb.Record{Data: []float32{1, 2, 3}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for n := 1; n <= numRecords; n++ {
+		if n%2 == 0 {
+			if i.Delete(uint64(n)) == nil {
+				t.Fatalf("record %d not found", n)
+			}
+		} else {
+			updatedRecord.Id = uint64(n)
+			if err := i.Update(&updatedRecord); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	before := countSegments(t)
+	if garbage := i.segments.garbage(); garbage < 0.5 {
+		t.Fatalf("expected at least 50%% of garbage, got %f", garbage)
+	} else if err := i.Compact(); err != nil {
+		t.Fatal(err)
+	} else if after := countSegments(t); after >= before {
+		t.Fatalf("expected less than %d segments after compaction, got %d", before, after)
+	}
+
+	reloaded := setupIndex(testFolder)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	} else if reloaded.Size() != numRecords/2 {
+		t.Fatalf("expected %d records, got %d", numRecords/2, reloaded.Size())
+	} else if next := reloaded.GetNextId(); next != uint64(numRecords+1) {
+		// the last record has been deleted and its tombstone compacted
+		t.Fatalf("expected next id %d after compaction, got %d", numRecords+1, next)
+	}
+
+	for n := 1; n <= numRecords; n++ {
+		m := reloaded.Find(uint64(n))
+		if n%2 == 0 && m != nil {
+			t.Fatalf("deleted record %d found after compaction", n)
+		} else if n%2 == 1 {
+			updatedRecord.Id = uint64(n)
+			if m == nil {
+				t.Fatalf("record %d not found after compaction", n)
+			} else if !sameRecord(*m.(*pb.Record), updatedRecord) {
+				t.Fatal("records should match")
+			}
+		}
+	}
+}
+
+func TestSegmentsInterruptedCompaction(t *testing.T) {
+	i := setupSegments(t, 256)
+	defer teardownSegments(t)
+
+	// disable automatic compaction
+	SetCompactionRatio(2.0)
+
+	numRecords := 20
+	for n := 0; n < numRecords; n++ {
+		if err := i.Create(&pb.Record{Data: []float32{1, 2, 3}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the tombstones end up in newer segments than the puts
+	for n := 1; n <= numRecords; n += 2 {
+		if i.Delete(uint64(n)) == nil {
+			t.Fatalf("record %d not found", n)
+		}
+	}
+
+	// crash after removing half of the sealed segments
+	sealed := countSegments(t) - 1
+	removed := 0
+	removeSegment = func(path string) error {
+		if removed++; removed > sealed/2 {
+			return fmt.Errorf("crash")
+		}
+		return os.Remove(path)
+	}
+	defer func() { removeSegment = os.Remove }()
+
+	if sealed < 2 {
+		t.Fatalf("expected multiple sealed segments, got %d", sealed)
+	} else if err := i.Compact(); err == nil {
+		t.Fatal("expected the compaction to be interrupted")
+	}
+
+	reloaded := setupIndex(testFolder)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	} else if reloaded.Size() != numRecords/2 {
+		t.Fatalf("expected %d records, got %d", numRecords/2, reloaded.Size())
+	}
+	for n := 1; n <= numRecords; n += 2 {
+		if reloaded.Find(uint64(n)) != nil {
+			t.Fatalf("deleted record %d came back", n)
+		}
+	}
+}
+
+func TestSegmentsAutomaticCompaction(t *testing.T) {
+	i := setupSegments(t, 256)
+	defer teardownSegments(t)
+
+	for n := 0; n < 100; n++ {
+		testRecord.Id = 1
+		if n == 0 {
+			if err := i.Create(&testRecord); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := i.Update(&testRecord); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if segs := countSegments(t); segs > 2 {
+		t.Fatalf("expected sealed segments to be compacted, %d found", segs)
+	} else if err := setupIndex(testFolder).Load(); err != nil {
+		t.Fatal(err)
+	}
+}
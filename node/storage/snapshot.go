@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	// SnapshotVersion is the version of the snapshot archive format
+	// written by this version of Sum.
+	SnapshotVersion = 2
+)
+
+// Snapshot is the deserialized contents of a snapshot archive, the
+// trash and the named collections are only there since version 2.
+type Snapshot struct {
+	Header      *pb.SnapshotHeader
+	Records     []*pb.Record
+	Trash       []*pb.Record
+	Oracles     []*pb.Oracle
+	Collections []*CollectionSnapshot
+}
+
+// CollectionSnapshot is the deserialized contents of a named collection
+// in a snapshot archive.
+type CollectionSnapshot struct {
+	Info    *pb.Collection
+	Records []*pb.Record
+	Trash   []*pb.Record
+}
+
+func writeMessage(w io.Writer, m proto.Message) error {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	size := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(size, uint64(len(data)))
+	if _, err = w.Write(size[:n]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func readMessage(r *bufio.Reader, m proto.Message) error {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	} else if size > math.MaxInt32 {
+		return fmt.Errorf("object of %d bytes is too big", size)
+	}
+
+	data := make([]byte, size)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// writes every object of an index, which must be locked
+func writeObjects(w io.Writer, index *Index) error {
+	for id, m := range index.index {
+		if m, err := index.resident(id, m); err != nil {
+			return err
+		} else if err := writeMessage(w, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read locks the records and their trash, returning the function
+// that unlocks them
+func lockRecords(records *Records) func() {
+	records.RLock()
+	if records.trash != nil {
+		records.trash.RLock()
+	}
+	return func() {
+		if records.trash != nil {
+			records.trash.RUnlock()
+		}
+		records.RUnlock()
+	}
+}
+
+// returns the trash of the records, or an empty index if they have none
+func trashOf(records *Records) *Index {
+	if records.trash == nil {
+		return WithDriver("", RecordDriver{})
+	}
+	return records.trash
+}
+
+// WriteSnapshot writes to w a gzip compressed archive with a consistent
+// view of the records of the default collection, the oracles and the
+// named collections with their schemas and trash. Every index is read
+// locked while the archive is being written.
+//
+// The archive is made of the header, the records and the trash of the
+// default collection, the oracles and then for every named collection
+// its info followed by its records and its trash.
+func WriteSnapshot(w io.Writer, records *Records, oracles *Oracles, collections map[string]*Records) (*pb.SnapshotHeader, error) {
+	names := make([]string, 0, len(collections))
+	for name, coll := range collections {
+		names = append(names, name)
+		defer lockRecords(coll)()
+	}
+	sort.Strings(names)
+
+	defer lockRecords(records)()
+	oracles.RLock()
+	defer oracles.RUnlock()
+
+	trash := trashOf(records)
+	header := &pb.SnapshotHeader{
+		Version:      SnapshotVersion,
+		Created:      time.Now().Unix(),
+		NextRecordId: records.nextID,
+		NextOracleId: oracles.nextID,
+		Records:      uint64(len(records.index)),
+		Oracles:      uint64(len(oracles.index)),
+		Schema:       records.schema,
+		Trashed:      uint64(len(trash.index)),
+		Collections:  uint64(len(names)),
+	}
+
+	gz := gzip.NewWriter(w)
+	if err := writeMessage(gz, header); err != nil {
+		return nil, err
+	} else if err := writeObjects(gz, records.Index); err != nil {
+		return nil, err
+	} else if err := writeObjects(gz, trash); err != nil {
+		return nil, err
+	} else if err := writeObjects(gz, oracles.Index); err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		coll := collections[name]
+		collTrash := trashOf(coll)
+		info := &pb.Collection{
+			Name:         name,
+			Records:      uint64(len(coll.index)),
+			NextRecordId: coll.nextID,
+			Schema:       coll.schema,
+			Trashed:      uint64(len(collTrash.index)),
+		}
+
+		if err := writeMessage(gz, info); err != nil {
+			return nil, err
+		} else if err := writeObjects(gz, coll.Index); err != nil {
+			return nil, err
+		} else if err := writeObjects(gz, collTrash); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// ReadSnapshot reads and validates a snapshot archive.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading snapshot: %s", err)
+	}
+	defer gz.Close()
+
+	reader := bufio.NewReader(gz)
+	snap := &Snapshot{Header: &pb.SnapshotHeader{}}
+	if err := readMessage(reader, snap.Header); err != nil {
+		return nil, fmt.Errorf("error while reading snapshot header: %s", err)
+	} else if snap.Header.Version == 0 || snap.Header.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snap.Header.Version)
+	}
+
+	if snap.Records, err = readRecords(reader, "record", snap.Header.Records); err != nil {
+		return nil, err
+	} else if snap.Trash, err = readRecords(reader, "trashed record", snap.Header.Trashed); err != nil {
+		return nil, err
+	}
+
+	for n := uint64(1); n <= snap.Header.Oracles; n++ {
+		oracle := new(pb.Oracle)
+		if err := readMessage(reader, oracle); err != nil {
+			return nil, fmt.Errorf("error while reading oracle %d of %d: %s", n, snap.Header.Oracles, err)
+		}
+		snap.Oracles = append(snap.Oracles, oracle)
+	}
+
+	for n := uint64(1); n <= snap.Header.Collections; n++ {
+		coll := &CollectionSnapshot{Info: new(pb.Collection)}
+		if err := readMessage(reader, coll.Info); err != nil {
+			return nil, fmt.Errorf("error while reading collection %d of %d: %s", n, snap.Header.Collections, err)
+		} else if coll.Records, err = readRecords(reader, "record", coll.Info.Records); err != nil {
+			return nil, fmt.Errorf("collection %s: %s", coll.Info.Name, err)
+		} else if coll.Trash, err = readRecords(reader, "trashed record", coll.Info.Trashed); err != nil {
+			return nil, fmt.Errorf("collection %s: %s", coll.Info.Name, err)
+		}
+		snap.Collections = append(snap.Collections, coll)
+	}
+
+	if _, err := reader.ReadByte(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the last snapshot object")
+	}
+
+	return snap, nil
+}
+
+func readRecords(reader *bufio.Reader, what string, count uint64) ([]*pb.Record, error) {
+	var records []*pb.Record
+	for n := uint64(1); n <= count; n++ {
+		record := new(pb.Record)
+		if err := readMessage(reader, record); err != nil {
+			return nil, fmt.Errorf("error while reading %s %d of %d: %s", what, n, count, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func setupSnapshot(t testing.TB) (*Records, *Oracles) {
+	teardownRecords(t)
+
+	recordsPath := filepath.Join(testFolder, "records")
+	oraclesPath := filepath.Join(testFolder, "oracles")
+	for _, path := range []string{recordsPath, oraclesPath} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatalf("Error creating %s: %s", path, err)
+		}
+	}
+
+	records, err := LoadRecords(recordsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= testRecords; i++ {
+		if err := records.Create(&testRecord); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oracles, err := LoadOracles(oraclesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= testOracles; i++ {
+		if err := oracles.Create(&testOracle); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return records, oracles
+}
+
+func TestSnapshotRoundtrip(t *testing.T) {
+	records, oracles := setupSnapshot(t)
+	defer teardownRecords(t)
+
+	buf := bytes.Buffer{}
+	header, err := WriteSnapshot(&buf, records, oracles, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if header.Version != SnapshotVersion {
+		t.Fatalf("unexpected version %d", header.Version)
+	} else if header.Records != testRecords || header.Oracles != testOracles {
+		t.Fatalf("unexpected header %v", header)
+	}
+
+	snap, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(snap.Records) != testRecords {
+		t.Fatalf("expected %d records, got %d", testRecords, len(snap.Records))
+	} else if len(snap.Oracles) != testOracles {
+		t.Fatalf("expected %d oracles, got %d", testOracles, len(snap.Oracles))
+	} else if snap.Header.NextRecordId != records.GetNextId() {
+		t.Fatalf("expected next record id %d, got %d", records.GetNextId(), snap.Header.NextRecordId)
+	}
+
+	for _, r := range snap.Records {
+		if stored := records.Find(r.Id); stored == nil {
+			t.Fatalf("record %d not found", r.Id)
+		} else if !sameRecord(*stored, *r) {
+			t.Fatal("records should match")
+		}
+	}
+}
+
+func TestSnapshotInvalid(t *testing.T) {
+	if _, err := ReadSnapshot(bytes.NewBufferString("i'm not a snapshot")); err == nil {
+		t.Fatal("expected error")
+	}
+
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+	if err := writeMessage(gz, &pb.SnapshotHeader{Version: SnapshotVersion + 1}); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+
+	if _, err := ReadSnapshot(&buf); err == nil {
+		t.Fatal("expected unsupported version error")
+	}
+}
+
+func TestSnapshotTruncated(t *testing.T) {
+	records, oracles := setupSnapshot(t)
+	defer teardownRecords(t)
+
+	buf := bytes.Buffer{}
+	if _, err := WriteSnapshot(&buf, records, oracles, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	gr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ioutil.ReadAll(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	truncated := bytes.Buffer{}
+	gz := gzip.NewWriter(&truncated)
+	gz.Write(raw[:len(raw)/2])
+	gz.Close()
+
+	if _, err := ReadSnapshot(&truncated); err == nil {
+		t.Fatal("expected error for truncated snapshot")
+	}
+}
+
+func TestSnapshotReplace(t *testing.T) {
+	records, oracles := setupSnapshot(t)
+	defer teardownRecords(t)
+
+	replacement := []*pb.Record{
+		{Id: 10, Data: []float32{1, 2, 3}, Meta: map[string]string{"name": "ten"}},
+		{Id: 20, Data: []float32{4, 5, 6}, Meta: map[string]string{"name": "twenty"}},
+	}
+	if err := records.Replace(replacement, 15); err != nil {
+		t.Fatal(err)
+	} else if records.Size() != len(replacement) {
+		t.Fatalf("expected %d records, got %d", len(replacement), records.Size())
+	} else if records.Find(1) != nil {
+		t.Fatal("old records should be gone")
+	} else if next := records.GetNextId(); next != 21 {
+		t.Fatalf("expected next id 21, got %d", next)
+	} else if found := records.FindBy("name", "twenty"); len(found) != 1 || found[0].Id != 20 {
+		t.Fatalf("unexpected meta index lookup result: %v", found)
+	} else if found := records.FindBy("666", "666"); len(found) != 0 {
+		t.Fatalf("stale meta index entries: %v", found)
+	}
+
+	if err := oracles.Replace(nil, 1); err != nil {
+		t.Fatal(err)
+	} else if oracles.Size() != 0 {
+		t.Fatalf("expected no oracles, got %d", oracles.Size())
+	}
+
+	reloaded, err := LoadRecords(filepath.Join(testFolder, "records"))
+	if err != nil {
+		t.Fatal(err)
+	} else if reloaded.Size() != len(replacement) {
+		t.Fatalf("expected %d records after reload, got %d", len(replacement), reloaded.Size())
+	} else if r := reloaded.Find(10); r == nil || !sameRecord(*r, *replacement[0]) {
+		t.Fatal("records should match after reload")
+	}
+}
+
+func TestSnapshotCollections(t *testing.T) {
+	records, oracles := setupSnapshot(t)
+	defer teardownRecords(t)
+
+	collPath := filepath.Join(testFolder, "collection")
+	if err := os.MkdirAll(collPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	coll, err := LoadRecords(collPath)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := coll.SetSchema(&pb.Schema{Dimension: uint64(len(testRecord.Data))}); err != nil {
+		t.Fatal(err)
+	} else if err := coll.Create(&testRecord); err != nil {
+		t.Fatal(err)
+	} else if records.Delete(1) == nil {
+		t.Fatal("record 1 not found")
+	}
+
+	buf := bytes.Buffer{}
+	header, err := WriteSnapshot(&buf, records, oracles, map[string]*Records{"collection": coll})
+	if err != nil {
+		t.Fatal(err)
+	} else if header.Collections != 1 || header.Trashed != 1 {
+		t.Fatalf("unexpected header %v", header)
+	}
+
+	snap, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(snap.Records) != testRecords-1 || len(snap.Trash) != 1 || snap.Trash[0].Id != 1 {
+		t.Fatalf("unexpected records %d and trash %v", len(snap.Records), snap.Trash)
+	} else if len(snap.Collections) != 1 {
+		t.Fatalf("expected 1 collection, got %d", len(snap.Collections))
+	}
+
+	info := snap.Collections[0].Info
+	if info.Name != "collection" || info.NextRecordId != 2 || info.Schema.GetDimension() != uint64(len(testRecord.Data)) {
+		t.Fatalf("unexpected collection %v", info)
+	} else if len(snap.Collections[0].Records) != 1 || len(snap.Collections[0].Trash) != 0 {
+		t.Fatalf("unexpected collection records %v", snap.Collections[0].Records)
+	}
+}
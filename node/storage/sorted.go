@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// kinds of meta values, in the order they are sorted by
+const (
+	metaText = iota
+	metaNumber
+	metaTime
+)
+
+// metaKey is the comparable form of a pb.MetaValue.
+type metaKey struct {
+	kind int
+	text string
+	num  float64
+	time int64
+}
+
+func keyOf(v *pb.MetaValue) (metaKey, error) {
+	switch x := v.GetValue().(type) {
+	case *pb.MetaValue_Text:
+		return metaKey{kind: metaText, text: x.Text}, nil
+	case *pb.MetaValue_Number:
+		// NaN can't be ordered
+		if math.IsNaN(x.Number) {
+			return metaKey{}, fmt.Errorf("NaN is not a valid meta value")
+		}
+		return metaKey{kind: metaNumber, num: x.Number}, nil
+	case *pb.MetaValue_Time:
+		return metaKey{kind: metaTime, time: x.Time}, nil
+	}
+	return metaKey{}, fmt.Errorf("empty meta value")
+}
+
+func (k metaKey) String() string {
+	switch k.kind {
+	case metaNumber:
+		return strconv.FormatFloat(k.num, 'g', -1, 64)
+	case metaTime:
+		return time.Unix(0, k.time).UTC().Format(time.RFC3339Nano)
+	}
+	return k.text
+}
+
+func (k metaKey) compare(o metaKey) int {
+	if k.kind != o.kind {
+		return k.kind - o.kind
+	}
+
+	switch k.kind {
+	case metaText:
+		return strings.Compare(k.text, o.text)
+	case metaNumber:
+		if k.num < o.num {
+			return -1
+		} else if k.num > o.num {
+			return 1
+		}
+	case metaTime:
+		if k.time < o.time {
+			return -1
+		} else if k.time > o.time {
+			return 1
+		}
+	}
+	return 0
+}
+
+type sortedEntry struct {
+	key metaKey
+	id  uint64
+}
+
+// sortedIndex holds the values of a meta key for every record that has
+// it, sorted by kind, value and record identifier.
+type sortedIndex []sortedEntry
+
+// search returns the position of the first entry not lower than (key, id).
+func (idx sortedIndex) search(key metaKey, id uint64) int {
+	return sort.Search(len(idx), func(i int) bool {
+		if c := idx[i].key.compare(key); c != 0 {
+			return c > 0
+		}
+		return idx[i].id >= id
+	})
+}
+
+// lower returns the position of the first entry not lower than key.
+func (idx sortedIndex) lower(key metaKey) int {
+	return idx.search(key, 0)
+}
+
+// upper returns the position of the first entry greater than key.
+func (idx sortedIndex) upper(key metaKey) int {
+	return sort.Search(len(idx), func(i int) bool {
+		return idx[i].key.compare(key) > 0
+	})
+}
+
+// kindBounds returns the range of entries with the given kind of value.
+func (idx sortedIndex) kindBounds(kind int) (int, int) {
+	from := sort.Search(len(idx), func(i int) bool { return idx[i].key.kind >= kind })
+	to := sort.Search(len(idx), func(i int) bool { return idx[i].key.kind > kind })
+	return from, to
+}
+
+// insert adds the entry unless it's already indexed.
+func (idx sortedIndex) insert(key metaKey, id uint64) sortedIndex {
+	at := idx.search(key, id)
+	if at < len(idx) && idx[at].id == id && idx[at].key.compare(key) == 0 {
+		return idx
+	}
+	idx = append(idx, sortedEntry{})
+	copy(idx[at+1:], idx[at:])
+	idx[at] = sortedEntry{key: key, id: id}
+	return idx
+}
+
+func (idx sortedIndex) remove(key metaKey, id uint64) sortedIndex {
+	if at := idx.search(key, id); at < len(idx) && idx[at].id == id && idx[at].key.compare(key) == 0 {
+		idx = append(idx[:at], idx[at+1:]...)
+	}
+	return idx
+}
+
+// match returns the entries matching the filter, the filter is
+// expected to be valid.
+func (idx sortedIndex) match(f *pb.MetaFilter, value, to metaKey) sortedIndex {
+	first, last := idx.kindBounds(value.kind)
+	from, until := first, last
+
+	switch f.Op {
+	case pb.MetaFilter_EQ:
+		from, until = idx.lower(value), idx.upper(value)
+	case pb.MetaFilter_LT:
+		until = idx.lower(value)
+	case pb.MetaFilter_LTE:
+		until = idx.upper(value)
+	case pb.MetaFilter_GT:
+		from = idx.upper(value)
+	case pb.MetaFilter_GTE:
+		from = idx.lower(value)
+	case pb.MetaFilter_BETWEEN:
+		from, until = idx.lower(value), idx.upper(to)
+	case pb.MetaFilter_PREFIX:
+		from = idx.lower(value)
+		until = from + sort.Search(last-from, func(i int) bool {
+			return !strings.HasPrefix(idx[from+i].key.text, value.text)
+		})
+	}
+
+	if from >= until {
+		return nil
+	}
+	return idx[from:until]
+}
+
+// validateFilter makes sure a filter can be evaluated and returns the
+// comparable forms of its values.
+func validateFilter(f *pb.MetaFilter) (value metaKey, to metaKey, err error) {
+	if f.Value == nil {
+		return value, to, fmt.Errorf("filter on meta %s has no value", f.Meta)
+	} else if value, err = keyOf(f.Value); err != nil {
+		return value, to, fmt.Errorf("filter on meta %s: %s", f.Meta, err)
+	}
+
+	switch f.Op {
+	case pb.MetaFilter_EQ, pb.MetaFilter_LT, pb.MetaFilter_LTE, pb.MetaFilter_GT, pb.MetaFilter_GTE:
+	case pb.MetaFilter_BETWEEN:
+		if f.To == nil {
+			return value, to, fmt.Errorf("filter on meta %s has no upper bound", f.Meta)
+		} else if to, err = keyOf(f.To); err != nil {
+			return value, to, fmt.Errorf("filter on meta %s: %s", f.Meta, err)
+		} else if to.kind != value.kind {
+			return value, to, fmt.Errorf("filter on meta %s has bounds of different types", f.Meta)
+		}
+	case pb.MetaFilter_PREFIX:
+		if value.kind != metaText {
+			return value, to, fmt.Errorf("filter on meta %s: prefix requires a text value", f.Meta)
+		}
+	default:
+		return value, to, fmt.Errorf("filter on meta %s has unknown operator %d", f.Meta, f.Op)
+	}
+
+	return value, to, nil
+}
+
+// calls cb for every indexable meta value of a record, text meta
+// data is indexed as text values.
+func forEachMetaKey(meta map[string]string, typed map[string]*pb.MetaValue, cb func(name string, key metaKey)) {
+	for name, val := range meta {
+		cb(name, metaKey{kind: metaText, text: val})
+	}
+	for name, val := range typed {
+		if key, err := keyOf(val); err == nil {
+			cb(name, key)
+		}
+	}
+}
+
+func (r *Records) _sortedIndexCreate(rec *pb.Record) {
+	forEachMetaKey(rec.Meta, rec.TypedMeta, func(name string, key metaKey) {
+		r.sortedBy[name] = r.sortedBy[name].insert(key, rec.Id)
+	})
+}
+
+func (r *Records) sortedIndexCreate(rec *pb.Record) {
+	r.Lock()
+	defer r.Unlock()
+
+	r._sortedIndexCreate(rec)
+}
+
+func (r *Records) _sortedIndexRemove(id uint64, meta map[string]string, typed map[string]*pb.MetaValue) {
+	forEachMetaKey(meta, typed, func(name string, key metaKey) {
+		if idx := r.sortedBy[name].remove(key, id); len(idx) > 0 {
+			r.sortedBy[name] = idx
+		} else {
+			delete(r.sortedBy, name)
+		}
+	})
+}
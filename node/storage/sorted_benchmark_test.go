@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"testing"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func BenchmarkRecordsFindWhere(b *testing.B) {
+	setupRecords(b, false, false)
+	defer teardownRecords(b)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for n := 0; n < 10000; n++ {
+		err := records.Create(&pb.Record{
+			Data:      []float32{1, 2, 3},
+			TypedMeta: map[string]*pb.MetaValue{"score": number(float64(n) / 10000.0)},
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	filters := []*pb.MetaFilter{{Meta: "score", Op: pb.MetaFilter_BETWEEN, Value: number(0.5), To: number(0.51)}}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if found, err := records.FindWhere(filters); err != nil {
+			b.Fatal(err)
+		} else if len(found) != 101 {
+			b.Fatalf("expected 101 records, got %d", len(found))
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func text(s string) *pb.MetaValue {
+	return &pb.MetaValue{Value: &pb.MetaValue_Text{Text: s}}
+}
+
+func number(n float64) *pb.MetaValue {
+	return &pb.MetaValue{Value: &pb.MetaValue_Number{Number: n}}
+}
+
+func timestamp(t time.Time) *pb.MetaValue {
+	return &pb.MetaValue{Value: &pb.MetaValue_Time{Time: t.UnixNano()}}
+}
+
+var (
+	sortedEpoch = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	// score = id / 10, created = epoch + id days, name = "item" + id
+	sortedScores = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1.0}
+)
+
+func setupSorted(t testing.TB) *Records {
+	setupRecords(t, false, false)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n, score := range sortedScores {
+		id := n + 1
+		err := records.Create(&pb.Record{
+			Data: []float32{1, 2, 3},
+			Meta: map[string]string{"name": "item" + string(rune('a'+n))},
+			TypedMeta: map[string]*pb.MetaValue{
+				"score":   number(score),
+				"created": timestamp(sortedEpoch.AddDate(0, 0, id)),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return records
+}
+
+func idsOf(records []*pb.Record) []uint64 {
+	ids := make([]uint64, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.Id)
+	}
+	return ids
+}
+
+func findWhere(t *testing.T, records *Records, filters ...*pb.MetaFilter) []uint64 {
+	found, err := records.FindWhere(filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idsOf(found)
+}
+
+func TestSortedIndexInsertRemove(t *testing.T) {
+	idx := sortedIndex(nil)
+	idx = idx.insert(metaKey{kind: metaNumber, num: 2}, 1)
+	idx = idx.insert(metaKey{kind: metaText, text: "b"}, 2)
+	idx = idx.insert(metaKey{kind: metaNumber, num: 1}, 3)
+	idx = idx.insert(metaKey{kind: metaNumber, num: 2}, 0)
+	idx = idx.insert(metaKey{kind: metaTime, time: 0}, 4)
+
+	expected := []uint64{2, 3, 0, 1, 4}
+	for n, entry := range idx {
+		if entry.id != expected[n] {
+			t.Fatalf("unexpected order %v", idx)
+		}
+	}
+
+	idx = idx.remove(metaKey{kind: metaNumber, num: 2}, 0)
+	idx = idx.remove(metaKey{kind: metaNumber, num: 2}, 666)
+	if len(idx) != 4 || idx[2].id != 1 {
+		t.Fatalf("unexpected index after remove %v", idx)
+	}
+}
+
+func TestRecordsFindWhere(t *testing.T) {
+	records := setupSorted(t)
+	defer teardownRecords(t)
+
+	filter := func(meta string, op pb.MetaFilter_Op, value *pb.MetaValue) *pb.MetaFilter {
+		return &pb.MetaFilter{Meta: meta, Op: op, Value: value}
+	}
+
+	cases := []struct {
+		filters  []*pb.MetaFilter
+		expected []uint64
+	}{
+		{[]*pb.MetaFilter{filter("score", pb.MetaFilter_EQ, number(0.5))}, []uint64{5}},
+		{[]*pb.MetaFilter{filter("score", pb.MetaFilter_LT, number(0.3))}, []uint64{1, 2}},
+		{[]*pb.MetaFilter{filter("score", pb.MetaFilter_LTE, number(0.3))}, []uint64{1, 2, 3}},
+		{[]*pb.MetaFilter{filter("score", pb.MetaFilter_GT, number(0.8))}, []uint64{9, 10}},
+		{[]*pb.MetaFilter{filter("score", pb.MetaFilter_GTE, number(0.8))}, []uint64{8, 9, 10}},
+		{[]*pb.MetaFilter{{Meta: "score", Op: pb.MetaFilter_BETWEEN, Value: number(0.35), To: number(0.6)}}, []uint64{4, 5, 6}},
+		{[]*pb.MetaFilter{{Meta: "score", Op: pb.MetaFilter_BETWEEN, Value: number(0.6), To: number(0.35)}}, []uint64{}},
+		{[]*pb.MetaFilter{filter("name", pb.MetaFilter_PREFIX, text("item"))}, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{[]*pb.MetaFilter{filter("name", pb.MetaFilter_PREFIX, text("itemc"))}, []uint64{3}},
+		{[]*pb.MetaFilter{filter("name", pb.MetaFilter_GTE, text("itemh"))}, []uint64{8, 9, 10}},
+		{[]*pb.MetaFilter{filter("name", pb.MetaFilter_PREFIX, text("nope"))}, []uint64{}},
+		// values of a different type never match
+		{[]*pb.MetaFilter{filter("score", pb.MetaFilter_LT, text("zzz"))}, []uint64{}},
+		{[]*pb.MetaFilter{filter("unknown", pb.MetaFilter_GT, number(0))}, []uint64{}},
+		// score > 0.5 and created after the 8th day
+		{[]*pb.MetaFilter{
+			filter("score", pb.MetaFilter_GT, number(0.5)),
+			filter("created", pb.MetaFilter_GT, timestamp(sortedEpoch.AddDate(0, 0, 8))),
+		}, []uint64{9, 10}},
+	}
+
+	for n, c := range cases {
+		if found := findWhere(t, records, c.filters...); !reflect.DeepEqual(found, c.expected) {
+			t.Fatalf("case %d: expected %v, got %v", n, c.expected, found)
+		}
+	}
+}
+
+func TestRecordsFindWhereInvalid(t *testing.T) {
+	records := setupSorted(t)
+	defer teardownRecords(t)
+
+	invalid := [][]*pb.MetaFilter{
+		nil,
+		{{Meta: "score", Op: pb.MetaFilter_GT}},
+		{{Meta: "score", Op: pb.MetaFilter_GT, Value: &pb.MetaValue{}}},
+		{{Meta: "score", Op: pb.MetaFilter_GT, Value: number(math.NaN())}},
+		{{Meta: "score", Op: pb.MetaFilter_BETWEEN, Value: number(0)}},
+		{{Meta: "score", Op: pb.MetaFilter_BETWEEN, Value: number(0), To: text("1")}},
+		{{Meta: "score", Op: pb.MetaFilter_PREFIX, Value: number(0)}},
+		{{Meta: "score", Op: pb.MetaFilter_Op(666), Value: number(0)}},
+	}
+
+	for n, filters := range invalid {
+		if _, err := records.FindWhere(filters); err == nil {
+			t.Fatalf("case %d: expected error", n)
+		}
+	}
+}
+
+func TestRecordsFindWhereUpdateAndDelete(t *testing.T) {
+	records := setupSorted(t)
+	defer teardownRecords(t)
+
+	top := &pb.MetaFilter{Meta: "score", Op: pb.MetaFilter_GTE, Value: number(0.9)}
+
+	// the old value must not match anymore
+	if err := records.Update(&pb.Record{Id: 10, TypedMeta: map[string]*pb.MetaValue{"score": number(0.01)}}); err != nil {
+		t.Fatal(err)
+	} else if found := findWhere(t, records, top); !reflect.DeepEqual(found, []uint64{9}) {
+		t.Fatalf("unexpected records %v", found)
+	} else if found := findWhere(t, records, &pb.MetaFilter{Meta: "score", Op: pb.MetaFilter_LT, Value: number(0.1)}); !reflect.DeepEqual(found, []uint64{10}) {
+		t.Fatalf("unexpected records %v", found)
+	}
+
+	// updating the data only keeps the meta data indexed
+	if err := records.Update(&pb.Record{Id: 9, Data: []float32{3, 2, 1}}); err != nil {
+		t.Fatal(err)
+	} else if found := findWhere(t, records, top); !reflect.DeepEqual(found, []uint64{9}) {
+		t.Fatalf("unexpected records %v", found)
+	}
+
+	if records.Delete(9) == nil {
+		t.Fatal("expected record to be deleted")
+	} else if found := findWhere(t, records, top); len(found) != 0 {
+		t.Fatalf("unexpected records %v", found)
+	} else if deleted := records.DeleteMany([]uint64{1, 2}); len(deleted) != 2 {
+		t.Fatalf("expected 2 deleted records, got %d", len(deleted))
+	} else if found := findWhere(t, records, &pb.MetaFilter{Meta: "score", Op: pb.MetaFilter_LTE, Value: number(0.3)}); !reflect.DeepEqual(found, []uint64{3, 10}) {
+		t.Fatalf("unexpected records %v", found)
+	}
+
+	// the index is rebuilt when loading
+	reloaded, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	} else if found := findWhere(t, reloaded, &pb.MetaFilter{Meta: "score", Op: pb.MetaFilter_LTE, Value: number(0.3)}); !reflect.DeepEqual(found, []uint64{3, 10}) {
+		t.Fatalf("unexpected records %v", found)
+	}
+}
+
+func TestRecordsFindWhereExpired(t *testing.T) {
+	records := setupSorted(t)
+	defer teardownRecords(t)
+
+	if err := records.Update(&pb.Record{Id: 5, ExpiresAt: time.Now().Add(-time.Second).UnixNano()}); err != nil {
+		t.Fatal(err)
+	} else if found := findWhere(t, records, &pb.MetaFilter{Meta: "score", Op: pb.MetaFilter_BETWEEN, Value: number(0.4), To: number(0.6)}); !reflect.DeepEqual(found, []uint64{4, 6}) {
+		t.Fatalf("unexpected records %v", found)
+	}
+}
+
+func TestRecordsFindWhereOverwrite(t *testing.T) {
+	records := setupSorted(t)
+	defer teardownRecords(t)
+
+	overwrite := func(name string) {
+		err := records.CreateManyWIthId([]*pb.Record{{Id: 3, Data: []float32{1, 2, 3}, Meta: map[string]string{"name": name}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// overwriting a record with the same meta data twice must not index it twice
+	overwrite("x")
+	overwrite("x")
+	if found := findWhere(t, records, &pb.MetaFilter{Meta: "name", Op: pb.MetaFilter_EQ, Value: text("x")}); !reflect.DeepEqual(found, []uint64{3}) {
+		t.Fatalf("unexpected records %v", found)
+	}
+
+	overwrite("y")
+	if found := findWhere(t, records, &pb.MetaFilter{Meta: "name", Op: pb.MetaFilter_EQ, Value: text("x")}); len(found) != 0 {
+		t.Fatalf("overwritten meta data should not match: %v", found)
+	} else if found := findWhere(t, records, &pb.MetaFilter{Meta: "name", Op: pb.MetaFilter_EQ, Value: text("y")}); !reflect.DeepEqual(found, []uint64{3}) {
+		t.Fatalf("unexpected records %v", found)
+	} else if found := findWhere(t, records, &pb.MetaFilter{Meta: "score", Op: pb.MetaFilter_EQ, Value: number(0.3)}); len(found) != 0 {
+		t.Fatalf("overwritten typed meta data should not match: %v", found)
+	}
+}
+
+func TestSortedIndexInsertTwice(t *testing.T) {
+	idx := sortedIndex(nil)
+	idx = idx.insert(metaKey{kind: metaNumber, num: 1}, 1)
+	idx = idx.insert(metaKey{kind: metaNumber, num: 1}, 1)
+	if len(idx) != 1 {
+		t.Fatalf("unexpected index %v", idx)
+	}
+}
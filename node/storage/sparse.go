@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"fmt"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// VectorSize returns the number of elements of the vector of a record,
+// either dense, sparse or quantized.
+func VectorSize(record *pb.Record) uint64 {
+	if record.Sparse != nil {
+		return record.Sparse.Size
+	} else if record.Quantized != nil {
+		return record.Quantized.Size
+	}
+	return uint64(len(record.Data))
+}
+
+// DenseData returns the vector of a record with all of its elements, for
+// sparse and quantized records a new slice is created.
+func DenseData(record *pb.Record) []float32 {
+	if record.Quantized != nil {
+		return dequantize(record.Quantized)
+	} else if record.Sparse == nil {
+		return record.Data
+	}
+
+	data := make([]float32, record.Sparse.Size)
+	for i, idx := range record.Sparse.Indices {
+		data[idx] = record.Sparse.Values[i]
+	}
+	return data
+}
+
+// ValidateSparse returns an error if the record has both a dense and
+// a sparse vector or if its sparse vector is inconsistent.
+func ValidateSparse(record *pb.Record) error {
+	sparse := record.Sparse
+	if sparse == nil {
+		return nil
+	} else if len(record.Data) > 0 {
+		return fmt.Errorf("a record can not have both a dense and a sparse vector")
+	} else if len(sparse.Indices) != len(sparse.Values) {
+		return fmt.Errorf("sparse vector has %d indices and %d values", len(sparse.Indices), len(sparse.Values))
+	}
+
+	for i, idx := range sparse.Indices {
+		if uint64(idx) >= sparse.Size {
+			return fmt.Errorf("sparse vector index %d is out of range for a vector of %d elements", idx, sparse.Size)
+		} else if i > 0 && idx <= sparse.Indices[i-1] {
+			return fmt.Errorf("sparse vector indices must be in increasing order, got %d after %d", idx, sparse.Indices[i-1])
+		}
+	}
+
+	return nil
+}
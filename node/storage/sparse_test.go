@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+var testSparse = pb.SparseVector{Size: 6, Indices: []uint32{1, 4}, Values: []float32{2, 3}}
+
+func TestValidateSparse(t *testing.T) {
+	valid := []*pb.Record{
+		{Data: []float32{1, 2}},
+		{Sparse: &testSparse},
+		{Sparse: &pb.SparseVector{Size: 10}},
+	}
+	for _, rec := range valid {
+		if err := ValidateSparse(rec); err != nil {
+			t.Fatalf("unexpected error for %v: %s", rec, err)
+		}
+	}
+
+	invalid := []*pb.Record{
+		{Data: []float32{1}, Sparse: &testSparse},
+		{Sparse: &pb.SparseVector{Size: 6, Indices: []uint32{1}, Values: []float32{1, 2}}},
+		{Sparse: &pb.SparseVector{Size: 6, Indices: []uint32{6}, Values: []float32{1}}},
+		{Sparse: &pb.SparseVector{Size: 6, Indices: []uint32{3, 1}, Values: []float32{1, 2}}},
+		{Sparse: &pb.SparseVector{Size: 6, Indices: []uint32{1, 1}, Values: []float32{1, 2}}},
+	}
+	for _, rec := range invalid {
+		if err := ValidateSparse(rec); err == nil {
+			t.Fatalf("expected error for %v", rec)
+		}
+	}
+}
+
+func TestDenseData(t *testing.T) {
+	rec := &pb.Record{Sparse: &testSparse}
+	if size := VectorSize(rec); size != 6 {
+		t.Fatalf("expected size 6, got %d", size)
+	} else if data := DenseData(rec); !reflect.DeepEqual(data, []float32{0, 2, 0, 0, 3, 0}) {
+		t.Fatalf("unexpected dense data %v", data)
+	}
+
+	rec = &pb.Record{Data: []float32{1, 2}}
+	if size := VectorSize(rec); size != 2 {
+		t.Fatalf("expected size 2, got %d", size)
+	} else if data := DenseData(rec); !reflect.DeepEqual(data, rec.Data) {
+		t.Fatalf("unexpected dense data %v", data)
+	}
+}
+
+func TestRecordsSparse(t *testing.T) {
+	setupRecords(t, false, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sparse := &pb.Record{Sparse: &testSparse}
+	if err := records.Create(sparse); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(sparse.Shape, []uint64{6}) {
+		t.Fatalf("unexpected default shape %v", sparse.Shape)
+	} else if err := records.Create(&pb.Record{Data: []float32{1}, Sparse: &testSparse}); err == nil {
+		t.Fatal("expected error for record with both vectors")
+	}
+
+	if err := records.SetSchema(&pb.Schema{Dimension: 6}); err != nil {
+		t.Fatal(err)
+	} else if err := records.Create(&pb.Record{Sparse: &pb.SparseVector{Size: 5}}); err == nil {
+		t.Fatal("expected error for sparse record with the wrong dimension")
+	}
+
+	// switching to a dense vector removes the sparse one and vice versa
+	if err := records.Update(&pb.Record{Id: sparse.Id, Data: []float32{1, 2, 3, 4, 5, 6}}); err != nil {
+		t.Fatal(err)
+	} else if stored := records.Find(sparse.Id); stored.Sparse != nil || len(stored.Data) != 6 {
+		t.Fatalf("unexpected record after update %v", stored)
+	} else if err := records.Update(&pb.Record{Id: sparse.Id, Sparse: &testSparse}); err != nil {
+		t.Fatal(err)
+	} else if stored := records.Find(sparse.Id); stored.Data != nil || !proto.Equal(stored.Sparse, &testSparse) {
+		t.Fatalf("unexpected record after update %v", stored)
+	} else if err := records.Update(&pb.Record{Id: sparse.Id, Sparse: &pb.SparseVector{Size: 6, Indices: []uint32{7}, Values: []float32{1}}}); err == nil {
+		t.Fatal("expected error for update with an invalid sparse vector")
+	}
+
+	if err := records.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	} else if stored := reloaded.Find(sparse.Id); stored == nil || !proto.Equal(stored.Sparse, &testSparse) {
+		t.Fatalf("unexpected reloaded record %v", stored)
+	}
+}
+
+func TestRecordDriverSparsePayload(t *testing.T) {
+	d := RecordDriver{}
+	rec := &pb.Record{Id: 1, Sparse: &testSparse}
+	if size := d.PayloadSize(rec); size != 16 {
+		t.Fatalf("expected payload of 16 bytes, got %d", size)
+	} else if stripped := d.Strip(rec).(*pb.Record); stripped.Sparse != nil {
+		t.Fatal("expected the sparse vector to be stripped")
+	} else if rec.Sparse == nil {
+		t.Fatal("the original record should not be modified")
+	}
+}
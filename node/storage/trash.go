@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+
+	"github.com/evilsocket/islazy/log"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	// TrashFolderName is the name of the folder, inside the one of the
+	// records, where deleted records are kept.
+	TrashFolderName = "trash"
+	// DefaultTrashRetention is the default time deleted records are
+	// kept in the trash before being purged.
+	DefaultTrashRetention = 7 * 24 * time.Hour
+)
+
+var trashRetention = DefaultTrashRetention
+
+// SetTrashRetention sets the time deleted records are kept in the trash
+// before being purged, if 0 deleted records are not moved to the trash.
+func SetTrashRetention(retention time.Duration) {
+	trashRetention = retention
+}
+
+func loadTrash(dataPath string) (*Index, error) {
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return nil, err
+	}
+
+	trash := WithDriver(dataPath, RecordDriver{})
+	if err := trash.Load(); err != nil {
+		return nil, fmt.Errorf("error while loading the trash: %s", err)
+	}
+	return trash, nil
+}
+
+// moves deleted records to the trash if enabled
+func (r *Records) toTrash(deleted []*pb.Record) {
+	if r.trash == nil || trashRetention <= 0 || len(deleted) == 0 {
+		return
+	}
+
+	now := time.Now().UnixNano()
+	trashed := make([]proto.Message, 0, len(deleted))
+	for _, rec := range deleted {
+		c := proto.Clone(rec).(*pb.Record)
+		c.DeletedAt = now
+		trashed = append(trashed, c)
+	}
+
+	// records deleted more than once are overwritten
+	if err := r.trash.CreateManyWIthId(trashed); err != nil {
+		log.Error("error while moving %d records to the trash: %s", len(trashed), err)
+	}
+}
+
+// Trash returns the deleted records that are still in the trash.
+func (r *Records) Trash() []proto.Message {
+	if r.trash == nil {
+		return nil
+	}
+	return r.trash.Objects()
+}
+
+// TrashSize returns the number of deleted records in the trash.
+func (r *Records) TrashSize() int {
+	if r.trash == nil {
+		return 0
+	}
+	return r.trash.Size()
+}
+
+// ReplaceTrash removes every record from the trash and replaces them
+// with the given ones.
+func (r *Records) ReplaceTrash(records []*pb.Record) error {
+	if r.trash == nil {
+		return nil
+	}
+
+	trashed := make([]proto.Message, 0, len(records))
+	for _, rec := range records {
+		trashed = append(trashed, rec)
+	}
+	return r.trash.Replace(trashed, 0)
+}
+
+// Undelete moves records from the trash back to the stored ones, it
+// fails without restoring any record if any of them is not in the trash
+// or if a record with the same identifier has been created meanwhile.
+func (r *Records) Undelete(ids []uint64) ([]*pb.Record, error) {
+	if r.trash == nil {
+		return nil, ErrRecordNotFound
+	}
+
+	restored := make([]*pb.Record, 0, len(ids))
+	for _, id := range ids {
+		m := r.trash.Find(id)
+		if m == nil {
+			return nil, fmt.Errorf("record %d: %s", id, ErrRecordNotFound)
+		} else if r.Find(id) != nil {
+			return nil, fmt.Errorf("record %d: %s", id, ErrInvalidID)
+		}
+
+		rec := proto.Clone(m).(*pb.Record)
+		rec.DeletedAt = 0
+		if err := r.validate(rec); err != nil {
+			return nil, fmt.Errorf("record %d: %s", id, err)
+		} else if err := r.checkKey(rec.Key, rec.Id); err != nil {
+			return nil, fmt.Errorf("record %d: %s", id, err)
+		}
+		restored = append(restored, rec)
+	}
+
+	for _, rec := range restored {
+		if err := r.CreateWithId(rec); err != nil {
+			return nil, fmt.Errorf("record %d: %s", rec.Id, err)
+		}
+		r.trash.Delete(rec.Id)
+	}
+
+	return restored, nil
+}
+
+// PurgeTrash permanently removes records from the trash, either the
+// given ones or all of them, and if before is not 0 only the ones
+// deleted before that time. It returns the number of purged records.
+func (r *Records) PurgeTrash(ids []uint64, before int64) int {
+	if r.trash == nil {
+		return 0
+	}
+
+	var wanted map[uint64]bool
+	if len(ids) > 0 {
+		wanted = make(map[uint64]bool, len(ids))
+		for _, id := range ids {
+			wanted[id] = true
+		}
+	}
+
+	// stripped records keep their deletion time, no need to page them in
+	r.trash.RLock()
+	purge := make([]uint64, 0)
+	for id, m := range r.trash.index {
+		if wanted != nil && !wanted[id] {
+			continue
+		} else if before == 0 || m.(*pb.Record).DeletedAt < before {
+			purge = append(purge, id)
+		}
+	}
+	r.trash.RUnlock()
+
+	sort.Slice(purge, func(i, j int) bool { return purge[i] < purge[j] })
+
+	return len(r.trash.DeleteMany(purge))
+}
+
+// ReapTrash permanently removes the records that have been in the
+// trash for longer than the retention period.
+func (r *Records) ReapTrash() int {
+	if trashRetention <= 0 {
+		return 0
+	}
+	return r.PurgeTrash(nil, time.Now().Add(-trashRetention).UnixNano())
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func TestRecordsTrash(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size := records.Size()
+	if records.Delete(testRecord.Id) == nil {
+		t.Fatal("record not deleted")
+	} else if records.Find(testRecord.Id) != nil {
+		t.Fatal("deleted record should not be found")
+	} else if records.TrashSize() != 1 {
+		t.Fatalf("expected 1 record in the trash, got %d", records.TrashSize())
+	} else if trashed := records.Trash()[0].(*pb.Record); trashed.Id != testRecord.Id || trashed.DeletedAt == 0 {
+		t.Fatalf("unexpected trashed record %v", trashed)
+	}
+
+	// the trash is persisted
+	records, err = LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	} else if records.TrashSize() != 1 {
+		t.Fatalf("expected 1 record in the trash, got %d", records.TrashSize())
+	} else if _, err := records.Undelete([]uint64{testRecord.Id, 12345}); err == nil {
+		t.Fatal("expected error for record not in the trash")
+	} else if records.Size() != size-1 {
+		t.Fatal("records should not be partially restored")
+	}
+
+	restored, err := records.Undelete([]uint64{testRecord.Id})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(restored) != 1 || restored[0].DeletedAt != 0 {
+		t.Fatalf("unexpected restored records %v", restored)
+	} else if found := records.Find(testRecord.Id); found == nil || !sameRecord(*found, testRecord) {
+		t.Fatalf("unexpected restored record %v", found)
+	} else if records.TrashSize() != 0 {
+		t.Fatalf("expected empty trash, got %d records", records.TrashSize())
+	} else if records.Size() != size {
+		t.Fatalf("expected %d records, got %d", size, records.Size())
+	}
+
+	// expired records are not moved to the trash
+	if err := records.Create(&pb.Record{Data: []float32{1}, ExpiresAt: time.Now().Add(-time.Second).UnixNano()}); err != nil {
+		t.Fatal(err)
+	} else if records.Reap() != 1 {
+		t.Fatal("expected one record to be reaped")
+	} else if records.TrashSize() != 0 {
+		t.Fatalf("expected empty trash, got %d records", records.TrashSize())
+	}
+}
+
+func TestRecordsTransferOutSkipsTrash(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if moved := records.TransferOut([]uint64{1, 2}); len(moved) != 2 {
+		t.Fatalf("expected 2 transferred records, got %d", len(moved))
+	} else if records.TrashSize() != 0 {
+		t.Fatalf("transferred records should not be trashed, got %d", records.TrashSize())
+	} else if deleted := records.DeleteMany([]uint64{3}); len(deleted) != 1 {
+		t.Fatalf("expected 1 deleted record, got %d", len(deleted))
+	} else if records.TrashSize() != 1 {
+		t.Fatalf("expected 1 record in the trash, got %d", records.TrashSize())
+	}
+}
+
+func TestRecordsPurgeTrash(t *testing.T) {
+	setupRecords(t, true, false)
+	defer teardownRecords(t)
+
+	records, err := LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]uint64, 0)
+	for i := 0; i < 4; i++ {
+		rec := &pb.Record{Data: []float32{float32(i)}}
+		if err := records.Create(rec); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, rec.Id)
+	}
+
+	if len(records.DeleteMany(ids)) != 4 {
+		t.Fatal("expected 4 records to be deleted")
+	} else if records.TrashSize() != 4 {
+		t.Fatalf("expected 4 records in the trash, got %d", records.TrashSize())
+	} else if purged := records.PurgeTrash(ids[:1], 0); purged != 1 {
+		t.Fatalf("expected 1 record to be purged, got %d", purged)
+	} else if purged := records.PurgeTrash(nil, time.Now().Add(-time.Hour).UnixNano()); purged != 0 {
+		t.Fatalf("expected no records deleted more than an hour ago, got %d", purged)
+	} else if purged := records.ReapTrash(); purged != 0 {
+		t.Fatalf("expected no records to be reaped, got %d", purged)
+	}
+
+	defer SetTrashRetention(DefaultTrashRetention)
+	SetTrashRetention(time.Nanosecond)
+
+	if purged := records.ReapTrash(); purged != 3 {
+		t.Fatalf("expected 3 records to be reaped, got %d", purged)
+	}
+
+	// a zero retention disables the trash
+	SetTrashRetention(0)
+	if records.Delete(testRecord.Id) == nil {
+		t.Fatal("record not deleted")
+	} else if records.TrashSize() != 0 {
+		t.Fatalf("expected empty trash, got %d records", records.TrashSize())
+	}
+}
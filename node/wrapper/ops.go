@@ -0,0 +1,105 @@
+package wrapper
+
+import (
+	"fmt"
+
+	"github.com/evilsocket/sum/node/backend"
+)
+
+// The methods in this file are computed by the backend of the record, the ones
+// returning a record return a new one with the shape of this record, that
+// can be used as any other record by the oracles. Sparse vectors have their
+// zeros filled in first and quantized ones are restored in full precision.
+
+// returns the vector of the record for the given backend, which
+// is not the one of the record when operating on two records.
+func (w *Record) vectorFor(b *backend.Backend) backend.Vector {
+	if !w.isDense() {
+		data := w.Data()
+		return b.Wrap(len(data), data)
+	} else if b != w.backend {
+		return b.Wrap(w.Size, w.record.Data)
+	}
+	return w.vec
+}
+
+func (w *Record) vector() backend.Vector {
+	return w.vectorFor(w.backend)
+}
+
+// returns the vectors of two records with the same size
+func (w *Record) vectors(b *Record) (backend.Vector, backend.Vector) {
+	if w.Size != b.Size {
+		panic(fmt.Errorf("vectors have different sizes: %d and %d", w.Size, b.Size))
+	}
+	return w.vector(), b.vectorFor(w.backend)
+}
+
+// returns a copy of the elements of the vector
+func (w *Record) clone() []float32 {
+	return append([]float32{}, w.Data()...)
+}
+
+func (w *Record) withData(data []float32) *Record {
+	return w.tensor(data, w.record.Shape)
+}
+
+// AbsSum returns the sum of the absolute values of the vector.
+func (w *Record) AbsSum() float64 {
+	return w.backend.Asum(w.vector())
+}
+
+// L1 returns the manhattan distance between a vector and another.
+func (w *Record) L1(b *Record) float64 {
+	return w.backend.L1(w.vectors(b))
+}
+
+// L2 returns the euclidean distance between a vector and another.
+func (w *Record) L2(b *Record) float64 {
+	return w.backend.L2(w.vectors(b))
+}
+
+// SquaredL2 returns the squared euclidean distance between a vector and another.
+func (w *Record) SquaredL2(b *Record) float64 {
+	return w.backend.SquaredL2(w.vectors(b))
+}
+
+// Hamming returns the number of elements that are different between a vector and another.
+func (w *Record) Hamming(b *Record) float64 {
+	return w.backend.Hamming(w.vectors(b))
+}
+
+// Add returns the element-wise sum of a vector and another.
+func (w *Record) Add(b *Record) *Record {
+	return w.withData(w.backend.Add(w.vectors(b)))
+}
+
+// Sub returns the element-wise difference of a vector and another.
+func (w *Record) Sub(b *Record) *Record {
+	return w.withData(w.backend.Sub(w.vectors(b)))
+}
+
+// Mul returns the element-wise product of a vector and another.
+func (w *Record) Mul(b *Record) *Record {
+	return w.withData(w.backend.Mul(w.vectors(b)))
+}
+
+// Div returns the element-wise quotient of a vector and another.
+func (w *Record) Div(b *Record) *Record {
+	return w.withData(w.backend.Div(w.vectors(b)))
+}
+
+// Scale returns the vector multiplied by alpha.
+func (w *Record) Scale(alpha float64) *Record {
+	data := w.clone()
+	w.backend.Scal(float32(alpha), w.backend.Wrap(len(data), data))
+	return w.withData(data)
+}
+
+// Axpy returns this vector plus the vector x multiplied by alpha.
+func (w *Record) Axpy(alpha float64, x *Record) *Record {
+	_, vx := w.vectors(x)
+	data := w.clone()
+	w.backend.Axpy(float32(alpha), vx, w.backend.Wrap(len(data), data))
+	return w.withData(data)
+}
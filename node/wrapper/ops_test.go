@@ -0,0 +1,72 @@
+package wrapper
+
+import (
+	"testing"
+
+	"github.com/evilsocket/sum/node/backend"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+func TestRecordVectorOps(t *testing.T) {
+	for _, name := range []string{"naive", "blas32"} {
+		backend.Select(name)
+
+		a := WrapRecord(&pb.Record{Data: []float32{1, -2, 3, 4}, Shape: []uint64{2, 2}})
+		b := WrapRecord(&pb.Record{Data: []float32{1, 2, 0, 2}})
+		sparse := WrapRecord(&pb.Record{Sparse: &pb.SparseVector{Size: 4, Indices: []uint32{1}, Values: []float32{2}}})
+
+		if v := a.AbsSum(); v != 10 {
+			t.Fatalf("%s: expected abs sum 10, got %f", name, v)
+		} else if v := a.L1(b); v != 9 {
+			t.Fatalf("%s: expected l1 9, got %f", name, v)
+		} else if v := a.SquaredL2(b); v != 29 {
+			t.Fatalf("%s: expected squared l2 29, got %f", name, v)
+		} else if v := sparse.L2(WrapRecord(&pb.Record{Data: []float32{0, 2, 3, 4}})); v != 5 {
+			t.Fatalf("%s: expected l2 5, got %f", name, v)
+		} else if v := a.Hamming(b); v != 3 {
+			t.Fatalf("%s: expected hamming 3, got %f", name, v)
+		} else if v := b.Hamming(sparse); v != 2 {
+			t.Fatalf("%s: expected hamming 2, got %f", name, v)
+		} else if v := b.Magnitude(); v != 3 {
+			t.Fatalf("%s: expected magnitude 3, got %f", name, v)
+		}
+
+		assertTensor(t, a.Add(b), []uint64{2, 2}, []float32{2, 0, 3, 6})
+		assertTensor(t, a.Sub(sparse), []uint64{2, 2}, []float32{1, -4, 3, 4})
+		assertTensor(t, a.Mul(b), []uint64{2, 2}, []float32{1, -4, 0, 8})
+		assertTensor(t, a.Div(a.Scale(2)), []uint64{2, 2}, []float32{0.5, 0.5, 0.5, 0.5})
+		assertTensor(t, b.Scale(-2), []uint64{4}, []float32{-2, -4, 0, -4})
+		assertTensor(t, b.Axpy(0.5, a), []uint64{4}, []float32{1.5, 1, 1.5, 4})
+		assertTensor(t, sparse.Scale(2), []uint64{4}, []float32{0, 4, 0, 0})
+
+		// the operands are never changed
+		assertTensor(t, b, []uint64{4}, []float32{1, 2, 0, 2})
+
+		assertPanic(t, "expected panic for different sizes", func() { a.L1(WrapRecord(&pb.Record{Data: []float32{1}})) })
+		assertPanic(t, "expected panic for different sizes", func() { a.Add(WrapRecord(&pb.Record{Data: []float32{1}})) })
+	}
+	backend.Select("blas32")
+}
+
+func TestRecordMixedBackends(t *testing.T) {
+	naive, _ := backend.Get("naive")
+	blas, _ := backend.Get("blas32")
+
+	a := WrapRecordWith(naive, &pb.Record{Data: []float32{1, 0, 1}})
+	b := WrapRecordWith(blas, &pb.Record{Data: []float32{1, 1, 0}})
+
+	if v := a.Dot(b); v != 1 {
+		t.Fatalf("expected dot 1, got %f", v)
+	} else if v := b.Dot(a); v != 1 {
+		t.Fatalf("expected dot 1, got %f", v)
+	} else if v := a.Jaccard(b); v != 1.0/3.0 {
+		t.Fatalf("expected jaccard 1/3, got %f", v)
+	} else if v := b.L1(a); v != 2 {
+		t.Fatalf("expected l1 2, got %f", v)
+	} else if sum := a.Add(b); sum.Backend() != "naive" {
+		t.Fatalf("expected the backend of the record, got %s", sum.Backend())
+	} else if row := b.Reshape(1, 3).Row(0); row.Backend() != "blas32" {
+		t.Fatalf("expected the backend of the record, got %s", row.Backend())
+	}
+}
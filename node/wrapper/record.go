@@ -18,24 +18,79 @@ type Record struct {
 	// Number of elements in the vector data.
 	Size int
 
-	record *pb.Record
-	vec    backend.Vector
+	record    *pb.Record
+	backend   *backend.Backend
+	vec       backend.Vector
+	sparse    *backend.Sparse
+	quantized *backend.Quantized
 }
 
-// WrapRecord creates a Record wrapper around a raw *pb.Record object.
+// WrapRecord creates a Record wrapper around a raw *pb.Record object,
+// using the default backend.
 func WrapRecord(record *pb.Record) *Record {
-	w := &Record{record: record}
+	return WrapRecordWith(backend.Default(), record)
+}
+
+// WrapRecordWith creates a Record wrapper around a raw *pb.Record object,
+// its vector operations are computed by the given backend.
+func WrapRecordWith(b *backend.Backend, record *pb.Record) *Record {
+	w := &Record{record: record, backend: b}
 	if record != nil {
 		w.ID = record.Id
-		w.SetData(record.Data)
+		if record.Sparse != nil {
+			w.setSparse(record.Sparse)
+		} else if record.Quantized != nil {
+			w.setQuantized(record.Quantized)
+		} else {
+			w.SetData(record.Data)
+		}
 	}
 	return w
 }
 
 func (w *Record) SetData(data []float32) {
 	w.record.Data = data
+	w.record.Sparse = nil
+	w.record.Quantized = nil
 	w.Size = len(data)
-	w.vec = backend.Wrap(w.Size, data)
+	w.vec = w.backend.Wrap(w.Size, data)
+	w.sparse = nil
+	w.quantized = nil
+}
+
+func (w *Record) setSparse(sparse *pb.SparseVector) {
+	w.Size = int(sparse.Size)
+	w.vec = nil
+	w.sparse = backend.NewSparse(w.Size, sparse.Indices, sparse.Values)
+}
+
+func (w *Record) setQuantized(q *pb.QuantizedVector) {
+	w.Size = int(q.Size)
+	w.vec = nil
+	w.quantized = backend.NewQuantized(backend.Quantization(q.Type), w.Size, q.Data, q.Scale, q.Offset)
+}
+
+// the vector of the record is stored with all of its elements in full precision
+func (w *Record) isDense() bool {
+	return w.sparse == nil && w.quantized == nil
+}
+
+// Backend returns the name of the backend computing the vector
+// operations of the record.
+func (w *Record) Backend() string {
+	return w.backend.Name()
+}
+
+// IsSparse returns true if the vector of the record only stores
+// its non zero elements.
+func (w *Record) IsSparse() bool {
+	return w.sparse != nil
+}
+
+// IsQuantized returns true if the vector of the record is stored
+// with less precision.
+func (w *Record) IsQuantized() bool {
+	return w.quantized != nil
 }
 
 // IsNull returns true if the record wrapped by this object is nil.
@@ -56,6 +111,11 @@ func (w *Record) Is(b *Record) bool {
 // Get returns the index-th elements of the *pb.Record contained
 // by this wrapper.
 func (w *Record) Get(index int) float32 {
+	if w.sparse != nil {
+		return w.sparse.Get(index)
+	} else if w.quantized != nil {
+		return w.quantized.Get(index)
+	}
 	return w.record.Data[index]
 }
 
@@ -67,20 +127,63 @@ func (w *Record) Meta(name string) string {
 
 // Equal returns whether the vectors have the same size and are element-wise equal.
 func (w *Record) Equal(b *Record) bool {
-	return reflect.DeepEqual(w.record.Data, b.record.Data)
+	if w.isDense() && b.isDense() {
+		return reflect.DeepEqual(w.record.Data, b.record.Data)
+	} else if w.Size != b.Size {
+		return false
+	}
+
+	for i := 0; i < w.Size; i++ {
+		if w.Get(i) != b.Get(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// returns the wrapper of a range of elements of the vector
+func (w *Record) slice(start uint, end uint) *Record {
+	sub := &Record{ID: w.ID, Size: int(end - start), record: &pb.Record{Id: w.ID}, backend: w.backend}
+	if w.sparse != nil {
+		sub.sparse = w.sparse.Slice(int(start), int(end))
+	} else if w.quantized != nil {
+		sub.quantized = w.quantized.Slice(int(start), int(end))
+	} else {
+		sub.record.Data = w.record.Data[start:end]
+		sub.vec = w.backend.Wrap(sub.Size, sub.record.Data)
+	}
+	return sub
 }
 
 // Dot performs the dot product between a vector and another.
 func (w *Record) Dot(b *Record) float64 {
-	return backend.Dot(w.vec, b.vec)
+	switch {
+	case w.isDense() && b.isDense():
+		return w.backend.Dot(w.vec, b.vectorFor(w.backend))
+	case w.sparse != nil && b.sparse != nil:
+		return backend.SparseDot(w.sparse, b.sparse)
+	case w.sparse != nil:
+		return backend.SparseDenseDot(w.sparse, b.Data())
+	case b.sparse != nil:
+		return backend.SparseDenseDot(b.sparse, w.Data())
+	case w.quantized != nil && b.quantized != nil:
+		return backend.QuantizedDot(w.quantized, b.quantized)
+	case w.quantized != nil:
+		return backend.QuantizedDenseDot(w.quantized, b.record.Data)
+	default:
+		return backend.QuantizedDenseDot(b.quantized, w.record.Data)
+	}
 }
 
 // DotRange performs the dot product between a vector and another using a range of elements.
 func (w *Record) DotRange(b *Record, start uint, end uint) float64 {
-	elems := int(end - start)
-	aRange := backend.Wrap(elems, w.record.Data[start:end])
-	bRange := backend.Wrap(elems, b.record.Data[start:end])
-	return backend.Dot(aRange, bRange)
+	if w.isDense() && b.isDense() {
+		elems := int(end - start)
+		aRange := w.backend.Wrap(elems, w.record.Data[start:end])
+		bRange := w.backend.Wrap(elems, b.record.Data[start:end])
+		return w.backend.Dot(aRange, bRange)
+	}
+	return w.slice(start, end).Dot(b.slice(start, end))
 }
 
 // DotSub performs the dot product between a vector and another using up until the specificed number of elements.
@@ -90,13 +193,32 @@ func (w *Record) DotSub(b *Record, elems uint) float64 {
 
 // Magnitude returns the magnitude of the vector.
 func (w *Record) Magnitude() float64 {
-	return math.Sqrt(float64(w.Dot(w)))
+	if !w.isDense() {
+		return math.Sqrt(w.Dot(w))
+	}
+	return w.backend.Nrm2(w.vec)
 }
 
 // Cosine returns the cosine similarity between a vector and another.
 func (w *Record) Cosine(b *Record) float64 {
+	switch {
+	case w.sparse != nil && b.sparse != nil:
+		return backend.SparseCosine(w.sparse, b.sparse)
+	case w.sparse != nil:
+		return backend.SparseDenseCosine(w.sparse, b.Data())
+	case b.sparse != nil:
+		return backend.SparseDenseCosine(b.sparse, w.Data())
+	case w.quantized != nil && b.quantized != nil:
+		return backend.QuantizedCosine(w.quantized, b.quantized)
+	case w.quantized != nil:
+		return backend.QuantizedDenseCosine(w.quantized, b.record.Data)
+	case b.quantized != nil:
+		return backend.QuantizedDenseCosine(b.quantized, w.record.Data)
+	}
+
+	// Nrm2 rounds differently than Dot, parallel vectors must have a cosine of 1
 	cos := 0.0
-	if den := w.Magnitude() * b.Magnitude(); den != 0.0 {
+	if den := math.Sqrt(w.Dot(w)) * math.Sqrt(b.Dot(b)); den != 0.0 {
 		cos = w.Dot(b) / den
 	}
 	return cos
@@ -116,6 +238,10 @@ func (w *Record) CosineSub(b *Record, elems uint) float64 {
 
 // CosineRange returns the cosine similarity between a vector and another within a range of elements.
 func (w *Record) CosineRange(b *Record, start uint, end uint) float64 {
+	if !w.isDense() || !b.isDense() {
+		return w.slice(start, end).Cosine(b.slice(start, end))
+	}
+
 	cos := 0.0
 	aMag := math.Sqrt(w.DotRange(w, start, end))
 	bMag := math.Sqrt(b.DotRange(b, start, end))
@@ -128,41 +254,25 @@ func (w *Record) CosineRange(b *Record, start uint, end uint) float64 {
 
 // Jaccard returns the Jaccard distance between a vector and another.
 func (w *Record) Jaccard(b *Record) float64 {
-	m11 := 0.0
-	m10 := 0.0
-
-	for i, va := range w.record.Data {
-		vb := b.record.Data[i]
-		m11 += float64(va * vb)
-		if (va + vb) == 1 {
-			m10++
-		}
-	}
-
-	if (m10 + m11) == 0 {
-		return 0
+	switch {
+	case w.sparse != nil && b.sparse != nil:
+		return backend.SparseJaccard(w.sparse, b.sparse)
+	case w.sparse != nil:
+		return backend.SparseDenseJaccard(w.sparse, b.Data())
+	case b.sparse != nil:
+		return backend.SparseDenseJaccard(b.sparse, w.Data())
 	}
-
-	return m11 / (m11 + m10)
+	return w.backend.Jaccard(w.vector(), b.vectorFor(w.backend))
 }
 
 // JaccardRange returns the Jaccard distance between a vector and another within a range of elements.
 func (w *Record) JaccardRange(b *Record, start uint, end uint) float64 {
-	m11 := 0.0
-	m10 := 0.0
-
-	for i := start; i < end; i++ {
-		va := w.record.Data[i]
-		vb := b.record.Data[i]
-		m11 += float64(va * vb)
-		if (va + vb) == 1 {
-			m10++
-		}
+	if !w.isDense() || !b.isDense() {
+		return w.slice(start, end).Jaccard(b.slice(start, end))
 	}
 
-	if (m10 + m11) == 0 {
-		return 0
-	}
-
-	return m11 / (m11 + m10)
+	elems := int(end - start)
+	aRange := w.backend.Wrap(elems, w.record.Data[start:end])
+	bRange := w.backend.Wrap(elems, b.record.Data[start:end])
+	return w.backend.Jaccard(aRange, bRange)
 }
@@ -1,9 +1,11 @@
 package wrapper
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
+	"github.com/evilsocket/sum/node/backend"
 	pb "github.com/evilsocket/sum/proto"
 
 	"github.com/evilsocket/islazy/log"
@@ -200,3 +202,129 @@ func TestWrappedRecordCosineWithIncompatibleSizes(t *testing.T) {
 		WrapRecord(&testRecord).Cosine(WrapRecord(&testShorterRecord))
 	})
 }
+
+func sparseAndDense() (*Record, *Record, *Record, *Record) {
+	denseA := WrapRecord(&pb.Record{Data: []float32{0, 1, 0, 0, 2.5, 0, 1, 0}})
+	denseB := WrapRecord(&pb.Record{Data: []float32{1, 1, 0, 3, 0, 0, 0, 0.5}})
+	sparseA := WrapRecord(&pb.Record{Sparse: &pb.SparseVector{Size: 8, Indices: []uint32{1, 4, 6}, Values: []float32{1, 2.5, 1}}})
+	sparseB := WrapRecord(&pb.Record{Sparse: &pb.SparseVector{Size: 8, Indices: []uint32{0, 1, 3, 7}, Values: []float32{1, 1, 3, 0.5}}})
+	return denseA, denseB, sparseA, sparseB
+}
+
+func TestWrappedRecordSparse(t *testing.T) {
+	denseA, _, sparseA, _ := sparseAndDense()
+	if !sparseA.IsSparse() || denseA.IsSparse() {
+		t.Fatal("unexpected sparse flag")
+	} else if sparseA.Size != denseA.Size {
+		t.Fatalf("expected size %d, got %d", denseA.Size, sparseA.Size)
+	}
+
+	for i := 0; i < denseA.Size; i++ {
+		if sparseA.Get(i) != denseA.Get(i) {
+			t.Fatalf("expected value %f at index %d, got %f", denseA.Get(i), i, sparseA.Get(i))
+		}
+	}
+
+	if !sparseA.Equal(denseA) || !denseA.Equal(sparseA) {
+		t.Fatal("sparse and dense records should be equal")
+	}
+}
+
+func TestWrappedRecordSparseOperations(t *testing.T) {
+	denseA, denseB, sparseA, sparseB := sparseAndDense()
+
+	type op func(a, b *Record) float64
+	ops := map[string]op{
+		"dot":           func(a, b *Record) float64 { return a.Dot(b) },
+		"dot range":     func(a, b *Record) float64 { return a.DotRange(b, 1, 6) },
+		"dot sub":       func(a, b *Record) float64 { return a.DotSub(b, 5) },
+		"cosine":        func(a, b *Record) float64 { return a.Cosine(b) },
+		"cosine range":  func(a, b *Record) float64 { return a.CosineRange(b, 1, 6) },
+		"cosine sub":    func(a, b *Record) float64 { return a.CosineSub(b, 5) },
+		"jaccard":       func(a, b *Record) float64 { return a.Jaccard(b) },
+		"jaccard range": func(a, b *Record) float64 { return a.JaccardRange(b, 1, 6) },
+	}
+
+	for name, f := range ops {
+		expected := f(denseA, denseB)
+		for _, pair := range [][2]*Record{{sparseA, sparseB}, {sparseA, denseB}, {denseA, sparseB}} {
+			if got := f(pair[0], pair[1]); math.Abs(got-expected) > 1e-6 {
+				t.Fatalf("%s: expected %f, got %f", name, expected, got)
+			}
+		}
+	}
+}
+
+func quantizedRecord(kind backend.Quantization, data []float32) *Record {
+	q := backend.Quantize(kind, data)
+	return WrapRecord(&pb.Record{Quantized: &pb.QuantizedVector{
+		Type:   pb.QuantizedVector_Type(q.Type),
+		Size:   uint64(q.Size),
+		Data:   q.Data,
+		Scale:  q.Scale,
+		Offset: q.Offset,
+	}})
+}
+
+func TestWrappedRecordQuantized(t *testing.T) {
+	denseA, _, _, _ := sparseAndDense()
+	// these values are exact in half precision
+	halfA := quantizedRecord(backend.Float16, denseA.Data())
+	if !halfA.IsQuantized() || denseA.IsQuantized() || halfA.IsSparse() {
+		t.Fatal("unexpected quantized flag")
+	} else if halfA.Size != denseA.Size {
+		t.Fatalf("expected size %d, got %d", denseA.Size, halfA.Size)
+	} else if !halfA.Equal(denseA) || !denseA.Equal(halfA) {
+		t.Fatal("quantized and dense records should be equal")
+	} else if !reflect.DeepEqual(halfA.Data(), denseA.Data()) {
+		t.Fatalf("expected data %v, got %v", denseA.Data(), halfA.Data())
+	}
+
+	int8A := quantizedRecord(backend.Int8, denseA.Data())
+	for i := 0; i < denseA.Size; i++ {
+		if math.Abs(float64(int8A.Get(i)-denseA.Get(i))) > 0.01 {
+			t.Fatalf("expected value %f at index %d, got %f", denseA.Get(i), i, int8A.Get(i))
+		}
+	}
+}
+
+func TestWrappedRecordQuantizedOperations(t *testing.T) {
+	denseA, denseB, sparseA, sparseB := sparseAndDense()
+	halfA := quantizedRecord(backend.Float16, denseA.Data())
+	halfB := quantizedRecord(backend.Float16, denseB.Data())
+	int8A := quantizedRecord(backend.Int8, denseA.Data())
+	int8B := quantizedRecord(backend.Int8, denseB.Data())
+
+	type op func(a, b *Record) float64
+	ops := map[string]op{
+		"dot":           func(a, b *Record) float64 { return a.Dot(b) },
+		"dot range":     func(a, b *Record) float64 { return a.DotRange(b, 1, 6) },
+		"cosine":        func(a, b *Record) float64 { return a.Cosine(b) },
+		"cosine range":  func(a, b *Record) float64 { return a.CosineRange(b, 1, 6) },
+		"jaccard":       func(a, b *Record) float64 { return a.Jaccard(b) },
+		"jaccard range": func(a, b *Record) float64 { return a.JaccardRange(b, 1, 6) },
+		"magnitude":     func(a, b *Record) float64 { return a.Magnitude() },
+		"l2":            func(a, b *Record) float64 { return a.L2(b) },
+		"sum":           func(a, b *Record) float64 { return a.Add(b).AbsSum() },
+	}
+
+	pairs := [][2]*Record{{halfA, halfB}, {halfA, denseB}, {denseA, halfB}, {halfA, sparseB}, {sparseA, halfB}}
+	for name, f := range ops {
+		expected := f(denseA, denseB)
+		for _, pair := range pairs {
+			if got := f(pair[0], pair[1]); math.Abs(got-expected) > 1e-6 {
+				t.Fatalf("%s: expected %f, got %f", name, expected, got)
+			}
+		}
+	}
+
+	// int8 vectors only approximate the values
+	for _, name := range []string{"dot", "cosine", "magnitude", "l2"} {
+		expected := ops[name](denseA, denseB)
+		for _, pair := range [][2]*Record{{int8A, int8B}, {int8A, denseB}, {denseA, int8B}, {int8A, halfB}} {
+			if got := ops[name](pair[0], pair[1]); math.Abs(got-expected) > 0.05 {
+				t.Fatalf("%s: expected %f, got %f", name, expected, got)
+			}
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package wrapper
 
 import (
+	"sync"
+	"time"
+
+	"github.com/evilsocket/sum/node/backend"
 	"github.com/evilsocket/sum/node/storage"
 
 	pb "github.com/evilsocket/sum/proto"
@@ -12,47 +16,102 @@ import (
 // execution.
 type Records struct {
 	records *storage.Records
+	backend *backend.Backend
+}
+
+// wrapped records are cached along with every storage and rebuilt only
+// when its generation changes, so that oracles scanning the whole dataset
+// don't allocate a new wrapper for every record at each call.
+type view struct {
+	sync.Mutex
+	built      bool
+	generation uint64
+	backend    *backend.Backend
+	records    []*Record
+}
+
+func (w Records) wrapAll() []*Record {
+	wrapped := make([]*Record, 0, w.records.Size())
+	w.records.ForEach(func(m proto.Message) error {
+		wrapped = append(wrapped, WrapRecordWith(w.backend, m.(*pb.Record)))
+		return nil
+	})
+	return wrapped
+}
+
+func (w Records) view() []*Record {
+	// caching every record would defeat the memory budget
+	if w.records.Paged() {
+		return w.wrapAll()
+	}
+
+	v := w.records.View(func() interface{} { return &view{} }).(*view)
+
+	v.Lock()
+	defer v.Unlock()
+
+	// records are wrapped again also when the backend changes
+	if generation := w.records.Generation(); !v.built || v.generation != generation || v.backend != w.backend {
+		v.records = w.wrapAll()
+		v.generation = generation
+		v.backend = w.backend
+		v.built = true
+	}
+
+	return v.records
 }
 
-// WrapRecords creates a Records wrapper around a *storage.Records object.
+// WrapRecords creates a Records wrapper around a *storage.Records object,
+// using the default backend.
 func WrapRecords(records *storage.Records) Records {
+	return WrapRecordsWith(backend.Default(), records)
+}
+
+// WrapRecordsWith creates a Records wrapper around a *storage.Records object,
+// the vector operations of its records are computed by the given backend.
+func WrapRecordsWith(b *backend.Backend, records *storage.Records) Records {
 	return Records{
 		records: records,
+		backend: b,
 	}
 }
 
-func (_ Records) New(record *pb.Record) *Record {
-	return WrapRecord(record)
+func (w Records) New(record *pb.Record) *Record {
+	return WrapRecordWith(w.backend, record)
 }
 
 // Find returns a wrapped Record given its identifier.
 // If not found, the resulting record will result as null
 // (record.IsNull() will be true).
 func (w Records) Find(id uint64) *Record {
-	return WrapRecord(w.records.Find(id))
+	return WrapRecordWith(w.backend, w.records.Find(id))
 }
 
 // All returns a wrapped list of records in the current storage.
 func (w Records) All() []*Record {
-	wrapped := make([]*Record, 0)
-	w.records.ForEach(func(m proto.Message) error {
-		wrapped = append(wrapped, WrapRecord(m.(*pb.Record)))
-		return nil
-	})
+	now := time.Now().UnixNano()
+	all := w.view()
+	wrapped := make([]*Record, 0, len(all))
+	for _, record := range all {
+		// the view might be older than the expiration of some records
+		if !storage.Expired(record.record, now) {
+			wrapped = append(wrapped, record)
+		}
+	}
 	return wrapped
 }
 
 // AllBut returns a wrapped list of records in the current storage
 // but the one specified.
 func (w Records) AllBut(exclude *Record) []*Record {
-	wrapped := make([]*Record, 0)
-	w.records.ForEach(func(m proto.Message) error {
-		record := m.(*pb.Record)
-		if record.Id != exclude.record.Id {
-			wrapped = append(wrapped, WrapRecord(record))
+	now := time.Now().UnixNano()
+	all := w.view()
+	wrapped := make([]*Record, 0, len(all))
+	for _, record := range all {
+		if record.ID != exclude.record.Id && !storage.Expired(record.record, now) {
+			wrapped = append(wrapped, record)
 		}
-		return nil
-	})
+	}
 	return wrapped
 }
 
@@ -60,5 +119,5 @@ func (w Records) AllBut(exclude *Record) []*Record {
 func (w Records) CreateRecord(data []float32) *Record {
 	r := new(pb.Record)
 	r.Data = data
-	return WrapRecord(r)
+	return WrapRecordWith(w.backend, r)
 }
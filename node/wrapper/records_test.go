@@ -2,6 +2,7 @@ package wrapper
 
 import (
 	"errors"
+	"github.com/evilsocket/sum/node/backend"
 	"github.com/evilsocket/sum/node/storage"
 	"io/ioutil"
 	"log"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	pb "github.com/evilsocket/sum/proto"
 	"github.com/golang/protobuf/proto"
@@ -204,3 +206,116 @@ func TestWrappedRecordsAllBut(t *testing.T) {
 		}
 	}
 }
+
+func TestWrappedRecordsAllIsCached(t *testing.T) {
+	setupRecords(t, true)
+	defer teardownRecords(t)
+
+	records, err := storage.LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := WrapRecords(records).All()
+	second := WrapRecords(records).All()
+	if len(first) != len(second) {
+		t.Fatalf("expected %d wrapped records, got %d", len(first), len(second))
+	}
+
+	cached := make(map[*Record]bool)
+	for _, wRec := range first {
+		cached[wRec] = true
+	}
+	for _, wRec := range second {
+		if !cached[wRec] {
+			t.Fatalf("record %d was wrapped again", wRec.ID)
+		}
+	}
+
+	// any change to the storage invalidates the cache
+	if err := records.Update(&pb.Record{Id: 1, Data: []float32{1, 2, 3, 4}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, wRec := range WrapRecords(records).All() {
+		if wRec.ID == 1 && wRec.Size != 4 {
+			t.Fatalf("expected record 1 to be wrapped again, size is %d", wRec.Size)
+		}
+	}
+
+	if deleted := records.Delete(2); deleted == nil {
+		t.Fatal("expected record 2 to be deleted")
+	} else if all := WrapRecords(records).All(); len(all) != testRecords-1 {
+		t.Fatalf("expected %d wrapped records, got %d", testRecords-1, len(all))
+	}
+}
+
+func TestWrappedRecordsExpired(t *testing.T) {
+	setupRecords(t, true)
+	defer teardownRecords(t)
+
+	records, err := storage.LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cache a view before the record expires
+	if all := WrapRecords(records).All(); len(all) != testRecords {
+		t.Fatalf("expected %d wrapped records, got %d", testRecords, len(all))
+	}
+
+	expiresAt := time.Now().Add(50 * time.Millisecond).UnixNano()
+	if err := records.Update(&pb.Record{Id: 1, ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	wrapped := WrapRecords(records)
+	if all := wrapped.All(); len(all) != testRecords {
+		t.Fatalf("expected %d wrapped records, got %d", testRecords, len(all))
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if !wrapped.Find(1).IsNull() {
+		t.Fatal("expired record should not be found")
+	} else if all := wrapped.All(); len(all) != testRecords-1 {
+		t.Fatalf("expected %d wrapped records, got %d", testRecords-1, len(all))
+	} else if but := wrapped.AllBut(wrapped.Find(2)); len(but) != testRecords-2 {
+		t.Fatalf("expected %d wrapped records, got %d", testRecords-2, len(but))
+	}
+}
+
+func TestWrappedRecordsBackend(t *testing.T) {
+	setupRecords(t, true)
+	defer teardownRecords(t)
+
+	records, err := storage.LoadRecords(testFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	naive, _ := backend.Get("naive")
+	blas, _ := backend.Get("blas32")
+
+	for _, wRec := range WrapRecordsWith(naive, records).All() {
+		if wRec.Backend() != "naive" {
+			t.Fatalf("expected naive backend for record %d, got %s", wRec.ID, wRec.Backend())
+		}
+	}
+
+	// the cached view is rebuilt for a different backend
+	wrapped := WrapRecordsWith(blas, records)
+	for _, wRec := range wrapped.All() {
+		if wRec.Backend() != "blas32" {
+			t.Fatalf("expected blas32 backend for record %d, got %s", wRec.ID, wRec.Backend())
+		}
+	}
+
+	if b := wrapped.Find(1).Backend(); b != "blas32" {
+		t.Fatalf("expected blas32 backend, got %s", b)
+	} else if b := wrapped.New(&pb.Record{Data: []float32{1}}).Backend(); b != "blas32" {
+		t.Fatalf("expected blas32 backend, got %s", b)
+	} else if b := wrapped.CreateRecord([]float32{1}).Backend(); b != "blas32" {
+		t.Fatalf("expected blas32 backend, got %s", b)
+	}
+}
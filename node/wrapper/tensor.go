@@ -0,0 +1,281 @@
+package wrapper
+
+import (
+	"fmt"
+	"math"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// The methods in this file treat the vector of a record as a tensor with
+// the dimensions of its shape, stored in row-major order. They return new
+// records, without an identifier, that can be used as any other record by
+// the oracles. Invalid arguments make them panic with an error, which is
+// then reported as the error of the oracle.
+
+// results have the same backend of the record
+func (w *Record) tensor(data []float32, shape []uint64) *Record {
+	return WrapRecordWith(w.backend, &pb.Record{Data: data, Shape: shape})
+}
+
+func numElems(shape []uint64) int {
+	n := 1
+	for _, dim := range shape {
+		n *= int(dim)
+	}
+	return n
+}
+
+// Shape returns the dimensions of the vector, records created without
+// a shape have a single dimension.
+func (w *Record) Shape() []uint64 {
+	if len(w.record.Shape) == 0 {
+		return []uint64{uint64(w.Size)}
+	}
+	return w.record.Shape
+}
+
+// Rank returns the number of dimensions of the vector.
+func (w *Record) Rank() int {
+	return len(w.Shape())
+}
+
+// Data returns all the elements of the vector, for sparse records
+// the zeros are filled in and quantized ones are restored.
+func (w *Record) Data() []float32 {
+	if w.sparse != nil {
+		return w.sparse.Dense()
+	} else if w.quantized != nil {
+		return w.quantized.Dense()
+	}
+	return w.record.Data
+}
+
+// returns the shape after checking it is consistent with the data
+func (w *Record) dims() []uint64 {
+	shape := w.Shape()
+	if n := numElems(shape); n != w.Size {
+		panic(fmt.Errorf("record %d has shape %v but %d elements", w.ID, shape, w.Size))
+	}
+	return shape
+}
+
+// returns the rows and the columns of a matrix
+func (w *Record) matrix() (int, int) {
+	shape := w.dims()
+	if len(shape) != 2 {
+		panic(fmt.Errorf("expected a matrix, got shape %v", shape))
+	}
+	return int(shape[0]), int(shape[1])
+}
+
+// At returns the element at the given position, one index for every
+// dimension of the shape.
+func (w *Record) At(indices ...int) float32 {
+	shape := w.dims()
+	if len(indices) != len(shape) {
+		panic(fmt.Errorf("expected %d indices for shape %v, got %d", len(shape), shape, len(indices)))
+	}
+
+	offset := 0
+	for i, idx := range indices {
+		if idx < 0 || idx >= int(shape[i]) {
+			panic(fmt.Errorf("index %d is out of range for dimension %d of shape %v", idx, i, shape))
+		}
+		offset = offset*int(shape[i]) + idx
+	}
+	return w.Get(offset)
+}
+
+// Row returns the index-th element along the first dimension, for a
+// matrix this is one of its rows.
+func (w *Record) Row(index int) *Record {
+	shape := w.dims()
+	if len(shape) < 2 {
+		panic(fmt.Errorf("expected at least 2 dimensions, got shape %v", shape))
+	} else if index < 0 || index >= int(shape[0]) {
+		panic(fmt.Errorf("row %d is out of range for shape %v", index, shape))
+	}
+
+	stride := numElems(shape[1:])
+	start, end := index*stride, (index+1)*stride
+	return w.tensor(w.Data()[start:end:end], shape[1:])
+}
+
+// Column returns the index-th column of a matrix.
+func (w *Record) Column(index int) *Record {
+	rows, cols := w.matrix()
+	if index < 0 || index >= cols {
+		panic(fmt.Errorf("column %d is out of range for shape %v", index, w.Shape()))
+	}
+
+	data := w.Data()
+	column := make([]float32, rows)
+	for i := range column {
+		column[i] = data[i*cols+index]
+	}
+	return w.tensor(column, []uint64{uint64(rows)})
+}
+
+// Reshape returns the same elements with a different shape, one of
+// the dimensions can be -1 and it is computed from the others.
+func (w *Record) Reshape(dims ...int) *Record {
+	w.dims()
+
+	shape := make([]uint64, len(dims))
+	inferred := -1
+	known := 1
+	for i, dim := range dims {
+		if dim == -1 && inferred == -1 {
+			inferred = i
+		} else if dim < 0 {
+			panic(fmt.Errorf("invalid dimension %d in shape %v", dim, dims))
+		} else {
+			shape[i] = uint64(dim)
+			known *= dim
+		}
+	}
+
+	if inferred != -1 {
+		if known == 0 || w.Size%known != 0 {
+			panic(fmt.Errorf("can not reshape %d elements to %v", w.Size, dims))
+		}
+		shape[inferred] = uint64(w.Size / known)
+	}
+
+	if numElems(shape) != w.Size {
+		panic(fmt.Errorf("can not reshape %d elements to %v", w.Size, dims))
+	}
+	return w.tensor(w.Data(), shape)
+}
+
+// Transpose returns the tensor with its dimensions reversed, for a
+// matrix its rows become its columns.
+func (w *Record) Transpose() *Record {
+	shape := w.dims()
+	rank := len(shape)
+	if rank < 2 {
+		return w.tensor(w.Data(), shape)
+	}
+
+	transposed := make([]uint64, rank)
+	for i, dim := range shape {
+		transposed[rank-1-i] = dim
+	}
+
+	// strides of the source dimensions
+	strides := make([]int, rank)
+	stride := 1
+	for i := rank - 1; i >= 0; i-- {
+		strides[i] = stride
+		stride *= int(shape[i])
+	}
+
+	data := w.Data()
+	out := make([]float32, len(data))
+	// position in the transposed tensor, last dimension first
+	pos := make([]int, rank)
+	for i := range out {
+		src := 0
+		for d, p := range pos {
+			src += p * strides[rank-1-d]
+		}
+		out[i] = data[src]
+
+		for d := rank - 1; d >= 0; d-- {
+			if pos[d]++; pos[d] < int(transposed[d]) {
+				break
+			}
+			pos[d] = 0
+		}
+	}
+
+	return w.tensor(out, transposed)
+}
+
+// MatVec returns the product of this matrix and the vector x.
+func (w *Record) MatVec(x *Record) *Record {
+	rows, cols := w.matrix()
+	if x.Size != cols {
+		panic(fmt.Errorf("can not multiply a matrix of shape %v by a vector of %d elements", w.Shape(), x.Size))
+	}
+	return w.tensor(w.backend.MatVec(rows, cols, w.Data(), x.Data()), []uint64{uint64(rows)})
+}
+
+// MatMul returns the product of this matrix and the matrix b, if b
+// has a single dimension it is the same as MatVec.
+func (w *Record) MatMul(b *Record) *Record {
+	if len(b.dims()) == 1 {
+		return w.MatVec(b)
+	}
+
+	rows, inner := w.matrix()
+	bRows, cols := b.matrix()
+	if inner != bRows {
+		panic(fmt.Errorf("can not multiply a matrix of shape %v by one of shape %v", w.Shape(), b.Shape()))
+	}
+	return w.tensor(w.backend.MatMul(rows, inner, cols, w.Data(), b.Data()), []uint64{uint64(rows), uint64(cols)})
+}
+
+// reduces the elements along an axis, negative axes count from the last
+func (w *Record) reduce(axis int, init float64, f func(acc, v float64) float64) (*Record, int) {
+	shape := w.dims()
+	if axis < 0 {
+		axis += len(shape)
+	}
+	if axis < 0 || axis >= len(shape) {
+		panic(fmt.Errorf("axis %d is out of range for shape %v", axis, shape))
+	}
+
+	outer := numElems(shape[:axis])
+	n := int(shape[axis])
+	inner := numElems(shape[axis+1:])
+
+	data := w.Data()
+	out := make([]float32, outer*inner)
+	for o := 0; o < outer; o++ {
+		for i := 0; i < inner; i++ {
+			acc := init
+			for k := 0; k < n; k++ {
+				acc = f(acc, float64(data[(o*n+k)*inner+i]))
+			}
+			out[o*inner+i] = float32(acc)
+		}
+	}
+
+	reduced := append(append([]uint64{}, shape[:axis]...), shape[axis+1:]...)
+	if len(reduced) == 0 {
+		reduced = []uint64{1}
+	}
+	return w.tensor(out, reduced), n
+}
+
+// Sum returns the sum of the elements along an axis, negative axes
+// count from the last one.
+func (w *Record) Sum(axis int) *Record {
+	sum, _ := w.reduce(axis, 0, func(acc, v float64) float64 { return acc + v })
+	return sum
+}
+
+// Mean returns the mean of the elements along an axis.
+func (w *Record) Mean(axis int) *Record {
+	sum, n := w.reduce(axis, 0, func(acc, v float64) float64 { return acc + v })
+	if n > 0 {
+		for i, v := range sum.record.Data {
+			sum.record.Data[i] = v / float32(n)
+		}
+	}
+	return sum
+}
+
+// Max returns the maximum of the elements along an axis.
+func (w *Record) Max(axis int) *Record {
+	max, _ := w.reduce(axis, math.Inf(-1), math.Max)
+	return max
+}
+
+// Min returns the minimum of the elements along an axis.
+func (w *Record) Min(axis int) *Record {
+	min, _ := w.reduce(axis, math.Inf(1), math.Min)
+	return min
+}
@@ -0,0 +1,128 @@
+package wrapper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/evilsocket/sum/node/backend"
+
+	pb "github.com/evilsocket/sum/proto"
+)
+
+// a 2x3 matrix
+func testMatrix() *Record {
+	return WrapRecord(&pb.Record{Data: []float32{1, 2, 3, 4, 5, 6}, Shape: []uint64{2, 3}})
+}
+
+func assertTensor(t *testing.T, r *Record, shape []uint64, data []float32) {
+	t.Helper()
+	if !reflect.DeepEqual(r.Shape(), shape) {
+		t.Fatalf("expected shape %v, got %v", shape, r.Shape())
+	} else if !reflect.DeepEqual(r.Data(), data) {
+		t.Fatalf("expected data %v, got %v", data, r.Data())
+	} else if r.Size != len(data) {
+		t.Fatalf("expected size %d, got %d", len(data), r.Size)
+	}
+}
+
+func TestRecordShape(t *testing.T) {
+	if shape := WrapRecord(&pb.Record{Data: []float32{1, 2, 3}}).Shape(); !reflect.DeepEqual(shape, []uint64{3}) {
+		t.Fatalf("unexpected default shape %v", shape)
+	} else if rank := testMatrix().Rank(); rank != 2 {
+		t.Fatalf("expected rank 2, got %d", rank)
+	}
+}
+
+func TestRecordAt(t *testing.T) {
+	m := testMatrix()
+	if v := m.At(1, 2); v != 6 {
+		t.Fatalf("expected 6, got %f", v)
+	} else if v := m.At(0, 1); v != 2 {
+		t.Fatalf("expected 2, got %f", v)
+	}
+
+	assertPanic(t, "expected panic for wrong number of indices", func() { m.At(1) })
+	assertPanic(t, "expected panic for index out of range", func() { m.At(2, 0) })
+	assertPanic(t, "expected panic for inconsistent shape", func() {
+		WrapRecord(&pb.Record{Data: []float32{1, 2, 3}, Shape: []uint64{2, 2}}).At(0, 0)
+	})
+}
+
+func TestRecordRowColumn(t *testing.T) {
+	m := testMatrix()
+	assertTensor(t, m.Row(1), []uint64{3}, []float32{4, 5, 6})
+	assertTensor(t, m.Column(2), []uint64{2}, []float32{3, 6})
+
+	assertPanic(t, "expected panic for row out of range", func() { m.Row(2) })
+	assertPanic(t, "expected panic for column out of range", func() { m.Column(3) })
+	assertPanic(t, "expected panic for row of a vector", func() { m.Row(0).Row(0) })
+
+	cube := WrapRecord(&pb.Record{Data: []float32{1, 2, 3, 4, 5, 6, 7, 8}, Shape: []uint64{2, 2, 2}})
+	assertTensor(t, cube.Row(1), []uint64{2, 2}, []float32{5, 6, 7, 8})
+}
+
+func TestRecordReshape(t *testing.T) {
+	m := testMatrix()
+	assertTensor(t, m.Reshape(3, 2), []uint64{3, 2}, []float32{1, 2, 3, 4, 5, 6})
+	assertTensor(t, m.Reshape(-1), []uint64{6}, []float32{1, 2, 3, 4, 5, 6})
+	assertTensor(t, m.Reshape(1, -1, 2), []uint64{1, 3, 2}, []float32{1, 2, 3, 4, 5, 6})
+
+	assertPanic(t, "expected panic for wrong number of elements", func() { m.Reshape(4, 2) })
+	assertPanic(t, "expected panic for two inferred dimensions", func() { m.Reshape(-1, -1) })
+	assertPanic(t, "expected panic for indivisible dimension", func() { m.Reshape(4, -1) })
+}
+
+func TestRecordTranspose(t *testing.T) {
+	m := testMatrix()
+	assertTensor(t, m.Transpose(), []uint64{3, 2}, []float32{1, 4, 2, 5, 3, 6})
+	assertTensor(t, m.Transpose().Transpose(), []uint64{2, 3}, m.Data())
+	assertTensor(t, m.Row(0).Transpose(), []uint64{3}, []float32{1, 2, 3})
+
+	// a 2x3x2 tensor becomes 2x3x2 with the axes reversed
+	cube := WrapRecord(&pb.Record{Data: []float32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, Shape: []uint64{2, 3, 2}})
+	tr := cube.Transpose()
+	if !reflect.DeepEqual(tr.Shape(), []uint64{2, 3, 2}) {
+		t.Fatalf("unexpected shape %v", tr.Shape())
+	}
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 2; k++ {
+				if cube.At(i, j, k) != tr.At(k, j, i) {
+					t.Fatalf("element %d,%d,%d was not transposed", i, j, k)
+				}
+			}
+		}
+	}
+}
+
+func TestRecordMatVecMatMul(t *testing.T) {
+	for _, name := range []string{"naive", "blas32"} {
+		backend.Select(name)
+
+		m := testMatrix()
+		x := WrapRecord(&pb.Record{Data: []float32{1, 0, -1}})
+		assertTensor(t, m.MatVec(x), []uint64{2}, []float32{-2, -2})
+		assertTensor(t, m.MatMul(x), []uint64{2}, []float32{-2, -2})
+		assertTensor(t, m.MatMul(m.Transpose()), []uint64{2, 2}, []float32{14, 32, 32, 77})
+
+		sparse := WrapRecord(&pb.Record{Sparse: &pb.SparseVector{Size: 3, Indices: []uint32{2}, Values: []float32{2}}})
+		assertTensor(t, m.MatVec(sparse), []uint64{2}, []float32{6, 12})
+
+		assertPanic(t, "expected panic for incompatible vector", func() { m.MatVec(WrapRecord(&pb.Record{Data: []float32{1, 2}})) })
+		assertPanic(t, "expected panic for incompatible matrix", func() { m.MatMul(m) })
+	}
+	backend.Select("blas32")
+}
+
+func TestRecordReductions(t *testing.T) {
+	m := testMatrix()
+	assertTensor(t, m.Sum(0), []uint64{3}, []float32{5, 7, 9})
+	assertTensor(t, m.Sum(1), []uint64{2}, []float32{6, 15})
+	assertTensor(t, m.Sum(-1), []uint64{2}, []float32{6, 15})
+	assertTensor(t, m.Mean(0), []uint64{3}, []float32{2.5, 3.5, 4.5})
+	assertTensor(t, m.Max(1), []uint64{2}, []float32{3, 6})
+	assertTensor(t, m.Min(0), []uint64{3}, []float32{1, 2, 3})
+	assertTensor(t, m.Sum(1).Sum(0), []uint64{1}, []float32{21})
+
+	assertPanic(t, "expected panic for axis out of range", func() { m.Sum(2) })
+}
@@ -24,6 +24,209 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type QuantizedVector_Type int32
+
+const (
+	QuantizedVector_FLOAT32 QuantizedVector_Type = 0
+	// one byte per element, the value is offset + scale * q
+	QuantizedVector_INT8 QuantizedVector_Type = 1
+	// IEEE 754 half precision, two little endian bytes per element
+	QuantizedVector_FLOAT16 QuantizedVector_Type = 2
+)
+
+var QuantizedVector_Type_name = map[int32]string{
+	0: "FLOAT32",
+	1: "INT8",
+	2: "FLOAT16",
+}
+
+var QuantizedVector_Type_value = map[string]int32{
+	"FLOAT32": 0,
+	"INT8":    1,
+	"FLOAT16": 2,
+}
+
+func (x QuantizedVector_Type) String() string {
+	return proto.EnumName(QuantizedVector_Type_name, int32(x))
+}
+
+func (QuantizedVector_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{4, 0}
+}
+
+type MetaFilter_Op int32
+
+const (
+	MetaFilter_EQ  MetaFilter_Op = 0
+	MetaFilter_LT  MetaFilter_Op = 1
+	MetaFilter_LTE MetaFilter_Op = 2
+	MetaFilter_GT  MetaFilter_Op = 3
+	MetaFilter_GTE MetaFilter_Op = 4
+	// between value and to, both inclusive
+	MetaFilter_BETWEEN MetaFilter_Op = 5
+	// text values starting with value
+	MetaFilter_PREFIX MetaFilter_Op = 6
+)
+
+var MetaFilter_Op_name = map[int32]string{
+	0: "EQ",
+	1: "LT",
+	2: "LTE",
+	3: "GT",
+	4: "GTE",
+	5: "BETWEEN",
+	6: "PREFIX",
+}
+
+var MetaFilter_Op_value = map[string]int32{
+	"EQ":      0,
+	"LT":      1,
+	"LTE":     2,
+	"GT":      3,
+	"GTE":     4,
+	"BETWEEN": 5,
+	"PREFIX":  6,
+}
+
+func (x MetaFilter_Op) String() string {
+	return proto.EnumName(MetaFilter_Op_name, int32(x))
+}
+
+func (MetaFilter_Op) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{6, 0}
+}
+
+type MetaQuery_Op int32
+
+const (
+	// matches the records matching the filter
+	MetaQuery_FILTER MetaQuery_Op = 0
+	// matches the records matching every operand
+	MetaQuery_AND MetaQuery_Op = 1
+	// matches the records matching at least one operand
+	MetaQuery_OR MetaQuery_Op = 2
+	// matches the records not matching its only operand
+	MetaQuery_NOT MetaQuery_Op = 3
+)
+
+var MetaQuery_Op_name = map[int32]string{
+	0: "FILTER",
+	1: "AND",
+	2: "OR",
+	3: "NOT",
+}
+
+var MetaQuery_Op_value = map[string]int32{
+	"FILTER": 0,
+	"AND":    1,
+	"OR":     2,
+	"NOT":    3,
+}
+
+func (x MetaQuery_Op) String() string {
+	return proto.EnumName(MetaQuery_Op_name, int32(x))
+}
+
+func (MetaQuery_Op) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{7, 0}
+}
+
+type Schema_Type int32
+
+const (
+	Schema_ANY    Schema_Type = 0
+	Schema_TEXT   Schema_Type = 1
+	Schema_NUMBER Schema_Type = 2
+	Schema_TIME   Schema_Type = 3
+)
+
+var Schema_Type_name = map[int32]string{
+	0: "ANY",
+	1: "TEXT",
+	2: "NUMBER",
+	3: "TIME",
+}
+
+var Schema_Type_value = map[string]int32{
+	"ANY":    0,
+	"TEXT":   1,
+	"NUMBER": 2,
+	"TIME":   3,
+}
+
+func (x Schema_Type) String() string {
+	return proto.EnumName(Schema_Type_name, int32(x))
+}
+
+func (Schema_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{28, 0}
+}
+
+type Event_Type int32
+
+const (
+	Event_CREATE Event_Type = 0
+	Event_UPDATE Event_Type = 1
+	Event_DELETE Event_Type = 2
+	Event_EXPIRE Event_Type = 3
+	// the records of the collection have been replaced or dropped,
+	// or the events since the requested sequence number are not
+	// available anymore, watchers must read them again
+	Event_RESET Event_Type = 4
+)
+
+var Event_Type_name = map[int32]string{
+	0: "CREATE",
+	1: "UPDATE",
+	2: "DELETE",
+	3: "EXPIRE",
+	4: "RESET",
+}
+
+var Event_Type_value = map[string]int32{
+	"CREATE": 0,
+	"UPDATE": 1,
+	"DELETE": 2,
+	"EXPIRE": 3,
+	"RESET":  4,
+}
+
+func (x Event_Type) String() string {
+	return proto.EnumName(Event_Type_name, int32(x))
+}
+
+func (Event_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{31, 0}
+}
+
+type ArrayHeader_Format int32
+
+const (
+	ArrayHeader_NPY         ArrayHeader_Format = 0
+	ArrayHeader_NPZ         ArrayHeader_Format = 1
+	ArrayHeader_SAFETENSORS ArrayHeader_Format = 2
+)
+
+var ArrayHeader_Format_name = map[int32]string{
+	0: "NPY",
+	1: "NPZ",
+	2: "SAFETENSORS",
+}
+
+var ArrayHeader_Format_value = map[string]int32{
+	"NPY":         0,
+	"NPZ":         1,
+	"SAFETENSORS": 2,
+}
+
+func (x ArrayHeader_Format) String() string {
+	return proto.EnumName(ArrayHeader_Format_name, int32(x))
+}
+
+func (ArrayHeader_Format) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{32, 0}
+}
+
 type Node struct {
 	Id                   uint64      `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name                 string      `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
@@ -135,13 +338,43 @@ func (m *NodeResponse) GetNodes() []*Node {
 }
 
 type Record struct {
-	Id                   uint64            `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Data                 []float32         `protobuf:"fixed32,2,rep,packed,name=data,proto3" json:"data,omitempty"`
-	Shape                []uint64          `protobuf:"varint,3,rep,packed,name=shape,proto3" json:"shape,omitempty"`
-	Meta                 map[string]string `protobuf:"bytes,4,rep,name=meta,proto3" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	Id    uint64            `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Data  []float32         `protobuf:"fixed32,2,rep,packed,name=data,proto3" json:"data,omitempty"`
+	Shape []uint64          `protobuf:"varint,3,rep,packed,name=shape,proto3" json:"shape,omitempty"`
+	Meta  map[string]string `protobuf:"bytes,4,rep,name=meta,proto3" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// incremented at every update, when sent with an update it is the
+	// version the client expects the stored record to have, 0 to ignore
+	Version uint64 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	// unix time in nanoseconds of the last update
+	UpdatedAt int64 `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// unix time in nanoseconds after which the record expires, 0 to never expire,
+	// a negative value when updating the record removes its expiration
+	ExpiresAt int64 `protobuf:"varint,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// if set when creating or updating the record, its expiration time is
+	// set to this number of seconds from now
+	Ttl uint64 `protobuf:"varint,8,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// typed meta data that can be queried by range, keys are shared
+	// with the meta field whose values are indexed as text
+	TypedMeta map[string]*MetaValue `protobuf:"bytes,9,rep,name=typed_meta,json=typedMeta,proto3" json:"typed_meta,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// collection of the record, empty for the default one
+	Collection string `protobuf:"bytes,10,opt,name=collection,proto3" json:"collection,omitempty"`
+	// unix time in nanoseconds the record was moved to the trash,
+	// only set for deleted records
+	DeletedAt int64 `protobuf:"varint,11,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// sparse vector, if set the data field must be empty
+	Sparse *SparseVector `protobuf:"bytes,12,opt,name=sparse,proto3" json:"sparse,omitempty"`
+	// optional unique key of the record within its collection,
+	// when updating the record an empty key leaves it unchanged
+	Key string `protobuf:"bytes,13,opt,name=key,proto3" json:"key,omitempty"`
+	// quantized vector, set by the node instead of the data field when
+	// the collection stores its vectors with less precision
+	Quantized *QuantizedVector `protobuf:"bytes,14,opt,name=quantized,proto3" json:"quantized,omitempty"`
+	// if set when updating the record its key is removed, the key
+	// field must be empty
+	ClearKey             bool     `protobuf:"varint,15,opt,name=clear_key,json=clearKey,proto3" json:"clear_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Record) Reset()         { *m = Record{} }
@@ -197,995 +430,2764 @@ func (m *Record) GetMeta() map[string]string {
 	return nil
 }
 
-type Records struct {
-	Records              []*Record `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+func (m *Record) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
 }
 
-func (m *Records) Reset()         { *m = Records{} }
-func (m *Records) String() string { return proto.CompactTextString(m) }
-func (*Records) ProtoMessage()    {}
-func (*Records) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{3}
+func (m *Record) GetUpdatedAt() int64 {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return 0
 }
 
-func (m *Records) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Records.Unmarshal(m, b)
-}
-func (m *Records) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Records.Marshal(b, m, deterministic)
-}
-func (m *Records) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Records.Merge(m, src)
-}
-func (m *Records) XXX_Size() int {
-	return xxx_messageInfo_Records.Size(m)
-}
-func (m *Records) XXX_DiscardUnknown() {
-	xxx_messageInfo_Records.DiscardUnknown(m)
+func (m *Record) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
 }
 
-var xxx_messageInfo_Records proto.InternalMessageInfo
+func (m *Record) GetTtl() uint64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
 
-func (m *Records) GetRecords() []*Record {
+func (m *Record) GetTypedMeta() map[string]*MetaValue {
 	if m != nil {
-		return m.Records
+		return m.TypedMeta
 	}
 	return nil
 }
 
-type RecordIds struct {
-	Ids                  []uint64 `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *Record) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
 }
 
-func (m *RecordIds) Reset()         { *m = RecordIds{} }
-func (m *RecordIds) String() string { return proto.CompactTextString(m) }
-func (*RecordIds) ProtoMessage()    {}
-func (*RecordIds) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{4}
+func (m *Record) GetDeletedAt() int64 {
+	if m != nil {
+		return m.DeletedAt
+	}
+	return 0
 }
 
-func (m *RecordIds) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RecordIds.Unmarshal(m, b)
-}
-func (m *RecordIds) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RecordIds.Marshal(b, m, deterministic)
-}
-func (m *RecordIds) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RecordIds.Merge(m, src)
-}
-func (m *RecordIds) XXX_Size() int {
-	return xxx_messageInfo_RecordIds.Size(m)
-}
-func (m *RecordIds) XXX_DiscardUnknown() {
-	xxx_messageInfo_RecordIds.DiscardUnknown(m)
+func (m *Record) GetSparse() *SparseVector {
+	if m != nil {
+		return m.Sparse
+	}
+	return nil
 }
 
-var xxx_messageInfo_RecordIds proto.InternalMessageInfo
+func (m *Record) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
 
-func (m *RecordIds) GetIds() []uint64 {
+func (m *Record) GetQuantized() *QuantizedVector {
 	if m != nil {
-		return m.Ids
+		return m.Quantized
 	}
 	return nil
 }
 
-type RecordResponse struct {
-	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Msg                  string   `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
-	Record               *Record  `protobuf:"bytes,3,opt,name=record,proto3" json:"record,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *Record) GetClearKey() bool {
+	if m != nil {
+		return m.ClearKey
+	}
+	return false
 }
 
-func (m *RecordResponse) Reset()         { *m = RecordResponse{} }
-func (m *RecordResponse) String() string { return proto.CompactTextString(m) }
-func (*RecordResponse) ProtoMessage()    {}
-func (*RecordResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{5}
+// SparseVector holds only the non zero elements of a vector.
+type SparseVector struct {
+	// number of elements of the whole vector
+	Size uint64 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	// positions of the non zero elements, in increasing order
+	Indices              []uint32  `protobuf:"varint,2,rep,packed,name=indices,proto3" json:"indices,omitempty"`
+	Values               []float32 `protobuf:"fixed32,3,rep,packed,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
 }
 
-func (m *RecordResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RecordResponse.Unmarshal(m, b)
+func (m *SparseVector) Reset()         { *m = SparseVector{} }
+func (m *SparseVector) String() string { return proto.CompactTextString(m) }
+func (*SparseVector) ProtoMessage()    {}
+func (*SparseVector) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{3}
 }
-func (m *RecordResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RecordResponse.Marshal(b, m, deterministic)
+
+func (m *SparseVector) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SparseVector.Unmarshal(m, b)
 }
-func (m *RecordResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RecordResponse.Merge(m, src)
+func (m *SparseVector) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SparseVector.Marshal(b, m, deterministic)
 }
-func (m *RecordResponse) XXX_Size() int {
-	return xxx_messageInfo_RecordResponse.Size(m)
+func (m *SparseVector) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SparseVector.Merge(m, src)
 }
-func (m *RecordResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_RecordResponse.DiscardUnknown(m)
+func (m *SparseVector) XXX_Size() int {
+	return xxx_messageInfo_SparseVector.Size(m)
+}
+func (m *SparseVector) XXX_DiscardUnknown() {
+	xxx_messageInfo_SparseVector.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_RecordResponse proto.InternalMessageInfo
+var xxx_messageInfo_SparseVector proto.InternalMessageInfo
 
-func (m *RecordResponse) GetSuccess() bool {
+func (m *SparseVector) GetSize() uint64 {
 	if m != nil {
-		return m.Success
+		return m.Size
 	}
-	return false
+	return 0
 }
 
-func (m *RecordResponse) GetMsg() string {
+func (m *SparseVector) GetIndices() []uint32 {
 	if m != nil {
-		return m.Msg
+		return m.Indices
 	}
-	return ""
+	return nil
 }
 
-func (m *RecordResponse) GetRecord() *Record {
+func (m *SparseVector) GetValues() []float32 {
 	if m != nil {
-		return m.Record
+		return m.Values
 	}
 	return nil
 }
 
-type ListRequest struct {
-	Page                 uint64   `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
-	PerPage              uint64   `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+// QuantizedVector holds the elements of a vector with less precision.
+type QuantizedVector struct {
+	Type QuantizedVector_Type `protobuf:"varint,1,opt,name=type,proto3,enum=sum.QuantizedVector_Type" json:"type,omitempty"`
+	// number of elements of the vector
+	Size                 uint64   `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Data                 []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Scale                float32  `protobuf:"fixed32,4,opt,name=scale,proto3" json:"scale,omitempty"`
+	Offset               float32  `protobuf:"fixed32,5,opt,name=offset,proto3" json:"offset,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ListRequest) Reset()         { *m = ListRequest{} }
-func (m *ListRequest) String() string { return proto.CompactTextString(m) }
-func (*ListRequest) ProtoMessage()    {}
-func (*ListRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{6}
+func (m *QuantizedVector) Reset()         { *m = QuantizedVector{} }
+func (m *QuantizedVector) String() string { return proto.CompactTextString(m) }
+func (*QuantizedVector) ProtoMessage()    {}
+func (*QuantizedVector) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{4}
 }
 
-func (m *ListRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ListRequest.Unmarshal(m, b)
+func (m *QuantizedVector) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QuantizedVector.Unmarshal(m, b)
 }
-func (m *ListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ListRequest.Marshal(b, m, deterministic)
+func (m *QuantizedVector) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QuantizedVector.Marshal(b, m, deterministic)
 }
-func (m *ListRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ListRequest.Merge(m, src)
+func (m *QuantizedVector) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QuantizedVector.Merge(m, src)
 }
-func (m *ListRequest) XXX_Size() int {
-	return xxx_messageInfo_ListRequest.Size(m)
+func (m *QuantizedVector) XXX_Size() int {
+	return xxx_messageInfo_QuantizedVector.Size(m)
 }
-func (m *ListRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_ListRequest.DiscardUnknown(m)
+func (m *QuantizedVector) XXX_DiscardUnknown() {
+	xxx_messageInfo_QuantizedVector.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ListRequest proto.InternalMessageInfo
+var xxx_messageInfo_QuantizedVector proto.InternalMessageInfo
 
-func (m *ListRequest) GetPage() uint64 {
+func (m *QuantizedVector) GetType() QuantizedVector_Type {
 	if m != nil {
-		return m.Page
+		return m.Type
 	}
-	return 0
+	return QuantizedVector_FLOAT32
 }
 
-func (m *ListRequest) GetPerPage() uint64 {
+func (m *QuantizedVector) GetSize() uint64 {
 	if m != nil {
-		return m.PerPage
+		return m.Size
 	}
 	return 0
 }
 
-type RecordListResponse struct {
-	Total                uint64    `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
-	Pages                uint64    `protobuf:"varint,2,opt,name=pages,proto3" json:"pages,omitempty"`
-	Records              []*Record `protobuf:"bytes,3,rep,name=records,proto3" json:"records,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
-}
-
-func (m *RecordListResponse) Reset()         { *m = RecordListResponse{} }
-func (m *RecordListResponse) String() string { return proto.CompactTextString(m) }
-func (*RecordListResponse) ProtoMessage()    {}
-func (*RecordListResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{7}
-}
-
-func (m *RecordListResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RecordListResponse.Unmarshal(m, b)
-}
-func (m *RecordListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RecordListResponse.Marshal(b, m, deterministic)
-}
-func (m *RecordListResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RecordListResponse.Merge(m, src)
-}
-func (m *RecordListResponse) XXX_Size() int {
-	return xxx_messageInfo_RecordListResponse.Size(m)
-}
-func (m *RecordListResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_RecordListResponse.DiscardUnknown(m)
+func (m *QuantizedVector) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
 }
 
-var xxx_messageInfo_RecordListResponse proto.InternalMessageInfo
-
-func (m *RecordListResponse) GetTotal() uint64 {
+func (m *QuantizedVector) GetScale() float32 {
 	if m != nil {
-		return m.Total
+		return m.Scale
 	}
 	return 0
 }
 
-func (m *RecordListResponse) GetPages() uint64 {
+func (m *QuantizedVector) GetOffset() float32 {
 	if m != nil {
-		return m.Pages
+		return m.Offset
 	}
 	return 0
 }
 
-func (m *RecordListResponse) GetRecords() []*Record {
+type MetaValue struct {
+	// Types that are valid to be assigned to Value:
+	//	*MetaValue_Text
+	//	*MetaValue_Number
+	//	*MetaValue_Time
+	Value                isMetaValue_Value `protobuf_oneof:"value"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *MetaValue) Reset()         { *m = MetaValue{} }
+func (m *MetaValue) String() string { return proto.CompactTextString(m) }
+func (*MetaValue) ProtoMessage()    {}
+func (*MetaValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{5}
+}
+
+func (m *MetaValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MetaValue.Unmarshal(m, b)
+}
+func (m *MetaValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MetaValue.Marshal(b, m, deterministic)
+}
+func (m *MetaValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetaValue.Merge(m, src)
+}
+func (m *MetaValue) XXX_Size() int {
+	return xxx_messageInfo_MetaValue.Size(m)
+}
+func (m *MetaValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetaValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetaValue proto.InternalMessageInfo
+
+type isMetaValue_Value interface {
+	isMetaValue_Value()
+}
+
+type MetaValue_Text struct {
+	Text string `protobuf:"bytes,1,opt,name=text,proto3,oneof"`
+}
+
+type MetaValue_Number struct {
+	Number float64 `protobuf:"fixed64,2,opt,name=number,proto3,oneof"`
+}
+
+type MetaValue_Time struct {
+	Time int64 `protobuf:"varint,3,opt,name=time,proto3,oneof"`
+}
+
+func (*MetaValue_Text) isMetaValue_Value() {}
+
+func (*MetaValue_Number) isMetaValue_Value() {}
+
+func (*MetaValue_Time) isMetaValue_Value() {}
+
+func (m *MetaValue) GetValue() isMetaValue_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *MetaValue) GetText() string {
+	if x, ok := m.GetValue().(*MetaValue_Text); ok {
+		return x.Text
+	}
+	return ""
+}
+
+func (m *MetaValue) GetNumber() float64 {
+	if x, ok := m.GetValue().(*MetaValue_Number); ok {
+		return x.Number
+	}
+	return 0
+}
+
+func (m *MetaValue) GetTime() int64 {
+	if x, ok := m.GetValue().(*MetaValue_Time); ok {
+		return x.Time
+	}
+	return 0
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*MetaValue) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*MetaValue_Text)(nil),
+		(*MetaValue_Number)(nil),
+		(*MetaValue_Time)(nil),
+	}
+}
+
+type MetaFilter struct {
+	Meta                 string        `protobuf:"bytes,1,opt,name=meta,proto3" json:"meta,omitempty"`
+	Op                   MetaFilter_Op `protobuf:"varint,2,opt,name=op,proto3,enum=sum.MetaFilter_Op" json:"op,omitempty"`
+	Value                *MetaValue    `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	To                   *MetaValue    `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *MetaFilter) Reset()         { *m = MetaFilter{} }
+func (m *MetaFilter) String() string { return proto.CompactTextString(m) }
+func (*MetaFilter) ProtoMessage()    {}
+func (*MetaFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{6}
+}
+
+func (m *MetaFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MetaFilter.Unmarshal(m, b)
+}
+func (m *MetaFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MetaFilter.Marshal(b, m, deterministic)
+}
+func (m *MetaFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetaFilter.Merge(m, src)
+}
+func (m *MetaFilter) XXX_Size() int {
+	return xxx_messageInfo_MetaFilter.Size(m)
+}
+func (m *MetaFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetaFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetaFilter proto.InternalMessageInfo
+
+func (m *MetaFilter) GetMeta() string {
+	if m != nil {
+		return m.Meta
+	}
+	return ""
+}
+
+func (m *MetaFilter) GetOp() MetaFilter_Op {
+	if m != nil {
+		return m.Op
+	}
+	return MetaFilter_EQ
+}
+
+func (m *MetaFilter) GetValue() *MetaValue {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *MetaFilter) GetTo() *MetaValue {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+// a boolean expression over meta filters
+type MetaQuery struct {
+	Op                   MetaQuery_Op `protobuf:"varint,1,opt,name=op,proto3,enum=sum.MetaQuery_Op" json:"op,omitempty"`
+	Filter               *MetaFilter  `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	Operands             []*MetaQuery `protobuf:"bytes,3,rep,name=operands,proto3" json:"operands,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *MetaQuery) Reset()         { *m = MetaQuery{} }
+func (m *MetaQuery) String() string { return proto.CompactTextString(m) }
+func (*MetaQuery) ProtoMessage()    {}
+func (*MetaQuery) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{7}
+}
+
+func (m *MetaQuery) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MetaQuery.Unmarshal(m, b)
+}
+func (m *MetaQuery) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MetaQuery.Marshal(b, m, deterministic)
+}
+func (m *MetaQuery) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MetaQuery.Merge(m, src)
+}
+func (m *MetaQuery) XXX_Size() int {
+	return xxx_messageInfo_MetaQuery.Size(m)
+}
+func (m *MetaQuery) XXX_DiscardUnknown() {
+	xxx_messageInfo_MetaQuery.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MetaQuery proto.InternalMessageInfo
+
+func (m *MetaQuery) GetOp() MetaQuery_Op {
+	if m != nil {
+		return m.Op
+	}
+	return MetaQuery_FILTER
+}
+
+func (m *MetaQuery) GetFilter() *MetaFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func (m *MetaQuery) GetOperands() []*MetaQuery {
+	if m != nil {
+		return m.Operands
+	}
+	return nil
+}
+
+type Records struct {
+	Records    []*Record `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	Collection string    `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	// set by the master when moving records between nodes
+	Transfer             bool     `protobuf:"varint,3,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Records) Reset()         { *m = Records{} }
+func (m *Records) String() string { return proto.CompactTextString(m) }
+func (*Records) ProtoMessage()    {}
+func (*Records) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{8}
+}
+
+func (m *Records) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Records.Unmarshal(m, b)
+}
+func (m *Records) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Records.Marshal(b, m, deterministic)
+}
+func (m *Records) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Records.Merge(m, src)
+}
+func (m *Records) XXX_Size() int {
+	return xxx_messageInfo_Records.Size(m)
+}
+func (m *Records) XXX_DiscardUnknown() {
+	xxx_messageInfo_Records.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Records proto.InternalMessageInfo
+
+func (m *Records) GetRecords() []*Record {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+func (m *Records) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *Records) GetTransfer() bool {
+	if m != nil {
+		return m.Transfer
+	}
+	return false
+}
+
+type RecordIds struct {
+	Ids        []uint64 `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	Collection string   `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	// set by the master when moving records between nodes
+	Transfer             bool     `protobuf:"varint,3,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RecordIds) Reset()         { *m = RecordIds{} }
+func (m *RecordIds) String() string { return proto.CompactTextString(m) }
+func (*RecordIds) ProtoMessage()    {}
+func (*RecordIds) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{9}
+}
+
+func (m *RecordIds) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecordIds.Unmarshal(m, b)
+}
+func (m *RecordIds) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecordIds.Marshal(b, m, deterministic)
+}
+func (m *RecordIds) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecordIds.Merge(m, src)
+}
+func (m *RecordIds) XXX_Size() int {
+	return xxx_messageInfo_RecordIds.Size(m)
+}
+func (m *RecordIds) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecordIds.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecordIds proto.InternalMessageInfo
+
+func (m *RecordIds) GetIds() []uint64 {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+func (m *RecordIds) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *RecordIds) GetTransfer() bool {
+	if m != nil {
+		return m.Transfer
+	}
+	return false
+}
+
+type RecordResponse struct {
+	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Msg                  string   `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	Record               *Record  `protobuf:"bytes,3,opt,name=record,proto3" json:"record,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RecordResponse) Reset()         { *m = RecordResponse{} }
+func (m *RecordResponse) String() string { return proto.CompactTextString(m) }
+func (*RecordResponse) ProtoMessage()    {}
+func (*RecordResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{10}
+}
+
+func (m *RecordResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecordResponse.Unmarshal(m, b)
+}
+func (m *RecordResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecordResponse.Marshal(b, m, deterministic)
+}
+func (m *RecordResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecordResponse.Merge(m, src)
+}
+func (m *RecordResponse) XXX_Size() int {
+	return xxx_messageInfo_RecordResponse.Size(m)
+}
+func (m *RecordResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecordResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecordResponse proto.InternalMessageInfo
+
+func (m *RecordResponse) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *RecordResponse) GetMsg() string {
+	if m != nil {
+		return m.Msg
+	}
+	return ""
+}
+
+func (m *RecordResponse) GetRecord() *Record {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
+type ListRequest struct {
+	Page    uint64 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage uint64 `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	// collection to list the records of, ignored for oracles
+	Collection string `protobuf:"bytes,3,opt,name=collection,proto3" json:"collection,omitempty"`
+	// return the quantized vectors of the records as stored instead
+	// of dequantizing them into their data
+	Quantized            bool     `protobuf:"varint,4,opt,name=quantized,proto3" json:"quantized,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListRequest) Reset()         { *m = ListRequest{} }
+func (m *ListRequest) String() string { return proto.CompactTextString(m) }
+func (*ListRequest) ProtoMessage()    {}
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{11}
+}
+
+func (m *ListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRequest.Unmarshal(m, b)
+}
+func (m *ListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRequest.Marshal(b, m, deterministic)
+}
+func (m *ListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRequest.Merge(m, src)
+}
+func (m *ListRequest) XXX_Size() int {
+	return xxx_messageInfo_ListRequest.Size(m)
+}
+func (m *ListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRequest proto.InternalMessageInfo
+
+func (m *ListRequest) GetPage() uint64 {
+	if m != nil {
+		return m.Page
+	}
+	return 0
+}
+
+func (m *ListRequest) GetPerPage() uint64 {
+	if m != nil {
+		return m.PerPage
+	}
+	return 0
+}
+
+func (m *ListRequest) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *ListRequest) GetQuantized() bool {
+	if m != nil {
+		return m.Quantized
+	}
+	return false
+}
+
+type RecordListResponse struct {
+	Total                uint64    `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Pages                uint64    `protobuf:"varint,2,opt,name=pages,proto3" json:"pages,omitempty"`
+	Records              []*Record `protobuf:"bytes,3,rep,name=records,proto3" json:"records,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *RecordListResponse) Reset()         { *m = RecordListResponse{} }
+func (m *RecordListResponse) String() string { return proto.CompactTextString(m) }
+func (*RecordListResponse) ProtoMessage()    {}
+func (*RecordListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{12}
+}
+
+func (m *RecordListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecordListResponse.Unmarshal(m, b)
+}
+func (m *RecordListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecordListResponse.Marshal(b, m, deterministic)
+}
+func (m *RecordListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecordListResponse.Merge(m, src)
+}
+func (m *RecordListResponse) XXX_Size() int {
+	return xxx_messageInfo_RecordListResponse.Size(m)
+}
+func (m *RecordListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecordListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecordListResponse proto.InternalMessageInfo
+
+func (m *RecordListResponse) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *RecordListResponse) GetPages() uint64 {
+	if m != nil {
+		return m.Pages
+	}
+	return 0
+}
+
+func (m *RecordListResponse) GetRecords() []*Record {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+type OracleListResponse struct {
+	Total                uint64    `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Pages                uint64    `protobuf:"varint,2,opt,name=pages,proto3" json:"pages,omitempty"`
+	Oracles              []*Oracle `protobuf:"bytes,3,rep,name=oracles,proto3" json:"oracles,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *OracleListResponse) Reset()         { *m = OracleListResponse{} }
+func (m *OracleListResponse) String() string { return proto.CompactTextString(m) }
+func (*OracleListResponse) ProtoMessage()    {}
+func (*OracleListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{13}
+}
+
+func (m *OracleListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OracleListResponse.Unmarshal(m, b)
+}
+func (m *OracleListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OracleListResponse.Marshal(b, m, deterministic)
+}
+func (m *OracleListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OracleListResponse.Merge(m, src)
+}
+func (m *OracleListResponse) XXX_Size() int {
+	return xxx_messageInfo_OracleListResponse.Size(m)
+}
+func (m *OracleListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_OracleListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OracleListResponse proto.InternalMessageInfo
+
+func (m *OracleListResponse) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *OracleListResponse) GetPages() uint64 {
+	if m != nil {
+		return m.Pages
+	}
+	return 0
+}
+
+func (m *OracleListResponse) GetOracles() []*Oracle {
+	if m != nil {
+		return m.Oracles
+	}
+	return nil
+}
+
+type FindResponse struct {
+	Success              bool      `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Msg                  string    `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	Records              []*Record `protobuf:"bytes,3,rep,name=records,proto3" json:"records,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *FindResponse) Reset()         { *m = FindResponse{} }
+func (m *FindResponse) String() string { return proto.CompactTextString(m) }
+func (*FindResponse) ProtoMessage()    {}
+func (*FindResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{14}
+}
+
+func (m *FindResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FindResponse.Unmarshal(m, b)
+}
+func (m *FindResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FindResponse.Marshal(b, m, deterministic)
+}
+func (m *FindResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FindResponse.Merge(m, src)
+}
+func (m *FindResponse) XXX_Size() int {
+	return xxx_messageInfo_FindResponse.Size(m)
+}
+func (m *FindResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FindResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FindResponse proto.InternalMessageInfo
+
+func (m *FindResponse) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *FindResponse) GetMsg() string {
+	if m != nil {
+		return m.Msg
+	}
+	return ""
+}
+
+func (m *FindResponse) GetRecords() []*Record {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+type Oracle struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Code                 string   `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Oracle) Reset()         { *m = Oracle{} }
+func (m *Oracle) String() string { return proto.CompactTextString(m) }
+func (*Oracle) ProtoMessage()    {}
+func (*Oracle) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{15}
+}
+
+func (m *Oracle) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Oracle.Unmarshal(m, b)
+}
+func (m *Oracle) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Oracle.Marshal(b, m, deterministic)
+}
+func (m *Oracle) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Oracle.Merge(m, src)
+}
+func (m *Oracle) XXX_Size() int {
+	return xxx_messageInfo_Oracle.Size(m)
+}
+func (m *Oracle) XXX_DiscardUnknown() {
+	xxx_messageInfo_Oracle.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Oracle proto.InternalMessageInfo
+
+func (m *Oracle) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Oracle) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Oracle) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+type OracleResponse struct {
+	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Msg                  string   `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	Oracle               *Oracle  `protobuf:"bytes,3,opt,name=oracle,proto3" json:"oracle,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OracleResponse) Reset()         { *m = OracleResponse{} }
+func (m *OracleResponse) String() string { return proto.CompactTextString(m) }
+func (*OracleResponse) ProtoMessage()    {}
+func (*OracleResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{16}
+}
+
+func (m *OracleResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OracleResponse.Unmarshal(m, b)
+}
+func (m *OracleResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OracleResponse.Marshal(b, m, deterministic)
+}
+func (m *OracleResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OracleResponse.Merge(m, src)
+}
+func (m *OracleResponse) XXX_Size() int {
+	return xxx_messageInfo_OracleResponse.Size(m)
+}
+func (m *OracleResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_OracleResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OracleResponse proto.InternalMessageInfo
+
+func (m *OracleResponse) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *OracleResponse) GetMsg() string {
+	if m != nil {
+		return m.Msg
+	}
+	return ""
+}
+
+func (m *OracleResponse) GetOracle() *Oracle {
+	if m != nil {
+		return m.Oracle
+	}
+	return nil
+}
+
+type Call struct {
+	OracleId uint64   `protobuf:"varint,1,opt,name=oracle_id,json=oracleId,proto3" json:"oracle_id,omitempty"`
+	Args     []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	// collection of records the oracle runs on
+	Collection           string   `protobuf:"bytes,3,opt,name=collection,proto3" json:"collection,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Call) Reset()         { *m = Call{} }
+func (m *Call) String() string { return proto.CompactTextString(m) }
+func (*Call) ProtoMessage()    {}
+func (*Call) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{17}
+}
+
+func (m *Call) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Call.Unmarshal(m, b)
+}
+func (m *Call) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Call.Marshal(b, m, deterministic)
+}
+func (m *Call) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Call.Merge(m, src)
+}
+func (m *Call) XXX_Size() int {
+	return xxx_messageInfo_Call.Size(m)
+}
+func (m *Call) XXX_DiscardUnknown() {
+	xxx_messageInfo_Call.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Call proto.InternalMessageInfo
+
+func (m *Call) GetOracleId() uint64 {
+	if m != nil {
+		return m.OracleId
+	}
+	return 0
+}
+
+func (m *Call) GetArgs() []string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *Call) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+type Data struct {
+	Compressed           bool     `protobuf:"varint,1,opt,name=compressed,proto3" json:"compressed,omitempty"`
+	Payload              []byte   `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Data) Reset()         { *m = Data{} }
+func (m *Data) String() string { return proto.CompactTextString(m) }
+func (*Data) ProtoMessage()    {}
+func (*Data) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{18}
+}
+
+func (m *Data) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Data.Unmarshal(m, b)
+}
+func (m *Data) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Data.Marshal(b, m, deterministic)
+}
+func (m *Data) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Data.Merge(m, src)
+}
+func (m *Data) XXX_Size() int {
+	return xxx_messageInfo_Data.Size(m)
+}
+func (m *Data) XXX_DiscardUnknown() {
+	xxx_messageInfo_Data.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Data proto.InternalMessageInfo
+
+func (m *Data) GetCompressed() bool {
+	if m != nil {
+		return m.Compressed
+	}
+	return false
+}
+
+func (m *Data) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+type CallResponse struct {
+	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Msg                  string   `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	Data                 *Data    `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CallResponse) Reset()         { *m = CallResponse{} }
+func (m *CallResponse) String() string { return proto.CompactTextString(m) }
+func (*CallResponse) ProtoMessage()    {}
+func (*CallResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{19}
+}
+
+func (m *CallResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CallResponse.Unmarshal(m, b)
+}
+func (m *CallResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CallResponse.Marshal(b, m, deterministic)
+}
+func (m *CallResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CallResponse.Merge(m, src)
+}
+func (m *CallResponse) XXX_Size() int {
+	return xxx_messageInfo_CallResponse.Size(m)
+}
+func (m *CallResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CallResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CallResponse proto.InternalMessageInfo
+
+func (m *CallResponse) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *CallResponse) GetMsg() string {
+	if m != nil {
+		return m.Msg
+	}
+	return ""
+}
+
+func (m *CallResponse) GetData() *Data {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type ById struct {
+	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// expected version of the object, 0 to ignore
+	Version uint64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	// collection of the record, ignored for oracles and nodes
+	Collection string `protobuf:"bytes,3,opt,name=collection,proto3" json:"collection,omitempty"`
+	// return the quantized vector of the record as stored instead
+	// of dequantizing it into its data
+	Quantized            bool     `protobuf:"varint,4,opt,name=quantized,proto3" json:"quantized,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ById) Reset()         { *m = ById{} }
+func (m *ById) String() string { return proto.CompactTextString(m) }
+func (*ById) ProtoMessage()    {}
+func (*ById) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{20}
+}
+
+func (m *ById) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ById.Unmarshal(m, b)
+}
+func (m *ById) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ById.Marshal(b, m, deterministic)
+}
+func (m *ById) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ById.Merge(m, src)
+}
+func (m *ById) XXX_Size() int {
+	return xxx_messageInfo_ById.Size(m)
+}
+func (m *ById) XXX_DiscardUnknown() {
+	xxx_messageInfo_ById.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ById proto.InternalMessageInfo
+
+func (m *ById) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *ById) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ById) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *ById) GetQuantized() bool {
+	if m != nil {
+		return m.Quantized
+	}
+	return false
+}
+
+type ByKey struct {
+	Key        string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Collection string `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	// return the quantized vector of the record as stored instead
+	// of dequantizing it into its data
+	Quantized            bool     `protobuf:"varint,3,opt,name=quantized,proto3" json:"quantized,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ByKey) Reset()         { *m = ByKey{} }
+func (m *ByKey) String() string { return proto.CompactTextString(m) }
+func (*ByKey) ProtoMessage()    {}
+func (*ByKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{21}
+}
+
+func (m *ByKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ByKey.Unmarshal(m, b)
+}
+func (m *ByKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ByKey.Marshal(b, m, deterministic)
+}
+func (m *ByKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ByKey.Merge(m, src)
+}
+func (m *ByKey) XXX_Size() int {
+	return xxx_messageInfo_ByKey.Size(m)
+}
+func (m *ByKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_ByKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ByKey proto.InternalMessageInfo
+
+func (m *ByKey) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *ByKey) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *ByKey) GetQuantized() bool {
+	if m != nil {
+		return m.Quantized
+	}
+	return false
+}
+
+type ByName struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ByName) Reset()         { *m = ByName{} }
+func (m *ByName) String() string { return proto.CompactTextString(m) }
+func (*ByName) ProtoMessage()    {}
+func (*ByName) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{22}
+}
+
+func (m *ByName) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ByName.Unmarshal(m, b)
+}
+func (m *ByName) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ByName.Marshal(b, m, deterministic)
+}
+func (m *ByName) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ByName.Merge(m, src)
+}
+func (m *ByName) XXX_Size() int {
+	return xxx_messageInfo_ByName.Size(m)
+}
+func (m *ByName) XXX_DiscardUnknown() {
+	xxx_messageInfo_ByName.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ByName proto.InternalMessageInfo
+
+func (m *ByName) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type ByAddr struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	CertFile             string   `protobuf:"bytes,2,opt,name=cert_file,json=certFile,proto3" json:"cert_file,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ByAddr) Reset()         { *m = ByAddr{} }
+func (m *ByAddr) String() string { return proto.CompactTextString(m) }
+func (*ByAddr) ProtoMessage()    {}
+func (*ByAddr) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{23}
+}
+
+func (m *ByAddr) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ByAddr.Unmarshal(m, b)
+}
+func (m *ByAddr) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ByAddr.Marshal(b, m, deterministic)
+}
+func (m *ByAddr) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ByAddr.Merge(m, src)
+}
+func (m *ByAddr) XXX_Size() int {
+	return xxx_messageInfo_ByAddr.Size(m)
+}
+func (m *ByAddr) XXX_DiscardUnknown() {
+	xxx_messageInfo_ByAddr.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ByAddr proto.InternalMessageInfo
+
+func (m *ByAddr) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *ByAddr) GetCertFile() string {
+	if m != nil {
+		return m.CertFile
+	}
+	return ""
+}
+
+type ByMeta struct {
+	Meta  string `protobuf:"bytes,1,opt,name=meta,proto3" json:"meta,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// if not empty, meta and value are ignored and the records
+	// matching every filter are returned
+	Filters []*MetaFilter `protobuf:"bytes,3,rep,name=filters,proto3" json:"filters,omitempty"`
+	// if set, meta, value and filters are ignored and the
+	// records matching the query are returned
+	Query      *MetaQuery `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`
+	Collection string     `protobuf:"bytes,5,opt,name=collection,proto3" json:"collection,omitempty"`
+	// return the quantized vectors of the records as stored instead
+	// of dequantizing them into their data
+	Quantized            bool     `protobuf:"varint,6,opt,name=quantized,proto3" json:"quantized,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ByMeta) Reset()         { *m = ByMeta{} }
+func (m *ByMeta) String() string { return proto.CompactTextString(m) }
+func (*ByMeta) ProtoMessage()    {}
+func (*ByMeta) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{24}
+}
+
+func (m *ByMeta) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ByMeta.Unmarshal(m, b)
+}
+func (m *ByMeta) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ByMeta.Marshal(b, m, deterministic)
+}
+func (m *ByMeta) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ByMeta.Merge(m, src)
+}
+func (m *ByMeta) XXX_Size() int {
+	return xxx_messageInfo_ByMeta.Size(m)
+}
+func (m *ByMeta) XXX_DiscardUnknown() {
+	xxx_messageInfo_ByMeta.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ByMeta proto.InternalMessageInfo
+
+func (m *ByMeta) GetMeta() string {
+	if m != nil {
+		return m.Meta
+	}
+	return ""
+}
+
+func (m *ByMeta) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *ByMeta) GetFilters() []*MetaFilter {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *ByMeta) GetQuery() *MetaQuery {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+func (m *ByMeta) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *ByMeta) GetQuantized() bool {
+	if m != nil {
+		return m.Quantized
+	}
+	return false
+}
+
+type ServerInfo struct {
+	Version        string   `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Os             string   `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
+	Arch           string   `protobuf:"bytes,3,opt,name=arch,proto3" json:"arch,omitempty"`
+	GoVersion      string   `protobuf:"bytes,4,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	Cpus           uint64   `protobuf:"varint,5,opt,name=cpus,proto3" json:"cpus,omitempty"`
+	MaxCpus        uint64   `protobuf:"varint,6,opt,name=max_cpus,json=maxCpus,proto3" json:"max_cpus,omitempty"`
+	Goroutines     uint64   `protobuf:"varint,7,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
+	Alloc          uint64   `protobuf:"varint,8,opt,name=alloc,proto3" json:"alloc,omitempty"`
+	Sys            uint64   `protobuf:"varint,9,opt,name=sys,proto3" json:"sys,omitempty"`
+	NumGc          uint64   `protobuf:"varint,10,opt,name=num_gc,json=numGc,proto3" json:"num_gc,omitempty"`
+	Datapath       string   `protobuf:"bytes,11,opt,name=datapath,proto3" json:"datapath,omitempty"`
+	Credspath      string   `protobuf:"bytes,12,opt,name=credspath,proto3" json:"credspath,omitempty"`
+	Address        string   `protobuf:"bytes,13,opt,name=address,proto3" json:"address,omitempty"`
+	Uptime         uint64   `protobuf:"varint,14,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	Pid            uint64   `protobuf:"varint,15,opt,name=pid,proto3" json:"pid,omitempty"`
+	Uid            uint64   `protobuf:"varint,16,opt,name=uid,proto3" json:"uid,omitempty"`
+	Argv           []string `protobuf:"bytes,17,rep,name=argv,proto3" json:"argv,omitempty"`
+	Records        uint64   `protobuf:"varint,18,opt,name=records,proto3" json:"records,omitempty"`
+	Oracles        uint64   `protobuf:"varint,19,opt,name=oracles,proto3" json:"oracles,omitempty"`
+	Backend        string   `protobuf:"bytes,20,opt,name=backend,proto3" json:"backend,omitempty"`
+	BackendSpace   uint64   `protobuf:"varint,21,opt,name=backend_space,json=backendSpace,proto3" json:"backend_space,omitempty"`
+	BackendUsed    uint64   `protobuf:"varint,22,opt,name=backend_used,json=backendUsed,proto3" json:"backend_used,omitempty"`
+	NextRecordId   uint64   `protobuf:"varint,23,opt,name=next_record_id,json=nextRecordId,proto3" json:"next_record_id,omitempty"`
+	CacheBudget    uint64   `protobuf:"varint,24,opt,name=cache_budget,json=cacheBudget,proto3" json:"cache_budget,omitempty"`
+	CacheUsed      uint64   `protobuf:"varint,25,opt,name=cache_used,json=cacheUsed,proto3" json:"cache_used,omitempty"`
+	CacheHits      uint64   `protobuf:"varint,26,opt,name=cache_hits,json=cacheHits,proto3" json:"cache_hits,omitempty"`
+	CacheMisses    uint64   `protobuf:"varint,27,opt,name=cache_misses,json=cacheMisses,proto3" json:"cache_misses,omitempty"`
+	CacheEvictions uint64   `protobuf:"varint,28,opt,name=cache_evictions,json=cacheEvictions,proto3" json:"cache_evictions,omitempty"`
+	// milliseconds it took to load records and oracles at startup
+	LoadTime           uint64 `protobuf:"varint,29,opt,name=load_time,json=loadTime,proto3" json:"load_time,omitempty"`
+	RecordsQuarantined uint64 `protobuf:"varint,30,opt,name=records_quarantined,json=recordsQuarantined,proto3" json:"records_quarantined,omitempty"`
+	OraclesQuarantined uint64 `protobuf:"varint,31,opt,name=oracles_quarantined,json=oraclesQuarantined,proto3" json:"oracles_quarantined,omitempty"`
+	// named collections, records and next_record_id are
+	// the ones of the default collection
+	Collections []*Collection `protobuf:"bytes,32,rep,name=collections,proto3" json:"collections,omitempty"`
+	// schema of the default collection
+	Schema *Schema `protobuf:"bytes,33,opt,name=schema,proto3" json:"schema,omitempty"`
+	// deleted records of the default collection still in the trash
+	RecordsTrashed uint64 `protobuf:"varint,34,opt,name=records_trashed,json=recordsTrashed,proto3" json:"records_trashed,omitempty"`
+	// timings of the backends measured at startup to select the fastest
+	// one, empty if the backend was not selected this way
+	BackendBenchmarks    []*BackendBenchmark `protobuf:"bytes,35,rep,name=backend_benchmarks,json=backendBenchmarks,proto3" json:"backend_benchmarks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *ServerInfo) Reset()         { *m = ServerInfo{} }
+func (m *ServerInfo) String() string { return proto.CompactTextString(m) }
+func (*ServerInfo) ProtoMessage()    {}
+func (*ServerInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{25}
+}
+
+func (m *ServerInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerInfo.Unmarshal(m, b)
+}
+func (m *ServerInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerInfo.Marshal(b, m, deterministic)
+}
+func (m *ServerInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerInfo.Merge(m, src)
+}
+func (m *ServerInfo) XXX_Size() int {
+	return xxx_messageInfo_ServerInfo.Size(m)
+}
+func (m *ServerInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerInfo proto.InternalMessageInfo
+
+func (m *ServerInfo) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *ServerInfo) GetOs() string {
+	if m != nil {
+		return m.Os
+	}
+	return ""
+}
+
+func (m *ServerInfo) GetArch() string {
+	if m != nil {
+		return m.Arch
+	}
+	return ""
+}
+
+func (m *ServerInfo) GetGoVersion() string {
+	if m != nil {
+		return m.GoVersion
+	}
+	return ""
+}
+
+func (m *ServerInfo) GetCpus() uint64 {
+	if m != nil {
+		return m.Cpus
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetMaxCpus() uint64 {
+	if m != nil {
+		return m.MaxCpus
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetGoroutines() uint64 {
+	if m != nil {
+		return m.Goroutines
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetAlloc() uint64 {
+	if m != nil {
+		return m.Alloc
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetSys() uint64 {
+	if m != nil {
+		return m.Sys
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetNumGc() uint64 {
+	if m != nil {
+		return m.NumGc
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetDatapath() string {
+	if m != nil {
+		return m.Datapath
+	}
+	return ""
+}
+
+func (m *ServerInfo) GetCredspath() string {
+	if m != nil {
+		return m.Credspath
+	}
+	return ""
+}
+
+func (m *ServerInfo) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *ServerInfo) GetUptime() uint64 {
+	if m != nil {
+		return m.Uptime
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetPid() uint64 {
+	if m != nil {
+		return m.Pid
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetUid() uint64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetArgv() []string {
+	if m != nil {
+		return m.Argv
+	}
+	return nil
+}
+
+func (m *ServerInfo) GetRecords() uint64 {
+	if m != nil {
+		return m.Records
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetOracles() uint64 {
+	if m != nil {
+		return m.Oracles
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetBackend() string {
+	if m != nil {
+		return m.Backend
+	}
+	return ""
+}
+
+func (m *ServerInfo) GetBackendSpace() uint64 {
+	if m != nil {
+		return m.BackendSpace
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetBackendUsed() uint64 {
+	if m != nil {
+		return m.BackendUsed
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetNextRecordId() uint64 {
+	if m != nil {
+		return m.NextRecordId
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetCacheBudget() uint64 {
+	if m != nil {
+		return m.CacheBudget
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetCacheUsed() uint64 {
+	if m != nil {
+		return m.CacheUsed
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetCacheHits() uint64 {
+	if m != nil {
+		return m.CacheHits
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetCacheMisses() uint64 {
+	if m != nil {
+		return m.CacheMisses
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetCacheEvictions() uint64 {
+	if m != nil {
+		return m.CacheEvictions
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetLoadTime() uint64 {
+	if m != nil {
+		return m.LoadTime
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetRecordsQuarantined() uint64 {
+	if m != nil {
+		return m.RecordsQuarantined
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetOraclesQuarantined() uint64 {
+	if m != nil {
+		return m.OraclesQuarantined
+	}
+	return 0
+}
+
+func (m *ServerInfo) GetCollections() []*Collection {
+	if m != nil {
+		return m.Collections
+	}
+	return nil
+}
+
+func (m *ServerInfo) GetSchema() *Schema {
 	if m != nil {
-		return m.Records
+		return m.Schema
 	}
 	return nil
 }
 
-type OracleListResponse struct {
-	Total                uint64    `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
-	Pages                uint64    `protobuf:"varint,2,opt,name=pages,proto3" json:"pages,omitempty"`
-	Oracles              []*Oracle `protobuf:"bytes,3,rep,name=oracles,proto3" json:"oracles,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+func (m *ServerInfo) GetRecordsTrashed() uint64 {
+	if m != nil {
+		return m.RecordsTrashed
+	}
+	return 0
 }
 
-func (m *OracleListResponse) Reset()         { *m = OracleListResponse{} }
-func (m *OracleListResponse) String() string { return proto.CompactTextString(m) }
-func (*OracleListResponse) ProtoMessage()    {}
-func (*OracleListResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{8}
+func (m *ServerInfo) GetBackendBenchmarks() []*BackendBenchmark {
+	if m != nil {
+		return m.BackendBenchmarks
+	}
+	return nil
 }
 
-func (m *OracleListResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_OracleListResponse.Unmarshal(m, b)
+// BackendBenchmark is the time a backend takes for a dot product.
+type BackendBenchmark struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// number of elements of the vectors
+	Size                 uint64   `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	NsPerOp              float64  `protobuf:"fixed64,3,opt,name=ns_per_op,json=nsPerOp,proto3" json:"ns_per_op,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
-func (m *OracleListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_OracleListResponse.Marshal(b, m, deterministic)
+
+func (m *BackendBenchmark) Reset()         { *m = BackendBenchmark{} }
+func (m *BackendBenchmark) String() string { return proto.CompactTextString(m) }
+func (*BackendBenchmark) ProtoMessage()    {}
+func (*BackendBenchmark) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{26}
 }
-func (m *OracleListResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_OracleListResponse.Merge(m, src)
+
+func (m *BackendBenchmark) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackendBenchmark.Unmarshal(m, b)
 }
-func (m *OracleListResponse) XXX_Size() int {
-	return xxx_messageInfo_OracleListResponse.Size(m)
+func (m *BackendBenchmark) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackendBenchmark.Marshal(b, m, deterministic)
 }
-func (m *OracleListResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_OracleListResponse.DiscardUnknown(m)
+func (m *BackendBenchmark) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackendBenchmark.Merge(m, src)
+}
+func (m *BackendBenchmark) XXX_Size() int {
+	return xxx_messageInfo_BackendBenchmark.Size(m)
+}
+func (m *BackendBenchmark) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackendBenchmark.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_OracleListResponse proto.InternalMessageInfo
+var xxx_messageInfo_BackendBenchmark proto.InternalMessageInfo
 
-func (m *OracleListResponse) GetTotal() uint64 {
+func (m *BackendBenchmark) GetName() string {
 	if m != nil {
-		return m.Total
+		return m.Name
+	}
+	return ""
+}
+
+func (m *BackendBenchmark) GetSize() uint64 {
+	if m != nil {
+		return m.Size
 	}
 	return 0
 }
 
-func (m *OracleListResponse) GetPages() uint64 {
+func (m *BackendBenchmark) GetNsPerOp() float64 {
 	if m != nil {
-		return m.Pages
+		return m.NsPerOp
 	}
 	return 0
 }
 
-func (m *OracleListResponse) GetOracles() []*Oracle {
+type Collection struct {
+	Name         string  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Records      uint64  `protobuf:"varint,2,opt,name=records,proto3" json:"records,omitempty"`
+	NextRecordId uint64  `protobuf:"varint,3,opt,name=next_record_id,json=nextRecordId,proto3" json:"next_record_id,omitempty"`
+	Schema       *Schema `protobuf:"bytes,4,opt,name=schema,proto3" json:"schema,omitempty"`
+	// deleted records still in the trash
+	Trashed              uint64   `protobuf:"varint,5,opt,name=trashed,proto3" json:"trashed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Collection) Reset()         { *m = Collection{} }
+func (m *Collection) String() string { return proto.CompactTextString(m) }
+func (*Collection) ProtoMessage()    {}
+func (*Collection) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{27}
+}
+
+func (m *Collection) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Collection.Unmarshal(m, b)
+}
+func (m *Collection) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Collection.Marshal(b, m, deterministic)
+}
+func (m *Collection) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Collection.Merge(m, src)
+}
+func (m *Collection) XXX_Size() int {
+	return xxx_messageInfo_Collection.Size(m)
+}
+func (m *Collection) XXX_DiscardUnknown() {
+	xxx_messageInfo_Collection.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Collection proto.InternalMessageInfo
+
+func (m *Collection) GetName() string {
 	if m != nil {
-		return m.Oracles
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Collection) GetRecords() uint64 {
+	if m != nil {
+		return m.Records
+	}
+	return 0
+}
+
+func (m *Collection) GetNextRecordId() uint64 {
+	if m != nil {
+		return m.NextRecordId
+	}
+	return 0
+}
+
+func (m *Collection) GetSchema() *Schema {
+	if m != nil {
+		return m.Schema
 	}
 	return nil
 }
 
-type FindResponse struct {
-	Success              bool      `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Msg                  string    `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
-	Records              []*Record `protobuf:"bytes,3,rep,name=records,proto3" json:"records,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+func (m *Collection) GetTrashed() uint64 {
+	if m != nil {
+		return m.Trashed
+	}
+	return 0
 }
 
-func (m *FindResponse) Reset()         { *m = FindResponse{} }
-func (m *FindResponse) String() string { return proto.CompactTextString(m) }
-func (*FindResponse) ProtoMessage()    {}
-func (*FindResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{9}
+// constraints the records of a collection must satisfy,
+// zero values are not enforced
+type Schema struct {
+	// number of elements of the vectors
+	Dimension uint64 `protobuf:"varint,1,opt,name=dimension,proto3" json:"dimension,omitempty"`
+	// shape of the vectors
+	Shape []uint64 `protobuf:"varint,2,rep,packed,name=shape,proto3" json:"shape,omitempty"`
+	// meta keys every record must have, either as meta or typed meta
+	RequiredMeta []string `protobuf:"bytes,3,rep,name=required_meta,json=requiredMeta,proto3" json:"required_meta,omitempty"`
+	// types the meta values must have, text meta is of TEXT type
+	MetaTypes map[string]Schema_Type `protobuf:"bytes,4,rep,name=meta_types,json=metaTypes,proto3" json:"meta_types,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3,enum=sum.Schema_Type"`
+	// how the vectors of new records are stored, FLOAT32 uses the
+	// default of the node
+	Quantization         QuantizedVector_Type `protobuf:"varint,5,opt,name=quantization,proto3,enum=sum.QuantizedVector_Type" json:"quantization,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
-func (m *FindResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_FindResponse.Unmarshal(m, b)
+func (m *Schema) Reset()         { *m = Schema{} }
+func (m *Schema) String() string { return proto.CompactTextString(m) }
+func (*Schema) ProtoMessage()    {}
+func (*Schema) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{28}
 }
-func (m *FindResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_FindResponse.Marshal(b, m, deterministic)
+
+func (m *Schema) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Schema.Unmarshal(m, b)
 }
-func (m *FindResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_FindResponse.Merge(m, src)
+func (m *Schema) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Schema.Marshal(b, m, deterministic)
 }
-func (m *FindResponse) XXX_Size() int {
-	return xxx_messageInfo_FindResponse.Size(m)
+func (m *Schema) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Schema.Merge(m, src)
 }
-func (m *FindResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_FindResponse.DiscardUnknown(m)
+func (m *Schema) XXX_Size() int {
+	return xxx_messageInfo_Schema.Size(m)
+}
+func (m *Schema) XXX_DiscardUnknown() {
+	xxx_messageInfo_Schema.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_FindResponse proto.InternalMessageInfo
+var xxx_messageInfo_Schema proto.InternalMessageInfo
 
-func (m *FindResponse) GetSuccess() bool {
+func (m *Schema) GetDimension() uint64 {
 	if m != nil {
-		return m.Success
+		return m.Dimension
 	}
-	return false
+	return 0
 }
 
-func (m *FindResponse) GetMsg() string {
+func (m *Schema) GetShape() []uint64 {
 	if m != nil {
-		return m.Msg
+		return m.Shape
 	}
-	return ""
+	return nil
 }
 
-func (m *FindResponse) GetRecords() []*Record {
+func (m *Schema) GetRequiredMeta() []string {
 	if m != nil {
-		return m.Records
+		return m.RequiredMeta
 	}
 	return nil
 }
 
-type Oracle struct {
-	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Code                 string   `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"`
+func (m *Schema) GetMetaTypes() map[string]Schema_Type {
+	if m != nil {
+		return m.MetaTypes
+	}
+	return nil
+}
+
+func (m *Schema) GetQuantization() QuantizedVector_Type {
+	if m != nil {
+		return m.Quantization
+	}
+	return QuantizedVector_FLOAT32
+}
+
+type PurgeRequest struct {
+	Collection string `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	// records to remove from the trash, all of them if empty
+	Ids []uint64 `protobuf:"varint,2,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	// if not 0, only remove the records deleted before this unix time in nanoseconds
+	Before               int64    `protobuf:"varint,3,opt,name=before,proto3" json:"before,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Oracle) Reset()         { *m = Oracle{} }
-func (m *Oracle) String() string { return proto.CompactTextString(m) }
-func (*Oracle) ProtoMessage()    {}
-func (*Oracle) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{10}
+func (m *PurgeRequest) Reset()         { *m = PurgeRequest{} }
+func (m *PurgeRequest) String() string { return proto.CompactTextString(m) }
+func (*PurgeRequest) ProtoMessage()    {}
+func (*PurgeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{29}
 }
 
-func (m *Oracle) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Oracle.Unmarshal(m, b)
+func (m *PurgeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PurgeRequest.Unmarshal(m, b)
 }
-func (m *Oracle) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Oracle.Marshal(b, m, deterministic)
+func (m *PurgeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PurgeRequest.Marshal(b, m, deterministic)
 }
-func (m *Oracle) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Oracle.Merge(m, src)
+func (m *PurgeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PurgeRequest.Merge(m, src)
 }
-func (m *Oracle) XXX_Size() int {
-	return xxx_messageInfo_Oracle.Size(m)
+func (m *PurgeRequest) XXX_Size() int {
+	return xxx_messageInfo_PurgeRequest.Size(m)
 }
-func (m *Oracle) XXX_DiscardUnknown() {
-	xxx_messageInfo_Oracle.DiscardUnknown(m)
+func (m *PurgeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PurgeRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Oracle proto.InternalMessageInfo
+var xxx_messageInfo_PurgeRequest proto.InternalMessageInfo
 
-func (m *Oracle) GetId() uint64 {
+func (m *PurgeRequest) GetCollection() string {
 	if m != nil {
-		return m.Id
+		return m.Collection
+	}
+	return ""
+}
+
+func (m *PurgeRequest) GetIds() []uint64 {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+func (m *PurgeRequest) GetBefore() int64 {
+	if m != nil {
+		return m.Before
 	}
 	return 0
 }
 
-func (m *Oracle) GetName() string {
+type WatchRequest struct {
+	// sequence number of the last event received to resume
+	// the feed from, 0 to only receive new events
+	Since uint64 `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"`
+	// only send the events of these collections, all if empty
+	Collections          []string `protobuf:"bytes,2,rep,name=collections,proto3" json:"collections,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{30}
+}
+
+func (m *WatchRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRequest.Unmarshal(m, b)
+}
+func (m *WatchRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRequest.Merge(m, src)
+}
+func (m *WatchRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRequest.Size(m)
+}
+func (m *WatchRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchRequest proto.InternalMessageInfo
+
+func (m *WatchRequest) GetSince() uint64 {
 	if m != nil {
-		return m.Name
+		return m.Since
 	}
-	return ""
+	return 0
 }
 
-func (m *Oracle) GetCode() string {
+func (m *WatchRequest) GetCollections() []string {
 	if m != nil {
-		return m.Code
+		return m.Collections
 	}
-	return ""
+	return nil
 }
 
-type OracleResponse struct {
-	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Msg                  string   `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
-	Oracle               *Oracle  `protobuf:"bytes,3,opt,name=oracle,proto3" json:"oracle,omitempty"`
+// a change of the records of a collection
+type Event struct {
+	Seq        uint64     `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Type       Event_Type `protobuf:"varint,2,opt,name=type,proto3,enum=sum.Event_Type" json:"type,omitempty"`
+	Collection string     `protobuf:"bytes,3,opt,name=collection,proto3" json:"collection,omitempty"`
+	RecordId   uint64     `protobuf:"varint,4,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	// the record after the change, or the removed one
+	Record *Record `protobuf:"bytes,5,opt,name=record,proto3" json:"record,omitempty"`
+	// unix nano timestamp of the change
+	Timestamp int64 `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// true if the change is due to records being moved between nodes
+	Transfer bool `protobuf:"varint,7,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	// identifier of the node, set by the master
+	Node                 uint64   `protobuf:"varint,8,opt,name=node,proto3" json:"node,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *OracleResponse) Reset()         { *m = OracleResponse{} }
-func (m *OracleResponse) String() string { return proto.CompactTextString(m) }
-func (*OracleResponse) ProtoMessage()    {}
-func (*OracleResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{11}
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{31}
 }
 
-func (m *OracleResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_OracleResponse.Unmarshal(m, b)
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
 }
-func (m *OracleResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_OracleResponse.Marshal(b, m, deterministic)
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
 }
-func (m *OracleResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_OracleResponse.Merge(m, src)
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
 }
-func (m *OracleResponse) XXX_Size() int {
-	return xxx_messageInfo_OracleResponse.Size(m)
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
 }
-func (m *OracleResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_OracleResponse.DiscardUnknown(m)
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_OracleResponse proto.InternalMessageInfo
+var xxx_messageInfo_Event proto.InternalMessageInfo
 
-func (m *OracleResponse) GetSuccess() bool {
+func (m *Event) GetSeq() uint64 {
 	if m != nil {
-		return m.Success
+		return m.Seq
 	}
-	return false
+	return 0
 }
 
-func (m *OracleResponse) GetMsg() string {
+func (m *Event) GetType() Event_Type {
 	if m != nil {
-		return m.Msg
+		return m.Type
+	}
+	return Event_CREATE
+}
+
+func (m *Event) GetCollection() string {
+	if m != nil {
+		return m.Collection
 	}
 	return ""
 }
 
-func (m *OracleResponse) GetOracle() *Oracle {
+func (m *Event) GetRecordId() uint64 {
 	if m != nil {
-		return m.Oracle
+		return m.RecordId
+	}
+	return 0
+}
+
+func (m *Event) GetRecord() *Record {
+	if m != nil {
+		return m.Record
 	}
 	return nil
 }
 
-type Call struct {
-	OracleId             uint64   `protobuf:"varint,1,opt,name=oracle_id,json=oracleId,proto3" json:"oracle_id,omitempty"`
-	Args                 []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+func (m *Event) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Event) GetTransfer() bool {
+	if m != nil {
+		return m.Transfer
+	}
+	return false
+}
+
+func (m *Event) GetNode() uint64 {
+	if m != nil {
+		return m.Node
+	}
+	return 0
+}
+
+type ArrayHeader struct {
+	Format     ArrayHeader_Format `protobuf:"varint,1,opt,name=format,proto3,enum=sum.ArrayHeader_Format" json:"format,omitempty"`
+	Collection string             `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	// name of the array in a .npz archive or of the tensor in a safetensors
+	// file, when importing the only one in the file is used if empty
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// when exporting, also stream the meta data of the records
+	Meta                 bool     `protobuf:"varint,4,opt,name=meta,proto3" json:"meta,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Call) Reset()         { *m = Call{} }
-func (m *Call) String() string { return proto.CompactTextString(m) }
-func (*Call) ProtoMessage()    {}
-func (*Call) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{12}
+func (m *ArrayHeader) Reset()         { *m = ArrayHeader{} }
+func (m *ArrayHeader) String() string { return proto.CompactTextString(m) }
+func (*ArrayHeader) ProtoMessage()    {}
+func (*ArrayHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{32}
 }
 
-func (m *Call) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Call.Unmarshal(m, b)
+func (m *ArrayHeader) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ArrayHeader.Unmarshal(m, b)
 }
-func (m *Call) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Call.Marshal(b, m, deterministic)
+func (m *ArrayHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ArrayHeader.Marshal(b, m, deterministic)
 }
-func (m *Call) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Call.Merge(m, src)
+func (m *ArrayHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ArrayHeader.Merge(m, src)
 }
-func (m *Call) XXX_Size() int {
-	return xxx_messageInfo_Call.Size(m)
+func (m *ArrayHeader) XXX_Size() int {
+	return xxx_messageInfo_ArrayHeader.Size(m)
 }
-func (m *Call) XXX_DiscardUnknown() {
-	xxx_messageInfo_Call.DiscardUnknown(m)
+func (m *ArrayHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_ArrayHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ArrayHeader proto.InternalMessageInfo
+
+func (m *ArrayHeader) GetFormat() ArrayHeader_Format {
+	if m != nil {
+		return m.Format
+	}
+	return ArrayHeader_NPY
 }
 
-var xxx_messageInfo_Call proto.InternalMessageInfo
+func (m *ArrayHeader) GetCollection() string {
+	if m != nil {
+		return m.Collection
+	}
+	return ""
+}
 
-func (m *Call) GetOracleId() uint64 {
+func (m *ArrayHeader) GetName() string {
 	if m != nil {
-		return m.OracleId
+		return m.Name
 	}
-	return 0
+	return ""
 }
 
-func (m *Call) GetArgs() []string {
+func (m *ArrayHeader) GetMeta() bool {
 	if m != nil {
-		return m.Args
+		return m.Meta
 	}
-	return nil
+	return false
 }
 
-type Data struct {
-	Compressed           bool     `protobuf:"varint,1,opt,name=compressed,proto3" json:"compressed,omitempty"`
-	Payload              []byte   `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+// every row of the array is a record, rows are sorted by record identifier
+type ArrayChunk struct {
+	// only set in the first chunk of an import
+	Header *ArrayHeader `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	Data   []byte       `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// JSON lines with the meta data of the rows, one object per row
+	Meta                 []byte   `protobuf:"bytes,3,opt,name=meta,proto3" json:"meta,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Data) Reset()         { *m = Data{} }
-func (m *Data) String() string { return proto.CompactTextString(m) }
-func (*Data) ProtoMessage()    {}
-func (*Data) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{13}
+func (m *ArrayChunk) Reset()         { *m = ArrayChunk{} }
+func (m *ArrayChunk) String() string { return proto.CompactTextString(m) }
+func (*ArrayChunk) ProtoMessage()    {}
+func (*ArrayChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{33}
 }
 
-func (m *Data) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Data.Unmarshal(m, b)
+func (m *ArrayChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ArrayChunk.Unmarshal(m, b)
 }
-func (m *Data) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Data.Marshal(b, m, deterministic)
+func (m *ArrayChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ArrayChunk.Marshal(b, m, deterministic)
 }
-func (m *Data) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Data.Merge(m, src)
+func (m *ArrayChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ArrayChunk.Merge(m, src)
 }
-func (m *Data) XXX_Size() int {
-	return xxx_messageInfo_Data.Size(m)
+func (m *ArrayChunk) XXX_Size() int {
+	return xxx_messageInfo_ArrayChunk.Size(m)
 }
-func (m *Data) XXX_DiscardUnknown() {
-	xxx_messageInfo_Data.DiscardUnknown(m)
+func (m *ArrayChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_ArrayChunk.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Data proto.InternalMessageInfo
+var xxx_messageInfo_ArrayChunk proto.InternalMessageInfo
 
-func (m *Data) GetCompressed() bool {
+func (m *ArrayChunk) GetHeader() *ArrayHeader {
 	if m != nil {
-		return m.Compressed
+		return m.Header
 	}
-	return false
+	return nil
 }
 
-func (m *Data) GetPayload() []byte {
+func (m *ArrayChunk) GetData() []byte {
 	if m != nil {
-		return m.Payload
+		return m.Data
 	}
 	return nil
 }
 
-type CallResponse struct {
-	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Msg                  string   `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
-	Data                 *Data    `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+func (m *ArrayChunk) GetMeta() []byte {
+	if m != nil {
+		return m.Meta
+	}
+	return nil
+}
+
+type ImportResponse struct {
+	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Msg     string `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	// the records created have consecutive identifiers starting from first_id
+	Records              uint64   `protobuf:"varint,3,opt,name=records,proto3" json:"records,omitempty"`
+	FirstId              uint64   `protobuf:"varint,4,opt,name=first_id,json=firstId,proto3" json:"first_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *CallResponse) Reset()         { *m = CallResponse{} }
-func (m *CallResponse) String() string { return proto.CompactTextString(m) }
-func (*CallResponse) ProtoMessage()    {}
-func (*CallResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{14}
+func (m *ImportResponse) Reset()         { *m = ImportResponse{} }
+func (m *ImportResponse) String() string { return proto.CompactTextString(m) }
+func (*ImportResponse) ProtoMessage()    {}
+func (*ImportResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{34}
 }
 
-func (m *CallResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_CallResponse.Unmarshal(m, b)
+func (m *ImportResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportResponse.Unmarshal(m, b)
 }
-func (m *CallResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_CallResponse.Marshal(b, m, deterministic)
+func (m *ImportResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportResponse.Marshal(b, m, deterministic)
 }
-func (m *CallResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_CallResponse.Merge(m, src)
+func (m *ImportResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportResponse.Merge(m, src)
 }
-func (m *CallResponse) XXX_Size() int {
-	return xxx_messageInfo_CallResponse.Size(m)
+func (m *ImportResponse) XXX_Size() int {
+	return xxx_messageInfo_ImportResponse.Size(m)
 }
-func (m *CallResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_CallResponse.DiscardUnknown(m)
+func (m *ImportResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_CallResponse proto.InternalMessageInfo
+var xxx_messageInfo_ImportResponse proto.InternalMessageInfo
 
-func (m *CallResponse) GetSuccess() bool {
+func (m *ImportResponse) GetSuccess() bool {
 	if m != nil {
 		return m.Success
 	}
 	return false
 }
 
-func (m *CallResponse) GetMsg() string {
+func (m *ImportResponse) GetMsg() string {
 	if m != nil {
 		return m.Msg
 	}
 	return ""
 }
 
-func (m *CallResponse) GetData() *Data {
+func (m *ImportResponse) GetRecords() uint64 {
 	if m != nil {
-		return m.Data
+		return m.Records
 	}
-	return nil
+	return 0
 }
 
-type ById struct {
-	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+func (m *ImportResponse) GetFirstId() uint64 {
+	if m != nil {
+		return m.FirstId
+	}
+	return 0
+}
+
+type CheckRequest struct {
+	Collection string `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	// rebuild the indexes from the records after checking them
+	Rebuild              bool     `protobuf:"varint,2,opt,name=rebuild,proto3" json:"rebuild,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ById) Reset()         { *m = ById{} }
-func (m *ById) String() string { return proto.CompactTextString(m) }
-func (*ById) ProtoMessage()    {}
-func (*ById) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{15}
+func (m *CheckRequest) Reset()         { *m = CheckRequest{} }
+func (m *CheckRequest) String() string { return proto.CompactTextString(m) }
+func (*CheckRequest) ProtoMessage()    {}
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{35}
 }
 
-func (m *ById) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ById.Unmarshal(m, b)
+func (m *CheckRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckRequest.Unmarshal(m, b)
 }
-func (m *ById) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ById.Marshal(b, m, deterministic)
+func (m *CheckRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckRequest.Marshal(b, m, deterministic)
 }
-func (m *ById) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ById.Merge(m, src)
+func (m *CheckRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckRequest.Merge(m, src)
 }
-func (m *ById) XXX_Size() int {
-	return xxx_messageInfo_ById.Size(m)
+func (m *CheckRequest) XXX_Size() int {
+	return xxx_messageInfo_CheckRequest.Size(m)
 }
-func (m *ById) XXX_DiscardUnknown() {
-	xxx_messageInfo_ById.DiscardUnknown(m)
+func (m *CheckRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ById proto.InternalMessageInfo
+var xxx_messageInfo_CheckRequest proto.InternalMessageInfo
 
-func (m *ById) GetId() uint64 {
+func (m *CheckRequest) GetCollection() string {
 	if m != nil {
-		return m.Id
+		return m.Collection
 	}
-	return 0
+	return ""
 }
 
-type ByName struct {
-	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+func (m *CheckRequest) GetRebuild() bool {
+	if m != nil {
+		return m.Rebuild
+	}
+	return false
+}
+
+type IndexEntry struct {
+	// meta, sorted or keys
+	Index                string   `protobuf:"bytes,1,opt,name=index,proto3" json:"index,omitempty"`
+	Meta                 string   `protobuf:"bytes,2,opt,name=meta,proto3" json:"meta,omitempty"`
+	Value                string   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Id                   uint64   `protobuf:"varint,4,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ByName) Reset()         { *m = ByName{} }
-func (m *ByName) String() string { return proto.CompactTextString(m) }
-func (*ByName) ProtoMessage()    {}
-func (*ByName) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{16}
+func (m *IndexEntry) Reset()         { *m = IndexEntry{} }
+func (m *IndexEntry) String() string { return proto.CompactTextString(m) }
+func (*IndexEntry) ProtoMessage()    {}
+func (*IndexEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{36}
 }
 
-func (m *ByName) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ByName.Unmarshal(m, b)
+func (m *IndexEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_IndexEntry.Unmarshal(m, b)
 }
-func (m *ByName) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ByName.Marshal(b, m, deterministic)
+func (m *IndexEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_IndexEntry.Marshal(b, m, deterministic)
 }
-func (m *ByName) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ByName.Merge(m, src)
+func (m *IndexEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IndexEntry.Merge(m, src)
 }
-func (m *ByName) XXX_Size() int {
-	return xxx_messageInfo_ByName.Size(m)
+func (m *IndexEntry) XXX_Size() int {
+	return xxx_messageInfo_IndexEntry.Size(m)
 }
-func (m *ByName) XXX_DiscardUnknown() {
-	xxx_messageInfo_ByName.DiscardUnknown(m)
+func (m *IndexEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_IndexEntry.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ByName proto.InternalMessageInfo
+var xxx_messageInfo_IndexEntry proto.InternalMessageInfo
 
-func (m *ByName) GetName() string {
+func (m *IndexEntry) GetIndex() string {
 	if m != nil {
-		return m.Name
+		return m.Index
 	}
 	return ""
 }
 
-type ByAddr struct {
-	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
-	CertFile             string   `protobuf:"bytes,2,opt,name=cert_file,json=certFile,proto3" json:"cert_file,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *IndexEntry) GetMeta() string {
+	if m != nil {
+		return m.Meta
+	}
+	return ""
 }
 
-func (m *ByAddr) Reset()         { *m = ByAddr{} }
-func (m *ByAddr) String() string { return proto.CompactTextString(m) }
-func (*ByAddr) ProtoMessage()    {}
-func (*ByAddr) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{17}
+func (m *IndexEntry) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
 }
 
-func (m *ByAddr) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ByAddr.Unmarshal(m, b)
+func (m *IndexEntry) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
 }
-func (m *ByAddr) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ByAddr.Marshal(b, m, deterministic)
+
+type CheckResponse struct {
+	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Msg     string `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	Records uint64 `protobuf:"varint,3,opt,name=records,proto3" json:"records,omitempty"`
+	Entries uint64 `protobuf:"varint,4,opt,name=entries,proto3" json:"entries,omitempty"`
+	// entries of the indexes pointing to a record that does not have that value
+	Stale []*IndexEntry `protobuf:"bytes,5,rep,name=stale,proto3" json:"stale,omitempty"`
+	// values of the records that are not indexed
+	Missing []*IndexEntry `protobuf:"bytes,6,rep,name=missing,proto3" json:"missing,omitempty"`
+	// entries of the indexes found more than once
+	Duplicated           []*IndexEntry `protobuf:"bytes,7,rep,name=duplicated,proto3" json:"duplicated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
-func (m *ByAddr) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ByAddr.Merge(m, src)
+
+func (m *CheckResponse) Reset()         { *m = CheckResponse{} }
+func (m *CheckResponse) String() string { return proto.CompactTextString(m) }
+func (*CheckResponse) ProtoMessage()    {}
+func (*CheckResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{37}
 }
-func (m *ByAddr) XXX_Size() int {
-	return xxx_messageInfo_ByAddr.Size(m)
+
+func (m *CheckResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckResponse.Unmarshal(m, b)
 }
-func (m *ByAddr) XXX_DiscardUnknown() {
-	xxx_messageInfo_ByAddr.DiscardUnknown(m)
+func (m *CheckResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckResponse.Marshal(b, m, deterministic)
+}
+func (m *CheckResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckResponse.Merge(m, src)
+}
+func (m *CheckResponse) XXX_Size() int {
+	return xxx_messageInfo_CheckResponse.Size(m)
+}
+func (m *CheckResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ByAddr proto.InternalMessageInfo
+var xxx_messageInfo_CheckResponse proto.InternalMessageInfo
 
-func (m *ByAddr) GetAddress() string {
+func (m *CheckResponse) GetSuccess() bool {
 	if m != nil {
-		return m.Address
+		return m.Success
 	}
-	return ""
+	return false
 }
 
-func (m *ByAddr) GetCertFile() string {
+func (m *CheckResponse) GetMsg() string {
 	if m != nil {
-		return m.CertFile
+		return m.Msg
 	}
 	return ""
 }
 
-type ByMeta struct {
-	Meta                 string   `protobuf:"bytes,1,opt,name=meta,proto3" json:"meta,omitempty"`
-	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *CheckResponse) GetRecords() uint64 {
+	if m != nil {
+		return m.Records
+	}
+	return 0
 }
 
-func (m *ByMeta) Reset()         { *m = ByMeta{} }
-func (m *ByMeta) String() string { return proto.CompactTextString(m) }
-func (*ByMeta) ProtoMessage()    {}
-func (*ByMeta) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{18}
+func (m *CheckResponse) GetEntries() uint64 {
+	if m != nil {
+		return m.Entries
+	}
+	return 0
 }
 
-func (m *ByMeta) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ByMeta.Unmarshal(m, b)
-}
-func (m *ByMeta) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ByMeta.Marshal(b, m, deterministic)
-}
-func (m *ByMeta) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ByMeta.Merge(m, src)
-}
-func (m *ByMeta) XXX_Size() int {
-	return xxx_messageInfo_ByMeta.Size(m)
-}
-func (m *ByMeta) XXX_DiscardUnknown() {
-	xxx_messageInfo_ByMeta.DiscardUnknown(m)
+func (m *CheckResponse) GetStale() []*IndexEntry {
+	if m != nil {
+		return m.Stale
+	}
+	return nil
 }
 
-var xxx_messageInfo_ByMeta proto.InternalMessageInfo
-
-func (m *ByMeta) GetMeta() string {
+func (m *CheckResponse) GetMissing() []*IndexEntry {
 	if m != nil {
-		return m.Meta
+		return m.Missing
 	}
-	return ""
+	return nil
 }
 
-func (m *ByMeta) GetValue() string {
+func (m *CheckResponse) GetDuplicated() []*IndexEntry {
 	if m != nil {
-		return m.Value
+		return m.Duplicated
 	}
-	return ""
+	return nil
 }
 
-type ServerInfo struct {
-	Version              string   `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
-	Os                   string   `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
-	Arch                 string   `protobuf:"bytes,3,opt,name=arch,proto3" json:"arch,omitempty"`
-	GoVersion            string   `protobuf:"bytes,4,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
-	Cpus                 uint64   `protobuf:"varint,5,opt,name=cpus,proto3" json:"cpus,omitempty"`
-	MaxCpus              uint64   `protobuf:"varint,6,opt,name=max_cpus,json=maxCpus,proto3" json:"max_cpus,omitempty"`
-	Goroutines           uint64   `protobuf:"varint,7,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
-	Alloc                uint64   `protobuf:"varint,8,opt,name=alloc,proto3" json:"alloc,omitempty"`
-	Sys                  uint64   `protobuf:"varint,9,opt,name=sys,proto3" json:"sys,omitempty"`
-	NumGc                uint64   `protobuf:"varint,10,opt,name=num_gc,json=numGc,proto3" json:"num_gc,omitempty"`
-	Datapath             string   `protobuf:"bytes,11,opt,name=datapath,proto3" json:"datapath,omitempty"`
-	Credspath            string   `protobuf:"bytes,12,opt,name=credspath,proto3" json:"credspath,omitempty"`
-	Address              string   `protobuf:"bytes,13,opt,name=address,proto3" json:"address,omitempty"`
-	Uptime               uint64   `protobuf:"varint,14,opt,name=uptime,proto3" json:"uptime,omitempty"`
-	Pid                  uint64   `protobuf:"varint,15,opt,name=pid,proto3" json:"pid,omitempty"`
-	Uid                  uint64   `protobuf:"varint,16,opt,name=uid,proto3" json:"uid,omitempty"`
-	Argv                 []string `protobuf:"bytes,17,rep,name=argv,proto3" json:"argv,omitempty"`
-	Records              uint64   `protobuf:"varint,18,opt,name=records,proto3" json:"records,omitempty"`
-	Oracles              uint64   `protobuf:"varint,19,opt,name=oracles,proto3" json:"oracles,omitempty"`
-	Backend              string   `protobuf:"bytes,20,opt,name=backend,proto3" json:"backend,omitempty"`
-	BackendSpace         uint64   `protobuf:"varint,21,opt,name=backend_space,json=backendSpace,proto3" json:"backend_space,omitempty"`
-	BackendUsed          uint64   `protobuf:"varint,22,opt,name=backend_used,json=backendUsed,proto3" json:"backend_used,omitempty"`
-	NextRecordId         uint64   `protobuf:"varint,23,opt,name=next_record_id,json=nextRecordId,proto3" json:"next_record_id,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type CollectionResponse struct {
+	Success              bool          `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Msg                  string        `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	Collections          []*Collection `protobuf:"bytes,3,rep,name=collections,proto3" json:"collections,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
 
-func (m *ServerInfo) Reset()         { *m = ServerInfo{} }
-func (m *ServerInfo) String() string { return proto.CompactTextString(m) }
-func (*ServerInfo) ProtoMessage()    {}
-func (*ServerInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{19}
+func (m *CollectionResponse) Reset()         { *m = CollectionResponse{} }
+func (m *CollectionResponse) String() string { return proto.CompactTextString(m) }
+func (*CollectionResponse) ProtoMessage()    {}
+func (*CollectionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{38}
 }
 
-func (m *ServerInfo) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ServerInfo.Unmarshal(m, b)
+func (m *CollectionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CollectionResponse.Unmarshal(m, b)
 }
-func (m *ServerInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ServerInfo.Marshal(b, m, deterministic)
+func (m *CollectionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CollectionResponse.Marshal(b, m, deterministic)
 }
-func (m *ServerInfo) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ServerInfo.Merge(m, src)
+func (m *CollectionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CollectionResponse.Merge(m, src)
 }
-func (m *ServerInfo) XXX_Size() int {
-	return xxx_messageInfo_ServerInfo.Size(m)
+func (m *CollectionResponse) XXX_Size() int {
+	return xxx_messageInfo_CollectionResponse.Size(m)
 }
-func (m *ServerInfo) XXX_DiscardUnknown() {
-	xxx_messageInfo_ServerInfo.DiscardUnknown(m)
+func (m *CollectionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CollectionResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ServerInfo proto.InternalMessageInfo
+var xxx_messageInfo_CollectionResponse proto.InternalMessageInfo
 
-func (m *ServerInfo) GetVersion() string {
+func (m *CollectionResponse) GetSuccess() bool {
 	if m != nil {
-		return m.Version
+		return m.Success
 	}
-	return ""
+	return false
 }
 
-func (m *ServerInfo) GetOs() string {
+func (m *CollectionResponse) GetMsg() string {
 	if m != nil {
-		return m.Os
+		return m.Msg
 	}
 	return ""
 }
 
-func (m *ServerInfo) GetArch() string {
+func (m *CollectionResponse) GetCollections() []*Collection {
 	if m != nil {
-		return m.Arch
+		return m.Collections
 	}
-	return ""
+	return nil
+}
+
+type SnapshotHeader struct {
+	Version      uint32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Created      int64  `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	NextRecordId uint64 `protobuf:"varint,3,opt,name=next_record_id,json=nextRecordId,proto3" json:"next_record_id,omitempty"`
+	NextOracleId uint64 `protobuf:"varint,4,opt,name=next_oracle_id,json=nextOracleId,proto3" json:"next_oracle_id,omitempty"`
+	Records      uint64 `protobuf:"varint,5,opt,name=records,proto3" json:"records,omitempty"`
+	Oracles      uint64 `protobuf:"varint,6,opt,name=oracles,proto3" json:"oracles,omitempty"`
+	// since version 2, the schema and the number of trashed records of
+	// the default collection, and the number of named collections
+	Schema               *Schema  `protobuf:"bytes,7,opt,name=schema,proto3" json:"schema,omitempty"`
+	Trashed              uint64   `protobuf:"varint,8,opt,name=trashed,proto3" json:"trashed,omitempty"`
+	Collections          uint64   `protobuf:"varint,9,opt,name=collections,proto3" json:"collections,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ServerInfo) GetGoVersion() string {
-	if m != nil {
-		return m.GoVersion
-	}
-	return ""
+func (m *SnapshotHeader) Reset()         { *m = SnapshotHeader{} }
+func (m *SnapshotHeader) String() string { return proto.CompactTextString(m) }
+func (*SnapshotHeader) ProtoMessage()    {}
+func (*SnapshotHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{39}
 }
 
-func (m *ServerInfo) GetCpus() uint64 {
-	if m != nil {
-		return m.Cpus
-	}
-	return 0
+func (m *SnapshotHeader) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnapshotHeader.Unmarshal(m, b)
+}
+func (m *SnapshotHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnapshotHeader.Marshal(b, m, deterministic)
+}
+func (m *SnapshotHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotHeader.Merge(m, src)
+}
+func (m *SnapshotHeader) XXX_Size() int {
+	return xxx_messageInfo_SnapshotHeader.Size(m)
+}
+func (m *SnapshotHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotHeader.DiscardUnknown(m)
 }
 
-func (m *ServerInfo) GetMaxCpus() uint64 {
+var xxx_messageInfo_SnapshotHeader proto.InternalMessageInfo
+
+func (m *SnapshotHeader) GetVersion() uint32 {
 	if m != nil {
-		return m.MaxCpus
+		return m.Version
 	}
 	return 0
 }
 
-func (m *ServerInfo) GetGoroutines() uint64 {
+func (m *SnapshotHeader) GetCreated() int64 {
 	if m != nil {
-		return m.Goroutines
+		return m.Created
 	}
 	return 0
 }
 
-func (m *ServerInfo) GetAlloc() uint64 {
+func (m *SnapshotHeader) GetNextRecordId() uint64 {
 	if m != nil {
-		return m.Alloc
+		return m.NextRecordId
 	}
 	return 0
 }
 
-func (m *ServerInfo) GetSys() uint64 {
+func (m *SnapshotHeader) GetNextOracleId() uint64 {
 	if m != nil {
-		return m.Sys
+		return m.NextOracleId
 	}
 	return 0
 }
 
-func (m *ServerInfo) GetNumGc() uint64 {
+func (m *SnapshotHeader) GetRecords() uint64 {
 	if m != nil {
-		return m.NumGc
+		return m.Records
 	}
 	return 0
 }
 
-func (m *ServerInfo) GetDatapath() string {
+func (m *SnapshotHeader) GetOracles() uint64 {
 	if m != nil {
-		return m.Datapath
+		return m.Oracles
 	}
-	return ""
+	return 0
 }
 
-func (m *ServerInfo) GetCredspath() string {
+func (m *SnapshotHeader) GetSchema() *Schema {
 	if m != nil {
-		return m.Credspath
+		return m.Schema
 	}
-	return ""
+	return nil
 }
 
-func (m *ServerInfo) GetAddress() string {
+func (m *SnapshotHeader) GetTrashed() uint64 {
 	if m != nil {
-		return m.Address
+		return m.Trashed
 	}
-	return ""
+	return 0
 }
 
-func (m *ServerInfo) GetUptime() uint64 {
+func (m *SnapshotHeader) GetCollections() uint64 {
 	if m != nil {
-		return m.Uptime
+		return m.Collections
 	}
 	return 0
 }
 
-func (m *ServerInfo) GetPid() uint64 {
-	if m != nil {
-		return m.Pid
-	}
-	return 0
+type SnapshotChunk struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ServerInfo) GetUid() uint64 {
-	if m != nil {
-		return m.Uid
-	}
-	return 0
+func (m *SnapshotChunk) Reset()         { *m = SnapshotChunk{} }
+func (m *SnapshotChunk) String() string { return proto.CompactTextString(m) }
+func (*SnapshotChunk) ProtoMessage()    {}
+func (*SnapshotChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{40}
 }
 
-func (m *ServerInfo) GetArgv() []string {
+func (m *SnapshotChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnapshotChunk.Unmarshal(m, b)
+}
+func (m *SnapshotChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnapshotChunk.Marshal(b, m, deterministic)
+}
+func (m *SnapshotChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotChunk.Merge(m, src)
+}
+func (m *SnapshotChunk) XXX_Size() int {
+	return xxx_messageInfo_SnapshotChunk.Size(m)
+}
+func (m *SnapshotChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotChunk proto.InternalMessageInfo
+
+func (m *SnapshotChunk) GetData() []byte {
 	if m != nil {
-		return m.Argv
+		return m.Data
 	}
 	return nil
 }
 
-func (m *ServerInfo) GetRecords() uint64 {
-	if m != nil {
-		return m.Records
-	}
-	return 0
+type RestoreResponse struct {
+	Success              bool            `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Msg                  string          `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	Header               *SnapshotHeader `protobuf:"bytes,3,opt,name=header,proto3" json:"header,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
-func (m *ServerInfo) GetOracles() uint64 {
-	if m != nil {
-		return m.Oracles
-	}
-	return 0
+func (m *RestoreResponse) Reset()         { *m = RestoreResponse{} }
+func (m *RestoreResponse) String() string { return proto.CompactTextString(m) }
+func (*RestoreResponse) ProtoMessage()    {}
+func (*RestoreResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33af41ac7b8d43b1, []int{41}
 }
 
-func (m *ServerInfo) GetBackend() string {
-	if m != nil {
-		return m.Backend
-	}
-	return ""
+func (m *RestoreResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreResponse.Unmarshal(m, b)
+}
+func (m *RestoreResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreResponse.Marshal(b, m, deterministic)
+}
+func (m *RestoreResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreResponse.Merge(m, src)
+}
+func (m *RestoreResponse) XXX_Size() int {
+	return xxx_messageInfo_RestoreResponse.Size(m)
+}
+func (m *RestoreResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreResponse.DiscardUnknown(m)
 }
 
-func (m *ServerInfo) GetBackendSpace() uint64 {
+var xxx_messageInfo_RestoreResponse proto.InternalMessageInfo
+
+func (m *RestoreResponse) GetSuccess() bool {
 	if m != nil {
-		return m.BackendSpace
+		return m.Success
 	}
-	return 0
+	return false
 }
 
-func (m *ServerInfo) GetBackendUsed() uint64 {
+func (m *RestoreResponse) GetMsg() string {
 	if m != nil {
-		return m.BackendUsed
+		return m.Msg
 	}
-	return 0
+	return ""
 }
 
-func (m *ServerInfo) GetNextRecordId() uint64 {
+func (m *RestoreResponse) GetHeader() *SnapshotHeader {
 	if m != nil {
-		return m.NextRecordId
+		return m.Header
 	}
-	return 0
+	return nil
 }
 
 type Empty struct {
@@ -1198,7 +3200,7 @@ func (m *Empty) Reset()         { *m = Empty{} }
 func (m *Empty) String() string { return proto.CompactTextString(m) }
 func (*Empty) ProtoMessage()    {}
 func (*Empty) Descriptor() ([]byte, []int) {
-	return fileDescriptor_33af41ac7b8d43b1, []int{20}
+	return fileDescriptor_33af41ac7b8d43b1, []int{42}
 }
 
 func (m *Empty) XXX_Unmarshal(b []byte) error {
@@ -1220,10 +3222,22 @@ func (m *Empty) XXX_DiscardUnknown() {
 var xxx_messageInfo_Empty proto.InternalMessageInfo
 
 func init() {
+	proto.RegisterEnum("sum.QuantizedVector_Type", QuantizedVector_Type_name, QuantizedVector_Type_value)
+	proto.RegisterEnum("sum.MetaFilter_Op", MetaFilter_Op_name, MetaFilter_Op_value)
+	proto.RegisterEnum("sum.MetaQuery_Op", MetaQuery_Op_name, MetaQuery_Op_value)
+	proto.RegisterEnum("sum.Schema_Type", Schema_Type_name, Schema_Type_value)
+	proto.RegisterEnum("sum.Event_Type", Event_Type_name, Event_Type_value)
+	proto.RegisterEnum("sum.ArrayHeader_Format", ArrayHeader_Format_name, ArrayHeader_Format_value)
 	proto.RegisterType((*Node)(nil), "sum.Node")
 	proto.RegisterType((*NodeResponse)(nil), "sum.NodeResponse")
 	proto.RegisterType((*Record)(nil), "sum.Record")
 	proto.RegisterMapType((map[string]string)(nil), "sum.Record.MetaEntry")
+	proto.RegisterMapType((map[string]*MetaValue)(nil), "sum.Record.TypedMetaEntry")
+	proto.RegisterType((*SparseVector)(nil), "sum.SparseVector")
+	proto.RegisterType((*QuantizedVector)(nil), "sum.QuantizedVector")
+	proto.RegisterType((*MetaValue)(nil), "sum.MetaValue")
+	proto.RegisterType((*MetaFilter)(nil), "sum.MetaFilter")
+	proto.RegisterType((*MetaQuery)(nil), "sum.MetaQuery")
 	proto.RegisterType((*Records)(nil), "sum.Records")
 	proto.RegisterType((*RecordIds)(nil), "sum.RecordIds")
 	proto.RegisterType((*RecordResponse)(nil), "sum.RecordResponse")
@@ -1237,91 +3251,223 @@ func init() {
 	proto.RegisterType((*Data)(nil), "sum.Data")
 	proto.RegisterType((*CallResponse)(nil), "sum.CallResponse")
 	proto.RegisterType((*ById)(nil), "sum.ById")
+	proto.RegisterType((*ByKey)(nil), "sum.ByKey")
 	proto.RegisterType((*ByName)(nil), "sum.ByName")
 	proto.RegisterType((*ByAddr)(nil), "sum.ByAddr")
 	proto.RegisterType((*ByMeta)(nil), "sum.ByMeta")
 	proto.RegisterType((*ServerInfo)(nil), "sum.ServerInfo")
+	proto.RegisterType((*BackendBenchmark)(nil), "sum.BackendBenchmark")
+	proto.RegisterType((*Collection)(nil), "sum.Collection")
+	proto.RegisterType((*Schema)(nil), "sum.Schema")
+	proto.RegisterMapType((map[string]Schema_Type)(nil), "sum.Schema.MetaTypesEntry")
+	proto.RegisterType((*PurgeRequest)(nil), "sum.PurgeRequest")
+	proto.RegisterType((*WatchRequest)(nil), "sum.WatchRequest")
+	proto.RegisterType((*Event)(nil), "sum.Event")
+	proto.RegisterType((*ArrayHeader)(nil), "sum.ArrayHeader")
+	proto.RegisterType((*ArrayChunk)(nil), "sum.ArrayChunk")
+	proto.RegisterType((*ImportResponse)(nil), "sum.ImportResponse")
+	proto.RegisterType((*CheckRequest)(nil), "sum.CheckRequest")
+	proto.RegisterType((*IndexEntry)(nil), "sum.IndexEntry")
+	proto.RegisterType((*CheckResponse)(nil), "sum.CheckResponse")
+	proto.RegisterType((*CollectionResponse)(nil), "sum.CollectionResponse")
+	proto.RegisterType((*SnapshotHeader)(nil), "sum.SnapshotHeader")
+	proto.RegisterType((*SnapshotChunk)(nil), "sum.SnapshotChunk")
+	proto.RegisterType((*RestoreResponse)(nil), "sum.RestoreResponse")
 	proto.RegisterType((*Empty)(nil), "sum.Empty")
 }
 
 func init() { proto.RegisterFile("proto/sum.proto", fileDescriptor_33af41ac7b8d43b1) }
 
 var fileDescriptor_33af41ac7b8d43b1 = []byte{
-	// 1176 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x57, 0xeb, 0x6e, 0x1b, 0x45,
-	0x14, 0x8e, 0xed, 0xf5, 0x65, 0x8f, 0xdd, 0x24, 0x9d, 0x5c, 0xba, 0x98, 0xa6, 0x84, 0x09, 0x95,
-	0x82, 0x40, 0x69, 0x65, 0x24, 0x5a, 0x45, 0x48, 0xb4, 0x4d, 0x5b, 0x64, 0x89, 0x36, 0x68, 0xa3,
-	0x82, 0x10, 0x3f, 0xac, 0xe9, 0xce, 0xc4, 0x59, 0x75, 0x6f, 0xec, 0xec, 0x46, 0xf1, 0xb3, 0xf0,
-	0x9f, 0x07, 0xe1, 0x1f, 0x4f, 0xc0, 0xeb, 0xa0, 0x73, 0x66, 0xd6, 0x5d, 0x93, 0x18, 0xd2, 0xf0,
-	0xef, 0xdc, 0xcf, 0x99, 0xef, 0x5c, 0xd6, 0x86, 0xb5, 0x2c, 0x4f, 0x8b, 0xf4, 0x81, 0x2e, 0xe3,
-	0x03, 0xa2, 0x58, 0x4b, 0x97, 0x31, 0x3f, 0x06, 0xe7, 0x75, 0x2a, 0x15, 0x5b, 0x85, 0x66, 0x28,
-	0xbd, 0xc6, 0x6e, 0x63, 0xdf, 0xf1, 0x9b, 0xa1, 0x64, 0x0c, 0x9c, 0x44, 0xc4, 0xca, 0x6b, 0xee,
-	0x36, 0xf6, 0x5d, 0x9f, 0x68, 0xb6, 0x07, 0x4e, 0x98, 0x9c, 0xa6, 0x5e, 0x6b, 0xb7, 0xb1, 0xdf,
-	0x1f, 0xad, 0x1d, 0x60, 0xa8, 0x13, 0x95, 0x9f, 0xab, 0x7c, 0x9c, 0x9c, 0xa6, 0x3e, 0x29, 0xf9,
-	0x2f, 0x30, 0xc0, 0x80, 0xbe, 0xd2, 0x59, 0x9a, 0x68, 0xc5, 0x3c, 0xe8, 0xea, 0x32, 0x08, 0x94,
-	0xd6, 0x14, 0xbd, 0xe7, 0x57, 0x2c, 0x5b, 0x87, 0x56, 0xac, 0xa7, 0x36, 0x03, 0x92, 0xec, 0x13,
-	0x68, 0x27, 0xa9, 0x54, 0xda, 0x6b, 0xed, 0xb6, 0xf6, 0xfb, 0x23, 0x97, 0x32, 0x50, 0x34, 0x23,
-	0xe7, 0xbf, 0x37, 0xa0, 0xe3, 0xab, 0x20, 0xcd, 0xe5, 0x55, 0x05, 0x4b, 0x51, 0x08, 0xaf, 0xb9,
-	0xdb, 0xda, 0x6f, 0xfa, 0x44, 0xb3, 0x4d, 0x68, 0xeb, 0x33, 0x91, 0x29, 0x8a, 0xe7, 0xf8, 0x86,
-	0x61, 0x9f, 0x83, 0x13, 0xab, 0x42, 0x78, 0x0e, 0x25, 0xd9, 0xa2, 0x24, 0x26, 0xe8, 0xc1, 0x2b,
-	0x55, 0x88, 0x17, 0x49, 0x91, 0xcf, 0x7c, 0x32, 0x19, 0x3e, 0x02, 0x77, 0x2e, 0xc2, 0x7a, 0xdf,
-	0xa9, 0x19, 0xa5, 0x74, 0x7d, 0x24, 0x31, 0xfe, 0xb9, 0x88, 0xca, 0x0a, 0x25, 0xc3, 0x1c, 0x36,
-	0x1f, 0x37, 0xf8, 0x43, 0xe8, 0x9a, 0x90, 0x9a, 0xdd, 0x87, 0x6e, 0x6e, 0x48, 0xaf, 0x41, 0x19,
-	0xfb, 0xb5, 0x8c, 0x7e, 0xa5, 0xe3, 0x3b, 0xe0, 0x1a, 0xd1, 0x58, 0x12, 0x34, 0xa1, 0xb5, 0x77,
-	0x7c, 0x24, 0xb9, 0x80, 0x55, 0xeb, 0x71, 0x13, 0x60, 0xf7, 0xa0, 0x63, 0xf2, 0xd8, 0xde, 0x2d,
-	0x94, 0x60, 0x55, 0xfc, 0x1b, 0xe8, 0x7f, 0x1f, 0xea, 0xc2, 0x57, 0xbf, 0x96, 0x4a, 0x17, 0x08,
-	0x68, 0x26, 0xa6, 0xca, 0x42, 0x4c, 0x34, 0xfb, 0x08, 0x7a, 0x99, 0xca, 0x27, 0x24, 0x6f, 0x92,
-	0xbc, 0x9b, 0xa9, 0xfc, 0x07, 0x31, 0x55, 0x7c, 0x0a, 0xcc, 0xc4, 0x33, 0x31, 0x6c, 0x91, 0x9b,
-	0xd0, 0x2e, 0xd2, 0x42, 0x44, 0x36, 0x8a, 0x61, 0x50, 0x8a, 0x21, 0xb4, 0x8d, 0x61, 0x98, 0x3a,
-	0x50, 0xad, 0x7f, 0x01, 0x6a, 0x0a, 0xec, 0x38, 0x17, 0x41, 0xa4, 0xfe, 0x4f, 0xa2, 0x94, 0x22,
-	0x2c, 0x26, 0x32, 0x51, 0xfd, 0x4a, 0xc7, 0x05, 0x0c, 0x5e, 0x86, 0xc9, 0xcd, 0x00, 0xbf, 0xe6,
-	0x5b, 0x9e, 0x40, 0xc7, 0x64, 0xbd, 0xd6, 0xfe, 0x31, 0x70, 0x82, 0x54, 0x2a, 0xea, 0xa1, 0xeb,
-	0x13, 0x8d, 0x73, 0x61, 0xeb, 0xbe, 0xe1, 0x5c, 0x98, 0xd7, 0x2e, 0xcc, 0x85, 0x0d, 0x68, 0x55,
-	0xfc, 0x11, 0x38, 0x47, 0x22, 0x8a, 0xd8, 0xc7, 0xe0, 0x1a, 0xc9, 0x64, 0x5e, 0x69, 0xcf, 0x08,
-	0xc6, 0x54, 0xaf, 0xc8, 0xa7, 0x9a, 0xd6, 0xcf, 0xf5, 0x89, 0xe6, 0x4f, 0xc0, 0x79, 0x8e, 0x6b,
-	0x78, 0x0f, 0x20, 0x48, 0xe3, 0x2c, 0x57, 0x5a, 0x2b, 0x69, 0x8b, 0xaa, 0x49, 0xb0, 0xe2, 0x4c,
-	0xcc, 0xa2, 0x54, 0x48, 0xaa, 0x6d, 0xe0, 0x57, 0x2c, 0xff, 0x19, 0x06, 0x98, 0xfa, 0x46, 0x6f,
-	0xdb, 0xb1, 0x07, 0xc1, 0xbc, 0xcc, 0xdc, 0x12, 0x2c, 0xc7, 0xdc, 0x06, 0xbe, 0x0d, 0xce, 0xb3,
-	0xd9, 0xf8, 0xd2, 0x1d, 0xe1, 0x77, 0xa1, 0xf3, 0x6c, 0xf6, 0xda, 0xc2, 0x4d, 0x2d, 0x68, 0xbc,
-	0x6f, 0x01, 0xff, 0x16, 0xb5, 0x4f, 0xa5, 0xcc, 0xb1, 0x14, 0x21, 0x65, 0x5e, 0x95, 0xe2, 0xfa,
-	0x15, 0x8b, 0x38, 0x05, 0x2a, 0x2f, 0x26, 0xa7, 0x61, 0x54, 0xf5, 0xaf, 0x87, 0x82, 0x97, 0x61,
-	0xa4, 0xf8, 0x08, 0x03, 0xe0, 0x4d, 0xc1, 0xf0, 0x74, 0x86, 0x6c, 0x78, 0xa4, 0xaf, 0x3e, 0x28,
-	0xfc, 0x2f, 0x07, 0xe0, 0xfd, 0x9d, 0xc5, 0xcc, 0xe7, 0x2a, 0xd7, 0x61, 0x9a, 0x54, 0x99, 0x2d,
-	0x8b, 0x6f, 0x49, 0xb5, 0xf5, 0x6d, 0xa6, 0xda, 0x34, 0x25, 0x38, 0xab, 0x06, 0x06, 0x69, 0xb6,
-	0x03, 0x30, 0x4d, 0x27, 0x55, 0x00, 0x87, 0x34, 0xee, 0x34, 0xfd, 0xd1, 0x86, 0xc0, 0x19, 0xcb,
-	0x4a, 0xed, 0xb5, 0xcd, 0xd6, 0x23, 0x8d, 0x5b, 0x1f, 0x8b, 0x8b, 0x09, 0xc9, 0x3b, 0x66, 0xeb,
-	0x63, 0x71, 0x71, 0x84, 0xaa, 0x7b, 0x18, 0x2d, 0x4f, 0xcb, 0x22, 0x4c, 0x94, 0xf6, 0xba, 0xa4,
-	0xac, 0x49, 0xf0, 0x41, 0x22, 0x8a, 0xd2, 0xc0, 0xeb, 0x99, 0x05, 0x24, 0x06, 0x9b, 0xa5, 0x67,
-	0xda, 0x73, 0x49, 0x86, 0x24, 0xdb, 0x82, 0x4e, 0x52, 0xc6, 0x93, 0x69, 0xe0, 0x81, 0x31, 0x4c,
-	0xca, 0xf8, 0xbb, 0x80, 0x0d, 0xa1, 0x87, 0xcd, 0xca, 0x44, 0x71, 0xe6, 0xf5, 0x0d, 0x92, 0x15,
-	0xcf, 0xee, 0x82, 0x1b, 0xe4, 0x4a, 0x6a, 0x52, 0x0e, 0xcc, 0x3b, 0xe6, 0x82, 0x7a, 0x7b, 0x6e,
-	0x2d, 0xb6, 0x67, 0x1b, 0x3a, 0x65, 0x56, 0x84, 0xb1, 0xf2, 0x56, 0x29, 0x95, 0xe5, 0xb0, 0xa8,
-	0x2c, 0x94, 0xde, 0x9a, 0x29, 0x2a, 0x0b, 0x25, 0x4a, 0xca, 0x50, 0x7a, 0xeb, 0x46, 0x52, 0x86,
-	0xd5, 0x94, 0x9f, 0x7b, 0xb7, 0xe7, 0x53, 0x7e, 0x8e, 0x99, 0xaa, 0x55, 0x67, 0x06, 0x1c, 0xcb,
-	0xa2, 0xa6, 0xba, 0x33, 0x1b, 0x46, 0x63, 0x59, 0xd4, 0xbc, 0x15, 0xc1, 0x3b, 0x95, 0x48, 0x6f,
-	0xd3, 0x54, 0x67, 0x59, 0xb6, 0x07, 0xb7, 0x2c, 0x39, 0xd1, 0x99, 0x08, 0x94, 0xb7, 0x45, 0x9e,
-	0x03, 0x2b, 0x3c, 0x41, 0x19, 0xfb, 0x14, 0x2a, 0x7e, 0x52, 0xe2, 0x4a, 0x6d, 0x93, 0x4d, 0xdf,
-	0xca, 0xde, 0xe0, 0x4e, 0x7d, 0x06, 0xab, 0x89, 0xba, 0x28, 0x26, 0xa6, 0x16, 0xdc, 0xd8, 0x3b,
-	0x26, 0x10, 0x4a, 0xab, 0x0f, 0x0d, 0xef, 0x42, 0xfb, 0x45, 0x9c, 0x15, 0xb3, 0xd1, 0x9f, 0x6d,
-	0x80, 0x93, 0x32, 0xc6, 0x29, 0x0b, 0x03, 0xc5, 0x46, 0x30, 0x38, 0xca, 0x95, 0x28, 0x94, 0xfd,
-	0xd8, 0xd6, 0xaf, 0xd7, 0x70, 0xa3, 0x7e, 0xca, 0xec, 0x66, 0xf2, 0x15, 0xf4, 0x79, 0x93, 0xc9,
-	0x0f, 0xf3, 0x39, 0x00, 0xf0, 0x95, 0x90, 0xd6, 0xc3, 0xec, 0x28, 0x6e, 0xe5, 0x32, 0xfb, 0xc3,
-	0xea, 0x13, 0x65, 0x00, 0x5e, 0x27, 0xab, 0xda, 0x47, 0x6b, 0x78, 0xa7, 0xe6, 0x57, 0xff, 0x3e,
-	0xf0, 0x15, 0xf6, 0x10, 0x06, 0xcf, 0x55, 0xa4, 0xe6, 0xf5, 0xfd, 0x77, 0xb6, 0x07, 0xd0, 0x37,
-	0x1f, 0x00, 0x93, 0xad, 0x6f, 0x1d, 0x70, 0x7b, 0x87, 0xb7, 0x89, 0xa9, 0x7f, 0x1f, 0x0c, 0x04,
-	0x06, 0x36, 0x7b, 0xd4, 0xeb, 0xe7, 0xd4, 0x26, 0x59, 0x3c, 0xd6, 0x75, 0xd8, 0x3e, 0xc0, 0xc7,
-	0xc2, 0x66, 0x3d, 0x2e, 0x3d, 0xe4, 0x92, 0xbd, 0x85, 0xed, 0xd8, 0x4e, 0xdf, 0x32, 0xd8, 0x2e,
-	0x7f, 0x56, 0x09, 0x36, 0xc0, 0x57, 0x2e, 0x54, 0x67, 0x0e, 0xe4, 0xb2, 0x6c, 0x73, 0xa0, 0xaf,
-	0x5d, 0xdf, 0x7d, 0x68, 0xf9, 0x65, 0x62, 0x0d, 0xf1, 0xe0, 0x5b, 0x78, 0xeb, 0xb7, 0x9f, 0xcc,
-	0x1c, 0x3a, 0x80, 0x40, 0x4a, 0x1a, 0xdc, 0xe1, 0x3f, 0x7f, 0x85, 0xf2, 0x95, 0xd1, 0x1f, 0x0d,
-	0x60, 0x27, 0x65, 0x3c, 0x4e, 0x0a, 0x95, 0x27, 0x22, 0xaa, 0x66, 0xfa, 0x31, 0xb0, 0xfa, 0x4c,
-	0xff, 0x14, 0x16, 0x67, 0xe3, 0xeb, 0x4d, 0xe9, 0x21, 0x6c, 0xd4, 0x3d, 0xb5, 0x75, 0x1d, 0xd4,
-	0xac, 0xf5, 0x32, 0xdf, 0xaf, 0xe1, 0x56, 0x7d, 0xea, 0x34, 0x5b, 0xad, 0xd9, 0x8d, 0x97, 0xfa,
-	0x8d, 0x7e, 0x6b, 0xc0, 0xfa, 0x49, 0x19, 0xbf, 0x12, 0xba, 0x50, 0x79, 0xf5, 0x84, 0x2f, 0xa0,
-	0xfb, 0x54, 0x4a, 0xfa, 0xbd, 0x5e, 0x35, 0x02, 0xbf, 0x45, 0x16, 0xad, 0xfa, 0xcf, 0x6e, 0xbe,
-	0xc2, 0xbe, 0x04, 0x17, 0x5b, 0x89, 0x52, 0xbd, 0x00, 0xd9, 0x12, 0x6b, 0x30, 0x75, 0x52, 0xf4,
-	0x5a, 0xcb, 0xae, 0xb2, 0x7e, 0xdb, 0xa1, 0x7f, 0x10, 0x5f, 0xfd, 0x1d, 0x00, 0x00, 0xff, 0xff,
-	0xd0, 0x93, 0x84, 0x90, 0x54, 0x0c, 0x00, 0x00,
+	// 3007 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x3a, 0x4d, 0x73, 0x1b, 0xc7,
+	0xb1, 0xdc, 0xc5, 0x62, 0x01, 0x34, 0x40, 0x10, 0x1a, 0xd1, 0xd2, 0x0a, 0xb6, 0x65, 0x7a, 0x65,
+	0x3f, 0xd3, 0xf2, 0x7b, 0x92, 0x4d, 0xbf, 0xb2, 0x65, 0x55, 0xbd, 0x7a, 0x26, 0x29, 0x50, 0x42,
+	0x59, 0x22, 0xa9, 0x25, 0xf4, 0xe5, 0xa4, 0x82, 0x5a, 0xee, 0x0e, 0xc9, 0x8d, 0x80, 0xdd, 0xd5,
+	0xce, 0x42, 0x25, 0xfa, 0xaf, 0xe4, 0x90, 0x53, 0xce, 0xa9, 0x54, 0x7e, 0x41, 0x7c, 0xcc, 0x35,
+	0xd7, 0x9c, 0xf3, 0x03, 0x52, 0xf9, 0x01, 0xa9, 0xe9, 0x99, 0xc1, 0xce, 0x82, 0x20, 0x45, 0xd1,
+	0x39, 0x71, 0xfb, 0x63, 0x7a, 0x7a, 0xba, 0x7b, 0x7a, 0xba, 0x1b, 0x84, 0xa5, 0x34, 0x4b, 0xf2,
+	0xe4, 0x36, 0x9b, 0x8c, 0x6f, 0xe1, 0x17, 0xa9, 0xb0, 0xc9, 0xd8, 0xdd, 0x01, 0x6b, 0x3b, 0x09,
+	0x29, 0x69, 0x83, 0x19, 0x85, 0x8e, 0xb1, 0x62, 0xac, 0x5a, 0x9e, 0x19, 0x85, 0x84, 0x80, 0x15,
+	0xfb, 0x63, 0xea, 0x98, 0x2b, 0xc6, 0x6a, 0xc3, 0xc3, 0x6f, 0x72, 0x03, 0xac, 0x28, 0x3e, 0x48,
+	0x9c, 0xca, 0x8a, 0xb1, 0xda, 0x5c, 0x5b, 0xba, 0xc5, 0x45, 0xed, 0xd1, 0xec, 0x35, 0xcd, 0xfa,
+	0xf1, 0x41, 0xe2, 0x21, 0xd1, 0xfd, 0x15, 0xb4, 0xb8, 0x40, 0x8f, 0xb2, 0x34, 0x89, 0x19, 0x25,
+	0x0e, 0xd4, 0xd8, 0x24, 0x08, 0x28, 0x63, 0x28, 0xbd, 0xee, 0x29, 0x90, 0x74, 0xa0, 0x32, 0x66,
+	0x87, 0x72, 0x07, 0xfe, 0x49, 0x3e, 0x82, 0x6a, 0x9c, 0x84, 0x94, 0x39, 0x95, 0x95, 0xca, 0x6a,
+	0x73, 0xad, 0x81, 0x3b, 0xa0, 0x34, 0x81, 0x77, 0xff, 0x61, 0x81, 0xed, 0xd1, 0x20, 0xc9, 0xc2,
+	0x79, 0x0a, 0x87, 0x7e, 0xee, 0x3b, 0xe6, 0x4a, 0x65, 0xd5, 0xf4, 0xf0, 0x9b, 0x2c, 0x43, 0x95,
+	0x1d, 0xf9, 0x29, 0x45, 0x79, 0x96, 0x27, 0x00, 0xf2, 0x39, 0x58, 0x63, 0x9a, 0xfb, 0x8e, 0x85,
+	0x9b, 0xbc, 0x87, 0x9b, 0x08, 0xa1, 0xb7, 0x1e, 0xd1, 0xdc, 0xef, 0xc5, 0x79, 0x76, 0xec, 0x21,
+	0x0b, 0x57, 0xfe, 0x35, 0xcd, 0x58, 0x94, 0xc4, 0x4e, 0x15, 0x77, 0x52, 0x20, 0xf9, 0x10, 0x60,
+	0x92, 0x86, 0x7e, 0x4e, 0xc3, 0xa1, 0x9f, 0x3b, 0xf6, 0x8a, 0xb1, 0x5a, 0xf1, 0x1a, 0x12, 0xb3,
+	0x9e, 0x73, 0x32, 0x7d, 0x93, 0x46, 0x19, 0x65, 0x9c, 0x5c, 0x13, 0x64, 0x89, 0x59, 0xcf, 0xf9,
+	0xd1, 0xf3, 0x7c, 0xe4, 0xd4, 0x51, 0x26, 0xff, 0x24, 0xdf, 0x01, 0xe4, 0xc7, 0x29, 0x0d, 0x87,
+	0xa8, 0x5a, 0x03, 0x55, 0xeb, 0xea, 0xaa, 0x0d, 0x38, 0xb5, 0xd0, 0xaf, 0x91, 0x2b, 0x98, 0x5c,
+	0x07, 0x08, 0x92, 0xd1, 0x88, 0x06, 0x39, 0xd7, 0x13, 0xd0, 0x9c, 0x1a, 0x86, 0xeb, 0x12, 0xd2,
+	0x11, 0x95, 0xaa, 0x36, 0x85, 0x2e, 0x12, 0xb3, 0x9e, 0x93, 0xcf, 0xc1, 0x66, 0xa9, 0x9f, 0x31,
+	0xea, 0xb4, 0xd0, 0xaf, 0x97, 0x84, 0x5f, 0x11, 0xf5, 0x94, 0x06, 0x79, 0x92, 0x79, 0x92, 0x81,
+	0xab, 0xfd, 0x92, 0x1e, 0x3b, 0x8b, 0xc2, 0x63, 0x2f, 0xe9, 0x31, 0x59, 0x83, 0xc6, 0xab, 0x89,
+	0x1f, 0xe7, 0xd1, 0x4f, 0x34, 0x74, 0xda, 0xb8, 0x7e, 0x19, 0xd7, 0x3f, 0x56, 0x58, 0x29, 0xa2,
+	0x60, 0x23, 0xef, 0x43, 0x23, 0x18, 0x51, 0x3f, 0x1b, 0x72, 0x59, 0x4b, 0x18, 0x13, 0x75, 0x44,
+	0xfc, 0x40, 0x8f, 0xbb, 0xdf, 0x42, 0x63, 0x7a, 0x48, 0xb5, 0x9f, 0x51, 0xec, 0xb7, 0x0c, 0xd5,
+	0xd7, 0xfe, 0x68, 0xa2, 0xe2, 0x52, 0x00, 0x77, 0xcd, 0x3b, 0x46, 0xf7, 0x21, 0xb4, 0xcb, 0x26,
+	0x9a, 0xb3, 0xfa, 0x13, 0x7d, 0x75, 0x73, 0xad, 0x8d, 0x9a, 0xf2, 0x05, 0x4f, 0x39, 0x56, 0x93,
+	0xe6, 0x0e, 0xa0, 0xa5, 0x5b, 0x80, 0x47, 0x17, 0x8b, 0x7e, 0xa2, 0x32, 0xde, 0xf0, 0x9b, 0x07,
+	0x47, 0x14, 0x87, 0x51, 0x40, 0x19, 0x06, 0xdd, 0xa2, 0xa7, 0x40, 0x72, 0x05, 0x6c, 0x14, 0x25,
+	0x02, 0xd9, 0xf4, 0x24, 0xe4, 0xfe, 0x6c, 0xc0, 0xd2, 0x8c, 0x61, 0xc8, 0xff, 0x80, 0xc5, 0x5d,
+	0x89, 0x92, 0xdb, 0x6b, 0xd7, 0xe6, 0x19, 0x0f, 0x7d, 0xef, 0x21, 0xdb, 0x54, 0x11, 0x53, 0x53,
+	0x44, 0x85, 0x3e, 0xbf, 0x97, 0x2d, 0x2d, 0xf4, 0x03, 0x7f, 0x44, 0x1d, 0x6b, 0xc5, 0x58, 0x35,
+	0x3d, 0x01, 0x70, 0xc5, 0x92, 0x83, 0x03, 0x46, 0x73, 0x0c, 0x67, 0xd3, 0x93, 0x90, 0x7b, 0x13,
+	0x2c, 0xbe, 0x07, 0x69, 0x42, 0x6d, 0xeb, 0xe1, 0xce, 0xfa, 0xe0, 0xeb, 0xb5, 0xce, 0x02, 0xa9,
+	0x83, 0xd5, 0xdf, 0x1e, 0xdc, 0xe9, 0x18, 0x53, 0xf4, 0x57, 0xdf, 0x74, 0x4c, 0xf7, 0x47, 0x68,
+	0x4c, 0x4d, 0x46, 0x96, 0xc1, 0xca, 0xe9, 0x9b, 0x5c, 0x18, 0xf9, 0xc1, 0x82, 0x87, 0x10, 0x71,
+	0xc0, 0x8e, 0x27, 0xe3, 0x7d, 0x9a, 0xa1, 0x9a, 0xc6, 0x83, 0x05, 0x4f, 0xc2, 0xc8, 0x1f, 0x8d,
+	0x29, 0xaa, 0x5a, 0x41, 0xfe, 0x68, 0x4c, 0x37, 0x6a, 0xd2, 0x2f, 0xee, 0xdf, 0x0c, 0x00, 0x2e,
+	0x7c, 0x2b, 0x1a, 0xe5, 0x14, 0xad, 0x8e, 0xd7, 0x41, 0xb8, 0x10, 0xbf, 0x89, 0x0b, 0x66, 0x92,
+	0xa2, 0xdc, 0xf6, 0x1a, 0x99, 0x3a, 0x50, 0x2c, 0xb8, 0xb5, 0x93, 0x7a, 0x66, 0x92, 0x16, 0x7e,
+	0xae, 0x9c, 0xe1, 0x67, 0x72, 0x1d, 0xcc, 0x3c, 0x71, 0xac, 0xb9, 0x2c, 0x66, 0x9e, 0xb8, 0xf7,
+	0xc1, 0xdc, 0x49, 0x89, 0x0d, 0x66, 0xef, 0x71, 0x67, 0x81, 0xff, 0x7d, 0x38, 0xe8, 0x18, 0xa4,
+	0x06, 0x95, 0x87, 0x83, 0x5e, 0xc7, 0xe4, 0x88, 0xfb, 0x83, 0x4e, 0x85, 0x23, 0xee, 0x0f, 0x7a,
+	0x1d, 0x8b, 0x5b, 0x69, 0xa3, 0x37, 0x78, 0xd6, 0xeb, 0x6d, 0x77, 0xaa, 0x04, 0xc0, 0xde, 0xf5,
+	0x7a, 0x5b, 0xfd, 0xe7, 0x1d, 0xdb, 0xfd, 0xa3, 0x21, 0x4c, 0xf6, 0x78, 0x42, 0xb3, 0x63, 0xf2,
+	0x31, 0x1e, 0x40, 0xb8, 0xfb, 0xd2, 0x74, 0x5b, 0xa4, 0x29, 0xfd, 0x3f, 0x03, 0xfb, 0x00, 0x0f,
+	0xe4, 0x98, 0x5a, 0xaa, 0x2d, 0xce, 0xe9, 0x49, 0x32, 0xb9, 0x09, 0xf5, 0x24, 0xa5, 0x99, 0x1f,
+	0x87, 0x2a, 0x67, 0xb6, 0xcb, 0x12, 0xbd, 0x29, 0xdd, 0xbd, 0x89, 0xc7, 0x01, 0xb0, 0xb7, 0xfa,
+	0x0f, 0x07, 0x3d, 0xaf, 0xb3, 0xc0, 0x35, 0x5f, 0xdf, 0xbe, 0xd7, 0x31, 0xf8, 0x51, 0x76, 0xbc,
+	0x8e, 0xc9, 0x11, 0xdb, 0x3b, 0x83, 0x4e, 0xc5, 0x1d, 0x41, 0x4d, 0xa4, 0x1d, 0x46, 0x3e, 0x85,
+	0x5a, 0x26, 0x3e, 0x1d, 0x03, 0x77, 0x68, 0x6a, 0x59, 0xc9, 0x53, 0xb4, 0x99, 0x24, 0x64, 0x9e,
+	0x48, 0x42, 0x5d, 0xa8, 0xe7, 0x99, 0x1f, 0xb3, 0x03, 0x9a, 0xa1, 0x57, 0xea, 0xde, 0x14, 0x76,
+	0x5f, 0x40, 0x43, 0x88, 0xeb, 0x87, 0xf8, 0x2a, 0x44, 0x72, 0x2f, 0xcb, 0xab, 0x44, 0xbf, 0x50,
+	0xb4, 0x0f, 0x6d, 0xa9, 0xe9, 0x45, 0xde, 0xa3, 0x1b, 0x60, 0x8b, 0xf3, 0xc9, 0x40, 0x2a, 0x1d,
+	0x5d, 0x92, 0xdc, 0x9f, 0xa0, 0xf9, 0x30, 0x62, 0xb9, 0x47, 0x5f, 0x4d, 0x28, 0xcb, 0x79, 0xcc,
+	0xa6, 0xfe, 0xe1, 0x34, 0x53, 0xf0, 0x6f, 0x72, 0x0d, 0xea, 0x29, 0xcd, 0x86, 0x88, 0x17, 0x17,
+	0xb7, 0x96, 0xd2, 0x6c, 0x97, 0x93, 0xca, 0x87, 0xab, 0x9c, 0x38, 0xdc, 0x07, 0x7a, 0x82, 0xb5,
+	0x50, 0xe1, 0x02, 0xe1, 0x1e, 0x02, 0x11, 0xda, 0x08, 0x0d, 0xe4, 0x11, 0x97, 0xa1, 0x9a, 0x27,
+	0xb9, 0x3f, 0x92, 0x3a, 0x08, 0x80, 0x63, 0xb9, 0x02, 0x4c, 0x6a, 0x20, 0x00, 0xdd, 0xbd, 0x95,
+	0xd3, 0xdd, 0xcb, 0x37, 0xda, 0xc9, 0xfc, 0x60, 0x44, 0x7f, 0xc9, 0x46, 0x09, 0x4a, 0x28, 0x6f,
+	0x24, 0xa4, 0x7a, 0x8a, 0xe6, 0xfa, 0xd0, 0xda, 0x8a, 0xe2, 0x8b, 0xb9, 0xeb, 0x9c, 0x67, 0xf9,
+	0x1e, 0x6c, 0xb1, 0xeb, 0xb9, 0x8a, 0x1e, 0x02, 0x56, 0x90, 0x84, 0x54, 0xba, 0x06, 0xbf, 0x79,
+	0x54, 0x49, 0xbd, 0x2f, 0x18, 0x55, 0xe2, 0xb4, 0xa5, 0xa8, 0x92, 0x02, 0x25, 0xc9, 0x7d, 0x06,
+	0xd6, 0xa6, 0x3f, 0x1a, 0xf1, 0xc7, 0x52, 0x60, 0x86, 0x53, 0x4d, 0xeb, 0x02, 0xd1, 0x47, 0x7d,
+	0xfd, 0xec, 0x50, 0x3c, 0x3f, 0x0d, 0x0f, 0xbf, 0xdf, 0x16, 0x50, 0xee, 0xf7, 0x60, 0xdd, 0xf3,
+	0x55, 0xd5, 0x30, 0x4e, 0x33, 0xca, 0x18, 0x0d, 0xa5, 0xd2, 0x1a, 0x86, 0x9f, 0x28, 0xf5, 0x8f,
+	0x47, 0x89, 0x1f, 0xa2, 0xee, 0x2d, 0x4f, 0x81, 0xee, 0x0b, 0x68, 0x71, 0xd5, 0x2e, 0x74, 0xf6,
+	0x0f, 0xb5, 0xa7, 0x4a, 0x15, 0x78, 0x5c, 0x1d, 0xf1, 0x6a, 0xb9, 0x31, 0x58, 0x1b, 0xc7, 0xfd,
+	0x93, 0xc5, 0x9d, 0x56, 0x87, 0x99, 0xe5, 0x3a, 0xec, 0x97, 0xdd, 0x9f, 0x67, 0x50, 0xdd, 0x38,
+	0xfe, 0x81, 0xce, 0xab, 0x15, 0xde, 0x96, 0x75, 0x4a, 0x82, 0x2b, 0xb3, 0x82, 0x3f, 0x00, 0x7b,
+	0xe3, 0x78, 0x5b, 0xc6, 0x0f, 0xc6, 0x94, 0x51, 0xc4, 0x94, 0xfb, 0xff, 0x9c, 0xba, 0x1e, 0x86,
+	0x19, 0x3f, 0x98, 0x1f, 0x86, 0x99, 0xb2, 0x5d, 0xc3, 0x53, 0x20, 0x56, 0x49, 0x34, 0xcb, 0x87,
+	0x07, 0xd1, 0x48, 0x05, 0x64, 0x9d, 0x23, 0xb6, 0xa2, 0x11, 0x75, 0xff, 0x62, 0x70, 0x09, 0x58,
+	0xfd, 0xcd, 0x7b, 0x23, 0xe7, 0x56, 0x49, 0xe4, 0x73, 0xa8, 0x89, 0x67, 0x43, 0x5d, 0x8f, 0x13,
+	0xcf, 0x8a, 0xa2, 0xf3, 0x07, 0xf4, 0x15, 0x7f, 0x3e, 0x4e, 0xbc, 0x8e, 0xe2, 0x51, 0x11, 0xc4,
+	0x19, 0x13, 0x55, 0xcf, 0x36, 0x91, 0x3d, 0x6b, 0xa2, 0xbf, 0xd6, 0x01, 0x8a, 0xee, 0x41, 0x77,
+	0xb1, 0xb4, 0x84, 0x04, 0x79, 0x30, 0x24, 0x4c, 0x1e, 0xc5, 0x4c, 0x98, 0x88, 0xfa, 0xe0, 0x48,
+	0xdd, 0x48, 0xfe, 0xcd, 0x6b, 0xdc, 0xc3, 0x64, 0xa8, 0x04, 0x58, 0x48, 0x69, 0x1c, 0x26, 0x4f,
+	0xa5, 0x08, 0x7e, 0x89, 0xd3, 0x09, 0x93, 0x45, 0x3c, 0x7e, 0xf3, 0xa4, 0x3c, 0xf6, 0xdf, 0x0c,
+	0x11, 0x6f, 0x8b, 0xa0, 0x1a, 0xfb, 0x6f, 0x36, 0x39, 0xe9, 0x3a, 0x97, 0x96, 0x25, 0x93, 0x3c,
+	0x8a, 0x29, 0xc3, 0xea, 0xdd, 0xf2, 0x34, 0x0c, 0xb7, 0xaf, 0x3f, 0x1a, 0x25, 0x81, 0x2c, 0xe0,
+	0x05, 0xc0, 0x63, 0x88, 0x1d, 0x33, 0xa7, 0x81, 0x38, 0xfe, 0x49, 0xde, 0xc3, 0x3a, 0x68, 0x78,
+	0x18, 0x60, 0x55, 0x6e, 0x79, 0xd5, 0x78, 0x32, 0xbe, 0x1f, 0xf0, 0x07, 0x8b, 0x47, 0x7b, 0xea,
+	0xe7, 0x47, 0x58, 0x8e, 0x37, 0xbc, 0x29, 0xcc, 0x6d, 0x16, 0x64, 0x34, 0x64, 0x48, 0x6c, 0x89,
+	0x73, 0x4c, 0x11, 0x7a, 0xb8, 0x2c, 0x96, 0xc3, 0xe5, 0x0a, 0xd8, 0x93, 0x14, 0x4b, 0xab, 0x36,
+	0x6e, 0x25, 0x21, 0xae, 0x54, 0x1a, 0x85, 0x58, 0x66, 0x5b, 0x1e, 0xff, 0xe4, 0x98, 0x49, 0x14,
+	0x3a, 0x1d, 0x81, 0x99, 0x44, 0x2a, 0x8d, 0xbc, 0x76, 0x2e, 0x4d, 0xd3, 0xc8, 0x6b, 0xbe, 0x93,
+	0xca, 0xa5, 0x44, 0x18, 0x47, 0x82, 0x9c, 0xa2, 0x12, 0xf9, 0x65, 0x41, 0x91, 0x20, 0xa7, 0xec,
+	0xfb, 0xc1, 0x4b, 0x1a, 0x87, 0xce, 0xb2, 0xd0, 0x4e, 0x82, 0xe4, 0x06, 0x2c, 0xca, 0xcf, 0x21,
+	0x4b, 0xfd, 0x80, 0x3a, 0xef, 0xe1, 0xca, 0x96, 0x44, 0xee, 0x71, 0x1c, 0xf9, 0x18, 0x14, 0x3c,
+	0x9c, 0xf0, 0x9c, 0x74, 0x05, 0x79, 0x9a, 0x12, 0xf7, 0x84, 0x27, 0xa5, 0x4f, 0xa0, 0x1d, 0xd3,
+	0x37, 0xf9, 0x50, 0xe8, 0xc2, 0x53, 0xe2, 0x55, 0x21, 0x88, 0x63, 0x55, 0x0d, 0xc1, 0x05, 0x05,
+	0x7e, 0x70, 0x44, 0x87, 0xfb, 0x93, 0xf0, 0x90, 0xe6, 0x8e, 0x23, 0x04, 0x21, 0x6e, 0x03, 0x51,
+	0x3c, 0x5e, 0x04, 0x0b, 0xee, 0x74, 0x0d, 0x19, 0x1a, 0x88, 0xc1, 0x7d, 0xa6, 0xe4, 0xa3, 0x28,
+	0x67, 0x4e, 0x57, 0x23, 0x3f, 0x88, 0x72, 0x56, 0x6c, 0x30, 0x8e, 0x18, 0xa3, 0xcc, 0x79, 0x5f,
+	0xdb, 0xe0, 0x11, 0xa2, 0xc8, 0x67, 0xb0, 0x24, 0x58, 0xe8, 0xeb, 0x08, 0x6f, 0x03, 0x73, 0x3e,
+	0x40, 0xae, 0x36, 0xa2, 0x7b, 0x0a, 0xcb, 0xef, 0x39, 0xcf, 0xaa, 0x43, 0xf4, 0xdd, 0x87, 0x22,
+	0xc1, 0x73, 0xc4, 0x80, 0x7b, 0xef, 0x36, 0x5c, 0x96, 0x66, 0x1f, 0xbe, 0x9a, 0xf8, 0x19, 0xbf,
+	0x3a, 0x31, 0x0d, 0x9d, 0xeb, 0xc8, 0x46, 0x24, 0xe9, 0x71, 0x41, 0xe1, 0x0b, 0xa4, 0x37, 0x4a,
+	0x0b, 0x3e, 0x12, 0x0b, 0x24, 0x49, 0x5f, 0xf0, 0x15, 0x34, 0x8b, 0x1b, 0xcb, 0x9c, 0x15, 0x2d,
+	0x31, 0x6c, 0x4e, 0xf1, 0x9e, 0xce, 0xc3, 0xdf, 0x2f, 0x16, 0x1c, 0xd1, 0xb1, 0xef, 0x7c, 0xac,
+	0xbd, 0x5f, 0x7b, 0x88, 0xf2, 0x24, 0x89, 0x9f, 0x5f, 0x69, 0x9e, 0x67, 0x3e, 0x3b, 0xa2, 0xa1,
+	0xe3, 0x8a, 0xf3, 0x4b, 0xf4, 0x40, 0x60, 0xc9, 0x3d, 0x20, 0xca, 0xeb, 0xfb, 0x34, 0x0e, 0x8e,
+	0xc6, 0x7e, 0xf6, 0x92, 0x39, 0x37, 0xb4, 0xde, 0x7c, 0x43, 0x90, 0x37, 0x14, 0xd5, 0xbb, 0xb4,
+	0x3f, 0x83, 0x61, 0xee, 0x53, 0xe8, 0xcc, 0xb2, 0xcd, 0xcb, 0xbc, 0x73, 0xdb, 0xa7, 0x2e, 0x34,
+	0x62, 0x36, 0xe4, 0x05, 0x5a, 0x92, 0x62, 0x52, 0x31, 0xbc, 0x5a, 0xcc, 0x76, 0x69, 0xb6, 0x93,
+	0xba, 0xbf, 0x37, 0x00, 0x0a, 0x3b, 0xcc, 0x15, 0xa9, 0xdd, 0x14, 0xb3, 0x7c, 0x53, 0x4e, 0x46,
+	0x6b, 0x65, 0x4e, 0xb4, 0x16, 0xe6, 0xb4, 0x4e, 0x37, 0xa7, 0x03, 0x35, 0x65, 0x46, 0x39, 0x88,
+	0x90, 0xa0, 0xfb, 0x77, 0x13, 0x6c, 0xc1, 0xcc, 0x73, 0x47, 0x18, 0x8d, 0x69, 0x3c, 0x4d, 0xa2,
+	0x96, 0x57, 0x20, 0x8a, 0x61, 0x88, 0xa9, 0x0f, 0x43, 0x6e, 0xc0, 0x62, 0x46, 0x5f, 0x4d, 0xa2,
+	0x4c, 0x8d, 0x1e, 0x2a, 0x98, 0x04, 0x5a, 0x0a, 0x89, 0x6f, 0xcc, 0x77, 0x00, 0x9c, 0x36, 0xe4,
+	0x1d, 0x28, 0x73, 0x2c, 0x6d, 0x38, 0x21, 0x76, 0xc6, 0xa7, 0x81, 0x37, 0x90, 0x4c, 0x0e, 0x27,
+	0xc6, 0x0a, 0x26, 0xff, 0x07, 0x2d, 0x99, 0xf2, 0xfd, 0xe9, 0x2b, 0x71, 0x66, 0x9b, 0x5b, 0x62,
+	0xef, 0x6e, 0x43, 0xbb, 0x2c, 0x7b, 0xce, 0x4b, 0xfd, 0x5f, 0xfa, 0x6b, 0xd7, 0x5e, 0xeb, 0xe8,
+	0x8a, 0xa1, 0x48, 0xad, 0xaf, 0xbf, 0x2d, 0x1b, 0x5d, 0x6c, 0x7d, 0x5e, 0x88, 0x26, 0x77, 0xd0,
+	0x7b, 0xce, 0x1b, 0x3b, 0x00, 0x7b, 0xfb, 0xc9, 0xa3, 0x8d, 0x1e, 0x6f, 0x84, 0x38, 0xb6, 0xff,
+	0xa8, 0xd7, 0xa9, 0xb8, 0xcf, 0xa1, 0xb5, 0x3b, 0xc9, 0x0e, 0xa9, 0x2a, 0xef, 0xcb, 0x6f, 0x9e,
+	0x71, 0xe2, 0xcd, 0x93, 0xed, 0x8b, 0x59, 0xb4, 0x2f, 0x57, 0xc0, 0xde, 0xa7, 0x07, 0x49, 0x26,
+	0x9b, 0x5e, 0x4f, 0x42, 0xee, 0x16, 0xb4, 0x9e, 0xf9, 0x79, 0x70, 0xa4, 0x24, 0x73, 0xff, 0x44,
+	0x71, 0xa0, 0x3a, 0x07, 0x01, 0x90, 0x95, 0xf2, 0xfd, 0x14, 0x95, 0x9e, 0x8e, 0x72, 0xff, 0x64,
+	0x42, 0xb5, 0xf7, 0x9a, 0xc6, 0x38, 0x55, 0x62, 0xf4, 0x95, 0x5c, 0xcf, 0x3f, 0xf9, 0xc4, 0x0e,
+	0x87, 0x0b, 0xc2, 0x32, 0xe2, 0x5a, 0x23, 0xaf, 0x3e, 0x52, 0x78, 0x5b, 0x09, 0xf5, 0x3e, 0x34,
+	0x8a, 0x08, 0xb6, 0x44, 0x86, 0xca, 0xb4, 0xe8, 0x15, 0xdf, 0x4e, 0x55, 0x8b, 0xde, 0x72, 0x8b,
+	0xc4, 0x03, 0x93, 0xa7, 0x37, 0x96, 0xfb, 0xe3, 0x54, 0xcd, 0xca, 0xa6, 0x88, 0x52, 0xff, 0x56,
+	0x2b, 0xf7, 0x6f, 0x78, 0xe1, 0x78, 0xf5, 0x2d, 0x1e, 0x5a, 0xfc, 0x76, 0xd7, 0xa5, 0x0f, 0x01,
+	0xec, 0x4d, 0xaf, 0xb7, 0x3e, 0xe8, 0x75, 0x16, 0xf8, 0xf7, 0x93, 0xdd, 0x7b, 0xfc, 0x1b, 0x1d,
+	0x79, 0xaf, 0xf7, 0xb0, 0x87, 0x4d, 0x3a, 0x80, 0xdd, 0x7b, 0xbe, 0xdb, 0xf7, 0x7a, 0x9d, 0x0a,
+	0x69, 0x40, 0xd5, 0xeb, 0xed, 0xf5, 0x06, 0x1d, 0xcb, 0xfd, 0xb3, 0x01, 0xcd, 0xf5, 0x2c, 0xf3,
+	0x8f, 0x1f, 0x50, 0x3f, 0xa4, 0x19, 0xb9, 0x0d, 0xf6, 0x41, 0x92, 0x8d, 0xfd, 0x5c, 0xf6, 0xe5,
+	0x57, 0xf1, 0x14, 0x1a, 0xc7, 0xad, 0x2d, 0x24, 0x7b, 0x92, 0xed, 0xad, 0xd5, 0xa1, 0x4a, 0x14,
+	0x95, 0x72, 0xee, 0x91, 0x73, 0x47, 0x7e, 0x46, 0xfc, 0x76, 0xbf, 0x00, 0x5b, 0x48, 0xc6, 0xde,
+	0x7b, 0xf7, 0x85, 0xe8, 0xca, 0xb7, 0x77, 0x7f, 0xec, 0x18, 0x64, 0x09, 0x9a, 0x7b, 0xeb, 0x5b,
+	0xbd, 0x41, 0x6f, 0x7b, 0x6f, 0xc7, 0xdb, 0xeb, 0x98, 0xee, 0x6f, 0x00, 0x50, 0xa5, 0xcd, 0xa3,
+	0x49, 0xfc, 0x92, 0xac, 0x82, 0x7d, 0x84, 0xba, 0xa1, 0xce, 0x4d, 0x19, 0xf7, 0x9a, 0xce, 0x9e,
+	0xa4, 0x6b, 0xa3, 0xd1, 0x62, 0x3e, 0xa4, 0x94, 0x91, 0x33, 0x23, 0x54, 0xe6, 0x15, 0xb4, 0xfb,
+	0xe3, 0x34, 0xc9, 0xf2, 0x0b, 0x95, 0xf6, 0x8e, 0xde, 0x7d, 0x95, 0xf2, 0xe0, 0x35, 0xa8, 0x1f,
+	0x44, 0x19, 0xcb, 0x8b, 0xf8, 0xa9, 0x21, 0xdc, 0x0f, 0xdd, 0x07, 0xd0, 0xda, 0x3c, 0xa2, 0xc1,
+	0xcb, 0xf3, 0x5e, 0x2f, 0xdc, 0x64, 0x7f, 0x12, 0x8d, 0x44, 0x57, 0x52, 0xf7, 0x14, 0xe8, 0xfe,
+	0x1a, 0xa0, 0x1f, 0x87, 0xf4, 0x8d, 0xc8, 0x12, 0xcb, 0x50, 0x8d, 0x38, 0x24, 0x45, 0x08, 0x60,
+	0x7a, 0x68, 0x73, 0x5e, 0xad, 0x5c, 0xd1, 0x6b, 0x65, 0xd1, 0x80, 0x58, 0xaa, 0x01, 0x71, 0xff,
+	0x69, 0xc0, 0xa2, 0x54, 0xf4, 0x3f, 0x6a, 0x1a, 0x07, 0x6a, 0x34, 0xce, 0xb3, 0x08, 0xd3, 0x2a,
+	0x52, 0x24, 0x48, 0x3e, 0x85, 0x2a, 0xcb, 0xf9, 0x00, 0xaf, 0xaa, 0x3d, 0xc9, 0xc5, 0x09, 0x3d,
+	0x41, 0xe5, 0x45, 0x3d, 0x2f, 0x42, 0xa2, 0xf8, 0xd0, 0xb1, 0xe7, 0x33, 0x2a, 0x3a, 0xb9, 0x0d,
+	0x10, 0x4e, 0xd2, 0x51, 0x14, 0xf0, 0x19, 0xb5, 0x53, 0x9b, 0xcf, 0xad, 0xb1, 0xb8, 0x0c, 0x88,
+	0x56, 0x03, 0x5c, 0xe4, 0xe0, 0x33, 0xd5, 0x45, 0xe5, 0xed, 0xd5, 0x85, 0xfb, 0x07, 0x13, 0xda,
+	0x7b, 0xb1, 0x9f, 0xb2, 0xa3, 0x24, 0x97, 0xb7, 0x73, 0xa6, 0x35, 0x58, 0x2c, 0x5a, 0x03, 0x07,
+	0x6a, 0x41, 0x46, 0xf1, 0x3c, 0x26, 0xa6, 0x15, 0x05, 0x9e, 0xf3, 0xed, 0x55, 0x5c, 0x45, 0x8b,
+	0x6d, 0x15, 0x5c, 0x3b, 0xaa, 0xcd, 0xd6, 0xdc, 0x57, 0x3d, 0xb5, 0x16, 0xb6, 0xcb, 0xb5, 0x70,
+	0xf1, 0xaa, 0xd7, 0xce, 0xf5, 0xaa, 0xd7, 0x4b, 0xaf, 0xfa, 0x6c, 0xda, 0x6f, 0xc8, 0x02, 0x53,
+	0xb3, 0xd3, 0x0d, 0x58, 0x54, 0x66, 0x12, 0xf9, 0x40, 0xdd, 0x72, 0xa3, 0xb8, 0xe5, 0xee, 0x6f,
+	0x61, 0xc9, 0xa3, 0x2c, 0x4f, 0xb2, 0x8b, 0x4d, 0x2a, 0xbe, 0x98, 0xa6, 0x18, 0xd1, 0xaf, 0x5f,
+	0x16, 0x87, 0x28, 0x79, 0x47, 0x65, 0x19, 0xb7, 0x06, 0xd5, 0xde, 0x38, 0xcd, 0x8f, 0xd7, 0xfe,
+	0xd5, 0x04, 0xd8, 0x9b, 0x8c, 0x79, 0x6f, 0x17, 0x05, 0x94, 0xac, 0x41, 0x6b, 0x13, 0x9d, 0x22,
+	0x7f, 0xb8, 0xd1, 0x5f, 0x88, 0xee, 0x65, 0x0d, 0x50, 0x2a, 0xba, 0x0b, 0x7c, 0xcd, 0x13, 0xfc,
+	0x2d, 0xe5, 0x1d, 0xd6, 0xdc, 0x02, 0xf0, 0xa8, 0x1f, 0xca, 0x15, 0x62, 0xb4, 0xc0, 0x87, 0x09,
+	0xa7, 0xf1, 0xdf, 0x55, 0x73, 0x3b, 0xe1, 0x4a, 0x91, 0x3e, 0xb5, 0x49, 0x5e, 0xf7, 0xaa, 0xb6,
+	0x4e, 0x1f, 0x7b, 0xb9, 0x0b, 0xe4, 0x4b, 0x68, 0xdd, 0xc3, 0x1f, 0x50, 0xce, 0xbd, 0xdb, 0x6d,
+	0x68, 0x8a, 0xb9, 0x96, 0xd8, 0xad, 0x29, 0x17, 0xf0, 0xe2, 0xa6, 0x2b, 0xa6, 0xc0, 0xfa, 0xd8,
+	0xcb, 0x5d, 0x20, 0xff, 0x0b, 0x4b, 0xc5, 0x71, 0xc4, 0x90, 0x02, 0xe4, 0x22, 0xfe, 0x3b, 0xc9,
+	0x59, 0x86, 0x63, 0x34, 0xcb, 0xdf, 0xcd, 0xd8, 0xc2, 0x41, 0x72, 0x2a, 0xa6, 0xcf, 0xa3, 0xba,
+	0x97, 0x35, 0x60, 0x9e, 0x83, 0xde, 0x61, 0x8d, 0x74, 0x90, 0x5c, 0x71, 0xc2, 0x64, 0x27, 0xf8,
+	0xa5, 0x83, 0x76, 0xe4, 0x8d, 0x3a, 0xcd, 0x41, 0x27, 0xe7, 0x92, 0xe8, 0x20, 0xe0, 0xf6, 0x2c,
+	0x69, 0x27, 0x06, 0x32, 0xa7, 0xed, 0x36, 0x75, 0xe9, 0xb9, 0xf5, 0xfb, 0x14, 0x2a, 0xde, 0x24,
+	0x96, 0x8c, 0x7c, 0x22, 0xd6, 0xbd, 0x34, 0xfd, 0x2c, 0xb1, 0x59, 0x38, 0xe0, 0x10, 0xde, 0xc3,
+	0x2b, 0xd2, 0x9d, 0xfd, 0xed, 0x14, 0xad, 0x53, 0x57, 0x17, 0xab, 0xc4, 0x4a, 0x4a, 0x77, 0x0e,
+	0xaf, 0xba, 0xbb, 0xf0, 0xa5, 0x41, 0xbe, 0x85, 0x9a, 0xbc, 0xda, 0x64, 0x0e, 0x4b, 0x77, 0x59,
+	0xfa, 0xba, 0x74, 0xf9, 0xdd, 0x85, 0x55, 0x83, 0xdc, 0x85, 0x8e, 0x70, 0xb7, 0xd6, 0xd7, 0x94,
+	0x0c, 0x74, 0x75, 0x36, 0x3f, 0x17, 0x67, 0xb9, 0x03, 0xed, 0x7b, 0x59, 0x92, 0x5e, 0x68, 0xe5,
+	0x12, 0x77, 0xd1, 0xa6, 0xd6, 0x47, 0xea, 0xa7, 0x3c, 0x63, 0xe5, 0xb7, 0xd0, 0xd8, 0xa3, 0xb9,
+	0x6c, 0x71, 0x66, 0xdf, 0x8e, 0xb3, 0x16, 0xde, 0x84, 0x2a, 0x16, 0xd8, 0x44, 0xb8, 0x45, 0x2f,
+	0xb6, 0xbb, 0x50, 0x94, 0xc2, 0x68, 0xcd, 0x3b, 0xd0, 0xe0, 0xea, 0x61, 0x53, 0xfa, 0x6e, 0xa9,
+	0xe0, 0x2b, 0xa8, 0x3f, 0x89, 0xc5, 0xaf, 0xa9, 0xa4, 0xad, 0xb1, 0xf5, 0x43, 0x76, 0xda, 0x85,
+	0xfb, 0x06, 0x00, 0x7b, 0x0a, 0xb1, 0x9b, 0xd0, 0x4e, 0x6f, 0x32, 0x4e, 0xbf, 0xa8, 0xb6, 0xa8,
+	0xcf, 0xc8, 0x52, 0x51, 0xeb, 0x09, 0x77, 0x8b, 0x15, 0xe5, 0xea, 0x0d, 0xbd, 0x7d, 0x1b, 0xec,
+	0xde, 0x1b, 0x5c, 0x73, 0xa2, 0x3e, 0xec, 0xce, 0x4a, 0x91, 0x71, 0x25, 0x2a, 0x32, 0xac, 0x09,
+	0x28, 0x93, 0xea, 0xe9, 0x45, 0x5a, 0x97, 0xe8, 0x28, 0xb5, 0xd7, 0xda, 0xcf, 0x06, 0x90, 0xbd,
+	0xc9, 0xb8, 0x1f, 0xe7, 0x34, 0x8b, 0xfd, 0x91, 0x4a, 0xff, 0x77, 0x80, 0xe8, 0xe9, 0xff, 0x59,
+	0x94, 0x1f, 0xf5, 0xcf, 0x97, 0x97, 0xee, 0xc2, 0x65, 0x7d, 0x25, 0x93, 0x4b, 0x5b, 0x1a, 0xf7,
+	0x19, 0x26, 0x5e, 0xd4, 0x13, 0x34, 0x3b, 0xa7, 0x6b, 0xd6, 0x7e, 0x67, 0x40, 0x67, 0x6f, 0x32,
+	0x7e, 0xe4, 0xb3, 0x9c, 0x66, 0xea, 0x08, 0x5f, 0x40, 0x6d, 0x3d, 0x0c, 0xf1, 0xdf, 0x24, 0x54,
+	0xb8, 0xf3, 0xe1, 0xad, 0xbc, 0xee, 0xfa, 0x7f, 0x3b, 0xb8, 0x0b, 0xe4, 0xbf, 0x45, 0x24, 0x71,
+	0x6c, 0x39, 0xc4, 0x4f, 0xe1, 0x06, 0xa1, 0x27, 0x4a, 0xd7, 0x72, 0xce, 0x3c, 0xee, 0x7d, 0x1b,
+	0xff, 0x71, 0xe3, 0xeb, 0x7f, 0x0f, 0x00, 0xa9, 0x36, 0x48, 0x82, 0xcb, 0x21, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1344,6 +3490,10 @@ type SumServiceClient interface {
 	DeleteRecord(ctx context.Context, in *ById, opts ...grpc.CallOption) (*RecordResponse, error)
 	// find a vector given a meta name and value to filter for
 	FindRecords(ctx context.Context, in *ByMeta, opts ...grpc.CallOption) (*FindResponse, error)
+	// read a record given its unique key
+	ReadRecordByKey(ctx context.Context, in *ByKey, opts ...grpc.CallOption) (*RecordResponse, error)
+	// update the record with the same key, or create it if there is none
+	UpsertRecord(ctx context.Context, in *Record, opts ...grpc.CallOption) (*RecordResponse, error)
 	// oracles CRUD
 	CreateOracle(ctx context.Context, in *Oracle, opts ...grpc.CallOption) (*OracleResponse, error)
 	UpdateOracle(ctx context.Context, in *Oracle, opts ...grpc.CallOption) (*OracleResponse, error)
@@ -1355,6 +3505,28 @@ type SumServiceClient interface {
 	Run(ctx context.Context, in *Call, opts ...grpc.CallOption) (*CallResponse, error)
 	// get info about the service
 	Info(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ServerInfo, error)
+	// stream a consistent snapshot of the records and oracles
+	Snapshot(ctx context.Context, in *Empty, opts ...grpc.CallOption) (SumService_SnapshotClient, error)
+	// replace records and oracles with the ones of a snapshot
+	Restore(ctx context.Context, opts ...grpc.CallOption) (SumService_RestoreClient, error)
+	// named collections of records
+	CreateCollection(ctx context.Context, in *ByName, opts ...grpc.CallOption) (*CollectionResponse, error)
+	DropCollection(ctx context.Context, in *ByName, opts ...grpc.CallOption) (*CollectionResponse, error)
+	ListCollections(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CollectionResponse, error)
+	// set or remove the schema of a collection, the default one if the name is empty
+	SetSchema(ctx context.Context, in *Collection, opts ...grpc.CallOption) (*CollectionResponse, error)
+	// stream the changes of the records as they happen
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (SumService_WatchClient, error)
+	// deleted records are kept in the trash until purged or for the retention period
+	ListTrash(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*RecordListResponse, error)
+	Undelete(ctx context.Context, in *RecordIds, opts ...grpc.CallOption) (*RecordResponse, error)
+	PurgeTrash(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*RecordResponse, error)
+	// create records from the rows of a NumPy or safetensors array
+	Import(ctx context.Context, opts ...grpc.CallOption) (SumService_ImportClient, error)
+	// stream the records of a collection as a NumPy or safetensors array
+	Export(ctx context.Context, in *ArrayHeader, opts ...grpc.CallOption) (SumService_ExportClient, error)
+	// check the meta data indexes of a collection against its records and optionally rebuild them
+	CheckIndexes(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error)
 }
 
 type sumServiceClient struct {
@@ -1419,6 +3591,24 @@ func (c *sumServiceClient) FindRecords(ctx context.Context, in *ByMeta, opts ...
 	return out, nil
 }
 
+func (c *sumServiceClient) ReadRecordByKey(ctx context.Context, in *ByKey, opts ...grpc.CallOption) (*RecordResponse, error) {
+	out := new(RecordResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/ReadRecordByKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) UpsertRecord(ctx context.Context, in *Record, opts ...grpc.CallOption) (*RecordResponse, error) {
+	out := new(RecordResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/UpsertRecord", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *sumServiceClient) CreateOracle(ctx context.Context, in *Oracle, opts ...grpc.CallOption) (*OracleResponse, error) {
 	out := new(OracleResponse)
 	err := c.cc.Invoke(ctx, "/sum.SumService/CreateOracle", in, out, opts...)
@@ -1461,30 +3651,266 @@ func (c *sumServiceClient) FindOracle(ctx context.Context, in *ByName, opts ...g
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	return out, nil
+}
+
+func (c *sumServiceClient) DeleteOracle(ctx context.Context, in *ById, opts ...grpc.CallOption) (*OracleResponse, error) {
+	out := new(OracleResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/DeleteOracle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) Run(ctx context.Context, in *Call, opts ...grpc.CallOption) (*CallResponse, error) {
+	out := new(CallResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/Run", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) Info(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ServerInfo, error) {
+	out := new(ServerInfo)
+	err := c.cc.Invoke(ctx, "/sum.SumService/Info", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) Snapshot(ctx context.Context, in *Empty, opts ...grpc.CallOption) (SumService_SnapshotClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SumService_serviceDesc.Streams[0], "/sum.SumService/Snapshot", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sumServiceSnapshotClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SumService_SnapshotClient interface {
+	Recv() (*SnapshotChunk, error)
+	grpc.ClientStream
+}
+
+type sumServiceSnapshotClient struct {
+	grpc.ClientStream
+}
+
+func (x *sumServiceSnapshotClient) Recv() (*SnapshotChunk, error) {
+	m := new(SnapshotChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sumServiceClient) Restore(ctx context.Context, opts ...grpc.CallOption) (SumService_RestoreClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SumService_serviceDesc.Streams[1], "/sum.SumService/Restore", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sumServiceRestoreClient{stream}
+	return x, nil
+}
+
+type SumService_RestoreClient interface {
+	Send(*SnapshotChunk) error
+	CloseAndRecv() (*RestoreResponse, error)
+	grpc.ClientStream
+}
+
+type sumServiceRestoreClient struct {
+	grpc.ClientStream
+}
+
+func (x *sumServiceRestoreClient) Send(m *SnapshotChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *sumServiceRestoreClient) CloseAndRecv() (*RestoreResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(RestoreResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sumServiceClient) CreateCollection(ctx context.Context, in *ByName, opts ...grpc.CallOption) (*CollectionResponse, error) {
+	out := new(CollectionResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/CreateCollection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) DropCollection(ctx context.Context, in *ByName, opts ...grpc.CallOption) (*CollectionResponse, error) {
+	out := new(CollectionResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/DropCollection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) ListCollections(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CollectionResponse, error) {
+	out := new(CollectionResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/ListCollections", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) SetSchema(ctx context.Context, in *Collection, opts ...grpc.CallOption) (*CollectionResponse, error) {
+	out := new(CollectionResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/SetSchema", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (SumService_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SumService_serviceDesc.Streams[2], "/sum.SumService/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sumServiceWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SumService_WatchClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type sumServiceWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *sumServiceWatchClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sumServiceClient) ListTrash(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*RecordListResponse, error) {
+	out := new(RecordListResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/ListTrash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) Undelete(ctx context.Context, in *RecordIds, opts ...grpc.CallOption) (*RecordResponse, error) {
+	out := new(RecordResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/Undelete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) PurgeTrash(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*RecordResponse, error) {
+	out := new(RecordResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/PurgeTrash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sumServiceClient) Import(ctx context.Context, opts ...grpc.CallOption) (SumService_ImportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SumService_serviceDesc.Streams[3], "/sum.SumService/Import", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sumServiceImportClient{stream}
+	return x, nil
+}
+
+type SumService_ImportClient interface {
+	Send(*ArrayChunk) error
+	CloseAndRecv() (*ImportResponse, error)
+	grpc.ClientStream
+}
+
+type sumServiceImportClient struct {
+	grpc.ClientStream
+}
+
+func (x *sumServiceImportClient) Send(m *ArrayChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *sumServiceImportClient) CloseAndRecv() (*ImportResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-func (c *sumServiceClient) DeleteOracle(ctx context.Context, in *ById, opts ...grpc.CallOption) (*OracleResponse, error) {
-	out := new(OracleResponse)
-	err := c.cc.Invoke(ctx, "/sum.SumService/DeleteOracle", in, out, opts...)
+func (c *sumServiceClient) Export(ctx context.Context, in *ArrayHeader, opts ...grpc.CallOption) (SumService_ExportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SumService_serviceDesc.Streams[4], "/sum.SumService/Export", opts...)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	x := &sumServiceExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-func (c *sumServiceClient) Run(ctx context.Context, in *Call, opts ...grpc.CallOption) (*CallResponse, error) {
-	out := new(CallResponse)
-	err := c.cc.Invoke(ctx, "/sum.SumService/Run", in, out, opts...)
-	if err != nil {
+type SumService_ExportClient interface {
+	Recv() (*ArrayChunk, error)
+	grpc.ClientStream
+}
+
+type sumServiceExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *sumServiceExportClient) Recv() (*ArrayChunk, error) {
+	m := new(ArrayChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
-	return out, nil
+	return m, nil
 }
 
-func (c *sumServiceClient) Info(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ServerInfo, error) {
-	out := new(ServerInfo)
-	err := c.cc.Invoke(ctx, "/sum.SumService/Info", in, out, opts...)
+func (c *sumServiceClient) CheckIndexes(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error) {
+	out := new(CheckResponse)
+	err := c.cc.Invoke(ctx, "/sum.SumService/CheckIndexes", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1501,6 +3927,10 @@ type SumServiceServer interface {
 	DeleteRecord(context.Context, *ById) (*RecordResponse, error)
 	// find a vector given a meta name and value to filter for
 	FindRecords(context.Context, *ByMeta) (*FindResponse, error)
+	// read a record given its unique key
+	ReadRecordByKey(context.Context, *ByKey) (*RecordResponse, error)
+	// update the record with the same key, or create it if there is none
+	UpsertRecord(context.Context, *Record) (*RecordResponse, error)
 	// oracles CRUD
 	CreateOracle(context.Context, *Oracle) (*OracleResponse, error)
 	UpdateOracle(context.Context, *Oracle) (*OracleResponse, error)
@@ -1512,6 +3942,28 @@ type SumServiceServer interface {
 	Run(context.Context, *Call) (*CallResponse, error)
 	// get info about the service
 	Info(context.Context, *Empty) (*ServerInfo, error)
+	// stream a consistent snapshot of the records and oracles
+	Snapshot(*Empty, SumService_SnapshotServer) error
+	// replace records and oracles with the ones of a snapshot
+	Restore(SumService_RestoreServer) error
+	// named collections of records
+	CreateCollection(context.Context, *ByName) (*CollectionResponse, error)
+	DropCollection(context.Context, *ByName) (*CollectionResponse, error)
+	ListCollections(context.Context, *Empty) (*CollectionResponse, error)
+	// set or remove the schema of a collection, the default one if the name is empty
+	SetSchema(context.Context, *Collection) (*CollectionResponse, error)
+	// stream the changes of the records as they happen
+	Watch(*WatchRequest, SumService_WatchServer) error
+	// deleted records are kept in the trash until purged or for the retention period
+	ListTrash(context.Context, *ListRequest) (*RecordListResponse, error)
+	Undelete(context.Context, *RecordIds) (*RecordResponse, error)
+	PurgeTrash(context.Context, *PurgeRequest) (*RecordResponse, error)
+	// create records from the rows of a NumPy or safetensors array
+	Import(SumService_ImportServer) error
+	// stream the records of a collection as a NumPy or safetensors array
+	Export(*ArrayHeader, SumService_ExportServer) error
+	// check the meta data indexes of a collection against its records and optionally rebuild them
+	CheckIndexes(context.Context, *CheckRequest) (*CheckResponse, error)
 }
 
 // UnimplementedSumServiceServer can be embedded to have forward compatible implementations.
@@ -1536,6 +3988,12 @@ func (*UnimplementedSumServiceServer) DeleteRecord(ctx context.Context, req *ByI
 func (*UnimplementedSumServiceServer) FindRecords(ctx context.Context, req *ByMeta) (*FindResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FindRecords not implemented")
 }
+func (*UnimplementedSumServiceServer) ReadRecordByKey(ctx context.Context, req *ByKey) (*RecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadRecordByKey not implemented")
+}
+func (*UnimplementedSumServiceServer) UpsertRecord(ctx context.Context, req *Record) (*RecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertRecord not implemented")
+}
 func (*UnimplementedSumServiceServer) CreateOracle(ctx context.Context, req *Oracle) (*OracleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateOracle not implemented")
 }
@@ -1560,6 +4018,45 @@ func (*UnimplementedSumServiceServer) Run(ctx context.Context, req *Call) (*Call
 func (*UnimplementedSumServiceServer) Info(ctx context.Context, req *Empty) (*ServerInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Info not implemented")
 }
+func (*UnimplementedSumServiceServer) Snapshot(req *Empty, srv SumService_SnapshotServer) error {
+	return status.Errorf(codes.Unimplemented, "method Snapshot not implemented")
+}
+func (*UnimplementedSumServiceServer) Restore(srv SumService_RestoreServer) error {
+	return status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (*UnimplementedSumServiceServer) CreateCollection(ctx context.Context, req *ByName) (*CollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCollection not implemented")
+}
+func (*UnimplementedSumServiceServer) DropCollection(ctx context.Context, req *ByName) (*CollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropCollection not implemented")
+}
+func (*UnimplementedSumServiceServer) ListCollections(ctx context.Context, req *Empty) (*CollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCollections not implemented")
+}
+func (*UnimplementedSumServiceServer) SetSchema(ctx context.Context, req *Collection) (*CollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSchema not implemented")
+}
+func (*UnimplementedSumServiceServer) Watch(req *WatchRequest, srv SumService_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (*UnimplementedSumServiceServer) ListTrash(ctx context.Context, req *ListRequest) (*RecordListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (*UnimplementedSumServiceServer) Undelete(ctx context.Context, req *RecordIds) (*RecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Undelete not implemented")
+}
+func (*UnimplementedSumServiceServer) PurgeTrash(ctx context.Context, req *PurgeRequest) (*RecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeTrash not implemented")
+}
+func (*UnimplementedSumServiceServer) Import(srv SumService_ImportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
+func (*UnimplementedSumServiceServer) Export(req *ArrayHeader, srv SumService_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (*UnimplementedSumServiceServer) CheckIndexes(ctx context.Context, req *CheckRequest) (*CheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckIndexes not implemented")
+}
 
 func RegisterSumServiceServer(s *grpc.Server, srv SumServiceServer) {
 	s.RegisterService(&_SumService_serviceDesc, srv)
@@ -1673,6 +4170,42 @@ func _SumService_FindRecords_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SumService_ReadRecordByKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ByKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SumServiceServer).ReadRecordByKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sum.SumService/ReadRecordByKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SumServiceServer).ReadRecordByKey(ctx, req.(*ByKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SumService_UpsertRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Record)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SumServiceServer).UpsertRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sum.SumService/UpsertRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SumServiceServer).UpsertRecord(ctx, req.(*Record))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SumService_CreateOracle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Oracle)
 	if err := dec(in); err != nil {
@@ -1817,6 +4350,265 @@ func _SumService_Info_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SumService_Snapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SumServiceServer).Snapshot(m, &sumServiceSnapshotServer{stream})
+}
+
+type SumService_SnapshotServer interface {
+	Send(*SnapshotChunk) error
+	grpc.ServerStream
+}
+
+type sumServiceSnapshotServer struct {
+	grpc.ServerStream
+}
+
+func (x *sumServiceSnapshotServer) Send(m *SnapshotChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SumService_Restore_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SumServiceServer).Restore(&sumServiceRestoreServer{stream})
+}
+
+type SumService_RestoreServer interface {
+	SendAndClose(*RestoreResponse) error
+	Recv() (*SnapshotChunk, error)
+	grpc.ServerStream
+}
+
+type sumServiceRestoreServer struct {
+	grpc.ServerStream
+}
+
+func (x *sumServiceRestoreServer) SendAndClose(m *RestoreResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *sumServiceRestoreServer) Recv() (*SnapshotChunk, error) {
+	m := new(SnapshotChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _SumService_CreateCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ByName)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SumServiceServer).CreateCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sum.SumService/CreateCollection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SumServiceServer).CreateCollection(ctx, req.(*ByName))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SumService_DropCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ByName)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SumServiceServer).DropCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sum.SumService/DropCollection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SumServiceServer).DropCollection(ctx, req.(*ByName))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SumService_ListCollections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SumServiceServer).ListCollections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sum.SumService/ListCollections",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SumServiceServer).ListCollections(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SumService_SetSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Collection)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SumServiceServer).SetSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sum.SumService/SetSchema",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SumServiceServer).SetSchema(ctx, req.(*Collection))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SumService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SumServiceServer).Watch(m, &sumServiceWatchServer{stream})
+}
+
+type SumService_WatchServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type sumServiceWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *sumServiceWatchServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SumService_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SumServiceServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sum.SumService/ListTrash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SumServiceServer).ListTrash(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SumService_Undelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordIds)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SumServiceServer).Undelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sum.SumService/Undelete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SumServiceServer).Undelete(ctx, req.(*RecordIds))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SumService_PurgeTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SumServiceServer).PurgeTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sum.SumService/PurgeTrash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SumServiceServer).PurgeTrash(ctx, req.(*PurgeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SumService_Import_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SumServiceServer).Import(&sumServiceImportServer{stream})
+}
+
+type SumService_ImportServer interface {
+	SendAndClose(*ImportResponse) error
+	Recv() (*ArrayChunk, error)
+	grpc.ServerStream
+}
+
+type sumServiceImportServer struct {
+	grpc.ServerStream
+}
+
+func (x *sumServiceImportServer) SendAndClose(m *ImportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *sumServiceImportServer) Recv() (*ArrayChunk, error) {
+	m := new(ArrayChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _SumService_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ArrayHeader)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SumServiceServer).Export(m, &sumServiceExportServer{stream})
+}
+
+type SumService_ExportServer interface {
+	Send(*ArrayChunk) error
+	grpc.ServerStream
+}
+
+type sumServiceExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *sumServiceExportServer) Send(m *ArrayChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SumService_CheckIndexes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SumServiceServer).CheckIndexes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sum.SumService/CheckIndexes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SumServiceServer).CheckIndexes(ctx, req.(*CheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _SumService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "sum.SumService",
 	HandlerType: (*SumServiceServer)(nil),
@@ -1845,6 +4637,14 @@ var _SumService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "FindRecords",
 			Handler:    _SumService_FindRecords_Handler,
 		},
+		{
+			MethodName: "ReadRecordByKey",
+			Handler:    _SumService_ReadRecordByKey_Handler,
+		},
+		{
+			MethodName: "UpsertRecord",
+			Handler:    _SumService_UpsertRecord_Handler,
+		},
 		{
 			MethodName: "CreateOracle",
 			Handler:    _SumService_CreateOracle_Handler,
@@ -1877,8 +4677,66 @@ var _SumService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Info",
 			Handler:    _SumService_Info_Handler,
 		},
+		{
+			MethodName: "CreateCollection",
+			Handler:    _SumService_CreateCollection_Handler,
+		},
+		{
+			MethodName: "DropCollection",
+			Handler:    _SumService_DropCollection_Handler,
+		},
+		{
+			MethodName: "ListCollections",
+			Handler:    _SumService_ListCollections_Handler,
+		},
+		{
+			MethodName: "SetSchema",
+			Handler:    _SumService_SetSchema_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _SumService_ListTrash_Handler,
+		},
+		{
+			MethodName: "Undelete",
+			Handler:    _SumService_Undelete_Handler,
+		},
+		{
+			MethodName: "PurgeTrash",
+			Handler:    _SumService_PurgeTrash_Handler,
+		},
+		{
+			MethodName: "CheckIndexes",
+			Handler:    _SumService_CheckIndexes_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Snapshot",
+			Handler:       _SumService_Snapshot_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Restore",
+			Handler:       _SumService_Restore_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _SumService_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Import",
+			Handler:       _SumService_Import_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Export",
+			Handler:       _SumService_Export_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/sum.proto",
 }
 